@@ -2,18 +2,29 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/go-chi/httprate"
 	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
 	"github.com/unrolled/render"
 	"kiramishima/m-backend/config"
+	"kiramishima/m-backend/internal/adapters/cache/cached"
 	"kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/adapters/mailer"
+	"kiramishima/m-backend/internal/adapters/oidc"
 	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/adapters/storage"
+	"kiramishima/m-backend/internal/adapters/webhook"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/hasher"
 	"kiramishima/m-backend/internal/core/services"
 	"kiramishima/m-backend/internal/handlers"
+	"kiramishima/m-backend/internal/middlewares"
+	"kiramishima/m-backend/internal/queue"
+	"kiramishima/m-backend/internal/scheduler"
 	"kiramishima/m-backend/internal/server"
 
 	"time"
@@ -22,52 +33,84 @@ import (
 	"go.uber.org/zap"
 )
 
+// stopTimeout bounds the whole shutdown: HTTP_SERVER_SHUTDOWN_DELAY and
+// HTTP_SERVER_SHUTDOWN_TIMEOUT, the tasks and jobs in progress and the NATS
+// drain
+const stopTimeout = time.Minute
+
 func bootstrap(
 	lifecycle fx.Lifecycle,
 	logger *zap.SugaredLogger,
 	server *server.Server,
 ) {
+	// the server is the last hook appended, so it is the first one stopped and
+	// no new request arrives while the workers and connections are closing
 	lifecycle.Append(
 		fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				logger.Info("Starting API")
-
-				/*go func() {
-					if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-						logger.Fatal("failed to start server")
-					}
-				}()*/
-				_ = server.Run()
-
-				return nil
+				return server.Start(ctx)
 			},
+			OnStop: server.Stop,
+		},
+	)
+}
+
+// closeConnections closes the connections after every hook that uses them:
+// NATS is drained first, so the subscribers finish the messages they are
+// writing to the database, then the database is closed and the logger synced.
+// It runs in the first module of the app, its hook is stopped after the hooks
+// of the modules that come later.
+func closeConnections(lifecycle fx.Lifecycle, logger *zap.SugaredLogger, db *sqlx.DB, ps *psnats.NATSPubSub) {
+	lifecycle.Append(
+		fx.Hook{
 			OnStop: func(ctx context.Context) error {
-				return logger.Sync()
+				natsErr := ps.Drain(ctx)
+				dbErr := db.Close()
+				logger.Info("Connections closed")
+				_ = logger.Sync()
+				return errors.Join(natsErr, dbErr)
 			},
 		},
 	)
 }
 
+// allowCredentials cookies only go to the origins of the allowlist, never to "*"
+func allowCredentials(origins []string) bool {
+	if len(origins) == 0 {
+		return false
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			return false
+		}
+	}
+	return true
+}
+
 var Module = fx.Options(
 	config.Module,
 	config.LoggerModule,
-	fx.Provide(func() *chi.Mux {
+	fx.StopTimeout(stopTimeout),
+	fx.Module("connections", fx.Invoke(closeConnections)),
+	fx.Provide(func(cfg *domain.Configuration, realIP *middlewares.RealIP, csrf *middlewares.CSRF, limiter *middlewares.RateLimiter, sessions *middlewares.ActiveSession) *chi.Mux {
 		var r = chi.NewRouter()
 		r.Use(cors.Handler(cors.Options{
-			// AllowedOrigins:   []string{"*"},
-			AllowedOrigins:   []string{"*"},
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
-			AllowCredentials: true,
+			AllowedOrigins:   cfg.CORSAllowedOrigins,
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-CSRF-Token", "X-Session-Mode"},
+			ExposedHeaders:   []string{"Link", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"},
+			AllowCredentials: allowCredentials(cfg.CORSAllowedOrigins),
 			MaxAge:           300, // Maximum value not ignored by any of major browsers
 		}))
+		r.Use(csrf.Protect)
 		r.Use(middleware.Timeout(60 * time.Second))
 		r.Use(middleware.RequestID)
-		r.Use(middleware.RealIP)
+		r.Use(realIP.Handler)
 		r.Use(middleware.Recoverer)
 		r.Use(middleware.Logger)
-		r.Use(httprate.LimitByIP(1000, 1*time.Minute))
+		r.Use(limiter.Limit)
+		r.Use(sessions.Check)
 		r.Use(middleware.Compress(5))
 		return r
 	}),
@@ -79,9 +122,18 @@ var Module = fx.Options(
 	}),
 	server.Module,
 	repository.DatabaseModule,
+	cached.Module,
+	hasher.Module,
 	services.Module,
+	middlewares.Module,
 	handlers.Module,
 	redis.Module,
+	mailer.Module,
+	oidc.Module,
+	storage.Module,
 	psnats.Module,
+	webhook.Module,
+	scheduler.Module,
+	queue.Module,
 	fx.Invoke(bootstrap),
 )
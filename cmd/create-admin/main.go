@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
+	"kiramishima/m-backend/config"
+	"kiramishima/m-backend/internal/adapters/cache/cached"
+	"kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/adapters/mailer"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/hasher"
+	"kiramishima/m-backend/internal/core/services"
+	"kiramishima/m-backend/internal/queue"
+	"kiramishima/m-backend/internal/scheduler"
+	"log"
+)
+
+// create-admin grants the admin role to the first operator. If the email is
+// not registered yet, the user is created with the given password and name.
+func main() {
+	var form = &domain.RegisterRequest{}
+	flag.StringVar(&form.Email, "email", "", "admin email")
+	flag.StringVar(&form.Password, "password", "", "admin password, used only when the user doesn't exist")
+	flag.StringVar(&form.Name, "name", "", "admin username, used only when the user doesn't exist")
+	flag.Parse()
+
+	var svc *services.AuthService
+	app := fx.New(
+		config.Module,
+		config.LoggerModule,
+		repository.DatabaseModule,
+		redis.Module,
+		cached.Module,
+		mailer.Module,
+		psnats.Module,
+		hasher.Module,
+		scheduler.Module,
+		queue.Module,
+		services.Module,
+		fx.NopLogger,
+		fx.Populate(&svc),
+	)
+	if err := app.Err(); err != nil {
+		log.Fatalf("failed to initialize: %s", err)
+	}
+
+	if err := form.Validate(validator.New(validator.WithRequiredStructEnabled())); err != nil {
+		log.Fatalf("invalid arguments: %s", err)
+	}
+
+	if err := svc.CreateAdmin(context.Background(), form); err != nil {
+		log.Fatalf("failed to create admin: %s", err)
+	}
+
+	log.Printf("%s is now an admin", form.Email)
+}
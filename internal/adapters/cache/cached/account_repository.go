@@ -0,0 +1,31 @@
+package cached
+
+import (
+	"context"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+)
+
+var _ rPort.AccountRepository = (*AccountRepository)(nil)
+
+// AccountRepository deletes the cached bonds and market bonds of a user when
+// the account is deleted, Anonymize delists and deletes them in SQL
+type AccountRepository struct {
+	rPort.AccountRepository
+	cache svcport.Cache
+}
+
+// NewAccountRepository Creates a new instance of the cached AccountRepository
+func NewAccountRepository(repo rPort.AccountRepository, cache svcport.Cache) *AccountRepository {
+	return &AccountRepository{
+		AccountRepository: repo,
+		cache:             cache,
+	}
+}
+
+// Anonymize anonymizes the user and deletes the entries of its bonds
+func (repo *AccountRepository) Anonymize(ctx context.Context, uid int) error {
+	err := repo.AccountRepository.Anonymize(ctx, uid)
+	invalidate(ctx, repo.cache, "account", []string{marketListKey}, ownerTag(uid))
+	return err
+}
@@ -0,0 +1,98 @@
+package cached
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var _ rPort.BondRepository = (*BondRepository)(nil)
+
+// BondRepository caches GetBondByID, the writes of a bond delete its entry, the
+// market bonds of the bond and the market listing, where the bond is shown too
+type BondRepository struct {
+	rPort.BondRepository
+	cache svcport.Cache
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+// NewBondRepository Creates a new instance of the cached BondRepository
+func NewBondRepository(repo rPort.BondRepository, cache svcport.Cache, ttl time.Duration) *BondRepository {
+	return &BondRepository{
+		BondRepository: repo,
+		cache:          cache,
+		ttl:            ttl,
+	}
+}
+
+// GetBondByID reads the bond from the cache
+func (repo *BondRepository) GetBondByID(ctx context.Context, bond_id int) (*domain.Bond, error) {
+	return readThrough(ctx, repo.cache, &repo.group, "bond", bondKey(bond_id), repo.ttl, func(ctx context.Context) (*domain.Bond, error) {
+		return repo.BondRepository.GetBondByID(ctx, bond_id)
+	}, bondTags, cloneBond)
+}
+
+// UpdateBond updates the bond and deletes its entry
+func (repo *BondRepository) UpdateBond(ctx context.Context, udata *domain.Bond) error {
+	tags := repo.tagsOf(ctx, udata.ID)
+	err := repo.BondRepository.UpdateBond(ctx, udata)
+	repo.invalidate(ctx, udata.ID, tags)
+	return err
+}
+
+// DeleteBond deletes the bond and its entry
+func (repo *BondRepository) DeleteBond(ctx context.Context, bond_id int) error {
+	tags := repo.tagsOf(ctx, bond_id)
+	err := repo.BondRepository.DeleteBond(ctx, bond_id)
+	repo.invalidate(ctx, bond_id, tags)
+	return err
+}
+
+// SetFrozen freezes the bond and deletes its entry
+func (repo *BondRepository) SetFrozen(ctx context.Context, bond_id int, frozen bool) error {
+	tags := repo.tagsOf(ctx, bond_id)
+	err := repo.BondRepository.SetFrozen(ctx, bond_id, frozen)
+	repo.invalidate(ctx, bond_id, tags)
+	return err
+}
+
+// tagsOf reads the UUID of the bond before it changes, the market bonds are
+// tagged with it. A bond that can't be read has no tags, its market bonds
+// expire with their TTL.
+func (repo *BondRepository) tagsOf(ctx context.Context, bond_id int) []string {
+	bond, err := repo.BondRepository.GetBondByID(ctx, bond_id)
+	if err != nil {
+		return nil
+	}
+	return bondTags(bond)
+}
+
+// invalidate runs even when the write fails, it may have been applied anyway
+func (repo *BondRepository) invalidate(ctx context.Context, bond_id int, tags []string) {
+	invalidate(ctx, repo.cache, "bond", []string{bondKey(bond_id), marketListKey}, tags...)
+}
+
+func bondKey(bond_id int) string {
+	return "bond:" + strconv.Itoa(bond_id)
+}
+
+func bondTags(b *domain.Bond) []string {
+	if b == nil || b.UUID == "" {
+		return nil
+	}
+	return []string{bondTag(b.UUID), ownerTag(b.CreatedByID)}
+}
+
+func cloneBond(b *domain.Bond) *domain.Bond {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
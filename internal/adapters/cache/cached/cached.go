@@ -0,0 +1,115 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/cache/memory"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/adapters/cache/tiered"
+	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Module caching decorators of the repositories, CACHE_DRIVER selects the cache
+var Module = fx.Module("cached",
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger) (svcport.Cache, error) {
+		switch cfg.CacheDriver {
+		case "memory":
+			return memory.NewLRUCache(cfg.LRUSize)
+		case "tiered":
+			local, err := memory.NewLRUCache(cfg.LRUSize)
+			if err != nil {
+				return nil, err
+			}
+			bus, err := psnats.NewNATSPubSub(cfg.NATS_Addr, logger)
+			if err != nil {
+				return nil, err
+			}
+			c := tiered.NewTieredCache(logger, local, cache.Connect(cfg.Cache, logger), bus, cfg.InvalidationSubject, time.Duration(cfg.LocalTTL)*time.Second)
+			lc.Append(fx.Hook{
+				OnStart: c.Start,
+				OnStop: func(ctx context.Context) error {
+					return errors.Join(c.Stop(ctx), bus.Drain(ctx))
+				},
+			})
+			return c, nil
+		}
+		return cache.Connect(cfg.Cache, logger), nil
+	}),
+	fx.Provide(func(cfg *domain.Configuration, repo *repository.BondRepository, c svcport.Cache) *BondRepository {
+		return NewBondRepository(repo, c, time.Duration(cfg.EntityTTL)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, repo *repository.MarketBondRepository, c svcport.Cache) *MarketBondRepository {
+		return NewMarketBondRepository(repo, c, time.Duration(cfg.EntityTTL)*time.Second, time.Duration(cfg.ListTTL)*time.Second)
+	}),
+	fx.Provide(func(repo *repository.AccountRepository, c svcport.Cache) *AccountRepository {
+		return NewAccountRepository(repo, c)
+	}),
+)
+
+// readThrough returns the cached value of key, or loads it once for every
+// concurrent caller (singleflight) and caches it for ttl under the tags of the
+// loaded value. Errors of the cache are counted and the value comes from load. The callers that share a load get
+// a clone each, the services modify what the repositories return.
+func readThrough[T any](ctx context.Context, c svcport.Cache, group *singleflight.Group, name string, key string, ttl time.Duration, load func(ctx context.Context) (T, error), tags func(T) []string, clone func(T) T) (T, error) {
+	var value T
+	err := c.Get(ctx, key, &value)
+	if err == nil {
+		hit(name)
+		return value, nil
+	}
+	if errors.Is(err, dbErrors.ErrCacheMiss) {
+		miss(name)
+	} else {
+		cacheError(name)
+	}
+
+	v, err, shared := group.Do(key, func() (interface{}, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return loaded, err
+		}
+		if err := c.Set(ctx, key, loaded, ttl, tags(loaded)...); err != nil {
+			cacheError(name)
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	if shared {
+		return clone(v.(T)), nil
+	}
+	return v.(T), nil
+}
+
+// invalidate deletes the keys and the keys of the tags, a key that can't be
+// deleted expires with its TTL
+func invalidate(ctx context.Context, c svcport.Cache, name string, keys []string, tags ...string) {
+	if err := c.Delete(ctx, keys...); err != nil {
+		cacheError(name)
+	}
+	if err := c.InvalidateTags(ctx, tags...); err != nil {
+		cacheError(name)
+	}
+}
+
+// bondTag tags the entries that show the bond, the market bonds only know its UUID
+func bondTag(uuid string) string {
+	return "bond:" + uuid
+}
+
+// ownerTag tags the bonds and market bonds of a user, the account deletion
+// removes them all
+func ownerTag(uid int) string {
+	return "owner:" + strconv.Itoa(uid)
+}
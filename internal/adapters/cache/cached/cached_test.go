@@ -0,0 +1,340 @@
+package cached
+
+import (
+	"context"
+	"expvar"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kiramishima/m-backend/internal/adapters/cache/memory"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) (*cache.RedisCache, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr(), MaxRetries: -1})
+	return cache.NewRedisCache(client, time.Second), srv
+}
+
+func counter(name string) int64 {
+	if v, ok := stats.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestCachedGetBondByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockBondRepository(ctrl)
+	store, srv := newTestStore(t)
+	repo := NewBondRepository(inner, store, time.Minute)
+	ctx := context.Background()
+	hits, misses := counter("bond.hits"), counter("bond.misses")
+
+	inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(1).Return(&domain.Bond{ID: 7, Name: "CETES", CreatedByID: 3}, nil)
+
+	for i := 0; i < 3; i++ {
+		bond, err := repo.GetBondByID(ctx, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, "CETES", bond.Name)
+		assert.Equal(t, 3, bond.CreatedByID)
+	}
+	assert.Equal(t, misses+1, counter("bond.misses"))
+	assert.Equal(t, hits+2, counter("bond.hits"))
+
+	// The entry expires with its TTL
+	srv.FastForward(time.Minute)
+	inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(1).Return(&domain.Bond{ID: 7, Name: "BONDES"}, nil)
+	bond, err := repo.GetBondByID(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "BONDES", bond.Name)
+}
+
+func TestCachedGetBondByIDErrorsAreNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockBondRepository(ctrl)
+	store, _ := newTestStore(t)
+	repo := NewBondRepository(inner, store, time.Minute)
+
+	inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(2).Return(nil, dbErrors.ErrNoRecords)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetBondByID(context.Background(), 7)
+		assert.ErrorIs(t, err, dbErrors.ErrNoRecords)
+	}
+}
+
+func TestCachedBondWritesInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockBondRepository(ctrl)
+	store, srv := newTestStore(t)
+	repo := NewBondRepository(inner, store, time.Minute)
+	ctx := context.Background()
+
+	testCases := map[string]func() error{
+		"Update": func() error {
+			inner.EXPECT().UpdateBond(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			return repo.UpdateBond(ctx, &domain.Bond{ID: 7})
+		},
+		"Delete": func() error {
+			inner.EXPECT().DeleteBond(gomock.Any(), 7).Times(1).Return(nil)
+			return repo.DeleteBond(ctx, 7)
+		},
+		"Freeze": func() error {
+			inner.EXPECT().SetFrozen(gomock.Any(), 7, true).Times(1).Return(nil)
+			return repo.SetFrozen(ctx, 7, true)
+		},
+		"Failed Write": func() error {
+			inner.EXPECT().SetFrozen(gomock.Any(), 7, true).Times(1).Return(dbErrors.ErrBondNotExist)
+			err := repo.SetFrozen(ctx, 7, true)
+			assert.ErrorIs(t, err, dbErrors.ErrBondNotExist)
+			return nil
+		},
+	}
+
+	for name, write := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// The market bonds of the bond are found by its UUID
+			inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(1).Return(&domain.Bond{ID: 7, UUID: "u-7"}, nil)
+			assert.NoError(t, store.Set(ctx, "bond:7", domain.Bond{}, time.Minute, bondTag("u-7")))
+			assert.NoError(t, store.Set(ctx, "bond:8", domain.Bond{}, time.Minute, bondTag("u-8")))
+			assert.NoError(t, store.Set(ctx, "market_bond:5", domain.MarketBond{}, time.Minute, bondTag("u-7")))
+			assert.NoError(t, store.Set(ctx, "market_bond:6", domain.MarketBond{}, time.Minute, bondTag("u-8")))
+			assert.NoError(t, srv.Set(marketListKey, "[]"))
+
+			assert.NoError(t, write())
+			for _, key := range []string{"bond:7", "market_bond:5", marketListKey} {
+				assert.False(t, srv.Exists(key), key)
+			}
+			for _, key := range []string{"bond:8", "market_bond:6"} {
+				assert.True(t, srv.Exists(key), key)
+			}
+		})
+	}
+}
+
+func TestCachedGetBondByIDStampede(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockBondRepository(ctrl)
+	store, _ := newTestStore(t)
+	repo := NewBondRepository(inner, store, time.Minute)
+
+	release := make(chan struct{})
+	inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(1).DoAndReturn(func(ctx context.Context, id int) (*domain.Bond, error) {
+		<-release
+		return &domain.Bond{ID: id}, nil
+	})
+
+	var wg sync.WaitGroup
+	bonds := make([]*domain.Bond, 10)
+	for i := range bonds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bond, err := repo.GetBondByID(context.Background(), 7)
+			assert.NoError(t, err)
+			bonds[i] = bond
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Every caller gets its own copy, the services modify them
+	bonds[0].IsOwner = true
+	for _, bond := range bonds[1:] {
+		assert.Equal(t, 7, bond.ID)
+		assert.False(t, bond.IsOwner)
+	}
+}
+
+func TestCachedListMarketBonds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockMarketBondRepository(ctrl)
+	store, _ := newTestStore(t)
+	repo := NewMarketBondRepository(inner, store, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	// Loaded once without owner and shared by every user
+	inner.EXPECT().ListMarketBonds(gomock.Any(), 0).Times(1).Return([]*domain.MarketBond{{ID: 1, CreatedByID: 3}, {ID: 2, CreatedByID: 4}}, nil)
+
+	for _, uid := range []int{3, 4} {
+		list, err := repo.ListMarketBonds(ctx, uid)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		for _, item := range list {
+			assert.Equal(t, item.CreatedByID == uid, item.IsOwner, "user "+strconv.Itoa(uid))
+		}
+	}
+}
+
+func TestCachedMarketBondWritesInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockMarketBondRepository(ctrl)
+	store, srv := newTestStore(t)
+	repo := NewMarketBondRepository(inner, store, time.Minute, time.Minute)
+	ctx := context.Background()
+	id, num := 5, 1
+
+	testCases := map[string]struct {
+		write   func() error
+		deleted []string
+		kept    []string
+	}{
+		"Buy": {
+			write: func() error {
+				// the bond of the market bond is found by its UUID
+				inner.EXPECT().GetMarketBondByID(gomock.Any(), 5).Times(1).Return(&domain.MarketBond{ID: 5, UUID: "u-7"}, nil)
+				inner.EXPECT().BuyMarketBond(gomock.Any(), gomock.Any()).Times(1).Return(0, nil)
+				_, err := repo.BuyMarketBond(ctx, &domain.MarketBondRequest{MarketBondID: &id, Order: &num})
+				return err
+			},
+			deleted: []string{"market_bond:5", "bond:7", marketListKey},
+			kept:    []string{"market_bond:6", "bond:8"},
+		},
+		"Sell": {
+			write: func() error {
+				bondID := 7
+				inner.EXPECT().SellMarketBond(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				return repo.SellMarketBond(ctx, &domain.MarketSellRequest{BondID: &bondID, Num: &num})
+			},
+			deleted: []string{"bond:7", marketListKey},
+			kept:    []string{"market_bond:5", "market_bond:6", "bond:8"},
+		},
+		"Delist": {
+			write: func() error {
+				inner.EXPECT().GetMarketBondByID(gomock.Any(), 5).Times(1).Return(&domain.MarketBond{ID: 5, UUID: "u-7"}, nil)
+				inner.EXPECT().DelistMarketBond(gomock.Any(), 5).Times(1).Return(nil)
+				return repo.DelistMarketBond(ctx, 5)
+			},
+			deleted: []string{"market_bond:5", "bond:7", marketListKey},
+			kept:    []string{"market_bond:6", "bond:8"},
+		},
+		"Delist Unknown": {
+			write: func() error {
+				inner.EXPECT().GetMarketBondByID(gomock.Any(), 5).Times(1).Return(nil, dbErrors.ErrMarketBondNotExist)
+				inner.EXPECT().DelistMarketBond(gomock.Any(), 5).Times(1).Return(dbErrors.ErrMarketBondNotExist)
+				err := repo.DelistMarketBond(ctx, 5)
+				assert.ErrorIs(t, err, dbErrors.ErrMarketBondNotExist)
+				return nil
+			},
+			deleted: []string{"market_bond:5", marketListKey},
+			kept:    []string{"market_bond:6", "bond:7", "bond:8"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"market_bond:5", "market_bond:6", marketListKey} {
+				assert.NoError(t, srv.Set(key, "{}"))
+			}
+			assert.NoError(t, store.Set(ctx, "bond:7", domain.Bond{}, time.Minute, bondTag("u-7")))
+			assert.NoError(t, store.Set(ctx, "bond:8", domain.Bond{}, time.Minute, bondTag("u-8")))
+
+			assert.NoError(t, tc.write())
+			for _, key := range tc.deleted {
+				assert.False(t, srv.Exists(key), key)
+			}
+			for _, key := range tc.kept {
+				assert.True(t, srv.Exists(key), key)
+			}
+		})
+	}
+}
+
+func TestCachedAccountDeletionInvalidates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	innerBonds := mock.NewMockBondRepository(ctrl)
+	innerMarket := mock.NewMockMarketBondRepository(ctrl)
+	innerAccounts := mock.NewMockAccountRepository(ctrl)
+	store, srv := newTestStore(t)
+	bonds := NewBondRepository(innerBonds, store, time.Minute)
+	market := NewMarketBondRepository(innerMarket, store, time.Minute, time.Minute)
+	accounts := NewAccountRepository(innerAccounts, store)
+	ctx := context.Background()
+
+	innerBonds.EXPECT().GetBondByID(gomock.Any(), 7).Times(1).Return(&domain.Bond{ID: 7, UUID: "u-7", CreatedByID: 3}, nil)
+	innerBonds.EXPECT().GetBondByID(gomock.Any(), 8).Times(1).Return(&domain.Bond{ID: 8, UUID: "u-8", CreatedByID: 4}, nil)
+	innerMarket.EXPECT().GetMarketBondByID(gomock.Any(), 5).Times(1).Return(&domain.MarketBond{ID: 5, UUID: "u-7", CreatedByID: 3}, nil)
+	for _, id := range []int{7, 8} {
+		_, err := bonds.GetBondByID(ctx, id)
+		assert.NoError(t, err)
+	}
+	_, err := market.GetMarketBondByID(ctx, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Set(marketListKey, "[]"))
+
+	// the listings and bonds of the user are removed in SQL
+	innerAccounts.EXPECT().Anonymize(gomock.Any(), 3).Times(1).Return(nil)
+	assert.NoError(t, accounts.Anonymize(ctx, 3))
+	for _, key := range []string{"bond:7", "market_bond:5", marketListKey} {
+		assert.False(t, srv.Exists(key), key)
+	}
+	assert.True(t, srv.Exists("bond:8"))
+}
+
+func TestCachedRedisDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockMarketBondRepository(ctrl)
+	store, srv := newTestStore(t)
+	repo := NewMarketBondRepository(inner, store, time.Minute, time.Minute)
+	srv.Close()
+	errs := counter("market_bond.errors")
+
+	inner.EXPECT().GetMarketBondByID(gomock.Any(), 5).Times(1).Return(&domain.MarketBond{ID: 5}, nil)
+
+	item, err := repo.GetMarketBondByID(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, item.ID)
+	assert.Equal(t, errs+2, counter("market_bond.errors"))
+}
+
+func TestCachedMemoryCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mock.NewMockBondRepository(ctrl)
+	store, err := memory.NewLRUCache(10)
+	assert.NoError(t, err)
+	repo := NewBondRepository(inner, store, time.Minute)
+	ctx := context.Background()
+
+	inner.EXPECT().GetBondByID(gomock.Any(), 7).Times(3).Return(&domain.Bond{ID: 7, UUID: "u-7"}, nil)
+	inner.EXPECT().DeleteBond(gomock.Any(), 7).Times(1).Return(nil)
+
+	for i := 0; i < 2; i++ {
+		bond, err := repo.GetBondByID(ctx, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, "u-7", bond.UUID)
+	}
+	assert.Equal(t, 1, store.Len())
+
+	assert.NoError(t, repo.DeleteBond(ctx, 7))
+	assert.Equal(t, 0, store.Len())
+	_, err = repo.GetBondByID(ctx, 7)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,138 @@
+package cached
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var _ rPort.MarketBondRepository = (*MarketBondRepository)(nil)
+
+// marketListKey the market listing, it is the same for every user
+const marketListKey = "market_bonds:list"
+
+// MarketBondRepository caches GetMarketBondByID and the market listing. The
+// orders, new listings and delistings delete the entries they change, and the
+// bond of the listing, whose on_sale changes with them.
+type MarketBondRepository struct {
+	rPort.MarketBondRepository
+	cache   svcport.Cache
+	ttl     time.Duration
+	listTTL time.Duration
+	group   singleflight.Group
+}
+
+// NewMarketBondRepository Creates a new instance of the cached MarketBondRepository
+func NewMarketBondRepository(repo rPort.MarketBondRepository, cache svcport.Cache, ttl time.Duration, listTTL time.Duration) *MarketBondRepository {
+	return &MarketBondRepository{
+		MarketBondRepository: repo,
+		cache:                cache,
+		ttl:                  ttl,
+		listTTL:              listTTL,
+	}
+}
+
+// ListMarketBonds reads the listing from the cache and marks the bonds of uid
+func (repo *MarketBondRepository) ListMarketBonds(ctx context.Context, uid int) ([]*domain.MarketBond, error) {
+	// the listing is loaded without owner so every user shares the entry
+	list, err := readThrough(ctx, repo.cache, &repo.group, "market_bonds", marketListKey, repo.listTTL, func(ctx context.Context) ([]*domain.MarketBond, error) {
+		return repo.MarketBondRepository.ListMarketBonds(ctx, 0)
+	}, noListTags, cloneMarketBonds)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		item.IsOwner = item.CreatedByID == uid
+	}
+	return list, nil
+}
+
+// GetMarketBondByID reads the market bond from the cache
+func (repo *MarketBondRepository) GetMarketBondByID(ctx context.Context, market_bond_id int) (*domain.MarketBond, error) {
+	return readThrough(ctx, repo.cache, &repo.group, "market_bond", marketBondKey(market_bond_id), repo.ttl, func(ctx context.Context) (*domain.MarketBond, error) {
+		return repo.MarketBondRepository.GetMarketBondByID(ctx, market_bond_id)
+	}, marketBondTags, cloneMarketBond)
+}
+
+// BuyMarketBond changes the available bonds of the market bond
+func (repo *MarketBondRepository) BuyMarketBond(ctx context.Context, order *domain.MarketBondRequest) (int, error) {
+	if order.MarketBondID == nil {
+		return repo.MarketBondRepository.BuyMarketBond(ctx, order)
+	}
+	tags := repo.tagsOf(ctx, *order.MarketBondID)
+	available, err := repo.MarketBondRepository.BuyMarketBond(ctx, order)
+	repo.invalidate(ctx, []string{marketBondKey(*order.MarketBondID), marketListKey}, tags)
+	return available, err
+}
+
+// SellMarketBond adds a market bond to the listing
+func (repo *MarketBondRepository) SellMarketBond(ctx context.Context, data *domain.MarketSellRequest) error {
+	err := repo.MarketBondRepository.SellMarketBond(ctx, data)
+	keys := []string{marketListKey}
+	if data.BondID != nil {
+		keys = append(keys, bondKey(*data.BondID))
+	}
+	repo.invalidate(ctx, keys, nil)
+	return err
+}
+
+// DelistMarketBond withdraws the market bond from the listing
+func (repo *MarketBondRepository) DelistMarketBond(ctx context.Context, market_bond_id int) error {
+	tags := repo.tagsOf(ctx, market_bond_id)
+	err := repo.MarketBondRepository.DelistMarketBond(ctx, market_bond_id)
+	repo.invalidate(ctx, []string{marketBondKey(market_bond_id), marketListKey}, tags)
+	return err
+}
+
+// tagsOf reads the UUID of the bond of the market bond before it changes, the
+// bond is tagged with it. A market bond that can't be read has no tags, the
+// bond expires with its TTL.
+func (repo *MarketBondRepository) tagsOf(ctx context.Context, market_bond_id int) []string {
+	mbond, err := repo.MarketBondRepository.GetMarketBondByID(ctx, market_bond_id)
+	if err != nil || mbond == nil || mbond.UUID == "" {
+		return nil
+	}
+	return []string{bondTag(mbond.UUID)}
+}
+
+// invalidate runs even when the write fails, it may have been applied anyway
+func (repo *MarketBondRepository) invalidate(ctx context.Context, keys []string, tags []string) {
+	invalidate(ctx, repo.cache, "market_bond", keys, tags...)
+}
+
+func marketBondKey(market_bond_id int) string {
+	return "market_bond:" + strconv.Itoa(market_bond_id)
+}
+
+func marketBondTags(b *domain.MarketBond) []string {
+	if b == nil || b.UUID == "" {
+		return nil
+	}
+	return []string{bondTag(b.UUID), ownerTag(b.CreatedByID)}
+}
+
+// noListTags the listing isn't tagged, every write deletes it by its key
+func noListTags([]*domain.MarketBond) []string {
+	return nil
+}
+
+func cloneMarketBond(b *domain.MarketBond) *domain.MarketBond {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
+func cloneMarketBonds(list []*domain.MarketBond) []*domain.MarketBond {
+	clone := make([]*domain.MarketBond, len(list))
+	for i, item := range list {
+		clone[i] = cloneMarketBond(item)
+	}
+	return clone
+}
@@ -0,0 +1,22 @@
+package cached
+
+import (
+	"expvar"
+)
+
+// stats hits, misses and errors of every cached query, published with expvar
+// as "cache", e.g. {"bond.hits": 10, "bond.misses": 2}
+var stats = expvar.NewMap("cache")
+
+func hit(name string) {
+	stats.Add(name+".hits", 1)
+}
+
+func miss(name string) {
+	stats.Add(name+".misses", 1)
+}
+
+// cacheError counts a cache that failed, the query is answered by the database
+func cacheError(name string) {
+	stats.Add(name+".errors", 1)
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"sync"
+	"time"
+)
+
+var _ svcport.Cache = (*LRUCache)(nil)
+
+type entry struct {
+	data    []byte
+	expires time.Time
+	tags    []string
+}
+
+// LRUCache struct, an in-process cache for tests and single-node deployments.
+// It keeps the JSON of the values like Redis does, so the callers never share
+// a value.
+type LRUCache struct {
+	mu   sync.Mutex
+	lru  *simplelru.LRU[string, *entry]
+	tags map[string]map[string]struct{}
+	now  func() time.Time
+}
+
+// NewLRUCache creates a cache of up to size entries, the least recently used
+// entry is evicted first
+func NewLRUCache(size int) (*LRUCache, error) {
+	c := &LRUCache{
+		tags: make(map[string]map[string]struct{}),
+		now:  time.Now,
+	}
+	lru, err := simplelru.NewLRU[string, *entry](size, c.untag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lru cache: %w", err)
+	}
+	c.lru = lru
+	return c, nil
+}
+
+// Get unmarshals the value of key into value
+func (c *LRUCache) Get(ctx context.Context, key string, value interface{}) error {
+	data, ok := c.get(key)
+	if !ok {
+		return fmt.Errorf("%w for key %q", dbErrors.ErrCacheMiss, key)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to unmarshal cache value for key %q: %w", key, err)
+	}
+	return nil
+}
+
+// MGet returns the JSON of the keys that were found
+func (c *LRUCache) MGet(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	var found = make(map[string][]byte)
+	for _, key := range keys {
+		if data, ok := c.get(key); ok {
+			found[key] = data
+		}
+	}
+	return found, nil
+}
+
+// Set stores value for ttl and adds key to the tags
+func (c *LRUCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value for key %q: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// replacing a key drops its old tags through the eviction callback
+	c.lru.Remove(key)
+	c.lru.Add(key, &entry{data: data, expires: c.now().Add(ttl), tags: tags})
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+// Delete deletes the keys
+func (c *LRUCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		c.lru.Remove(key)
+	}
+	return nil
+}
+
+// InvalidateTags deletes every key of the tags
+func (c *LRUCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.lru.Remove(key)
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+// Len number of entries, expired entries included until they are read or evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *LRUCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		c.lru.Remove(key)
+		return nil, false
+	}
+	return e.data, true
+}
+
+// untag removes an evicted or deleted key from its tags, it runs with mu held
+func (c *LRUCache) untag(key string, e *entry) {
+	for _, tag := range e.tags {
+		delete(c.tags[tag], key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
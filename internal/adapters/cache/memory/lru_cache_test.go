@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+type cachedValue struct {
+	Name string `json:"name"`
+}
+
+func TestLRUCacheGetSet(t *testing.T) {
+	c, err := NewLRUCache(10)
+	assert.NoError(t, err)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	var value cachedValue
+	assert.ErrorIs(t, c.Get(ctx, "bond:1", &value), dbErrors.ErrCacheMiss)
+
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{Name: "CETES"}, time.Minute))
+	assert.NoError(t, c.Get(ctx, "bond:1", &value))
+	assert.Equal(t, "CETES", value.Name)
+
+	found, err := c.MGet(ctx, "bond:1", "bond:2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"bond:1": []byte(`{"name":"CETES"}`)}, found)
+
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, c.Get(ctx, "bond:1", &value), dbErrors.ErrCacheMiss)
+	assert.Equal(t, 0, c.Len())
+
+	assert.NoError(t, c.Set(ctx, "bond:2", cachedValue{}, time.Minute))
+	assert.NoError(t, c.Delete(ctx, "bond:2", "bond:3"))
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUCacheInvalidateTags(t *testing.T) {
+	c, err := NewLRUCache(10)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{}, time.Minute, "bond:u-1"))
+	assert.NoError(t, c.Set(ctx, "market_bond:5", cachedValue{}, time.Minute, "bond:u-1"))
+	assert.NoError(t, c.Set(ctx, "bond:2", cachedValue{}, time.Minute, "bond:u-2"))
+
+	assert.NoError(t, c.InvalidateTags(ctx, "bond:u-1"))
+	found, err := c.MGet(ctx, "bond:1", "market_bond:5", "bond:2")
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Contains(t, found, "bond:2")
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	c, err := NewLRUCache(2)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{}, time.Minute, "bond:u-1"))
+	assert.NoError(t, c.Set(ctx, "bond:2", cachedValue{}, time.Minute, "bond:u-2"))
+	var value cachedValue
+	assert.NoError(t, c.Get(ctx, "bond:1", &value))
+	assert.NoError(t, c.Set(ctx, "bond:3", cachedValue{}, time.Minute))
+
+	// The least recently used entry goes first and leaves its tags
+	assert.ErrorIs(t, c.Get(ctx, "bond:2", &value), dbErrors.ErrCacheMiss)
+	assert.NoError(t, c.Get(ctx, "bond:1", &value))
+	assert.NotContains(t, c.tags, "bond:u-2")
+
+	// A replaced entry leaves its old tags
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{}, time.Minute))
+	assert.NotContains(t, c.tags, "bond:u-1")
+}
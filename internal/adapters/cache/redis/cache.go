@@ -7,68 +7,211 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"sync/atomic"
 	"time"
 )
 
-// RedisCache struct
+var _ svcport.Cache = (*RedisCache)(nil)
+
+// setTagged stores the value and adds the key to the set of every tag. A tag
+// set lives as long as its longest key.
+var setTagged = redis.NewScript(`
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+for i = 2, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if redis.call('PTTL', KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call('PEXPIRE', KEYS[i], ARGV[2])
+	end
+end
+return 1
+`)
+
+// invalidateTags deletes the keys of every tag and the tag sets
+var invalidateTags = redis.NewScript(`
+for i = 1, #KEYS do
+	local keys = redis.call('SMEMBERS', KEYS[i])
+	for _, key in ipairs(keys) do
+		redis.call('DEL', key)
+	end
+	redis.call('DEL', KEYS[i])
+end
+return 1
+`)
+
+// RedisCache struct, the cache shared by every replica. After a failed call
+// the cache answers ErrCacheUnavailable for the retry period, so a Redis that
+// is down doesn't add its timeout to every request.
 type RedisCache struct {
 	client *redis.Client
+	retry  time.Duration
+	// downUntil unix nanoseconds until Redis is tried again
+	downUntil atomic.Int64
 }
 
-func NewRedisCache(addr, password string) (*RedisCache, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       0, // use default DB
-	})
-
-	_, err := client.Ping(context.Background()).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+// NewRedisCache creates the cache, the client connects on the first call
+func NewRedisCache(client *redis.Client, retry time.Duration) *RedisCache {
+	return &RedisCache{
+		client: client,
+		retry:  retry,
 	}
-
-	return &RedisCache{client: client}, nil
 }
 
-func (c *RedisCache) Get(key string, value interface{}) error {
-	data, err := c.client.Get(context.Background(), key).Result()
+// Get unmarshals the value of key into value
+func (c *RedisCache) Get(ctx context.Context, key string, value interface{}) error {
+	if err := c.available(); err != nil {
+		return err
+	}
+	data, err := c.client.Get(ctx, key).Bytes()
 	if errors.Is(err, redis.Nil) {
-		return fmt.Errorf("cache miss for key %q", key)
+		return fmt.Errorf("%w for key %q", dbErrors.ErrCacheMiss, key)
 	} else if err != nil {
-		return fmt.Errorf("failed to get value for key %q: %v", key, err)
+		return c.fail(fmt.Errorf("failed to get value for key %q: %w", key, err))
 	}
 
-	if err := json.Unmarshal([]byte(data), value); err != nil {
-		return fmt.Errorf("failed to unmarshal cache value for key %q: %v", key, err)
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to unmarshal cache value for key %q: %w", key, err)
 	}
 
 	return nil
 }
 
-func (c *RedisCache) Set(key string, value interface{}, duration time.Duration) error {
+// MGet returns the JSON of the keys that were found
+func (c *RedisCache) MGet(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	var found = make(map[string][]byte)
+	if len(keys) == 0 {
+		return found, nil
+	}
+	if err := c.available(); err != nil {
+		return nil, err
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("failed to get values of %d keys: %w", len(keys), err))
+	}
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			found[keys[i]] = []byte(s)
+		}
+	}
+	return found, nil
+}
+
+// Set stores value for ttl and adds key to the tags
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
 	data, err := json.Marshal(value)
 	if err != nil {
-		return fmt.Errorf("failed to marshal cache value for key %q: %v", key, err)
+		return fmt.Errorf("failed to marshal cache value for key %q: %w", key, err)
+	}
+	if err := c.available(); err != nil {
+		return err
 	}
 
-	if err := c.client.Set(context.Background(), key, data, duration).Err(); err != nil {
-		return fmt.Errorf("failed to set value for key %q: %v", key, err)
+	if len(tags) == 0 {
+		err = c.client.Set(ctx, key, data, ttl).Err()
+	} else {
+		keys := append([]string{key}, tagKeys(tags)...)
+		err = setTagged.Run(ctx, c.client, keys, data, ttl.Milliseconds()).Err()
+	}
+	if err != nil {
+		return c.fail(fmt.Errorf("failed to set value for key %q: %w", key, err))
+	}
+
+	return nil
+}
+
+// Delete deletes the keys
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.available(); err != nil {
+		return err
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return c.fail(fmt.Errorf("failed to delete %d keys: %w", len(keys), err))
 	}
+	return nil
+}
 
+// InvalidateTags deletes every key of the tags
+func (c *RedisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := c.available(); err != nil {
+		return err
+	}
+	if err := invalidateTags.Run(ctx, c.client, tagKeys(tags)).Err(); err != nil {
+		return c.fail(fmt.Errorf("failed to invalidate %d tags: %w", len(tags), err))
+	}
 	return nil
 }
 
-func (c *RedisCache) Delete(key string) error {
-	if err := c.client.Del(context.Background(), key).Err(); err != nil {
-		return fmt.Errorf("failed to delete value for key %q: %v", key, err)
+func (c *RedisCache) available() error {
+	if time.Now().UnixNano() < c.downUntil.Load() {
+		return dbErrors.ErrCacheUnavailable
 	}
 	return nil
 }
 
+// fail skips Redis for the retry period
+func (c *RedisCache) fail(err error) error {
+	c.downUntil.Store(time.Now().Add(c.retry).UnixNano())
+	return err
+}
+
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = "tag:" + tag
+	}
+	return keys
+}
+
+// Connect creates the cache of the configuration with its own client. Short
+// timeouts and no retries, a miss is cheaper than a slow cache. An unreachable
+// Redis is only logged, the cache starts skipped and is tried again later.
+func Connect(cfg domain.Cache, logger *zap.SugaredLogger) *RedisCache {
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DialTimeout:  cfg.CacheTimeout,
+		ReadTimeout:  cfg.CacheTimeout,
+		WriteTimeout: cfg.CacheTimeout,
+		MaxRetries:   -1,
+	})
+	c := NewRedisCache(client, time.Duration(cfg.CacheRetry)*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.CacheTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		logger.Warnw("redis cache unreachable, queries go to the database until it is back", "error", err.Error())
+		c.fail(err)
+	}
+	return c
+}
+
 var Module = fx.Module("cache",
-	fx.Provide(func(cfg *domain.Configuration) *RedisCache {
-		cache, _ := NewRedisCache(cfg.Addr, cfg.Password)
-		return cache
+	fx.Provide(func(cfg *domain.Configuration) *redis.Client {
+		return redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+		})
+	}),
+	fx.Provide(func(client *redis.Client) *LoginAttemptRepository {
+		return NewLoginAttemptRepository(client)
+	}),
+	fx.Provide(func(client *redis.Client) *OIDCStateRepository {
+		return NewOIDCStateRepository(client)
+	}),
+	fx.Provide(func(client *redis.Client) *RateLimitRepository {
+		return NewRateLimitRepository(client)
+	}),
+	fx.Provide(func(client *redis.Client) *JobLockRepository {
+		return NewJobLockRepository(client)
 	}),
 )
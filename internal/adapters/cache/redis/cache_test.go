@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+type cachedValue struct {
+	Name string `json:"name"`
+}
+
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisCache(client, time.Minute), srv
+}
+
+func TestRedisCacheGetSet(t *testing.T) {
+	c, srv := newTestRedisCache(t)
+	ctx := context.Background()
+
+	var value cachedValue
+	assert.ErrorIs(t, c.Get(ctx, "bond:1", &value), dbErrors.ErrCacheMiss)
+
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{Name: "CETES"}, time.Minute))
+	assert.NoError(t, c.Get(ctx, "bond:1", &value))
+	assert.Equal(t, "CETES", value.Name)
+
+	found, err := c.MGet(ctx, "bond:1", "bond:2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"bond:1": []byte(`{"name":"CETES"}`)}, found)
+
+	srv.FastForward(time.Minute)
+	assert.ErrorIs(t, c.Get(ctx, "bond:1", &value), dbErrors.ErrCacheMiss)
+
+	assert.NoError(t, c.Set(ctx, "bond:2", cachedValue{}, time.Minute))
+	assert.NoError(t, c.Delete(ctx, "bond:2", "bond:3"))
+	assert.False(t, srv.Exists("bond:2"))
+}
+
+func TestRedisCacheInvalidateTags(t *testing.T) {
+	c, srv := newTestRedisCache(t)
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "bond:1", cachedValue{}, time.Minute, "bond:u-1"))
+	assert.NoError(t, c.Set(ctx, "market_bond:5", cachedValue{}, 2*time.Minute, "bond:u-1"))
+	assert.NoError(t, c.Set(ctx, "bond:2", cachedValue{}, time.Minute, "bond:u-2"))
+
+	// The tag set lives as long as its longest key
+	assert.Equal(t, 2*time.Minute, srv.TTL("tag:bond:u-1"))
+
+	assert.NoError(t, c.InvalidateTags(ctx, "bond:u-1"))
+	assert.False(t, srv.Exists("bond:1"))
+	assert.False(t, srv.Exists("market_bond:5"))
+	assert.False(t, srv.Exists("tag:bond:u-1"))
+	assert.True(t, srv.Exists("bond:2"))
+}
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	c, srv := newTestRedisCache(t)
+	ctx := context.Background()
+	srv.Close()
+
+	var value cachedValue
+	err := c.Get(ctx, "bond:1", &value)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, dbErrors.ErrCacheUnavailable)
+
+	// Skipped until the retry period passes
+	assert.ErrorIs(t, c.Get(ctx, "bond:1", &value), dbErrors.ErrCacheUnavailable)
+	assert.ErrorIs(t, c.Set(ctx, "bond:1", value, time.Minute), dbErrors.ErrCacheUnavailable)
+	assert.ErrorIs(t, c.InvalidateTags(ctx, "bond:u-1"), dbErrors.ErrCacheUnavailable)
+
+	c.downUntil.Store(0)
+	assert.NotErrorIs(t, c.Delete(ctx, "bond:1"), dbErrors.ErrCacheUnavailable)
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	"strconv"
+	"time"
+)
+
+var _ rPort.JobLockRepository = (*JobLockRepository)(nil)
+
+// renewLock extends the lease only while the token still holds it
+var renewLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseLock deletes the lock only while the token still holds it, a lease
+// that expired may belong to another replica by now
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// JobLockRepository struct, leases in Redis so a scheduled job runs on one
+// replica at a time
+type JobLockRepository struct {
+	client *redis.Client
+}
+
+// NewJobLockRepository Creates a new instance of JobLockRepository
+func NewJobLockRepository(client *redis.Client) *JobLockRepository {
+	return &JobLockRepository{
+		client: client,
+	}
+}
+
+// Acquire takes the lock of the job for ttl unless another holder has it
+func (repo *JobLockRepository) Acquire(ctx context.Context, job string, token string, ttl time.Duration) (bool, error) {
+	ok, err := repo.client.SetNX(ctx, jobLockKey(job), token, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lock job %q: %w", job, err)
+	}
+	return ok, nil
+}
+
+// Renew extends the lease of the holder by ttl
+func (repo *JobLockRepository) Renew(ctx context.Context, job string, token string, ttl time.Duration) (bool, error) {
+	n, err := renewLock.Run(ctx, repo.client, []string{jobLockKey(job)}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew the lock of job %q: %w", job, err)
+	}
+	return n == 1, nil
+}
+
+// Release frees the lock of the holder
+func (repo *JobLockRepository) Release(ctx context.Context, job string, token string) error {
+	if err := releaseLock.Run(ctx, repo.client, []string{jobLockKey(job)}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release the lock of job %q: %w", job, err)
+	}
+	return nil
+}
+
+// ClaimTick takes the scheduled run of the job at tick, one replica wins it
+func (repo *JobLockRepository) ClaimTick(ctx context.Context, job string, tick time.Time, ttl time.Duration) (bool, error) {
+	ok, err := repo.client.SetNX(ctx, jobTickKey(job, tick), "1", ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim the run of job %q: %w", job, err)
+	}
+	return ok, nil
+}
+
+func jobLockKey(job string) string {
+	return "scheduler:lock:" + job
+}
+
+func jobTickKey(job string, tick time.Time) string {
+	return jobLockKey(job) + ":" + strconv.FormatInt(tick.Unix(), 10)
+}
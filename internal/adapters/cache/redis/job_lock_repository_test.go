@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestJobLock(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewJobLockRepository(client)
+	ctx := context.Background()
+
+	ok, err := repo.Acquire(ctx, "job_runs.purge", "a", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// The lock has a single holder
+	ok, err = repo.Acquire(ctx, "job_runs.purge", "b", 30*time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Only the holder renews it
+	ok, err = repo.Renew(ctx, "job_runs.purge", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.Renew(ctx, "job_runs.purge", "a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, srv.TTL("scheduler:lock:job_runs.purge"))
+
+	// Or releases it
+	assert.NoError(t, repo.Release(ctx, "job_runs.purge", "b"))
+	assert.True(t, srv.Exists("scheduler:lock:job_runs.purge"))
+	assert.NoError(t, repo.Release(ctx, "job_runs.purge", "a"))
+	assert.False(t, srv.Exists("scheduler:lock:job_runs.purge"))
+}
+
+func TestJobLockExpires(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewJobLockRepository(client)
+	ctx := context.Background()
+
+	ok, err := repo.Acquire(ctx, "job_runs.purge", "a", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// A lease that wasn't renewed in time is lost
+	srv.FastForward(30 * time.Second)
+	ok, err = repo.Renew(ctx, "job_runs.purge", "a", 30*time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = repo.Acquire(ctx, "job_runs.purge", "b", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestJobClaimTick(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewJobLockRepository(client)
+	ctx := context.Background()
+	tick := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	ok, err := repo.ClaimTick(ctx, "job_runs.purge", tick, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, srv.TTL("scheduler:lock:job_runs.purge:1704164400"))
+
+	// One replica runs a tick
+	ok, err = repo.ClaimTick(ctx, "job_runs.purge", tick, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// The next tick is claimed apart
+	ok, err = repo.ClaimTick(ctx, "job_runs.purge", tick.Add(time.Hour), time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// And the claim expires
+	srv.FastForward(time.Minute)
+	assert.False(t, srv.Exists("scheduler:lock:job_runs.purge:1704164400"))
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.LoginAttemptRepository = (*LoginAttemptRepository)(nil)
+
+// LoginAttemptRepository struct, keeps the failed sign in counters in Redis
+// so every replica shares them
+type LoginAttemptRepository struct {
+	client *redis.Client
+}
+
+// NewLoginAttemptRepository Creates a new instance of LoginAttemptRepository
+func NewLoginAttemptRepository(client *redis.Client) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		client: client,
+	}
+}
+
+// Status returns the failures, the lockout and the backoff delay of the subject
+func (repo *LoginAttemptRepository) Status(ctx context.Context, subject string) (*domain.LoginAttemptStatus, error) {
+	pipe := repo.client.Pipeline()
+	failures := pipe.Get(ctx, failuresKey(subject))
+	locked := pipe.PTTL(ctx, lockKey(subject))
+	delayed := pipe.PTTL(ctx, delayKey(subject))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load login attempts of %q: %w", subject, err)
+	}
+
+	count, err := failures.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load login attempts of %q: %w", subject, err)
+	}
+
+	return &domain.LoginAttemptStatus{
+		Failures:   count,
+		LockedFor:  remaining(locked.Val()),
+		DelayedFor: remaining(delayed.Val()),
+	}, nil
+}
+
+// RegisterFailure increments the failures of the subject, the counter expires
+// a window after the first failure
+func (repo *LoginAttemptRepository) RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	key := failuresKey(subject)
+	count, err := repo.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to register login failure of %q: %w", subject, err)
+	}
+	if count == 1 {
+		if err := repo.client.Expire(ctx, key, window).Err(); err != nil {
+			return count, fmt.Errorf("failed to register login failure of %q: %w", subject, err)
+		}
+	}
+
+	return count, nil
+}
+
+// Delay rejects the attempts of the subject for d
+func (repo *LoginAttemptRepository) Delay(ctx context.Context, subject string, d time.Duration) error {
+	if err := repo.client.Set(ctx, delayKey(subject), 1, d).Err(); err != nil {
+		return fmt.Errorf("failed to delay login of %q: %w", subject, err)
+	}
+	return nil
+}
+
+// Lock locks the subject for d. It returns false when the subject was already locked
+func (repo *LoginAttemptRepository) Lock(ctx context.Context, subject string, d time.Duration) (bool, error) {
+	ok, err := repo.client.SetNX(ctx, lockKey(subject), 1, d).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lock login of %q: %w", subject, err)
+	}
+	return ok, nil
+}
+
+// Reset clears the failures, the delay and the lockout of the subject
+func (repo *LoginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	if err := repo.client.Del(ctx, failuresKey(subject), delayKey(subject), lockKey(subject)).Err(); err != nil {
+		return fmt.Errorf("failed to reset login attempts of %q: %w", subject, err)
+	}
+	return nil
+}
+
+// SaveUnlockToken stores the hash of an unlock token
+func (repo *LoginAttemptRepository) SaveUnlockToken(ctx context.Context, tokenHash string, subject string, ttl time.Duration) error {
+	if err := repo.client.Set(ctx, unlockKey(tokenHash), subject, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save unlock token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeUnlockToken returns the subject of the token, a token works only once
+func (repo *LoginAttemptRepository) ConsumeUnlockToken(ctx context.Context, tokenHash string) (string, error) {
+	subject, err := repo.client.GetDel(ctx, unlockKey(tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", dbErrors.ErrInvalidUnlockToken
+	} else if err != nil {
+		return "", fmt.Errorf("failed to consume unlock token: %w", err)
+	}
+	return subject, nil
+}
+
+func failuresKey(subject string) string {
+	return "login:failures:" + subject
+}
+
+func delayKey(subject string) string {
+	return "login:delay:" + subject
+}
+
+func lockKey(subject string) string {
+	return "login:lock:" + subject
+}
+
+func unlockKey(tokenHash string) string {
+	return "login:unlock:" + tokenHash
+}
+
+// remaining PTTL returns negative values for missing keys
+func remaining(ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func newTestLoginAttemptRepository(t *testing.T) (*LoginAttemptRepository, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewLoginAttemptRepository(client), srv
+}
+
+func TestRegisterLoginFailure(t *testing.T) {
+	repo, srv := newTestLoginAttemptRepository(t)
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := repo.RegisterFailure(ctx, "account:gini@mail.com", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+
+	status, err := repo.Status(ctx, "account:gini@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), status.Failures)
+	assert.False(t, status.Blocked())
+
+	// The window starts with the first failure
+	srv.FastForward(time.Minute)
+	status, err = repo.Status(ctx, "account:gini@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Failures)
+}
+
+func TestLockLogin(t *testing.T) {
+	repo, srv := newTestLoginAttemptRepository(t)
+	ctx := context.Background()
+
+	ok, err := repo.Lock(ctx, "account:gini@mail.com", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.Lock(ctx, "account:gini@mail.com", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, repo.Delay(ctx, "account:gini@mail.com", 2*time.Second))
+
+	status, err := repo.Status(ctx, "account:gini@mail.com")
+	assert.NoError(t, err)
+	assert.True(t, status.Blocked())
+	assert.Equal(t, 15*time.Minute, status.RetryAfter())
+
+	srv.FastForward(15 * time.Minute)
+	status, err = repo.Status(ctx, "account:gini@mail.com")
+	assert.NoError(t, err)
+	assert.False(t, status.Blocked())
+}
+
+func TestUnlockToken(t *testing.T) {
+	repo, _ := newTestLoginAttemptRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.RegisterFailure(ctx, "account:gini@mail.com", time.Minute)
+	assert.NoError(t, err)
+	_, err = repo.Lock(ctx, "account:gini@mail.com", time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SaveUnlockToken(ctx, "hash", "account:gini@mail.com", time.Minute))
+
+	subject, err := repo.ConsumeUnlockToken(ctx, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "account:gini@mail.com", subject)
+
+	_, err = repo.ConsumeUnlockToken(ctx, "hash")
+	assert.ErrorIs(t, err, dbErrors.ErrInvalidUnlockToken)
+
+	assert.NoError(t, repo.Reset(ctx, subject))
+	status, err := repo.Status(ctx, subject)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), status.Failures)
+	assert.False(t, status.Blocked())
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.OIDCStateRepository = (*OIDCStateRepository)(nil)
+
+// OIDCStateRepository struct, the login and the callback may hit different replicas
+type OIDCStateRepository struct {
+	client *redis.Client
+}
+
+// NewOIDCStateRepository Creates a new instance of OIDCStateRepository
+func NewOIDCStateRepository(client *redis.Client) *OIDCStateRepository {
+	return &OIDCStateRepository{
+		client: client,
+	}
+}
+
+// Save stores the data of a login until the ttl expires
+func (repo *OIDCStateRepository) Save(ctx context.Context, state string, data *domain.OIDCState, ttl time.Duration) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal oidc state: %w", err)
+	}
+	if err := repo.client.Set(ctx, oidcStateKey(state), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save oidc state: %w", err)
+	}
+	return nil
+}
+
+// Consume loads and deletes the data of a login
+func (repo *OIDCStateRepository) Consume(ctx context.Context, state string) (*domain.OIDCState, error) {
+	value, err := repo.client.GetDel(ctx, oidcStateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, dbErrors.ErrInvalidOIDCState
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to consume oidc state: %w", err)
+	}
+
+	var data = &domain.OIDCState{}
+	if err := json.Unmarshal(value, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal oidc state: %w", err)
+	}
+	return data, nil
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
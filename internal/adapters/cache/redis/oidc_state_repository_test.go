@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestOIDCState(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewOIDCStateRepository(client)
+	ctx := context.Background()
+
+	t.Run("Single Use", func(t *testing.T) {
+		err := repo.Save(ctx, "state-1", &domain.OIDCState{CodeVerifier: "verifier", Nonce: "nonce"}, time.Minute)
+		assert.NoError(t, err)
+
+		data, err := repo.Consume(ctx, "state-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "verifier", data.CodeVerifier)
+		assert.Equal(t, "nonce", data.Nonce)
+
+		_, err = repo.Consume(ctx, "state-1")
+		assert.ErrorIs(t, err, dbErrors.ErrInvalidOIDCState)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		err := repo.Save(ctx, "state-2", &domain.OIDCState{CodeVerifier: "verifier", Nonce: "nonce"}, time.Minute)
+		assert.NoError(t, err)
+
+		srv.FastForward(time.Minute)
+		_, err = repo.Consume(ctx, "state-2")
+		assert.ErrorIs(t, err, dbErrors.ErrInvalidOIDCState)
+	})
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	"strconv"
+	"time"
+)
+
+var _ rPort.RateLimitRepository = (*RateLimitRepository)(nil)
+
+// RateLimitRepository struct, keeps the rate limit counters in Redis so every
+// replica behind the load balancer shares the budgets
+type RateLimitRepository struct {
+	client *redis.Client
+}
+
+// NewRateLimitRepository Creates a new instance of RateLimitRepository
+func NewRateLimitRepository(client *redis.Client) *RateLimitRepository {
+	return &RateLimitRepository{
+		client: client,
+	}
+}
+
+// Hit increments the counter of the current window of the key. The window is
+// part of the key, so a counter never outlives its window.
+func (repo *RateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (*domain.RateLimitHit, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	k := rateLimitKey(key, start)
+
+	pipe := repo.client.TxPipeline()
+	incr := pipe.Incr(ctx, k)
+	pipe.Expire(ctx, k, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count request of %q: %w", key, err)
+	}
+
+	return &domain.RateLimitHit{
+		Count: incr.Val(),
+		Reset: start.Add(window).Sub(now),
+	}, nil
+}
+
+func rateLimitKey(key string, start time.Time) string {
+	return "ratelimit:" + key + ":" + strconv.FormatInt(start.Unix(), 10)
+}
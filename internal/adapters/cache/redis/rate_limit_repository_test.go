@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHit(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewRateLimitRepository(client)
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		hit, err := repo.Hit(ctx, "read:user:1", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, i, hit.Count)
+		assert.True(t, hit.Reset > 0 && hit.Reset <= time.Hour)
+	}
+
+	// Every key has its own counter
+	hit, err := repo.Hit(ctx, "read:user:2", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), hit.Count)
+
+	// The counters expire with their window
+	srv.FastForward(time.Hour)
+	assert.Empty(t, srv.Keys())
+}
+
+func TestRateLimitHitRedisDown(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	repo := NewRateLimitRepository(client)
+	srv.Close()
+
+	hit, err := repo.Hit(context.Background(), "read:user:1", time.Minute)
+	assert.Error(t, err)
+	assert.Nil(t, hit)
+}
@@ -0,0 +1,182 @@
+package tiered
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"time"
+)
+
+var _ svcport.Cache = (*TieredCache)(nil)
+
+// envelope the shared tier keeps the tags with the value, so an instance that
+// fills its local tier from it can evict the entry by tag later
+type envelope struct {
+	Tags  []string        `json:"tags,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// TieredCache struct, a local LRU in front of the cache shared by the replicas.
+// Every write is broadcast to the other instances, which evict their local
+// copies. A lost message leaves a stale local entry for at most localTTL.
+type TieredCache struct {
+	logger   *zap.SugaredLogger
+	local    svcport.Cache
+	shared   svcport.Cache
+	bus      *psnats.NATSPubSub
+	subject  string
+	localTTL time.Duration
+	// origin the id of the instance, it ignores its own messages
+	origin string
+	sub    *nats.Subscription
+}
+
+// NewTieredCache creates the cache, Start subscribes to the invalidations of the other instances
+func NewTieredCache(logger *zap.SugaredLogger, local svcport.Cache, shared svcport.Cache, bus *psnats.NATSPubSub, subject string, localTTL time.Duration) *TieredCache {
+	return &TieredCache{
+		logger:   logger,
+		local:    local,
+		shared:   shared,
+		bus:      bus,
+		subject:  subject,
+		localTTL: localTTL,
+		origin:   uuid.NewString(),
+	}
+}
+
+// Start subscribes to the invalidation subject
+func (c *TieredCache) Start(ctx context.Context) error {
+	sub, err := psnats.Subscribe(c.bus, c.subject, c.evict)
+	if err != nil {
+		return err
+	}
+	c.sub = sub
+	return nil
+}
+
+// Stop unsubscribes from the invalidation subject
+func (c *TieredCache) Stop(ctx context.Context) error {
+	if c.sub == nil {
+		return nil
+	}
+	return c.sub.Unsubscribe()
+}
+
+// Get reads the local tier, then the shared one
+func (c *TieredCache) Get(ctx context.Context, key string, value interface{}) error {
+	if err := c.local.Get(ctx, key, value); err == nil {
+		return nil
+	}
+
+	var e envelope
+	if err := c.shared.Get(ctx, key, &e); err != nil {
+		return err
+	}
+	c.fill(ctx, key, e, c.localTTL)
+	if err := json.Unmarshal(e.Value, value); err != nil {
+		return fmt.Errorf("failed to unmarshal cache value for key %q: %w", key, err)
+	}
+	return nil
+}
+
+// MGet returns the JSON of the keys that were found. When the shared tier
+// fails the keys of the local tier are returned with the error.
+func (c *TieredCache) MGet(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	found, err := c.local.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	shared, err := c.shared.MGet(ctx, missing...)
+	if err != nil {
+		return found, err
+	}
+	for key, data := range shared {
+		var e envelope
+		if err := json.Unmarshal(data, &e); err != nil {
+			continue
+		}
+		c.fill(ctx, key, e, c.localTTL)
+		found[key] = e.Value
+	}
+	return found, nil
+}
+
+// Set stores value in both tiers and evicts the copies of the other instances
+func (c *TieredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value for key %q: %w", key, err)
+	}
+	e := envelope{Tags: tags, Value: data}
+
+	c.fill(ctx, key, e, min(ttl, c.localTTL))
+	err = c.shared.Set(ctx, key, e, ttl, tags...)
+	return errors.Join(err, c.broadcast(domain.CacheInvalidation{Keys: []string{key}}))
+}
+
+// Delete deletes the keys of both tiers and of the other instances
+func (c *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_ = c.local.Delete(ctx, keys...)
+	err := c.shared.Delete(ctx, keys...)
+	return errors.Join(err, c.broadcast(domain.CacheInvalidation{Keys: keys}))
+}
+
+// InvalidateTags deletes the keys of the tags in both tiers and in the other instances
+func (c *TieredCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_ = c.local.InvalidateTags(ctx, tags...)
+	err := c.shared.InvalidateTags(ctx, tags...)
+	return errors.Join(err, c.broadcast(domain.CacheInvalidation{Tags: tags}))
+}
+
+// fill keeps the value in the local tier, a failure only costs a local miss
+func (c *TieredCache) fill(ctx context.Context, key string, e envelope, ttl time.Duration) {
+	if err := c.local.Set(ctx, key, e.Value, ttl, e.Tags...); err != nil {
+		c.logger.Warnw("failed to fill the local cache", "key", key, "error", err.Error())
+	}
+}
+
+func (c *TieredCache) broadcast(msg domain.CacheInvalidation) error {
+	msg.Origin = c.origin
+	if err := c.bus.PublishEvent(c.subject, msg); err != nil {
+		return fmt.Errorf("failed to broadcast cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// evict applies the invalidation of another instance to the local tier, the
+// local tier doesn't fail so the message is never retried
+func (c *TieredCache) evict(ctx context.Context, msg domain.CacheInvalidation) error {
+	if msg.Origin == c.origin {
+		return nil
+	}
+	if len(msg.Keys) > 0 {
+		_ = c.local.Delete(ctx, msg.Keys...)
+	}
+	if len(msg.Tags) > 0 {
+		_ = c.local.InvalidateTags(ctx, msg.Tags...)
+	}
+	return nil
+}
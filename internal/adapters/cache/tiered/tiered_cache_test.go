@@ -0,0 +1,140 @@
+package tiered
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/cache/memory"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+type cachedValue struct {
+	Name string `json:"name"`
+}
+
+func runNATSServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	assert.NoError(t, err)
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+// newTestInstance an API replica, the replicas share Redis and NATS
+func newTestInstance(t *testing.T, ns *server.Server, rs *miniredis.Miniredis) (*TieredCache, *memory.LRUCache) {
+	bus, err := psnats.NewNATSPubSub(ns.ClientURL(), zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	t.Cleanup(bus.Close)
+
+	local, err := memory.NewLRUCache(10)
+	assert.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: rs.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c := NewTieredCache(zap.NewNop().Sugar(), local, cache.NewRedisCache(client, time.Minute), bus, "cache.invalidate", time.Minute)
+	assert.NoError(t, c.Start(context.Background()))
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+	return c, local
+}
+
+func localKeys(local *memory.LRUCache, keys ...string) int {
+	found, _ := local.MGet(context.Background(), keys...)
+	return len(found)
+}
+
+func TestTieredCacheReadsThroughTiers(t *testing.T) {
+	ns, rs := runNATSServer(t), miniredis.RunT(t)
+	a, _ := newTestInstance(t, ns, rs)
+	ctx := context.Background()
+
+	var value cachedValue
+	assert.ErrorIs(t, a.Get(ctx, "bond:1", &value), dbErrors.ErrCacheMiss)
+	assert.NoError(t, a.Set(ctx, "bond:1", cachedValue{Name: "CETES"}, time.Minute, "bond:u-1"))
+
+	// Started after the write, it only finds the value in Redis
+	b, localB := newTestInstance(t, ns, rs)
+	assert.Equal(t, 0, localKeys(localB, "bond:1"))
+	assert.NoError(t, b.Get(ctx, "bond:1", &value))
+	assert.Equal(t, "CETES", value.Name)
+	assert.Equal(t, 1, localKeys(localB, "bond:1"))
+
+	// Served by the local tier while Redis is down
+	rs.Close()
+	value = cachedValue{}
+	assert.NoError(t, b.Get(ctx, "bond:1", &value))
+	assert.Equal(t, "CETES", value.Name)
+	assert.Error(t, b.Get(ctx, "bond:2", &value))
+
+	found, err := b.MGet(ctx, "bond:1", "bond:2")
+	assert.Error(t, err)
+	assert.Equal(t, map[string][]byte{"bond:1": []byte(`{"name":"CETES"}`)}, found)
+}
+
+func TestTieredCacheInvalidatesOtherInstances(t *testing.T) {
+	ns, rs := runNATSServer(t), miniredis.RunT(t)
+	a, _ := newTestInstance(t, ns, rs)
+	b, localB := newTestInstance(t, ns, rs)
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		write func() error
+		want  string
+	}{
+		"Set": {
+			write: func() error {
+				return a.Set(ctx, "bond:1", cachedValue{Name: "BONDES"}, time.Minute, "bond:u-1")
+			},
+			want: "BONDES",
+		},
+		"Delete": {
+			write: func() error { return a.Delete(ctx, "bond:1") },
+		},
+		"Invalidate Tags": {
+			write: func() error { return a.InvalidateTags(ctx, "bond:u-1") },
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// An instance ignores its own messages, b keeps what it wrote
+			assert.NoError(t, b.Set(ctx, "bond:1", cachedValue{Name: "CETES"}, time.Minute, "bond:u-1"))
+			assert.Equal(t, 1, localKeys(localB, "bond:1"))
+
+			assert.NoError(t, tc.write())
+			assert.Eventually(t, func() bool { return localKeys(localB, "bond:1") == 0 }, time.Second, 10*time.Millisecond)
+
+			var value cachedValue
+			err := b.Get(ctx, "bond:1", &value)
+			if tc.want == "" {
+				assert.ErrorIs(t, err, dbErrors.ErrCacheMiss)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, value.Name)
+		})
+	}
+}
+
+func TestTieredCacheIgnoresOwnMessages(t *testing.T) {
+	ns, rs := runNATSServer(t), miniredis.RunT(t)
+	a, localA := newTestInstance(t, ns, rs)
+	ctx := context.Background()
+
+	assert.NoError(t, a.Set(ctx, "bond:1", cachedValue{Name: "CETES"}, time.Minute))
+	assert.NoError(t, a.evict(ctx, domain.CacheInvalidation{Origin: a.origin, Keys: []string{"bond:1"}}))
+	assert.Equal(t, 1, localKeys(localA, "bond:1"))
+
+	assert.NoError(t, a.evict(ctx, domain.CacheInvalidation{Origin: "other", Keys: []string{"bond:1"}}))
+	assert.Equal(t, 0, localKeys(localA, "bond:1"))
+}
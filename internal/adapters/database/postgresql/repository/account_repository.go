@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.AccountRepository = (*AccountRepository)(nil)
+
+// anonymizeQueries run by Anonymize once the user is deleted, each one takes the user id.
+// Transactions and the admin audit log are kept as they are, they only hold ids.
+var anonymizeQueries = []string{
+	`UPDATE users_profile SET username = CONCAT('deleted_', user_id), photo = 'default_profile.png', gender = 'undisclosed', updated_at = NOW(), deleted_at = NOW() WHERE user_id = ?`,
+	`UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`,
+	`UPDATE api_keys SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`,
+	`DELETE FROM user_recovery_codes WHERE user_id = ?`,
+	`DELETE FROM user_totp WHERE user_id = ?`,
+	`DELETE FROM user_identities WHERE user_id = ?`,
+	`DELETE FROM webhooks WHERE user_id = ?`,
+	`DELETE FROM notifications WHERE user_id = ?`,
+	`DELETE FROM notification_preferences WHERE user_id = ?`,
+	`DELETE FROM alerts WHERE user_id = ?`,
+	`DELETE FROM watchlist WHERE user_id = ?`,
+	`UPDATE seller_ratings SET comment = '' WHERE buyer_id = ?`,
+	`UPDATE market_bonds mb INNER JOIN bonds b ON b.id = mb.bond_id SET mb.status = 'delisted', mb.delisted_at = NOW(), mb.updated_at = NOW() WHERE b.created_by = ? AND mb.status = 'available' AND mb.deleted_at IS NULL`,
+	`UPDATE bonds SET updated_at = NOW(), deleted_at = NOW() WHERE created_by = ? AND deleted_at IS NULL`,
+	`DELETE FROM data_exports WHERE user_id = ?`,
+	`DELETE FROM tasks WHERE user_id = ?`,
+}
+
+// AccountRepository struct
+type AccountRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountRepository Creates a new instance of AccountRepository
+func NewAccountRepository(conn *sqlx.DB) *AccountRepository {
+	return &AccountRepository{
+		db: conn,
+	}
+}
+
+// GetAccount repository method for loading the sign in data of an active user.
+func (repo *AccountRepository) GetAccount(ctx context.Context, uid int) (*domain.Account, error) {
+	var query = `SELECT id, email, created_at FROM users WHERE id = ? AND deleted_at IS NULL`
+
+	var item = &domain.Account{}
+	if err := repo.db.GetContext(ctx, item, query, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
+
+// GetListings repository method for listing every market listing of the user, in any status.
+func (repo *AccountRepository) GetListings(ctx context.Context, uid int) ([]*domain.MarketBond, error) {
+	var query = `SELECT
+			mb.id,
+			b.uuid,
+			b.name,
+			b.price,
+			mb.available,
+			b.currency_id AS currency,
+			b.created_by AS created_by_id,
+			mb.status,
+			mb.created_at,
+			mb.updated_at
+		FROM market_bonds mb
+			INNER JOIN bonds b ON b.id = mb.bond_id
+		WHERE b.created_by = ? AND mb.deleted_at IS NULL
+		ORDER BY mb.created_at DESC`
+
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryxContext(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	defer rows.Close()
+
+	var list = make([]*domain.MarketBond, 0)
+	for rows.Next() {
+		var createdAt sql.NullTime
+		var updatedAt sql.NullTime
+		var item = &domain.MarketBond{IsOwner: true}
+		if err := rows.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Available, &item.Currency, &item.CreatedByID, &item.Status, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", dbErrors.ErrScanData, err)
+		}
+		if createdAt.Valid {
+			item.CreatedAt = createdAt.Time
+		}
+		if updatedAt.Valid {
+			item.UpdateAt = updatedAt.Time
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrScanData, err)
+	}
+
+	return list, nil
+}
+
+// CreateExport repository method for storing a new data export request.
+func (repo *AccountRepository) CreateExport(ctx context.Context, export *domain.DataExport) error {
+	var query = `INSERT INTO data_exports (user_id, status) VALUES (?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, export.UserID, export.Status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	export.ID = int(id)
+
+	return nil
+}
+
+// UpdateExport repository method for saving the progress of a data export.
+func (repo *AccountRepository) UpdateExport(ctx context.Context, export *domain.DataExport) error {
+	var query = `UPDATE data_exports SET status = ?, file_key = ?, size = ?, completed_at = ?, expires_at = ? WHERE id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, export.Status, export.FileKey, export.Size, export.CompletedAt, export.ExpiresAt, export.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		// The account was deleted while the export was running
+		return dbErrors.ErrExportNotFound
+	}
+
+	return nil
+}
+
+// GetLatestExport repository method for loading the last data export of the user.
+func (repo *AccountRepository) GetLatestExport(ctx context.Context, uid int) (*domain.DataExport, error) {
+	var query = `SELECT id, user_id, status, file_key, size, created_at, completed_at, expires_at
+		FROM data_exports
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT 1`
+
+	var item = &domain.DataExport{}
+	if err := repo.db.GetContext(ctx, item, query, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
+
+// ListExportKeys repository method for listing the storage keys of the archives of the user.
+func (repo *AccountRepository) ListExportKeys(ctx context.Context, uid int) ([]string, error) {
+	var query = `SELECT file_key FROM data_exports WHERE user_id = ? AND file_key <> ''`
+
+	var keys = make([]string, 0)
+	if err := repo.db.SelectContext(ctx, &keys, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return keys, nil
+}
+
+// Anonymize repository method for soft deleting the user and wiping the personal data.
+func (repo *AccountRepository) Anonymize(ctx context.Context, uid int) error {
+	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return dbErrors.ErrBeginTransaction
+	}
+
+	// The events are matched by the email too, it has to run before the email is replaced
+	var query = `UPDATE auth_events SET email = '', ip = '', user_agent = '' WHERE user_id = ? OR email = (SELECT email FROM users WHERE id = ?)`
+	if _, err := tx.ExecContext(ctx, query, uid, uid); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	query = `UPDATE users
+		SET email = CONCAT('deleted-', id, '@deleted.invalid'), password = 'deleted-account', email_verified_at = NULL, suspended_reason = NULL, updated_at = NOW(), deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, uid)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return dbErrors.ErrUserNotFound
+	}
+
+	for _, query := range anonymizeQueries {
+		if _, err := tx.ExecContext(ctx, query, uid); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return dbErrors.ErrCommit
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGetAccount(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAccountRepository(sqlxDB)
+
+	var query = `SELECT id, email, created_at FROM users WHERE id = ? AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "email", "created_at"}).AddRow(1, "gini@mail.com", time.Now())
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+
+		account, err := repo.GetAccount(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "gini@mail.com", account.Email)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetAccount(ctx, 2)
+		assert.ErrorIs(t, err, dbErrors.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAccountListings(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAccountRepository(sqlxDB)
+
+	var query = `SELECT
+			mb.id,
+			b.uuid,
+			b.name,
+			b.price,
+			mb.available,
+			b.currency_id AS currency,
+			b.created_by AS created_by_id,
+			mb.status,
+			mb.created_at,
+			mb.updated_at
+		FROM market_bonds mb
+			INNER JOIN bonds b ON b.id = mb.bond_id
+		WHERE b.created_by = ? AND mb.deleted_at IS NULL
+		ORDER BY mb.created_at DESC`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "uuid", "name", "price", "available", "currency", "created_by_id", "status", "created_at", "updated_at"}).
+			AddRow(3, "uuid", "Bond A", 100, 0, 1, 1, "bought", time.Now(), nil).
+			AddRow(4, "uuid", "Bond B", 100, 5, 1, 1, "delisted", time.Now(), time.Now())
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(1).WillReturnRows(rows)
+
+		list, err := repo.GetListings(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Equal(t, "delisted", list[1].Status)
+		assert.True(t, list[0].IsOwner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDataExports(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAccountRepository(sqlxDB)
+
+	t.Run("Create", func(t *testing.T) {
+		mock.ExpectPrepare(`INSERT INTO data_exports (user_id, status) VALUES (?, ?)`).ExpectExec().
+			WithArgs(1, domain.ExportPending).
+			WillReturnResult(sqlmock.NewResult(5, 1))
+
+		export := &domain.DataExport{UserID: 1, Status: domain.ExportPending}
+		assert.NoError(t, repo.CreateExport(ctx, export))
+		assert.Equal(t, 5, export.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	var update = `UPDATE data_exports SET status = ?, file_key = ?, size = ?, completed_at = ?, expires_at = ? WHERE id = ?`
+
+	t.Run("Update", func(t *testing.T) {
+		completedAt := time.Now()
+		expiresAt := completedAt.Add(time.Hour)
+		mock.ExpectPrepare(update).ExpectExec().
+			WithArgs(domain.ExportReady, "exports/1/a.zip", int64(120), &completedAt, &expiresAt, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdateExport(ctx, &domain.DataExport{ID: 5, Status: domain.ExportReady, FileKey: "exports/1/a.zip", Size: 120, CompletedAt: &completedAt, ExpiresAt: &expiresAt})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Update Deleted Account", func(t *testing.T) {
+		mock.ExpectPrepare(update).ExpectExec().
+			WithArgs(domain.ExportRunning, "", int64(0), nil, nil, 5).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateExport(ctx, &domain.DataExport{ID: 5, Status: domain.ExportRunning})
+		assert.ErrorIs(t, err, dbErrors.ErrExportNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	var latest = `SELECT id, user_id, status, file_key, size, created_at, completed_at, expires_at
+		FROM data_exports
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT 1`
+
+	t.Run("Get Latest", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "status", "file_key", "size", "created_at", "completed_at", "expires_at"}).
+			AddRow(5, 1, domain.ExportPending, "", 0, time.Now(), nil, nil)
+		mock.ExpectQuery(latest).WithArgs(1).WillReturnRows(rows)
+
+		export, err := repo.GetLatestExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.True(t, export.Active())
+		assert.Nil(t, export.ExpiresAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Get Latest Not Found", func(t *testing.T) {
+		mock.ExpectQuery(latest).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetLatestExport(ctx, 1)
+		assert.ErrorIs(t, err, dbErrors.ErrExportNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("List Keys", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"file_key"}).AddRow("exports/1/a.zip")
+		mock.ExpectQuery(`SELECT file_key FROM data_exports WHERE user_id = ? AND file_key <> ''`).WithArgs(1).WillReturnRows(rows)
+
+		keys, err := repo.ListExportKeys(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"exports/1/a.zip"}, keys)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAnonymize(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAccountRepository(sqlxDB)
+
+	var events = `UPDATE auth_events SET email = '', ip = '', user_agent = '' WHERE user_id = ? OR email = (SELECT email FROM users WHERE id = ?)`
+	var users = `UPDATE users
+		SET email = CONCAT('deleted-', id, '@deleted.invalid'), password = 'deleted-account', email_verified_at = NULL, suspended_reason = NULL, updated_at = NOW(), deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(events).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec(users).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		for _, query := range anonymizeQueries {
+			mock.ExpectExec(query).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Anonymize(ctx, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Deleted", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(events).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(users).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Anonymize(ctx, 1), dbErrors.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls Back On Failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(events).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(users).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(anonymizeQueries[0]).WithArgs(1).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Anonymize(ctx, 1), sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.AlertRepository = (*AlertRepository)(nil)
+
+// alertSelect columns of an alert with its bond
+const alertSelect = `SELECT a.id, a.user_id, a.bond_id, b.uuid, b.name, a.type, a.threshold, a.active, a.last_triggered_at, a.created_at
+		FROM alerts a
+			INNER JOIN bonds b ON b.id = a.bond_id`
+
+// bondMarketSelect columns of a bond with the quantity of it on sale
+const bondMarketSelect = `SELECT b.id, b.uuid, b.name, b.price, b.currency_id AS currency, b.created_by, b.frozen_at IS NOT NULL AS frozen,
+			COALESCE(SUM(mb.available), 0) AS available
+		FROM bonds b
+			LEFT JOIN market_bonds mb ON mb.bond_id = b.id AND mb.status = 'available' AND mb.deleted_at IS NULL`
+
+// AlertRepository struct
+type AlertRepository struct {
+	db *sqlx.DB
+}
+
+// NewAlertRepository Creates a new instance of AlertRepository
+func NewAlertRepository(conn *sqlx.DB) *AlertRepository {
+	return &AlertRepository{
+		db: conn,
+	}
+}
+
+// ListWatchlist repository method for the bonds followed by the user, newest first
+func (repo *AlertRepository) ListWatchlist(ctx context.Context, uid int) ([]*domain.WatchlistItem, error) {
+	var query = `SELECT b.uuid, b.name, b.price, b.currency_id AS currency, w.created_at,
+			COALESCE(SUM(mb.available), 0) AS available
+		FROM watchlist w
+			INNER JOIN bonds b ON b.id = w.bond_id
+			LEFT JOIN market_bonds mb ON mb.bond_id = b.id AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.frozen_at IS NULL
+		WHERE w.user_id = ? AND b.deleted_at IS NULL
+		GROUP BY b.id, w.created_at
+		ORDER BY w.created_at DESC`
+
+	var list = make([]*domain.WatchlistItem, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// AddToWatchlist repository method for following a bond
+func (repo *AlertRepository) AddToWatchlist(ctx context.Context, uid int, bondID int) error {
+	var query = `INSERT IGNORE INTO watchlist (user_id, bond_id) VALUES (?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, uid, bondID); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// RemoveFromWatchlist repository method for unfollowing a bond
+func (repo *AlertRepository) RemoveFromWatchlist(ctx context.Context, uid int, bondUUID string) error {
+	var query = `DELETE w FROM watchlist w INNER JOIN bonds b ON b.id = w.bond_id WHERE w.user_id = ? AND b.uuid = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, uid, bondUUID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrWatchlistItemNotFound
+	}
+
+	return nil
+}
+
+// GetBondMarket repository method for the market of a bond by its id
+func (repo *AlertRepository) GetBondMarket(ctx context.Context, bondID int) (*domain.BondMarket, error) {
+	return repo.getBondMarket(ctx, `b.id = ?`, bondID)
+}
+
+// GetBondMarketByUUID repository method for the market of a bond by its uuid
+func (repo *AlertRepository) GetBondMarketByUUID(ctx context.Context, uuid string) (*domain.BondMarket, error) {
+	return repo.getBondMarket(ctx, `b.uuid = ?`, uuid)
+}
+
+func (repo *AlertRepository) getBondMarket(ctx context.Context, where string, arg interface{}) (*domain.BondMarket, error) {
+	var query = bondMarketSelect + `
+		WHERE ` + where + ` AND b.deleted_at IS NULL
+		GROUP BY b.id`
+
+	var market = &domain.BondMarket{}
+	if err := repo.db.GetContext(ctx, market, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrBondNotExist
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return market, nil
+}
+
+// Create repository method for storing an alert
+func (repo *AlertRepository) Create(ctx context.Context, alert *domain.Alert) error {
+	var query = `INSERT INTO alerts (user_id, bond_id, type, threshold, active) VALUES (?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, alert.UserID, alert.BondID, alert.Type, alert.Threshold, alert.Active)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	alert.ID = int(id)
+
+	return nil
+}
+
+// List repository method for the alerts of the user, newest first
+func (repo *AlertRepository) List(ctx context.Context, uid int) ([]*domain.Alert, error) {
+	var query = alertSelect + `
+		WHERE a.user_id = ?
+		ORDER BY a.id DESC`
+
+	var list = make([]*domain.Alert, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// CountByUser repository method for counting the alerts of the user
+func (repo *AlertRepository) CountByUser(ctx context.Context, uid int) (int, error) {
+	var query = `SELECT COUNT(*) FROM alerts WHERE user_id = ?`
+
+	var count int
+	if err := repo.db.GetContext(ctx, &count, query, uid); err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return count, nil
+}
+
+// GetByID repository method for an alert of the user
+func (repo *AlertRepository) GetByID(ctx context.Context, uid int, id int) (*domain.Alert, error) {
+	var query = alertSelect + `
+		WHERE a.id = ? AND a.user_id = ?`
+
+	var alert = &domain.Alert{}
+	if err := repo.db.GetContext(ctx, alert, query, id, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrAlertNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return alert, nil
+}
+
+// Update repository method for changing an alert of the user
+func (repo *AlertRepository) Update(ctx context.Context, alert *domain.Alert) error {
+	var query = `UPDATE alerts SET bond_id = ?, type = ?, threshold = ?, active = ? WHERE id = ? AND user_id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, alert.BondID, alert.Type, alert.Threshold, alert.Active, alert.ID, alert.UserID); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// Delete repository method for deleting an alert of the user
+func (repo *AlertRepository) Delete(ctx context.Context, uid int, id int) error {
+	var query = `DELETE FROM alerts WHERE id = ? AND user_id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrAlertNotFound
+	}
+
+	return nil
+}
+
+// ListActiveByBond repository method for the active alerts on a bond of the
+// users that aren't deleted
+func (repo *AlertRepository) ListActiveByBond(ctx context.Context, bondID int) ([]*domain.Alert, error) {
+	var query = alertSelect + `
+			INNER JOIN users u ON u.id = a.user_id
+		WHERE a.bond_id = ? AND a.active = TRUE AND u.deleted_at IS NULL
+		ORDER BY a.id`
+
+	var list = make([]*domain.Alert, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, bondID); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// MarkTriggered repository method for setting the trigger date of an alert.
+// The condition on the previous date keeps two instances from triggering the
+// same alert within the cooldown.
+func (repo *AlertRepository) MarkTriggered(ctx context.Context, id int, at time.Time, quietSince time.Time) (bool, error) {
+	var query = `UPDATE alerts SET last_triggered_at = ?
+		WHERE id = ? AND active = TRUE AND (last_triggered_at IS NULL OR last_triggered_at <= ?)`
+	res, err := repo.db.ExecContext(ctx, query, at, id, quietSince)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, dbErrors.ErrRetrieveRows
+	}
+
+	return affected > 0, nil
+}
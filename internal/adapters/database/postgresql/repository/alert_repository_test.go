@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var alertColumns = []string{"id", "user_id", "bond_id", "uuid", "name", "type", "threshold", "active", "last_triggered_at", "created_at"}
+
+var bondMarketColumns = []string{"id", "uuid", "name", "price", "currency", "created_by", "frozen", "available"}
+
+func TestGetBondMarket(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAlertRepository(sqlxDB)
+
+	var query = `SELECT b.id, b.uuid, b.name, b.price, b.currency_id AS currency, b.created_by, b.frozen_at IS NOT NULL AS frozen,
+			COALESCE(SUM(mb.available), 0) AS available
+		FROM bonds b
+			LEFT JOIN market_bonds mb ON mb.bond_id = b.id AND mb.status = 'available' AND mb.deleted_at IS NULL
+		WHERE b.uuid = ? AND b.deleted_at IS NULL
+		GROUP BY b.id`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(bondMarketColumns).AddRow(4, "bond-uuid", "Bond", 9.5, 1, 2, false, 30)
+		mock.ExpectQuery(query).WithArgs("bond-uuid").WillReturnRows(rows)
+
+		market, err := repo.GetBondMarketByUUID(ctx, "bond-uuid")
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.BondMarket{BondID: 4, BondUUID: "bond-uuid", Name: "Bond", Price: 9.5, Currency: 1, SellerID: 2, Available: 30}, market)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("bond-uuid").WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetBondMarketByUUID(ctx, "bond-uuid")
+		assert.ErrorIs(t, err, dbErrors.ErrBondNotExist)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveFromWatchlist(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAlertRepository(sqlxDB)
+
+	var query = `DELETE w FROM watchlist w INNER JOIN bonds b ON b.id = w.bond_id WHERE w.user_id = ? AND b.uuid = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, "bond-uuid").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.RemoveFromWatchlist(ctx, 1, "bond-uuid")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, "bond-uuid").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RemoveFromWatchlist(ctx, 1, "bond-uuid")
+		assert.ErrorIs(t, err, dbErrors.ErrWatchlistItemNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAlertByID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAlertRepository(sqlxDB)
+
+	var query = `SELECT a.id, a.user_id, a.bond_id, b.uuid, b.name, a.type, a.threshold, a.active, a.last_triggered_at, a.created_at
+		FROM alerts a
+			INNER JOIN bonds b ON b.id = a.bond_id
+		WHERE a.id = ? AND a.user_id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(alertColumns).AddRow(8, 1, 4, "bond-uuid", "Bond", domain.AlertPriceBelow, []byte("9.5000"), true, nil, time.Now())
+		mock.ExpectQuery(query).WithArgs(8, 1).WillReturnRows(rows)
+
+		alert, err := repo.GetByID(ctx, 1, 8)
+		assert.NoError(t, err)
+		assert.Equal(t, 9.5, *alert.Threshold)
+		assert.Nil(t, alert.LastTriggeredAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(8, 2).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByID(ctx, 2, 8)
+		assert.ErrorIs(t, err, dbErrors.ErrAlertNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListActiveAlertsByBond(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAlertRepository(sqlxDB)
+
+	var query = `SELECT a.id, a.user_id, a.bond_id, b.uuid, b.name, a.type, a.threshold, a.active, a.last_triggered_at, a.created_at
+		FROM alerts a
+			INNER JOIN bonds b ON b.id = a.bond_id
+			INNER JOIN users u ON u.id = a.user_id
+		WHERE a.bond_id = ? AND a.active = TRUE AND u.deleted_at IS NULL
+		ORDER BY a.id`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(alertColumns).
+			AddRow(8, 1, 4, "bond-uuid", "Bond", domain.AlertPriceBelow, []byte("9.5000"), true, nil, time.Now()).
+			AddRow(9, 3, 4, "bond-uuid", "Bond", domain.AlertNewListing, nil, true, time.Now(), time.Now())
+		mock.ExpectQuery(query).WithArgs(4).WillReturnRows(rows)
+
+		list, err := repo.ListActiveByBond(ctx, 4)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Nil(t, list[1].Threshold)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkAlertTriggered(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAlertRepository(sqlxDB)
+
+	var query = `UPDATE alerts SET last_triggered_at = ?
+		WHERE id = ? AND active = TRUE AND (last_triggered_at IS NULL OR last_triggered_at <= ?)`
+	now := time.Now()
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(now, 8, now.Add(-time.Hour)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		triggered, err := repo.MarkTriggered(ctx, 8, now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.True(t, triggered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cooling down", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(now, 8, now.Add(-time.Hour)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		triggered, err := repo.MarkTriggered(ctx, 8, now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.False(t, triggered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+)
+
+var _ rPort.APIKeyRepository = (*APIKeyRepository)(nil)
+
+// APIKeyRepository struct
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyRepository Creates a new instance of APIKeyRepository
+func NewAPIKeyRepository(conn *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: conn,
+	}
+}
+
+// apiKeyRow the scopes and the allowlist are stored comma separated
+type apiKeyRow struct {
+	domain.APIKey
+	Scopes     string `db:"scopes"`
+	AllowedIPs string `db:"allowed_ips"`
+}
+
+func (row *apiKeyRow) toDomain() *domain.APIKey {
+	key := row.APIKey
+	key.Scopes = splitList(row.Scopes)
+	key.AllowedIPs = splitList(row.AllowedIPs)
+	return &key
+}
+
+// Create repository method for storing a new api key.
+func (repo *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	var query = `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, key.UserID, key.Name, key.Prefix, key.KeyHash,
+		strings.Join(key.Scopes, ","), strings.Join(key.AllowedIPs, ","), key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	key.ID = int(id)
+
+	return nil
+}
+
+// ListByUser repository method for listing the active api keys of a user.
+func (repo *APIKeyRepository) ListByUser(ctx context.Context, uid int) ([]*domain.APIKey, error) {
+	var query = `SELECT id, user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, last_used_ip, usage_count, created_at
+		FROM api_keys
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY id`
+
+	var rows = make([]*apiKeyRow, 0)
+	if err := repo.db.SelectContext(ctx, &rows, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	var list = make([]*domain.APIKey, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.toDomain())
+	}
+
+	return list, nil
+}
+
+// FindByPrefix repository method for loading an active api key by its public prefix.
+// The keys of suspended or deleted users are not found.
+func (repo *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	var query = `SELECT k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.allowed_ips, k.expires_at, k.last_used_at, k.last_used_ip, k.usage_count, k.created_at
+		FROM api_keys k
+			INNER JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = ? AND k.revoked_at IS NULL AND u.suspended_at IS NULL AND u.deleted_at IS NULL`
+
+	var row = &apiKeyRow{}
+	if err := repo.db.GetContext(ctx, row, query, prefix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return row.toDomain(), nil
+}
+
+// Revoke repository method for revoking an api key of the user.
+func (repo *APIKeyRepository) Revoke(ctx context.Context, uid int, id int) error {
+	var query = `UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TrackUsage repository method for counting a request made with the api key.
+func (repo *APIKeyRepository) TrackUsage(ctx context.Context, id int, ip string) error {
+	var query = `UPDATE api_keys SET last_used_at = NOW(), last_used_ip = ?, usage_count = usage_count + 1 WHERE id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, ip, id); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// splitList splits a comma separated column, an empty column is an empty list
+func splitList(s string) []string {
+	if s == "" {
+		return make([]string, 0)
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var apiKeyColumns = []string{"id", "user_id", "name", "prefix", "key_hash", "scopes", "allowed_ips", "expires_at", "last_used_at", "last_used_ip", "usage_count", "created_at"}
+
+func TestCreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAPIKeyRepository(sqlxDB)
+
+	var query = `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		key := &domain.APIKey{
+			UserID:     1,
+			Name:       "bot",
+			Prefix:     "0123456789ab",
+			KeyHash:    "hash",
+			Scopes:     []string{domain.ScopeMarketRead, domain.ScopeMarketTrade},
+			AllowedIPs: []string{"10.0.0.0/8", "192.0.2.1"},
+		}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(1, "bot", "0123456789ab", "hash", "market:read,market:trade", "10.0.0.0/8,192.0.2.1", nil).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		err := repo.Create(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, key.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindAPIKeyByPrefix(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAPIKeyRepository(sqlxDB)
+
+	var query = `SELECT k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.allowed_ips, k.expires_at, k.last_used_at, k.last_used_ip, k.usage_count, k.created_at
+		FROM api_keys k
+			INNER JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = ? AND k.revoked_at IS NULL AND u.suspended_at IS NULL AND u.deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(7, 1, "bot", "0123456789ab", "hash", "market:read", "", nil, nil, "", 0, time.Now())
+		mock.ExpectQuery(query).WithArgs("0123456789ab").WillReturnRows(rows)
+
+		key, err := repo.FindByPrefix(ctx, "0123456789ab")
+		assert.NoError(t, err)
+		assert.Equal(t, 7, key.ID)
+		assert.Equal(t, 1, key.UserID)
+		assert.Equal(t, []string{domain.ScopeMarketRead}, key.Scopes)
+		assert.Empty(t, key.AllowedIPs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		key, err := repo.FindByPrefix(ctx, "missing")
+		assert.ErrorIs(t, err, dbErrors.ErrAPIKeyNotFound)
+		assert.Nil(t, key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Suspended Owner", func(t *testing.T) {
+		// the join leaves out the keys of suspended and deleted users
+		mock.ExpectQuery(query).WithArgs("0123456789ab").WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		key, err := repo.FindByPrefix(ctx, "0123456789ab")
+		assert.ErrorIs(t, err, dbErrors.ErrAPIKeyNotFound)
+		assert.Nil(t, key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAPIKeyRepository(sqlxDB)
+
+	var query = `UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(7, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Revoke(ctx, 1, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(7, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.Revoke(ctx, 2, 7), dbErrors.ErrAPIKeyNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTrackAPIKeyUsage(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAPIKeyRepository(sqlxDB)
+
+	mock.ExpectPrepare(`UPDATE api_keys SET last_used_at = NOW(), last_used_ip = ?, usage_count = usage_count + 1 WHERE id = ?`).
+		ExpectExec().WithArgs("192.0.2.1", 7).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.TrackUsage(ctx, 7, "192.0.2.1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
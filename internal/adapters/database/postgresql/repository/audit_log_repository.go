@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.AuditLogRepository = (*AuditLogRepository)(nil)
+
+// AuditLogRepository struct
+type AuditLogRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditLogRepository Creates a new instance of AuditLogRepository
+func NewAuditLogRepository(conn *sqlx.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: conn,
+	}
+}
+
+// Create repository method for recording an admin action.
+func (repo *AuditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	var query = `INSERT INTO admin_audit_log (admin_id, action, target_type, target_id, details, ip)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, entry.AdminID, entry.Action, entry.TargetType, entry.TargetID, entry.Details, entry.IP)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+)
+
+func TestCreateAuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAuditLogRepository(sqlxDB)
+
+	var query = `INSERT INTO admin_audit_log (admin_id, action, target_type, target_id, details, ip)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	var entry = &domain.AuditLog{
+		AdminID:    1,
+		Action:     domain.AuditSuspendUser,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   2,
+		Details:    "fraud",
+		IP:         "127.0.0.1",
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(entry.AdminID, entry.Action, entry.TargetType, entry.TargetID, entry.Details, entry.IP).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Create(ctx, entry)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exec Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(entry.AdminID, entry.Action, entry.TargetType, entry.TargetID, entry.Details, entry.IP).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Create(ctx, entry)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Prepare Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Create(ctx, entry)
+		assert.ErrorIs(t, err, dbErrors.ErrPrepareStatement)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+)
+
+var _ rPort.AuthEventRepository = (*AuthEventRepository)(nil)
+
+// AuthEventRepository struct
+type AuthEventRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuthEventRepository Creates a new instance of AuthEventRepository
+func NewAuthEventRepository(conn *sqlx.DB) *AuthEventRepository {
+	return &AuthEventRepository{
+		db: conn,
+	}
+}
+
+// Create repository method for recording an auth event.
+func (repo *AuthEventRepository) Create(ctx context.Context, event *domain.AuthEvent) error {
+	var query = `INSERT INTO auth_events (user_id, email, ip, user_agent, event, outcome, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, event.UserID, event.Email, event.IP, event.UserAgent, event.Event, event.Outcome, event.Reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// Search repository method for listing the auth events that match the filters.
+func (repo *AuthEventRepository) Search(ctx context.Context, search *domain.AuthEventSearch) ([]*domain.AuthEvent, error) {
+	var where = []string{"1 = 1"}
+	var args = make([]interface{}, 0)
+	if search.UserID > 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, search.UserID)
+	}
+	if search.Email != "" {
+		where = append(where, "email = ?")
+		args = append(args, search.Email)
+	}
+	if search.IP != "" {
+		where = append(where, "ip = ?")
+		args = append(args, search.IP)
+	}
+	if search.Event != "" {
+		where = append(where, "event = ?")
+		args = append(args, search.Event)
+	}
+	if search.Outcome != "" {
+		where = append(where, "outcome = ?")
+		args = append(args, search.Outcome)
+	}
+	if search.From != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, *search.From)
+	}
+	if search.To != nil {
+		where = append(where, "created_at < ?")
+		args = append(args, *search.To)
+	}
+	args = append(args, search.Limit, search.Offset)
+
+	var query = `SELECT id, user_id, email, ip, user_agent, event, outcome, reason, created_at
+		FROM auth_events
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+
+	var list = make([]*domain.AuthEvent, 0)
+	err := repo.db.SelectContext(ctx, &list, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
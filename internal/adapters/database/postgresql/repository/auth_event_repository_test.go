@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestCreateAuthEvent(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAuthEventRepository(sqlxDB)
+
+	var query = `INSERT INTO auth_events (user_id, email, ip, user_agent, event, outcome, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	var uid = 1
+	var event = &domain.AuthEvent{
+		UserID:  &uid,
+		Email:   "gini@mail.com",
+		IP:      "127.0.0.1",
+		Event:   domain.AuthEventAccountLocked,
+		Outcome: domain.AuthOutcomeBlocked,
+		Reason:  "10 failed attempts",
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(event.UserID, event.Email, event.IP, event.UserAgent, event.Event, event.Outcome, event.Reason).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Create(ctx, event)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exec Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(event.UserID, event.Email, event.IP, event.UserAgent, event.Event, event.Outcome, event.Reason).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Create(ctx, event)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSearchAuthEvents(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAuthEventRepository(sqlxDB)
+	columns := []string{"id", "user_id", "email", "ip", "user_agent", "event", "outcome", "reason", "created_at"}
+
+	t.Run("By User", func(t *testing.T) {
+		var query = `SELECT id, user_id, email, ip, user_agent, event, outcome, reason, created_at
+		FROM auth_events
+		WHERE 1 = 1 AND user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+		rows := sqlmock.NewRows(columns).
+			AddRow(2, 1, "gini@mail.com", "127.0.0.1", "curl/8.0", domain.AuthEventSignIn, domain.AuthOutcomeSuccess, "password", time.Now()).
+			AddRow(1, 1, "gini@mail.com", "127.0.0.1", "curl/8.0", domain.AuthEventSignIn, domain.AuthOutcomeFailure, "bad password", time.Now())
+		mock.ExpectQuery(query).WithArgs(1, 20, 0).WillReturnRows(rows)
+
+		list, err := repo.Search(ctx, &domain.AuthEventSearch{UserID: 1, Limit: 20})
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Equal(t, 1, *list[0].UserID)
+		assert.Equal(t, "bad password", list[1].Reason)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("All Filters", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(24 * time.Hour)
+		var query = `SELECT id, user_id, email, ip, user_agent, event, outcome, reason, created_at
+		FROM auth_events
+		WHERE 1 = 1 AND email = ? AND ip = ? AND event = ? AND outcome = ? AND created_at >= ? AND created_at < ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+		rows := sqlmock.NewRows(columns).
+			AddRow(3, nil, "nobody@mail.com", "10.0.0.1", "", domain.AuthEventSignIn, domain.AuthOutcomeFailure, "unknown email", from)
+		mock.ExpectQuery(query).
+			WithArgs("nobody@mail.com", "10.0.0.1", domain.AuthEventSignIn, domain.AuthOutcomeFailure, from, to, 50, 100).
+			WillReturnRows(rows)
+
+		list, err := repo.Search(ctx, &domain.AuthEventSearch{
+			Email:   "nobody@mail.com",
+			IP:      "10.0.0.1",
+			Event:   domain.AuthEventSignIn,
+			Outcome: domain.AuthOutcomeFailure,
+			From:    &from,
+			To:      &to,
+			Limit:   50,
+			Offset:  100,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+		assert.Nil(t, list[0].UserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query Failed", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, user_id, email, ip, user_agent, event, outcome, reason, created_at
+		FROM auth_events
+		WHERE 1 = 1
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`).WithArgs(20, 0).WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.Search(ctx, &domain.AuthEventSearch{Limit: 20})
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
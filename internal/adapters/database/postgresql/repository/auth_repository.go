@@ -32,6 +32,7 @@ func (repo *AuthRepository) FindByCredentials(ctx context.Context, data *domain.
 	var query = `SELECT id,
 		   email,
 		   password,
+		   suspended_at IS NOT NULL AS suspended,
 		   created_at,
 		   updated_at
 	FROM users
@@ -47,7 +48,7 @@ func (repo *AuthRepository) FindByCredentials(ctx context.Context, data *domain.
 	row := stmt.QueryRowContext(ctx, data.Email)
 	var createdAt sql.NullTime
 	var updatedAt sql.NullTime
-	err = row.Scan(&u.ID, &u.Email, &u.Password, &createdAt, &updatedAt)
+	err = row.Scan(&u.ID, &u.Email, &u.Password, &u.Suspended, &createdAt, &updatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, dbErrors.ErrUserNotFound
@@ -65,31 +66,59 @@ func (repo *AuthRepository) FindByCredentials(ctx context.Context, data *domain.
 	return u, nil
 }
 
-// Register repository method for create a new user.
-func (repo *AuthRepository) Register(ctx context.Context, registerReq *domain.RegisterRequest) error {
-	var query = `INSERT INTO users(email, password) VALUES(?, ?)`
-	stmt, err := repo.db.PreparexContext(ctx, query)
+// FindByID Repository method for loading a user by id
+func (repo *AuthRepository) FindByID(ctx context.Context, uid int) (*domain.User, error) {
+	var query = `SELECT id,
+		   email,
+		   password,
+		   suspended_at IS NOT NULL AS suspended,
+		   created_at,
+		   updated_at
+	FROM users
+	WHERE id = ? AND deleted_at IS NULL`
+
+	var u = &domain.User{}
+	var createdAt sql.NullTime
+	var updatedAt sql.NullTime
+	row := repo.db.QueryRowxContext(ctx, query, uid)
+	err := row.Scan(&u.ID, &u.Email, &u.Password, &u.Suspended, &createdAt, &updatedAt)
 	if err != nil {
-		return dbErrors.ErrPrepareStatement
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrScanData, err)
 	}
-	defer stmt.Close()
+	if createdAt.Valid {
+		u.CreatedAt = createdAt.Time
+	}
+	if updatedAt.Valid {
+		u.UpdatedAt = updatedAt.Time
+	}
+
+	return u, nil
+}
 
+// Register repository method for create a new user.
+func (repo *AuthRepository) Register(ctx context.Context, registerReq *domain.RegisterRequest) error {
 	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		return dbErrors.ErrBeginTransaction
 	}
 
+	var query = `INSERT INTO users(email, password) VALUES(?, ?)`
+	stmt, err := tx.PreparexContext(ctx, query)
+	if err != nil {
+		tx.Rollback()
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
 	res, err := stmt.ExecContext(ctx, registerReq.Email, registerReq.Password)
 
 	if err != nil {
 		log.Println(err)
 		tx.Rollback()
-		log.Println("Code ", my.MySQLErrorCode(err))
-		// log.Println("Code 2 ", errors.Is(err, my.ErrDupeKey))
-		if err.Error() == "Error 1062 (23000): Duplicate entry 'mail3@mail.com' for key 'email'" {
-			return dbErrors.ErrAlreadyExists
-		}
-		if errors.Is(err, my.ErrDupeKey) {
+		if ok, myerr := my.Error(err); ok && errors.Is(myerr, my.ErrDupeKey) {
 			return dbErrors.ErrAlreadyExists
 		} else if errors.Is(err, sql.ErrConnDone) {
 			return sql.ErrConnDone
@@ -100,11 +129,11 @@ func (repo *AuthRepository) Register(ctx context.Context, registerReq *domain.Re
 
 	LastInsID, _ := res.LastInsertId()
 	query = `INSERT INTO users_profile(user_id, username) VALUES(?, ?)`
-	_, err = repo.db.ExecContext(ctx, query, LastInsID, registerReq.Name)
+	_, err = tx.ExecContext(ctx, query, LastInsID, registerReq.Name)
 	if err != nil {
 		log.Println(err)
 		tx.Rollback()
-		if errors.Is(err, my.ErrDupeKey) {
+		if ok, myerr := my.Error(err); ok && errors.Is(myerr, my.ErrDupeKey) {
 			return dbErrors.ErrAlreadyExists
 		} else if errors.Is(err, sql.ErrConnDone) {
 			return sql.ErrConnDone
@@ -113,6 +142,14 @@ func (repo *AuthRepository) Register(ctx context.Context, registerReq *domain.Re
 		}
 	}
 
+	query = `INSERT INTO user_roles(user_id, role_id) SELECT ?, id FROM roles WHERE name = ?`
+	_, err = tx.ExecContext(ctx, query, LastInsID, domain.RoleCustomer)
+	if err != nil {
+		log.Println(err)
+		tx.Rollback()
+		return dbErrors.ErrScanData
+	}
+
 	if err = tx.Commit(); err != nil {
 		tx.Rollback()
 		return dbErrors.ErrCommit
@@ -120,3 +157,28 @@ func (repo *AuthRepository) Register(ctx context.Context, registerReq *domain.Re
 
 	return nil
 }
+
+// UpdatePassword repository method for replace the password hash of a user.
+func (repo *AuthRepository) UpdatePassword(ctx context.Context, uid string, hash string) error {
+	var query = `UPDATE users SET password = ?, updated_at = NOW() WHERE id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, hash, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrUpdatingRecord, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrUserNotFound
+	}
+
+	return nil
+}
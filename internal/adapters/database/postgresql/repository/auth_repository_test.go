@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"kiramishima/m-backend/internal/core/domain"
@@ -35,10 +36,10 @@ func TestFindByCredentials(t *testing.T) {
 	}
 
 	t.Run("OK", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "password", "created_at", "updated_at"}).
-			AddRow(user.ID, user.Email, user.Password, user.CreatedAt, user.UpdatedAt)
+		rows := sqlmock.NewRows([]string{"id", "email", "password", "suspended", "created_at", "updated_at"}).
+			AddRow(user.ID, user.Email, user.Password, user.Suspended, user.CreatedAt, user.UpdatedAt)
 
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ?").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(form.Email).
 			WillReturnRows(rows)
@@ -51,7 +52,7 @@ func TestFindByCredentials(t *testing.T) {
 	})
 
 	t.Run("Query Failed", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ?").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(form.Email).
 			WillReturnError(sql.ErrConnDone)
@@ -64,7 +65,7 @@ func TestFindByCredentials(t *testing.T) {
 	})
 
 	t.Run("Prepare Failed", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ?").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			WillReturnError(sql.ErrConnDone)
 
 		userMock, err := repo.FindByCredentials(ctx, &domain.AuthRequest{Email: form.Email, Password: form.Password})
@@ -75,7 +76,7 @@ func TestFindByCredentials(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ?").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(form.Email).
 			WillReturnError(sql.ErrNoRows)
@@ -97,77 +98,101 @@ func TestRegister(t *testing.T) {
 	ctx := context.Background()
 	repo := NewAuthRepository(sqlxDB)
 
-	var users = []*domain.User{
-		{
-			ID:        "1",
-			Email:     "gini@mail.com",
-			Password:  "12356",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Time{},
-		},
-		{
-			ID:        "2",
-			Email:     "gin@mail.com",
-			Password:  "12356",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Time{},
-		},
-	}
-
-	form := &domain.AuthRequest{
+	form := &domain.RegisterRequest{
 		Email:    "gini2@mail.com",
 		Password: "12356",
-	}
-
-	form2 := &domain.AuthRequest{
-		Email:    "gini@mail.com",
-		Password: "12356",
+		Name:     "gini1234",
 	}
 
 	t.Run("OK", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "password", "created_at", "updated_at"}).
-			AddRow(users[0].ID, users[0].Email, users[0].Password, users[0].CreatedAt, users[0].UpdatedAt).
-			AddRow(users[1].ID, users[1].Email, users[1].Password, users[1].CreatedAt, users[1].UpdatedAt)
-
+		mock.ExpectBegin()
 		mock.ExpectPrepare("INSERT INTO users(email, password) VALUES(?, ?)").
-			ExpectQuery().
+			ExpectExec().
 			WithArgs(form.Email, form.Password).
-			WillReturnRows(rows)
-
-		err := repo.Register(ctx, &domain.RegisterRequest{Email: form.Email, Password: form.Password})
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectExec("INSERT INTO users_profile(user_id, username) VALUES(?, ?)").
+			WithArgs(3, form.Name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_roles(user_id, role_id) SELECT ?, id FROM roles WHERE name = ?").
+			WithArgs(3, domain.RoleCustomer).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.Register(ctx, form)
 		assert.NoError(t, err)
-		assert.Nil(t, err)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Duplicate record", func(t *testing.T) {
+		mock.ExpectBegin()
 		mock.ExpectPrepare("INSERT INTO users(email, password) VALUES(?, ?)").
-			ExpectQuery().
-			WithArgs(form2.Email, form2.Password).
-			WillReturnError(dbErrors.ErrAlreadyExists)
+			ExpectExec().
+			WithArgs(form.Email, form.Password).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+		mock.ExpectRollback()
 
-		err := repo.Register(ctx, &domain.RegisterRequest{Email: form2.Email, Password: form2.Password})
-		// t.Log(err)
+		err := repo.Register(ctx, form)
 		assert.ErrorIs(t, err, dbErrors.ErrAlreadyExists)
-		// assert.NoError(t, mock.ExpectationsWereMet())
+
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("Query Failed", func(t *testing.T) {
+	t.Run("Prepare Failed", func(t *testing.T) {
+		mock.ExpectBegin()
 		mock.ExpectPrepare("INSERT INTO users(email, password) VALUES(?, ?)").
 			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err := repo.Register(ctx, form)
+		assert.ErrorIs(t, err, dbErrors.ErrPrepareStatement)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewAuthRepository(sqlxDB)
+
+	var query = "UPDATE users SET password = ?, updated_at = NOW() WHERE id = ?"
+	var hash = "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA"
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(hash, "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePassword(ctx, "1", hash)
+		assert.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(hash, "2").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdatePassword(ctx, "2", hash)
+		assert.ErrorIs(t, err, dbErrors.ErrUserNotFound)
 
-		err := repo.Register(ctx, &domain.RegisterRequest{Email: form2.Email, Password: form2.Password})
-		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Prepare Failed", func(t *testing.T) {
-		mock.ExpectPrepare("INSERT INTO users(email, password) VALUES(?, ?)").
-			WillReturnError(dbErrors.ErrPrepareStatement)
+		mock.ExpectPrepare(query).
+			WillReturnError(sql.ErrConnDone)
 
-		err := repo.Register(ctx, &domain.RegisterRequest{Email: form.Email, Password: form.Password})
-		assert.Error(t, err)
+		err := repo.UpdatePassword(ctx, "1", hash)
+		assert.ErrorIs(t, err, dbErrors.ErrPrepareStatement)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -8,7 +8,6 @@ import (
 	my "github.com/go-mysql/errors"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	cache "kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/core/domain"
 	rPort "kiramishima/m-backend/internal/core/ports/repository"
 	dbErrors "kiramishima/m-backend/pkg/errors"
@@ -19,15 +18,13 @@ var _ rPort.BondRepository = (*BondRepository)(nil)
 
 // BondRepository struct
 type BondRepository struct {
-	db    *sqlx.DB
-	cache *cache.RedisCache
+	db *sqlx.DB
 }
 
 // NewBondRepository Creates a new instance of BondRepository
-func NewBondRepository(conn *sqlx.DB, cache *cache.RedisCache) *BondRepository {
+func NewBondRepository(conn *sqlx.DB) *BondRepository {
 	return &BondRepository{
-		db:    conn,
-		cache: cache,
+		db: conn,
 	}
 }
 
@@ -43,13 +40,14 @@ func (repo *BondRepository) ListBonds(ctx context.Context, uid int) ([]*domain.B
     		up.username AS created_by,
     		b.created_by AS created_by_id,
     		b.status,
-    		(SELECT COUNT(*) FROM market_bonds WHERE bond_id = b.id) on_sale,
+    		(SELECT COUNT(*) FROM market_bonds WHERE bond_id = b.id AND status = 'available' AND deleted_at IS NULL) > 0 AS on_sale,
+    		b.frozen_at IS NOT NULL AS frozen,
     		b.created_at,
     		b.updated_at
     	FROM bonds b
 			INNER JOIN currencies c on c.id = b.currency_id
 			INNER JOIN users_profile up on b.created_by = up.user_id
-		WHERE b.deleted_at IS NOT NULL AND b.created_by = ?`
+		WHERE b.deleted_at IS NULL AND b.created_by = ?`
 
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
@@ -71,7 +69,7 @@ func (repo *BondRepository) ListBonds(ctx context.Context, uid int) ([]*domain.B
 		var createAt sql.NullTime
 		var updatedAt sql.NullTime
 		var item = &domain.Bond{}
-		err = rows.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Number, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &createAt, &updatedAt)
+		err = rows.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Number, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &item.OnSale, &item.Frozen, &createAt, &updatedAt)
 		if err != nil {
 			break
 		}
@@ -103,13 +101,14 @@ func (repo *BondRepository) GetBondByID(ctx context.Context, bond_id int) (*doma
     		up.username AS created_by,
     		b.created_by AS created_by_id,
     		b.status,
-    		(SELECT COUNT(*) FROM market_bonds WHERE bond_id = b.id) on_sale,
+    		(SELECT COUNT(*) FROM market_bonds WHERE bond_id = b.id AND status = 'available' AND deleted_at IS NULL) > 0 AS on_sale,
+    		b.frozen_at IS NOT NULL AS frozen,
     		b.created_at,
     		b.updated_at
     	FROM bonds b
 			INNER JOIN currencies c on c.id = b.currency_id
 			INNER JOIN users_profile up on b.created_by = up.user_id
-		WHERE b.deleted_at IS NOT NULL AND b.id = ?`
+		WHERE b.deleted_at IS NULL AND b.id = ?`
 
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
@@ -122,7 +121,7 @@ func (repo *BondRepository) GetBondByID(ctx context.Context, bond_id int) (*doma
 	var createAt sql.NullTime
 	var updatedAt sql.NullTime
 	var item = &domain.Bond{}
-	err = row.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Number, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &createAt, &updatedAt)
+	err = row.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Number, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &item.OnSale, &item.Frozen, &createAt, &updatedAt)
 	if err != nil {
 		return nil, dbErrors.ErrScanData
 	}
@@ -152,9 +151,10 @@ func (repo *BondRepository) CreateBond(ctx context.Context, data *domain.BondReq
 
 	// uuid
 	var uid = uuid.NewString()
-	_, err = repo.db.ExecContext(ctx, query, uid, data.Name, data.Number, data.Price, data.CurrencyID, data.CreatedBy, data.Status)
+	_, err = tx.ExecContext(ctx, query, uid, data.Name, data.Number, data.Price, data.CurrencyID, data.CreatedBy, data.Status)
 
 	if err != nil {
+		tx.Rollback()
 		if ok, myerr := my.Error(err); ok {
 			if errors.Is(myerr, my.ErrDupeKey) {
 				return dbErrors.ErrBondAlreadyExists
@@ -184,16 +184,11 @@ func (repo *BondRepository) UpdateBond(ctx context.Context, udata *domain.Bond)
 		return dbErrors.ErrBeginTransaction
 	}
 	// uuid
-	_, err = repo.db.ExecContext(ctx, query, udata.Name, udata.Number, udata.Price, udata.Currency, udata.CreatedBy, udata.Status, udata.UUID, udata.CreatedByID)
+	_, err = tx.ExecContext(ctx, query, udata.Name, udata.Number, udata.Price, udata.Currency, udata.Status, udata.UUID, udata.CreatedByID)
 
 	if err != nil {
 		tx.Rollback()
-		switch {
-		case errors.Is(err, dbErrors.ErrNoRecords):
-			return dbErrors.ErrUpdatingRecord
-		default:
-			return dbErrors.InternalServerError
-		}
+		return fmt.Errorf("%s: %w", dbErrors.ErrUpdatingRecord, err)
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -237,3 +232,31 @@ func (repo *BondRepository) DeleteBond(ctx context.Context, bond_id int) error {
 
 	return nil
 }
+
+// SetFrozen repository method for freezing or unfreezing a bond from trading.
+func (repo *BondRepository) SetFrozen(ctx context.Context, bond_id int, frozen bool) error {
+	var query = `UPDATE bonds SET frozen_at = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	if frozen {
+		query = `UPDATE bonds SET frozen_at = NOW(), updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	}
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, bond_id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrBondNotExist
+	}
+
+	return nil
+}
@@ -24,7 +24,7 @@ func TestListBonds(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewBondRepository(sqlxDB)
 
 	var uuid1 = uuid.NewString()
 	var uuid2 = uuid.NewString()
@@ -54,27 +54,33 @@ func TestListBonds(t *testing.T) {
 	}
 
 	var query = `SELECT
+    		b.id,
     		b.uuid,
     		b.name,
     		b.price,
+    		b.number,
     		c.currency,
     		up.username AS created_by,
     		b.created_by AS created_by_id,
     		b.status,
+    		(SELECT COUNT(*) FROM market_bonds WHERE bond_id = b.id AND status = 'available' AND deleted_at IS NULL) > 0 AS on_sale,
+    		b.frozen_at IS NOT NULL AS frozen,
     		b.created_at,
     		b.updated_at
     	FROM bonds b
 			INNER JOIN currencies c on c.id = b.currency_id
-			INNER JOIN users_profile up on b.created_by = up.user_id`
+			INNER JOIN users_profile up on b.created_by = up.user_id
+		WHERE b.deleted_at IS NULL AND b.created_by = ?`
 
-	rows := sqlmock.NewRows([]string{"uuid", "name", "price", "currency", "created_by", "created_by_id", "status", "created_at", "updated_at"}).
-		AddRow(&bonds[0].UUID, bonds[0].Name, bonds[0].Price, bonds[0].Currency, bonds[0].CreatedBy, bonds[0].CreatedByID, bonds[0].Status, bonds[0].CreatedAt, bonds[0].UpdateAt).
-		AddRow(&bonds[1].UUID, bonds[1].Name, bonds[1].Price, bonds[1].Currency, bonds[1].CreatedBy, bonds[1].CreatedByID, bonds[1].Status, bonds[1].CreatedAt, bonds[1].UpdateAt)
+	rows := sqlmock.NewRows([]string{"id", "uuid", "name", "price", "number", "currency", "created_by", "created_by_id", "status", "on_sale", "frozen", "created_at", "updated_at"}).
+		AddRow(1, bonds[0].UUID, bonds[0].Name, bonds[0].Price, bonds[0].Number, bonds[0].Currency, bonds[0].CreatedBy, bonds[0].CreatedByID, bonds[0].Status, false, false, bonds[0].CreatedAt, bonds[0].UpdateAt).
+		AddRow(2, bonds[1].UUID, bonds[1].Name, bonds[1].Price, bonds[1].Number, bonds[1].Currency, bonds[1].CreatedBy, bonds[1].CreatedByID, bonds[1].Status, true, false, bonds[1].CreatedAt, bonds[1].UpdateAt)
 
 	t.Run("OK", func(t *testing.T) {
 
 		mock.ExpectPrepare(query).
 			ExpectQuery().
+			WithArgs(1).
 			WillReturnRows(rows)
 
 		list, err := repo.ListBonds(ctx, 1)
@@ -82,12 +88,14 @@ func TestListBonds(t *testing.T) {
 		assert.NotEmpty(t, list)
 		assert.Equal(t, len(list), 2)
 		assert.Equal(t, list[0].UUID, uuid1)
+		assert.True(t, list[1].OnSale)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Query Failed", func(t *testing.T) {
 		mock.ExpectPrepare(query).
 			ExpectQuery().
+			WithArgs(1).
 			WillReturnError(sql.ErrConnDone)
 
 		list, err := repo.ListBonds(ctx, 1)
@@ -119,7 +127,7 @@ func TestCreateBond(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewBondRepository(sqlxDB)
 
 	var bonds = []*domain.Bond{
 		{
@@ -191,13 +199,13 @@ func TestCreateBond(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.ErrBondAlreadyExists)
 
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.CreateBond(ctx, bondExisting)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrBondAlreadyExists)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Exec Failed", func(t *testing.T) {
@@ -205,13 +213,13 @@ func TestCreateBond(t *testing.T) {
 		mock.ExpectExec(query).
 			WithArgs(sqlmock.AnyArg(), bondExisting.Name, bondExisting.Number, bondExisting.Price, bondExisting.CurrencyID, bondExisting.CreatedBy, bondExisting.Status).
 			WillReturnError(dbErrors.ErrExecuteQuery)
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.CreateBond(ctx, bondExisting)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrExecuteQuery)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -225,7 +233,7 @@ func TestUpdateBond(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewBondRepository(sqlxDB)
 
 	var bonds = []*domain.Bond{
 		{
@@ -264,7 +272,7 @@ func TestUpdateBond(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedByID, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
@@ -279,33 +287,76 @@ func TestUpdateBond(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedBy, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.ErrUpdatingRecord)
 
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.UpdateBond(ctx, bond)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrUpdatingRecord)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Query Failed", func(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedBy, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.InternalServerError)
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.UpdateBond(ctx, bond)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.InternalServerError)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
 func TestDeleteBond(t *testing.T) {}
+
+func TestSetFrozen(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewBondRepository(sqlxDB)
+
+	t.Run("Freeze", func(t *testing.T) {
+		mock.ExpectPrepare("UPDATE bonds SET frozen_at = NOW(), updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			ExpectExec().
+			WithArgs(5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SetFrozen(ctx, 5, true)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unfreeze", func(t *testing.T) {
+		mock.ExpectPrepare("UPDATE bonds SET frozen_at = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			ExpectExec().
+			WithArgs(5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SetFrozen(ctx, 5, false)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare("UPDATE bonds SET frozen_at = NOW(), updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			ExpectExec().
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.SetFrozen(ctx, 9, true)
+		assert.ErrorIs(t, err, dbErrors.ErrBondNotExist)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
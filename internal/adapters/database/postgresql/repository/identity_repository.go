@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.IdentityRepository = (*IdentityRepository)(nil)
+
+// IdentityRepository struct
+type IdentityRepository struct {
+	db *sqlx.DB
+}
+
+// NewIdentityRepository Creates a new instance of IdentityRepository
+func NewIdentityRepository(conn *sqlx.DB) *IdentityRepository {
+	return &IdentityRepository{
+		db: conn,
+	}
+}
+
+// FindUserID repository method for resolving the user linked to an identity.
+func (repo *IdentityRepository) FindUserID(ctx context.Context, issuer string, subject string) (int, error) {
+	var query = `SELECT user_id FROM user_identities WHERE issuer = ? AND subject = ?`
+
+	var uid int
+	if err := repo.db.GetContext(ctx, &uid, query, issuer, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, dbErrors.ErrIdentityNotLinked
+		}
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return uid, nil
+}
+
+// Link repository method for linking an identity to a user. A link never moves
+// to another user, a repeated link only refreshes the email.
+func (repo *IdentityRepository) Link(ctx context.Context, identity *domain.UserIdentity) error {
+	var query = `INSERT INTO user_identities (user_id, issuer, subject, email) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE email = VALUES(email)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, identity.UserID, identity.Issuer, identity.Subject, identity.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
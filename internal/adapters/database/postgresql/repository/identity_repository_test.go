@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+)
+
+func TestFindIdentityUserID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewIdentityRepository(sqlxDB)
+
+	var query = `SELECT user_id FROM user_identities WHERE issuer = ? AND subject = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("https://idp.example.com", "sub-1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+
+		uid, err := repo.FindUserID(ctx, "https://idp.example.com", "sub-1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, uid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Linked", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("https://idp.example.com", "sub-2").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindUserID(ctx, "https://idp.example.com", "sub-2")
+		assert.ErrorIs(t, err, dbErrors.ErrIdentityNotLinked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLinkIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewIdentityRepository(sqlxDB)
+
+	var query = `INSERT INTO user_identities (user_id, issuer, subject, email) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE email = VALUES(email)`
+	var identity = &domain.UserIdentity{UserID: 1, Issuer: "https://idp.example.com", Subject: "sub-1", Email: "gini@mail.com"}
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Link(ctx, identity)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exec Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Link(ctx, identity)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
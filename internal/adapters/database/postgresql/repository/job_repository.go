@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.JobRepository = (*JobRepository)(nil)
+
+// JobRepository struct
+type JobRepository struct {
+	db *sqlx.DB
+}
+
+// NewJobRepository Creates a new instance of JobRepository
+func NewJobRepository(conn *sqlx.DB) *JobRepository {
+	return &JobRepository{
+		db: conn,
+	}
+}
+
+// CreateRun repository method for storing a run that started
+func (repo *JobRepository) CreateRun(ctx context.Context, run *domain.JobRun) error {
+	var query = `INSERT INTO job_runs (job, instance, source, status, started_at) VALUES (?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, run.Job, run.Instance, run.Source, run.Status, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	run.ID = int(id)
+
+	return nil
+}
+
+// FinishRun repository method for storing the result of a run
+func (repo *JobRepository) FinishRun(ctx context.Context, run *domain.JobRun) error {
+	var query = `UPDATE job_runs SET status = ?, error = ?, finished_at = ? WHERE id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, run.Status, run.Error, run.FinishedAt, run.ID); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// ListRuns repository method for the latest runs of a job, newest first
+func (repo *JobRepository) ListRuns(ctx context.Context, job string, limit int) ([]*domain.JobRun, error) {
+	var query = `SELECT id, job, instance, source, status, error, started_at, finished_at
+		FROM job_runs
+		WHERE job = ?
+		ORDER BY id DESC
+		LIMIT ?`
+
+	var list = make([]*domain.JobRun, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, job, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// ListLatestRuns repository method for the last run of every job
+func (repo *JobRepository) ListLatestRuns(ctx context.Context) ([]*domain.JobRun, error) {
+	var query = `SELECT r.id, r.job, r.instance, r.source, r.status, r.error, r.started_at, r.finished_at
+		FROM job_runs r
+			INNER JOIN (SELECT MAX(id) AS id FROM job_runs GROUP BY job) l ON l.id = r.id`
+
+	var list = make([]*domain.JobRun, 0)
+	if err := repo.db.SelectContext(ctx, &list, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// FailStaleRuns repository method for failing the runs left running by a
+// replica that stopped
+func (repo *JobRepository) FailStaleRuns(ctx context.Context, before time.Time) (int64, error) {
+	var query = `UPDATE job_runs SET status = 'failed', error = 'interrupted', finished_at = NOW()
+		WHERE status = 'running' AND started_at < ?`
+	res, err := repo.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbErrors.ErrRetrieveRows
+	}
+
+	return affected, nil
+}
+
+// PurgeRuns repository method for deleting the runs finished before the date
+func (repo *JobRepository) PurgeRuns(ctx context.Context, before time.Time) (int64, error) {
+	var query = `DELETE FROM job_runs WHERE status != 'running' AND started_at < ?`
+	res, err := repo.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbErrors.ErrRetrieveRows
+	}
+
+	return affected, nil
+}
+
+// ListPaused repository method for the names of the paused jobs
+func (repo *JobRepository) ListPaused(ctx context.Context) ([]string, error) {
+	var query = `SELECT name FROM scheduled_jobs WHERE paused = TRUE`
+
+	var list = make([]string, 0)
+	if err := repo.db.SelectContext(ctx, &list, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// IsPaused repository method for the state of a job
+func (repo *JobRepository) IsPaused(ctx context.Context, job string) (bool, error) {
+	var query = `SELECT EXISTS(SELECT 1 FROM scheduled_jobs WHERE name = ? AND paused = TRUE)`
+
+	var paused bool
+	if err := repo.db.GetContext(ctx, &paused, query, job); err != nil {
+		return false, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return paused, nil
+}
+
+// SetPaused repository method for pausing or resuming a job
+func (repo *JobRepository) SetPaused(ctx context.Context, job string, paused bool) error {
+	var query = `INSERT INTO scheduled_jobs (name, paused) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE paused = VALUES(paused)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, job, paused); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+var jobRunColumns = []string{"id", "job", "instance", "source", "status", "error", "started_at", "finished_at"}
+
+func TestCreateJobRun(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewJobRepository(sqlxDB)
+	started := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	var query = `INSERT INTO job_runs (job, instance, source, status, started_at) VALUES (?, ?, ?, ?, ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		run := &domain.JobRun{Job: "job_runs.purge", Instance: "api-1", Source: domain.JobSourceSchedule, Status: domain.JobRunRunning, StartedAt: started}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("job_runs.purge", "api-1", domain.JobSourceSchedule, domain.JobRunRunning, started).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		err := repo.CreateRun(ctx, run)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, run.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exec Error", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("job_runs.purge", "", "", "", started).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.CreateRun(ctx, &domain.JobRun{Job: "job_runs.purge", StartedAt: started})
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListLatestJobRuns(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewJobRepository(sqlxDB)
+	started := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	finished := started.Add(time.Minute)
+
+	var query = `SELECT r.id, r.job, r.instance, r.source, r.status, r.error, r.started_at, r.finished_at
+		FROM job_runs r
+			INNER JOIN (SELECT MAX(id) AS id FROM job_runs GROUP BY job) l ON l.id = r.id`
+
+	rows := sqlmock.NewRows(jobRunColumns).
+		AddRow(7, "job_runs.purge", "api-1", "schedule", "failed", "boom", started, finished).
+		AddRow(8, "cache.warm", "api-2", "manual", "running", "", started, nil)
+	mock.ExpectQuery(query).WillReturnRows(rows)
+
+	list, err := repo.ListLatestRuns(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.JobRun{
+		{ID: 7, Job: "job_runs.purge", Instance: "api-1", Source: domain.JobSourceSchedule, Status: domain.JobRunFailed, Error: "boom", StartedAt: started, FinishedAt: &finished},
+		{ID: 8, Job: "cache.warm", Instance: "api-2", Source: domain.JobSourceManual, Status: domain.JobRunRunning, StartedAt: started},
+	}, list)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFailStaleJobRuns(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewJobRepository(sqlxDB)
+	before := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	var query = `UPDATE job_runs SET status = 'failed', error = 'interrupted', finished_at = NOW()
+		WHERE status = 'running' AND started_at < ?`
+	mock.ExpectExec(query).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.FailStaleRuns(ctx, before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobPaused(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewJobRepository(sqlxDB)
+
+	t.Run("SetPaused", func(t *testing.T) {
+		mock.ExpectPrepare(`INSERT INTO scheduled_jobs (name, paused) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE paused = VALUES(paused)`).ExpectExec().
+			WithArgs("job_runs.purge", true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SetPaused(ctx, "job_runs.purge", true)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("IsPaused", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT EXISTS(SELECT 1 FROM scheduled_jobs WHERE name = ? AND paused = TRUE)`).
+			WithArgs("job_runs.purge").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		paused, err := repo.IsPaused(ctx, "job_runs.purge")
+		assert.NoError(t, err)
+		assert.True(t, paused)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
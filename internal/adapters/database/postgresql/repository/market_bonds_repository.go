@@ -4,8 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/jmoiron/sqlx"
-	cache "kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/core/domain"
 	rPort "kiramishima/m-backend/internal/core/ports/repository"
 	dbErrors "kiramishima/m-backend/pkg/errors"
@@ -15,15 +15,13 @@ var _ rPort.MarketBondRepository = (*MarketBondRepository)(nil)
 
 // MarketBondRepository struct
 type MarketBondRepository struct {
-	db    *sqlx.DB
-	cache *cache.RedisCache
+	db *sqlx.DB
 }
 
 // NewMarketBondRepository Creates a new instance of BondRepository
-func NewMarketBondRepository(conn *sqlx.DB, cache *cache.RedisCache) *MarketBondRepository {
+func NewMarketBondRepository(conn *sqlx.DB) *MarketBondRepository {
 	return &MarketBondRepository{
-		db:    conn,
-		cache: cache,
+		db: conn,
 	}
 }
 
@@ -38,14 +36,17 @@ func (repo *MarketBondRepository) ListMarketBonds(ctx context.Context, uid int)
     		c.currency,
     		up.username AS created_by,
     		b.created_by AS created_by_id,
+    		sr.rating AS seller_rating,
+    		COALESCE(sr.ratings, 0) AS seller_ratings,
     		b.status,
     		b.created_at,
     		b.updated_at
     	FROM market_bonds mb
-			INNER JOIN bonds b on b.id = mb.bonds_id
+			INNER JOIN bonds b on b.id = mb.bond_id
 			INNER JOIN currencies c on c.id = b.currency_id
 			INNER JOIN users_profile up on b.created_by = up.user_id
-		WHERE b.status = 'on_sell'`
+			LEFT JOIN (SELECT seller_id, ROUND(AVG(score), 2) AS rating, COUNT(*) AS ratings FROM seller_ratings GROUP BY seller_id) sr on sr.seller_id = b.created_by
+		WHERE b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.deleted_at IS NULL AND b.frozen_at IS NULL`
 
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
@@ -67,7 +68,7 @@ func (repo *MarketBondRepository) ListMarketBonds(ctx context.Context, uid int)
 		var createAt sql.NullTime
 		var updatedAt sql.NullTime
 		var item = &domain.MarketBond{}
-		err = rows.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Available, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &createAt, &updatedAt)
+		err = rows.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Available, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.SellerRating, &item.SellerRatings, &item.Status, &createAt, &updatedAt)
 		if err != nil {
 			break
 		}
@@ -99,14 +100,17 @@ func (repo *MarketBondRepository) GetMarketBondByID(ctx context.Context, market_
     		c.currency,
     		up.username AS created_by,
     		b.created_by AS created_by_id,
+    		sr.rating AS seller_rating,
+    		COALESCE(sr.ratings, 0) AS seller_ratings,
     		b.status,
     		b.created_at,
     		b.updated_at
     	FROM market_bonds mb
-			INNER JOIN bonds b on b.id = mb.bonds_id
+			INNER JOIN bonds b on b.id = mb.bond_id
 			INNER JOIN currencies c on c.id = b.currency_id
 			INNER JOIN users_profile up on b.created_by = up.user_id
-		WHERE b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NOT NULL AND b.deleted_at IS NOT NULL AND b.uuid = ?`
+			LEFT JOIN (SELECT seller_id, ROUND(AVG(score), 2) AS rating, COUNT(*) AS ratings FROM seller_ratings GROUP BY seller_id) sr on sr.seller_id = b.created_by
+		WHERE b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.deleted_at IS NULL AND b.frozen_at IS NULL AND mb.id = ?`
 
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
@@ -119,8 +123,11 @@ func (repo *MarketBondRepository) GetMarketBondByID(ctx context.Context, market_
 	var createAt sql.NullTime
 	var updatedAt sql.NullTime
 	var item = &domain.MarketBond{}
-	err = row.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Available, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &createAt, &updatedAt)
+	err = row.Scan(&item.ID, &item.UUID, &item.Name, &item.Price, &item.Available, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.SellerRating, &item.SellerRatings, &item.Status, &createAt, &updatedAt)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrNoRecords
+		}
 		return nil, dbErrors.ErrScanData
 	}
 	if createAt.Valid {
@@ -137,52 +144,64 @@ func (repo *MarketBondRepository) GetMarketBondByID(ctx context.Context, market_
 }
 
 // BuyMarketBond repository method
-func (repo *MarketBondRepository) BuyMarketBond(ctx context.Context, order *domain.MarketBondRequest) error {
+func (repo *MarketBondRepository) BuyMarketBond(ctx context.Context, order *domain.MarketBondRequest) (int, error) {
 	var mbond = struct {
-		BondID    int `db:"bond_id"`
-		Available int `db:"available"`
+		BondID    int  `db:"bond_id"`
+		SellerID  int  `db:"seller_id"`
+		Available int  `db:"available"`
+		Frozen    bool `db:"frozen"`
 	}{
 		BondID:    0,
 		Available: 0,
 	}
-	var query = `SELECT id, bond_id, available FROM market_bonds WHERE id = ? LIMIT 1`
-	err := repo.db.SelectContext(ctx, &mbond, query, order.MarketBondID)
+	var query = `SELECT mb.bond_id, b.created_by AS seller_id, mb.available, b.frozen_at IS NOT NULL AS frozen
+		FROM market_bonds mb
+			INNER JOIN bonds b ON b.id = mb.bond_id
+		WHERE mb.id = ? AND mb.status = 'available' AND mb.deleted_at IS NULL LIMIT 1`
+	err := repo.db.GetContext(ctx, &mbond, query, order.MarketBondID)
 	if err != nil {
-		return dbErrors.ErrExecuteQuery
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, dbErrors.ErrMarketBondNotExist
+		}
+		return 0, dbErrors.ErrExecuteQuery
+	}
+
+	if mbond.Frozen {
+		return 0, dbErrors.ErrBondFrozen
 	}
 
 	if mbond.Available < *order.Order {
-		return dbErrors.ErrNoAvailableBonds
+		return 0, dbErrors.ErrNoAvailableBonds
 	}
 
 	// Init TX
 	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 
 	if err != nil {
-		return dbErrors.ErrBeginTransaction
+		return 0, dbErrors.ErrBeginTransaction
 	}
 
 	op := mbond.Available - *order.Order
 	status := "available"
 	if op < 0 {
 		tx.Rollback()
-		return dbErrors.ErrNoAvailableBonds
+		return 0, dbErrors.ErrNoAvailableBonds
 	} else if op == 0 {
 		status = "bought"
 	}
 
 	query = `INSERT INTO transactions (seller_id, buyer_id, bond_id, total_acquired, status)
 		VALUES(?, ?, ?, ?, ?)`
-	result, err := repo.db.ExecContext(ctx, query, order.SellerID, order.BuyerID, mbond.BondID, order.Order, 0)
+	result, err := repo.db.ExecContext(ctx, query, mbond.SellerID, order.BuyerID, mbond.BondID, order.Order, 0)
 	LastInsID, _ := result.LastInsertId()
 
 	if err != nil {
 		tx.Rollback()
 		switch {
 		case errors.Is(err, dbErrors.ErrNoRecords):
-			return dbErrors.ErrDeleteBond
+			return 0, dbErrors.ErrDeleteBond
 		default:
-			return dbErrors.ErrDeleteBond
+			return 0, dbErrors.ErrDeleteBond
 		}
 	}
 	// Update Market Bonds
@@ -193,9 +212,9 @@ func (repo *MarketBondRepository) BuyMarketBond(ctx context.Context, order *doma
 		tx.Rollback()
 		switch {
 		case errors.Is(err, dbErrors.ErrNoRecords):
-			return dbErrors.ErrDeleteBond
+			return 0, dbErrors.ErrDeleteBond
 		default:
-			return dbErrors.ErrDeleteBond
+			return 0, dbErrors.ErrDeleteBond
 		}
 	}
 
@@ -205,20 +224,31 @@ func (repo *MarketBondRepository) BuyMarketBond(ctx context.Context, order *doma
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
-		return dbErrors.ErrCommit
+		return 0, dbErrors.ErrCommit
 	}
 
-	return nil
+	return op, nil
 }
 
 func (repo *MarketBondRepository) SellMarketBond(ctx context.Context, data *domain.MarketSellRequest) error {
-	var available int
-	var query = `SELECT number FROM bonds WHERE id = ? AND created_by = ?`
-	err := repo.db.SelectContext(ctx, &available, query, data.BondID, data.SellerID)
+	var bond = struct {
+		Number int  `db:"number"`
+		Frozen bool `db:"frozen"`
+	}{}
+	var query = `SELECT number, frozen_at IS NOT NULL AS frozen FROM bonds WHERE id = ? AND created_by = ? AND deleted_at IS NULL`
+	err := repo.db.GetContext(ctx, &bond, query, data.BondID, data.SellerID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbErrors.ErrBondNotExist
+		}
 		return dbErrors.ErrExecuteQuery
 	}
 
+	if bond.Frozen {
+		return dbErrors.ErrBondFrozen
+	}
+	available := bond.Number
+
 	// Init TX
 	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 
@@ -258,3 +288,47 @@ func (repo *MarketBondRepository) SellMarketBond(ctx context.Context, data *doma
 
 	return nil
 }
+
+// DelistMarketBond repository method for withdrawing a market bond from the market.
+func (repo *MarketBondRepository) DelistMarketBond(ctx context.Context, market_bond_id int) error {
+	var query = `UPDATE market_bonds SET status = 'delisted', delisted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status = 'available' AND deleted_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, market_bond_id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrMarketBondNotExist
+	}
+
+	return nil
+}
+
+// FindMarketBond repository method for loading the bond and the seller of a market bond, listed or not.
+func (repo *MarketBondRepository) FindMarketBond(ctx context.Context, market_bond_id int) (*domain.MarketBond, error) {
+	var query = `SELECT mb.id, b.uuid, b.name, b.created_by AS created_by_id, mb.status
+		FROM market_bonds mb
+			INNER JOIN bonds b on b.id = mb.bond_id
+		WHERE mb.id = ? AND mb.deleted_at IS NULL`
+
+	var item = &domain.MarketBond{}
+	if err := repo.db.GetContext(ctx, item, query, market_bond_id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrMarketBondNotExist
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
@@ -24,52 +24,61 @@ func TestListMarketBonds(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewMarketBondRepository(sqlxDB)
 
 	var uuid1 = uuid.NewString()
 	var uuid2 = uuid.NewString()
-	var bonds = []*domain.Bond{
+	var bonds = []*domain.MarketBond{
 		{
+			ID:          1,
 			UUID:        uuid1,
 			Name:        faker.Name(),
 			Price:       10000,
+			Available:   10,
 			Currency:    1,
 			CreatedBy:   faker.Username(),
 			CreatedByID: 1,
-			Status:      "available",
-			IsOwner:     false,
+			Status:      "on_sell",
 			CreatedAt:   time.Now(),
 		},
 		{
+			ID:          2,
 			UUID:        uuid2,
 			Name:        faker.Name(),
 			Price:       12000,
+			Available:   5,
 			Currency:    1,
 			CreatedBy:   faker.Username(),
 			CreatedByID: 2,
-			Status:      "available",
-			IsOwner:     true,
+			Status:      "on_sell",
 			CreatedAt:   time.Now(),
 		},
 	}
 
 	var query = `SELECT
+    		mb.id,
     		b.uuid,
     		b.name,
     		b.price,
+    		mb.available,
     		c.currency,
     		up.username AS created_by,
     		b.created_by AS created_by_id,
+    		sr.rating AS seller_rating,
+    		COALESCE(sr.ratings, 0) AS seller_ratings,
     		b.status,
     		b.created_at,
     		b.updated_at
-    	FROM bonds b
+    	FROM market_bonds mb
+			INNER JOIN bonds b on b.id = mb.bond_id
 			INNER JOIN currencies c on c.id = b.currency_id
-			INNER JOIN users_profile up on b.created_by = up.user_id`
+			INNER JOIN users_profile up on b.created_by = up.user_id
+			LEFT JOIN (SELECT seller_id, ROUND(AVG(score), 2) AS rating, COUNT(*) AS ratings FROM seller_ratings GROUP BY seller_id) sr on sr.seller_id = b.created_by
+		WHERE b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.deleted_at IS NULL AND b.frozen_at IS NULL`
 
-	rows := sqlmock.NewRows([]string{"uuid", "name", "price", "currency", "created_by", "created_by_id", "status", "created_at", "updated_at"}).
-		AddRow(&bonds[0].UUID, bonds[0].Name, bonds[0].Price, bonds[0].Currency, bonds[0].CreatedBy, bonds[0].CreatedByID, bonds[0].Status, bonds[0].CreatedAt, bonds[0].UpdateAt).
-		AddRow(&bonds[1].UUID, bonds[1].Name, bonds[1].Price, bonds[1].Currency, bonds[1].CreatedBy, bonds[1].CreatedByID, bonds[1].Status, bonds[1].CreatedAt, bonds[1].UpdateAt)
+	rows := sqlmock.NewRows([]string{"id", "uuid", "name", "price", "available", "currency", "created_by", "created_by_id", "seller_rating", "seller_ratings", "status", "created_at", "updated_at"}).
+		AddRow(bonds[0].ID, bonds[0].UUID, bonds[0].Name, bonds[0].Price, bonds[0].Available, bonds[0].Currency, bonds[0].CreatedBy, bonds[0].CreatedByID, "4.50", 2, bonds[0].Status, bonds[0].CreatedAt, bonds[0].UpdateAt).
+		AddRow(bonds[1].ID, bonds[1].UUID, bonds[1].Name, bonds[1].Price, bonds[1].Available, bonds[1].Currency, bonds[1].CreatedBy, bonds[1].CreatedByID, nil, 0, bonds[1].Status, bonds[1].CreatedAt, bonds[1].UpdateAt)
 
 	t.Run("OK", func(t *testing.T) {
 
@@ -77,11 +86,15 @@ func TestListMarketBonds(t *testing.T) {
 			ExpectQuery().
 			WillReturnRows(rows)
 
-		list, err := repo.ListBonds(ctx, 1)
+		list, err := repo.ListMarketBonds(ctx, 1)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, list)
 		assert.Equal(t, len(list), 2)
 		assert.Equal(t, list[0].UUID, uuid1)
+		assert.True(t, list[0].IsOwner)
+		assert.Equal(t, 4.5, *list[0].SellerRating)
+		assert.Equal(t, 2, list[0].SellerRatings)
+		assert.Nil(t, list[1].SellerRating)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -90,7 +103,7 @@ func TestListMarketBonds(t *testing.T) {
 			ExpectQuery().
 			WillReturnError(sql.ErrConnDone)
 
-		list, err := repo.ListBonds(ctx, 1)
+		list, err := repo.ListMarketBonds(ctx, 1)
 		t.Log("err", err, list)
 		assert.Error(t, err)
 		assert.Nil(t, list)
@@ -101,7 +114,7 @@ func TestListMarketBonds(t *testing.T) {
 		mock.ExpectPrepare(query).
 			WillReturnError(dbErrors.ErrPrepareStatement)
 
-		list, err := repo.ListBonds(ctx, 1)
+		list, err := repo.ListMarketBonds(ctx, 1)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.Nil(t, list)
@@ -119,7 +132,7 @@ func TestSellMarketBond(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewBondRepository(sqlxDB)
 
 	var bonds = []*domain.Bond{
 		{
@@ -191,13 +204,13 @@ func TestSellMarketBond(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.ErrBondAlreadyExists)
 
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.CreateBond(ctx, bondExisting)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrBondAlreadyExists)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Exec Failed", func(t *testing.T) {
@@ -205,13 +218,13 @@ func TestSellMarketBond(t *testing.T) {
 		mock.ExpectExec(query).
 			WithArgs(sqlmock.AnyArg(), bondExisting.Name, bondExisting.Number, bondExisting.Price, bondExisting.CurrencyID, bondExisting.CreatedBy, bondExisting.Status).
 			WillReturnError(dbErrors.ErrExecuteQuery)
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.CreateBond(ctx, bondExisting)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrExecuteQuery)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -225,7 +238,7 @@ func TestUpdateMarketBond(t *testing.T) {
 	ctx, cancel := context.WithTimeout(c, time.Duration(5)*time.Second)
 	defer cancel()
 
-	repo := NewBondRepository(sqlxDB, nil)
+	repo := NewBondRepository(sqlxDB)
 
 	var bonds = []*domain.Bond{
 		{
@@ -264,7 +277,7 @@ func TestUpdateMarketBond(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedByID, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
@@ -279,35 +292,143 @@ func TestUpdateMarketBond(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedBy, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.ErrUpdatingRecord)
 
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.UpdateBond(ctx, bond)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.ErrUpdatingRecord)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Query Failed", func(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(query).
-			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.CreatedBy, bond.Status, bond.UUID, bond.CreatedByID).
+			WithArgs(bond.Name, bond.Number, bond.Price, bond.Currency, bond.Status, bond.UUID, bond.CreatedByID).
 			WillReturnResult(sqlmock.NewResult(0, 0)).
 			WillReturnError(dbErrors.InternalServerError)
-		mock.ExpectCommit()
+		mock.ExpectRollback()
 
 		err := repo.UpdateBond(ctx, bond)
 		t.Log("err", err)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, dbErrors.InternalServerError)
-		assert.Error(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
 func TestDeleteMarketBond(t *testing.T) {}
 
-func TestBuyMarketBond(t *testing.T) {}
+func TestDelistMarketBond(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewMarketBondRepository(sqlxDB)
+
+	var query = `UPDATE market_bonds SET status = 'delisted', delisted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status = 'available' AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DelistMarketBond(ctx, 7)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DelistMarketBond(ctx, 9)
+		assert.ErrorIs(t, err, dbErrors.ErrMarketBondNotExist)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBuyMarketBond(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewMarketBondRepository(sqlxDB)
+
+	t.Run("Seller Of The Bond", func(t *testing.T) {
+		id, num := 7, 3
+		// the client sends another seller, the transaction is recorded against the owner of the bond
+		order := &domain.MarketBondRequest{MarketBondID: &id, SellerID: 99, BuyerID: 1, Order: &num}
+
+		mock.ExpectQuery(`SELECT mb.bond_id, b.created_by AS seller_id, mb.available, b.frozen_at IS NOT NULL AS frozen
+		FROM market_bonds mb
+			INNER JOIN bonds b ON b.id = mb.bond_id
+		WHERE mb.id = ? AND mb.status = 'available' AND mb.deleted_at IS NULL LIMIT 1`).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"bond_id", "seller_id", "available", "frozen"}).AddRow(4, 2, 5, false))
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO transactions (seller_id, buyer_id, bond_id, total_acquired, status)
+		VALUES(?, ?, ?, ?, ?)`).
+			WithArgs(2, 1, 4, &num, 0).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectExec(`UPDATE market_bonds SET available = ?, status = ? WHERE id = ?`).
+			WithArgs(2, "available", &id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE transactions SET status = ? WHERE id = ?`).
+			WithArgs(1, int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		available, err := repo.BuyMarketBond(ctx, order)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, available)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindMarketBond(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewMarketBondRepository(sqlxDB)
+
+	var query = `SELECT mb.id, b.uuid, b.name, b.created_by AS created_by_id, mb.status
+		FROM market_bonds mb
+			INNER JOIN bonds b on b.id = mb.bond_id
+		WHERE mb.id = ? AND mb.deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "name", "created_by_id", "status"}).AddRow(7, "u-1", "CETES", 3, "delisted"))
+
+		item, err := repo.FindMarketBond(ctx, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.MarketBond{ID: 7, UUID: "u-1", Name: "CETES", CreatedByID: 3, Status: "delisted"}, item)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(9).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindMarketBond(ctx, 9)
+		assert.ErrorIs(t, err, dbErrors.ErrMarketBondNotExist)
+	})
+}
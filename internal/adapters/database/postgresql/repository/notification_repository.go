@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+)
+
+var _ rPort.NotificationRepository = (*NotificationRepository)(nil)
+
+// NotificationRepository struct
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepository Creates a new instance of NotificationRepository
+func NewNotificationRepository(conn *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: conn,
+	}
+}
+
+// Create repository method for storing a notification. An event notifies a
+// user once, a repeated event is ignored.
+func (repo *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) (bool, error) {
+	var query = `INSERT IGNORE INTO notifications (user_id, event_id, type, title, body, data) VALUES (?, ?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return false, dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, notification.UserID, notification.EventID, notification.Type, notification.Title, notification.Body, string(notification.Data))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return false, nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, dbErrors.ErrRetrieveRows
+	}
+	notification.ID = int(id)
+
+	return true, nil
+}
+
+// List repository method for a page of the inbox, newest first.
+func (repo *NotificationRepository) List(ctx context.Context, search *domain.NotificationSearch) ([]*domain.Notification, error) {
+	var where = []string{"user_id = ?"}
+	var args = []interface{}{search.UserID}
+	if search.Read != nil {
+		if *search.Read {
+			where = append(where, "read_at IS NOT NULL")
+		} else {
+			where = append(where, "read_at IS NULL")
+		}
+	}
+	args = append(args, search.Limit, search.Offset)
+
+	var query = `SELECT id, user_id, event_id, type, title, body, data, created_at, read_at
+		FROM notifications
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`
+
+	var list = make([]*domain.Notification, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+	for _, item := range list {
+		item.Read = item.ReadAt != nil
+	}
+
+	return list, nil
+}
+
+// CountUnread repository method for counting the unread notifications of a user.
+func (repo *NotificationRepository) CountUnread(ctx context.Context, uid int) (int, error) {
+	var query = `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`
+
+	var count int
+	if err := repo.db.GetContext(ctx, &count, query, uid); err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return count, nil
+}
+
+// MarkRead repository method for marking a notification of the user as read.
+// A notification already read keeps its read date.
+func (repo *NotificationRepository) MarkRead(ctx context.Context, uid int, id int) error {
+	var query = `UPDATE notifications SET read_at = NOW() WHERE id = ? AND user_id = ? AND read_at IS NULL`
+	res, err := repo.db.ExecContext(ctx, query, id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := repo.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM notifications WHERE id = ? AND user_id = ?)`, id, uid); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+	if !exists {
+		return dbErrors.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead repository method for marking every notification of the user as read.
+func (repo *NotificationRepository) MarkAllRead(ctx context.Context, uid int) error {
+	var query = `UPDATE notifications SET read_at = NOW() WHERE user_id = ? AND read_at IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, uid); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// GetPreferences repository method for loading the channels of a user.
+func (repo *NotificationRepository) GetPreferences(ctx context.Context, uid int) (*domain.NotificationPreferences, error) {
+	var query = `SELECT in_app, email, webhook FROM notification_preferences WHERE user_id = ?`
+
+	var preferences = &domain.NotificationPreferences{}
+	if err := repo.db.GetContext(ctx, preferences, query, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DefaultNotificationPreferences(), nil
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return preferences, nil
+}
+
+// SavePreferences repository method for storing the channels of a user.
+func (repo *NotificationRepository) SavePreferences(ctx context.Context, uid int, preferences *domain.NotificationPreferences) error {
+	var query = `INSERT INTO notification_preferences (user_id, in_app, email, webhook) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), email = VALUES(email), webhook = VALUES(webhook)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, uid, preferences.InApp, preferences.Email, preferences.Webhook); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// ListRecipients repository method for loading the email and the channels of
+// the users. Deleted users are left out.
+func (repo *NotificationRepository) ListRecipients(ctx context.Context, uids []int) ([]*domain.NotificationRecipient, error) {
+	if len(uids) == 0 {
+		return make([]*domain.NotificationRecipient, 0), nil
+	}
+	query, args, err := sqlx.In(`SELECT u.id, u.email AS address,
+			COALESCE(p.in_app, TRUE) AS in_app, COALESCE(p.email, TRUE) AS email, COALESCE(p.webhook, TRUE) AS webhook
+		FROM users u
+			LEFT JOIN notification_preferences p ON p.user_id = u.id
+		WHERE u.id IN (?) AND u.deleted_at IS NULL`, uids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	var list = make([]*domain.NotificationRecipient, 0)
+	if err := repo.db.SelectContext(ctx, &list, repo.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
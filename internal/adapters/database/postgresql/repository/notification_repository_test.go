@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var notificationColumns = []string{"id", "user_id", "event_id", "type", "title", "body", "data", "created_at", "read_at"}
+
+func TestCreateNotification(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewNotificationRepository(sqlxDB)
+
+	var query = `INSERT IGNORE INTO notifications (user_id, event_id, type, title, body, data) VALUES (?, ?, ?, ?, ?, ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		notification := &domain.Notification{UserID: 1, EventID: "evt", Type: domain.EventListingSoldOut, Title: "Your listing sold out", Body: "body", Data: []byte(`{}`)}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(1, "evt", domain.EventListingSoldOut, "Your listing sold out", "body", "{}").
+			WillReturnResult(sqlmock.NewResult(6, 1))
+
+		created, err := repo.Create(ctx, notification)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, 6, notification.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate event", func(t *testing.T) {
+		notification := &domain.Notification{UserID: 1, EventID: "evt", Type: domain.EventListingSoldOut, Title: "Your listing sold out", Body: "body", Data: []byte(`{}`)}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(1, "evt", domain.EventListingSoldOut, "Your listing sold out", "body", "{}").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		created, err := repo.Create(ctx, notification)
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, 0, notification.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewNotificationRepository(sqlxDB)
+
+	t.Run("Unread", func(t *testing.T) {
+		var query = `SELECT id, user_id, event_id, type, title, body, data, created_at, read_at
+		FROM notifications
+		WHERE user_id = ? AND read_at IS NULL
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`
+		rows := sqlmock.NewRows(notificationColumns).
+			AddRow(6, 1, "evt", domain.EventListingSoldOut, "Your listing sold out", "body", []byte(`{}`), time.Now(), nil)
+		mock.ExpectQuery(query).WithArgs(1, 20, 0).WillReturnRows(rows)
+
+		read := false
+		list, err := repo.List(ctx, &domain.NotificationSearch{UserID: 1, Read: &read, Limit: 20})
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+		assert.False(t, list[0].Read)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		var query = `SELECT id, user_id, event_id, type, title, body, data, created_at, read_at
+		FROM notifications
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`
+		rows := sqlmock.NewRows(notificationColumns).
+			AddRow(6, 1, "evt", domain.EventListingSoldOut, "Your listing sold out", "body", []byte(`{}`), time.Now(), time.Now())
+		mock.ExpectQuery(query).WithArgs(1, 20, 20).WillReturnRows(rows)
+
+		list, err := repo.List(ctx, &domain.NotificationSearch{UserID: 1, Limit: 20, Offset: 20})
+		assert.NoError(t, err)
+		assert.True(t, list[0].Read)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewNotificationRepository(sqlxDB)
+
+	var query = `UPDATE notifications SET read_at = NOW() WHERE id = ? AND user_id = ? AND read_at IS NULL`
+	var exists = `SELECT EXISTS(SELECT 1 FROM notifications WHERE id = ? AND user_id = ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(6, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkRead(ctx, 1, 6)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already read", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(6, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs(6, 1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err := repo.MarkRead(ctx, 1, 6)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(6, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs(6, 2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		err := repo.MarkRead(ctx, 2, 6)
+		assert.ErrorIs(t, err, dbErrors.ErrNotificationNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewNotificationRepository(sqlxDB)
+
+	var query = `SELECT in_app, email, webhook FROM notification_preferences WHERE user_id = ?`
+
+	t.Run("Get", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"in_app", "email", "webhook"}).AddRow(true, false, true))
+
+		preferences, err := repo.GetPreferences(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.NotificationPreferences{InApp: true, Email: false, Webhook: true}, preferences)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Get defaults", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		preferences, err := repo.GetPreferences(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DefaultNotificationPreferences(), preferences)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		var query = `INSERT INTO notification_preferences (user_id, in_app, email, webhook) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), email = VALUES(email), webhook = VALUES(webhook)`
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, true, false, false).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SavePreferences(ctx, 1, &domain.NotificationPreferences{InApp: true})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListNotificationRecipients(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewNotificationRepository(sqlxDB)
+
+	var query = `SELECT u.id, u.email AS address,
+			COALESCE(p.in_app, TRUE) AS in_app, COALESCE(p.email, TRUE) AS email, COALESCE(p.webhook, TRUE) AS webhook
+		FROM users u
+			LEFT JOIN notification_preferences p ON p.user_id = u.id
+		WHERE u.id IN (?, ?) AND u.deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "address", "in_app", "email", "webhook"}).
+			AddRow(1, "gini@mail.com", true, false, true).
+			AddRow(2, "rob@mail.com", true, true, true)
+		mock.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+
+		list, err := repo.ListRecipients(ctx, []int{1, 2})
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Equal(t, "gini@mail.com", list[0].Address)
+		assert.False(t, list[0].Email)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		list, err := repo.ListRecipients(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
-	cache "kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/core/domain"
 	"time"
 )
@@ -16,14 +15,56 @@ var DatabaseModule = fx.Module("db",
 	fx.Provide(func(conn *sqlx.DB) *AuthRepository {
 		return NewAuthRepository(conn)
 	}),
-	fx.Provide(func(conn *sqlx.DB, cache *cache.RedisCache) *BondRepository {
-		return NewBondRepository(conn, cache)
+	fx.Provide(func(conn *sqlx.DB) *RoleRepository {
+		return NewRoleRepository(conn)
 	}),
-	fx.Provide(func(conn *sqlx.DB, cache *cache.RedisCache) *MarketBondRepository {
-		return NewMarketBondRepository(conn, cache)
+	fx.Provide(func(conn *sqlx.DB) *TwoFactorRepository {
+		return NewTwoFactorRepository(conn)
 	}),
-	fx.Provide(func(conn *sqlx.DB, cache *cache.RedisCache) *UserRepository {
-		return NewUserRepository(conn, cache)
+	fx.Provide(func(conn *sqlx.DB) *AuditLogRepository {
+		return NewAuditLogRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *AuthEventRepository {
+		return NewAuthEventRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *APIKeyRepository {
+		return NewAPIKeyRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *IdentityRepository {
+		return NewIdentityRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *SessionRepository {
+		return NewSessionRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *AccountRepository {
+		return NewAccountRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *SellerRepository {
+		return NewSellerRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *WebhookRepository {
+		return NewWebhookRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *NotificationRepository {
+		return NewNotificationRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *AlertRepository {
+		return NewAlertRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *JobRepository {
+		return NewJobRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *TaskRepository {
+		return NewTaskRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *BondRepository {
+		return NewBondRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *MarketBondRepository {
+		return NewMarketBondRepository(conn)
+	}),
+	fx.Provide(func(conn *sqlx.DB) *UserRepository {
+		return NewUserRepository(conn)
 	}),
 )
 
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.RoleRepository = (*RoleRepository)(nil)
+
+// RoleRepository struct
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository Creates a new instance of RoleRepository
+func NewRoleRepository(conn *sqlx.DB) *RoleRepository {
+	return &RoleRepository{
+		db: conn,
+	}
+}
+
+// GetUserRoles repository method for listing the role names of a user.
+func (repo *RoleRepository) GetUserRoles(ctx context.Context, uid int) ([]string, error) {
+	var query = `SELECT r.name
+		FROM user_roles ur
+			INNER JOIN roles r ON r.id = ur.role_id
+		WHERE r.deleted_at IS NULL AND ur.user_id = ?`
+
+	var roles = make([]string, 0)
+	if err := repo.db.SelectContext(ctx, &roles, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return roles, nil
+}
+
+// GetRolesPermissions repository method for listing the permissions granted to the roles.
+func (repo *RoleRepository) GetRolesPermissions(ctx context.Context, roles []string) ([]string, error) {
+	var permissions = make([]string, 0)
+	if len(roles) == 0 {
+		return permissions, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT DISTINCT p.name
+		FROM role_permissions rp
+			INNER JOIN roles r ON r.id = rp.role_id
+			INNER JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.deleted_at IS NULL AND p.deleted_at IS NULL AND r.name IN (?)`, roles)
+	if err != nil {
+		return nil, dbErrors.ErrPrepareStatement
+	}
+
+	if err := repo.db.SelectContext(ctx, &permissions, repo.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return permissions, nil
+}
+
+// AssignRole repository method for granting a role to a user.
+func (repo *RoleRepository) AssignRole(ctx context.Context, uid int, role string) error {
+	var query = `INSERT IGNORE INTO user_roles (user_id, role_id)
+		SELECT ?, id FROM roles WHERE name = ? AND deleted_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, uid, role)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	// INSERT IGNORE affects no rows when the role is missing or already granted
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		var exists int
+		err = repo.db.GetContext(ctx, &exists, `SELECT COUNT(*) FROM roles WHERE name = ? AND deleted_at IS NULL`, role)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+		}
+		if exists == 0 {
+			return dbErrors.ErrRoleNotFound
+		}
+	}
+
+	return nil
+}
+
+// CountUsersWithRole repository method for counting the users with a role.
+func (repo *RoleRepository) CountUsersWithRole(ctx context.Context, role string) (int, error) {
+	var query = `SELECT COUNT(*)
+		FROM user_roles ur
+			INNER JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = ?`
+
+	var total int
+	if err := repo.db.GetContext(ctx, &total, query, role); err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return total, nil
+}
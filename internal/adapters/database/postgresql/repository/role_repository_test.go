@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+)
+
+func TestGetUserRoles(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewRoleRepository(sqlxDB)
+
+	var query = `SELECT r.name
+		FROM user_roles ur
+			INNER JOIN roles r ON r.id = ur.role_id
+		WHERE r.deleted_at IS NULL AND ur.user_id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name"}).AddRow(domain.RoleAdmin).AddRow(domain.RoleCustomer)
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+
+		roles, err := repo.GetUserRoles(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.RoleAdmin, domain.RoleCustomer}, roles)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query Failed", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		roles, err := repo.GetUserRoles(ctx, 1)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Empty(t, roles)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetRolesPermissions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewRoleRepository(sqlxDB)
+
+	var query = `SELECT DISTINCT p.name
+		FROM role_permissions rp
+			INNER JOIN roles r ON r.id = rp.role_id
+			INNER JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.deleted_at IS NULL AND p.deleted_at IS NULL AND r.name IN (?, ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name"}).AddRow(domain.PermissionManageUsers)
+		mock.ExpectQuery(query).WithArgs(domain.RoleAdmin, domain.RoleCustomer).WillReturnRows(rows)
+
+		permissions, err := repo.GetRolesPermissions(ctx, []string{domain.RoleAdmin, domain.RoleCustomer})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.PermissionManageUsers}, permissions)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Without roles", func(t *testing.T) {
+		permissions, err := repo.GetRolesPermissions(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, permissions)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAssignRole(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewRoleRepository(sqlxDB)
+
+	var query = `INSERT IGNORE INTO user_roles (user_id, role_id)
+		SELECT ?, id FROM roles WHERE name = ? AND deleted_at IS NULL`
+	var exists = `SELECT COUNT(*) FROM roles WHERE name = ? AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, domain.RoleAdmin).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.AssignRole(ctx, 1, domain.RoleAdmin))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already granted", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, domain.RoleAdmin).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs(domain.RoleAdmin).WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1))
+
+		assert.NoError(t, repo.AssignRole(ctx, 1, domain.RoleAdmin))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Role not found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, "ghost").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(exists).WithArgs("ghost").WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(0))
+
+		assert.ErrorIs(t, repo.AssignRole(ctx, 1, "ghost"), dbErrors.ErrRoleNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	my "github.com/go-mysql/errors"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.SellerRepository = (*SellerRepository)(nil)
+
+// SellerRepository struct
+type SellerRepository struct {
+	db *sqlx.DB
+}
+
+// NewSellerRepository Creates a new instance of SellerRepository
+func NewSellerRepository(conn *sqlx.DB) *SellerRepository {
+	return &SellerRepository{
+		db: conn,
+	}
+}
+
+// GetSellerProfile repository method for loading the public profile of an active user.
+func (repo *SellerRepository) GetSellerProfile(ctx context.Context, username string) (*domain.SellerProfile, error) {
+	var query = `SELECT
+			up.user_id,
+			up.username,
+			u.created_at AS member_since,
+			(SELECT COUNT(*) FROM market_bonds mb
+				INNER JOIN bonds b ON b.id = mb.bond_id
+				WHERE b.created_by = up.user_id AND b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.deleted_at IS NULL AND b.frozen_at IS NULL) AS listings,
+			(SELECT COUNT(*) FROM transactions t WHERE t.seller_id = up.user_id AND t.status = ? AND t.deleted_at IS NULL) AS completed_trades,
+			(SELECT ROUND(AVG(sr.score), 2) FROM seller_ratings sr WHERE sr.seller_id = up.user_id) AS rating,
+			(SELECT COUNT(*) FROM seller_ratings sr WHERE sr.seller_id = up.user_id) AS ratings
+		FROM users_profile up
+			INNER JOIN users u ON u.id = up.user_id
+		WHERE up.username = ? AND up.deleted_at IS NULL AND u.deleted_at IS NULL AND u.suspended_at IS NULL`
+
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
+	}
+	defer stmt.Close()
+
+	var item = &domain.SellerProfile{}
+	if err := stmt.GetContext(ctx, item, domain.TransactionSettled, username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrSellerNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
+
+// CreateRating repository method for rating the seller of a settled transaction.
+// The rating is only stored when the buyer and the seller match the transaction.
+func (repo *SellerRepository) CreateRating(ctx context.Context, rating *domain.SellerRating) error {
+	var query = `INSERT INTO seller_ratings (transaction_id, seller_id, buyer_id, score, comment)
+		SELECT t.id, t.seller_id, t.buyer_id, ?, ?
+		FROM transactions t
+		WHERE t.id = ? AND t.buyer_id = ? AND t.seller_id = ? AND t.seller_id <> t.buyer_id AND t.status = ? AND t.deleted_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, rating.Score, rating.Comment, rating.TransactionID, rating.BuyerID, rating.SellerID, domain.TransactionSettled)
+	if err != nil {
+		if ok, myerr := my.Error(err); ok && errors.Is(myerr, my.ErrDupeKey) {
+			return dbErrors.ErrAlreadyRated
+		}
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrTransactionNotRateable
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	rating.ID = int(id)
+
+	return nil
+}
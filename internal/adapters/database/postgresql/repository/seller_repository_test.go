@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGetSellerProfile(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSellerRepository(sqlxDB)
+
+	var query = `SELECT
+			up.user_id,
+			up.username,
+			u.created_at AS member_since,
+			(SELECT COUNT(*) FROM market_bonds mb
+				INNER JOIN bonds b ON b.id = mb.bond_id
+				WHERE b.created_by = up.user_id AND b.status = 'on_sell' AND mb.status = 'available' AND mb.deleted_at IS NULL AND b.deleted_at IS NULL AND b.frozen_at IS NULL) AS listings,
+			(SELECT COUNT(*) FROM transactions t WHERE t.seller_id = up.user_id AND t.status = ? AND t.deleted_at IS NULL) AS completed_trades,
+			(SELECT ROUND(AVG(sr.score), 2) FROM seller_ratings sr WHERE sr.seller_id = up.user_id) AS rating,
+			(SELECT COUNT(*) FROM seller_ratings sr WHERE sr.seller_id = up.user_id) AS ratings
+		FROM users_profile up
+			INNER JOIN users u ON u.id = up.user_id
+		WHERE up.username = ? AND up.deleted_at IS NULL AND u.deleted_at IS NULL AND u.suspended_at IS NULL`
+	var columns = []string{"user_id", "username", "member_since", "listings", "completed_trades", "rating", "ratings"}
+
+	t.Run("OK", func(t *testing.T) {
+		since := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(columns).AddRow(2, "ginigini", since, 3, 10, "4.50", 4)
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(domain.TransactionSettled, "ginigini").WillReturnRows(rows)
+
+		profile, err := repo.GetSellerProfile(ctx, "ginigini")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, profile.UserID)
+		assert.Equal(t, since, profile.MemberSince)
+		assert.Equal(t, 10, profile.CompletedTrades)
+		assert.Equal(t, 4.5, *profile.Rating)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Without Ratings", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(2, "ginigini", time.Now(), 0, 0, nil, 0)
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(domain.TransactionSettled, "ginigini").WillReturnRows(rows)
+
+		profile, err := repo.GetSellerProfile(ctx, "ginigini")
+		assert.NoError(t, err)
+		assert.Nil(t, profile.Rating)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(domain.TransactionSettled, "nobody").WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetSellerProfile(ctx, "nobody")
+		assert.ErrorIs(t, err, dbErrors.ErrSellerNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateSellerRating(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSellerRepository(sqlxDB)
+
+	var query = `INSERT INTO seller_ratings (transaction_id, seller_id, buyer_id, score, comment)
+		SELECT t.id, t.seller_id, t.buyer_id, ?, ?
+		FROM transactions t
+		WHERE t.id = ? AND t.buyer_id = ? AND t.seller_id = ? AND t.seller_id <> t.buyer_id AND t.status = ? AND t.deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		rating := &domain.SellerRating{TransactionID: 9, SellerID: 2, BuyerID: 1, Score: 5, Comment: "fast"}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(5, "fast", 9, 1, 2, domain.TransactionSettled).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+
+		err := repo.CreateRating(ctx, rating)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, rating.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not The Buyer", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(5, "", 9, 3, 2, domain.TransactionSettled).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.CreateRating(ctx, &domain.SellerRating{TransactionID: 9, SellerID: 2, BuyerID: 3, Score: 5})
+		assert.ErrorIs(t, err, dbErrors.ErrTransactionNotRateable)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Rated", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(4, "", 9, 1, 2, domain.TransactionSettled).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+		err := repo.CreateRating(ctx, &domain.SellerRating{TransactionID: 9, SellerID: 2, BuyerID: 1, Score: 4})
+		assert.ErrorIs(t, err, dbErrors.ErrAlreadyRated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.SessionRepository = (*SessionRepository)(nil)
+
+// SessionRepository struct
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+// NewSessionRepository Creates a new instance of SessionRepository
+func NewSessionRepository(conn *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		db: conn,
+	}
+}
+
+// Create repository method for storing a new session.
+func (repo *SessionRepository) Create(ctx context.Context, session *domain.UserSession) error {
+	var query = `INSERT INTO user_sessions (user_id, token_hash, user_agent, ip, expires_at)
+		VALUES (?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, session.UserID, session.TokenHash, session.UserAgent, session.IP, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	session.ID = int(id)
+
+	return nil
+}
+
+// FindByToken repository method for loading an active session by the hash of its refresh token.
+func (repo *SessionRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.UserSession, error) {
+	var query = `SELECT id, user_id, token_hash, previous_hash, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE (token_hash = ? OR previous_hash = ?) AND revoked_at IS NULL
+		LIMIT 1`
+
+	var item = &domain.UserSession{}
+	if err := repo.db.GetContext(ctx, item, query, tokenHash, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
+
+// Rotate repository method for replacing the refresh token of a session.
+func (repo *SessionRepository) Rotate(ctx context.Context, id int, oldHash string, newHash string, ip string, userAgent string, expiresAt time.Time) error {
+	var query = `UPDATE user_sessions
+		SET previous_hash = token_hash, token_hash = ?, ip = ?, user_agent = ?, last_seen_at = NOW(), expires_at = ?
+		WHERE id = ? AND token_hash = ? AND revoked_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, newHash, ip, userAgent, expiresAt, id, oldHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// ListActive repository method for listing the sessions that can still be refreshed.
+func (repo *SessionRepository) ListActive(ctx context.Context, uid int) ([]*domain.UserSession, error) {
+	var query = `SELECT id, user_id, token_hash, previous_hash, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC`
+
+	var list = make([]*domain.UserSession, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// Revoke repository method for revoking a session of the user.
+func (repo *SessionRepository) Revoke(ctx context.Context, uid int, id int) error {
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll repository method for revoking every session of the user.
+func (repo *SessionRepository) RevokeAll(ctx context.Context, uid int) (int64, error) {
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return 0, dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, uid)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbErrors.ErrRetrieveRows
+	}
+
+	return affected, nil
+}
+
+// RevokeOthers repository method for revoking the sessions of the user but one
+func (repo *SessionRepository) RevokeOthers(ctx context.Context, uid int, keep int) (int64, error) {
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND id <> ? AND revoked_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return 0, dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, uid, keep)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbErrors.ErrRetrieveRows
+	}
+
+	return affected, nil
+}
+
+// IsActive repository method for checking that the session of an access token
+// can still be used: not revoked, and its user not suspended or deleted.
+func (repo *SessionRepository) IsActive(ctx context.Context, uid int, id int) (bool, error) {
+	var query = `SELECT COUNT(*)
+		FROM user_sessions s
+			INNER JOIN users u ON u.id = s.user_id
+		WHERE s.id = ? AND s.user_id = ? AND s.revoked_at IS NULL AND u.suspended_at IS NULL AND u.deleted_at IS NULL`
+
+	var count int
+	if err := repo.db.GetContext(ctx, &count, query, id, uid); err != nil {
+		return false, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var sessionColumns = []string{"id", "user_id", "token_hash", "previous_hash", "user_agent", "ip", "created_at", "last_seen_at", "expires_at"}
+
+func TestCreateSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `INSERT INTO user_sessions (user_id, token_hash, user_agent, ip, expires_at)
+		VALUES (?, ?, ?, ?, ?)`
+	var expiresAt = time.Now().Add(time.Hour)
+
+	t.Run("OK", func(t *testing.T) {
+		session := &domain.UserSession{UserID: 1, TokenHash: "hash", UserAgent: "curl/8.0", IP: "127.0.0.1", ExpiresAt: expiresAt}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(1, "hash", "curl/8.0", "127.0.0.1", expiresAt).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		err := repo.Create(ctx, session)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, session.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindSessionByToken(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `SELECT id, user_id, token_hash, previous_hash, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE (token_hash = ? OR previous_hash = ?) AND revoked_at IS NULL
+		LIMIT 1`
+
+	t.Run("OK", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(sessionColumns).AddRow(3, 1, "hash", "", "curl/8.0", "127.0.0.1", now, now, now.Add(time.Hour))
+		mock.ExpectQuery(query).WithArgs("hash", "hash").WillReturnRows(rows)
+
+		session, err := repo.FindByToken(ctx, "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, session.ID)
+		assert.Equal(t, 1, session.UserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("missing", "missing").WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.FindByToken(ctx, "missing")
+		assert.ErrorIs(t, err, dbErrors.ErrSessionNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRotateSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `UPDATE user_sessions
+		SET previous_hash = token_hash, token_hash = ?, ip = ?, user_agent = ?, last_seen_at = NOW(), expires_at = ?
+		WHERE id = ? AND token_hash = ? AND revoked_at IS NULL`
+	var expiresAt = time.Now().Add(time.Hour)
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("new", "127.0.0.1", "curl/8.0", expiresAt, 3, "old").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Rotate(ctx, 3, "old", "new", "127.0.0.1", "curl/8.0", expiresAt)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Rotated", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("new", "127.0.0.1", "curl/8.0", expiresAt, 3, "old").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Rotate(ctx, 3, "old", "new", "127.0.0.1", "curl/8.0", expiresAt)
+		assert.ErrorIs(t, err, dbErrors.ErrSessionNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListActiveSessions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `SELECT id, user_id, token_hash, previous_hash, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC`
+
+	t.Run("OK", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(sessionColumns).
+			AddRow(4, 1, "hash-4", "", "Firefox", "10.0.0.2", now, now, now.Add(time.Hour)).
+			AddRow(3, 1, "hash-3", "", "curl/8.0", "127.0.0.1", now, now, now.Add(time.Hour))
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+
+		list, err := repo.ListActive(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Equal(t, 4, list[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Revoke(ctx, 1, 3)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(9, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Revoke(ctx, 1, 9)
+		assert.ErrorIs(t, err, dbErrors.ErrSessionNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`
+
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	total, err := repo.RevokeAll(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND id <> ? AND revoked_at IS NULL`
+
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(1, 9).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	total, err := repo.RevokeOthers(ctx, 1, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsActiveSession(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewSessionRepository(sqlxDB)
+
+	var query = `SELECT COUNT(*)
+		FROM user_sessions s
+			INNER JOIN users u ON u.id = s.user_id
+		WHERE s.id = ? AND s.user_id = ? AND s.revoked_at IS NULL AND u.suspended_at IS NULL AND u.deleted_at IS NULL`
+
+	t.Run("Active", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(4, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		active, err := repo.IsActive(ctx, 1, 4)
+		assert.NoError(t, err)
+		assert.True(t, active)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoked Or Suspended", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(4, 1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		active, err := repo.IsActive(ctx, 1, 4)
+		assert.NoError(t, err)
+		assert.False(t, active)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
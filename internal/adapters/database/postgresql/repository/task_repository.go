@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ rPort.TaskRepository = (*TaskRepository)(nil)
+
+const taskColumns = `id, type, user_id, payload, status, attempts, max_attempts, COALESCE(result, '') AS result, error,
+		run_at, locked_until, created_at, started_at, finished_at`
+
+// TaskRepository struct, the task queue on MySQL. The workers claim the tasks
+// with SELECT ... FOR UPDATE SKIP LOCKED so they never wait on each other.
+type TaskRepository struct {
+	db *sqlx.DB
+}
+
+// NewTaskRepository Creates a new instance of TaskRepository
+func NewTaskRepository(conn *sqlx.DB) *TaskRepository {
+	return &TaskRepository{
+		db: conn,
+	}
+}
+
+// Create repository method for queuing a task
+func (repo *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	var query = `INSERT INTO tasks (id, type, user_id, payload, status, max_attempts, run_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, task.ID, task.Type, task.UserID, []byte(task.Payload), task.Status, task.MaxAttempts, task.RunAt, task.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// Get repository method for a task by id
+func (repo *TaskRepository) Get(ctx context.Context, id string) (*domain.Task, error) {
+	var query = `SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`
+
+	var task = &domain.Task{}
+	if err := repo.db.GetContext(ctx, task, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return task, nil
+}
+
+// Claim repository method for taking the next due task: a queued one whose
+// run_at has come, or a running one whose worker let the lock expire
+func (repo *TaskRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration) (*domain.Task, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, dbErrors.ErrBeginTransaction
+	}
+
+	var id string
+	err = tx.GetContext(ctx, &id, `SELECT id FROM tasks
+		WHERE (status = 'queued' AND run_at <= ?) OR (status = 'running' AND locked_until <= ?)
+		ORDER BY run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, now, now)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tasks SET status = 'running', attempts = attempts + 1, locked_until = ?, started_at = ? WHERE id = ?`,
+		now.Add(visibility), now, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	var task = &domain.Task{}
+	if err := tx.GetContext(ctx, task, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return nil, dbErrors.ErrCommit
+	}
+
+	return task, nil
+}
+
+// Finish repository method for storing the result of a task that won't run again.
+// Only the claim of task.Attempts can store it, once the task was claimed again
+// it returns ErrTaskLeaseLost.
+func (repo *TaskRepository) Finish(ctx context.Context, task *domain.Task) error {
+	var query = `UPDATE tasks SET status = ?, result = ?, error = ?, locked_until = NULL, finished_at = ? WHERE id = ? AND status = 'running' AND attempts = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	// a task without result keeps it NULL
+	var result any
+	if len(task.Result) > 0 {
+		result = []byte(task.Result)
+	}
+	res, err := stmt.ExecContext(ctx, task.Status, result, task.Error, task.FinishedAt, task.ID, task.Attempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return leaseHeld(res)
+}
+
+// Retry repository method for queuing a failed task again at its run_at, like
+// Finish it returns ErrTaskLeaseLost when the task was claimed again
+func (repo *TaskRepository) Retry(ctx context.Context, task *domain.Task) error {
+	var query = `UPDATE tasks SET status = 'queued', error = ?, run_at = ?, locked_until = NULL WHERE id = ? AND status = 'running' AND attempts = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, task.Error, task.RunAt, task.ID, task.Attempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return leaseHeld(res)
+}
+
+// Purge repository method for deleting the tasks finished before the date
+func (repo *TaskRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var query = `DELETE FROM tasks WHERE status IN ('succeeded', 'failed') AND finished_at < ?`
+	res, err := repo.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbErrors.ErrRetrieveRows
+	}
+
+	return affected, nil
+}
+
+// leaseHeld fails with ErrTaskLeaseLost when the update of a claimed task
+// matched no row
+func leaseHeld(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrTaskLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var taskRowColumns = []string{"id", "type", "user_id", "payload", "status", "attempts", "max_attempts", "result", "error", "run_at", "locked_until", "created_at", "started_at", "finished_at"}
+
+func TestClaimTask(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTaskRepository(sqlxDB)
+	now := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	until := now.Add(5 * time.Minute)
+
+	var selectQuery = `SELECT id FROM tasks
+		WHERE (status = 'queued' AND run_at <= ?) OR (status = 'running' AND locked_until <= ?)
+		ORDER BY run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+	var updateQuery = `UPDATE tasks SET status = 'running', attempts = attempts + 1, locked_until = ?, started_at = ? WHERE id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(now, now).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("task-1"))
+		mock.ExpectExec(updateQuery).WithArgs(until, now, "task-1").WillReturnResult(sqlmock.NewResult(0, 1))
+		rows := sqlmock.NewRows(taskRowColumns).
+			AddRow("task-1", domain.TaskAccountExport, 2, []byte(`{"export_id":4}`), domain.TaskRunning, 1, 5, []byte(""), "", now, until, now, now, nil)
+		mock.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`).WithArgs("task-1").WillReturnRows(rows)
+		mock.ExpectCommit()
+
+		task, err := repo.Claim(ctx, now, 5*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Task{
+			ID:          "task-1",
+			Type:        domain.TaskAccountExport,
+			UserID:      2,
+			Payload:     json.RawMessage(`{"export_id":4}`),
+			Status:      domain.TaskRunning,
+			Attempts:    1,
+			MaxAttempts: 5,
+			Result:      json.RawMessage(""),
+			RunAt:       now,
+			LockedUntil: &until,
+			CreatedAt:   now,
+			StartedAt:   &now,
+		}, task)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing Due", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(now, now).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.Claim(ctx, now, 5*time.Minute)
+		assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTask(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTaskRepository(sqlxDB)
+
+	mock.ExpectQuery(`SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`).WithArgs("nope").WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.Get(ctx, "nope")
+	assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFinishTask(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTaskRepository(sqlxDB)
+	finished := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	var query = `UPDATE tasks SET status = ?, result = ?, error = ?, locked_until = NULL, finished_at = ? WHERE id = ? AND status = 'running' AND attempts = ?`
+
+	t.Run("Succeeded", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(domain.TaskSucceeded, []byte(`{"rows":3}`), "", &finished, "task-1", 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Finish(ctx, &domain.Task{ID: "task-1", Status: domain.TaskSucceeded, Attempts: 2, Result: json.RawMessage(`{"rows":3}`), FinishedAt: &finished})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed without result", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(domain.TaskFailed, nil, "boom", &finished, "task-1", 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Finish(ctx, &domain.Task{ID: "task-1", Status: domain.TaskFailed, Attempts: 3, Error: "boom", FinishedAt: &finished})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lease Lost", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(domain.TaskSucceeded, nil, "", &finished, "task-1", 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Finish(ctx, &domain.Task{ID: "task-1", Status: domain.TaskSucceeded, Attempts: 1, FinishedAt: &finished})
+		assert.ErrorIs(t, err, dbErrors.ErrTaskLeaseLost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRetryTask(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTaskRepository(sqlxDB)
+	runAt := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	var query = `UPDATE tasks SET status = 'queued', error = ?, run_at = ?, locked_until = NULL WHERE id = ? AND status = 'running' AND attempts = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("boom", runAt, "task-1", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Retry(ctx, &domain.Task{ID: "task-1", Attempts: 1, Error: "boom", RunAt: runAt})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lease Lost", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("boom", runAt, "task-1", 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Retry(ctx, &domain.Task{ID: "task-1", Attempts: 1, Error: "boom", RunAt: runAt})
+		assert.ErrorIs(t, err, dbErrors.ErrTaskLeaseLost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+)
+
+var _ rPort.TwoFactorRepository = (*TwoFactorRepository)(nil)
+
+// TwoFactorRepository struct
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+// NewTwoFactorRepository Creates a new instance of TwoFactorRepository
+func NewTwoFactorRepository(conn *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: conn,
+	}
+}
+
+// GetTOTP repository method for loading the TOTP enrollment of a user.
+func (repo *TwoFactorRepository) GetTOTP(ctx context.Context, uid int) (*domain.UserTOTP, error) {
+	var query = `SELECT user_id, secret, confirmed_at, last_used_step FROM user_totp WHERE user_id = ?`
+
+	var item = &domain.UserTOTP{}
+	if err := repo.db.GetContext(ctx, item, query, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrTwoFactorNotEnrolled
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return item, nil
+}
+
+// SaveTOTP repository method for starting an enrollment, it replaces any pending one.
+func (repo *TwoFactorRepository) SaveTOTP(ctx context.Context, uid int, secret string) error {
+	var query = `INSERT INTO user_totp (user_id, secret) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), confirmed_at = NULL, last_used_step = 0, updated_at = NOW()`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, uid, secret); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+// EnableTOTP repository method for confirming an enrollment and storing its recovery codes.
+func (repo *TwoFactorRepository) EnableTOTP(ctx context.Context, uid int, step int64, recoveryCodes []string) error {
+	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return dbErrors.ErrBeginTransaction
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE user_totp SET confirmed_at = NOW(), last_used_step = ?, updated_at = NOW()
+		WHERE user_id = ? AND confirmed_at IS NULL`, step, uid)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return dbErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, uid, recoveryCodes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbErrors.ErrCommit
+	}
+
+	return nil
+}
+
+// UseTOTPStep repository method for consuming a time step, a step can only be used once.
+func (repo *TwoFactorRepository) UseTOTPStep(ctx context.Context, uid int, step int64) error {
+	var query = `UPDATE user_totp SET last_used_step = ?, updated_at = NOW() WHERE user_id = ? AND last_used_step < ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, step, uid, step)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes repository method for regenerating the recovery codes.
+func (repo *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, uid int, recoveryCodes []string) error {
+	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return dbErrors.ErrBeginTransaction
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, uid, recoveryCodes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbErrors.ErrCommit
+	}
+
+	return nil
+}
+
+// UseRecoveryCode repository method for consuming a recovery code.
+func (repo *TwoFactorRepository) UseRecoveryCode(ctx context.Context, uid int, recoveryCode string) error {
+	var query = `UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, uid, recoveryCode)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// DeleteTOTP repository method for disabling two-factor authentication.
+func (repo *TwoFactorRepository) DeleteTOTP(ctx context.Context, uid int) error {
+	tx, err := repo.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return dbErrors.ErrBeginTransaction
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, uid); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, uid); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbErrors.ErrCommit
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes deletes the previous codes of the user and inserts the new ones
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, uid int, recoveryCodes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, uid); err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	for _, code := range recoveryCodes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`, uid, code); err != nil {
+			return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGetTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTwoFactorRepository(sqlxDB)
+
+	var query = `SELECT user_id, secret, confirmed_at, last_used_step FROM user_totp WHERE user_id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"user_id", "secret", "confirmed_at", "last_used_step"}).
+			AddRow(1, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", time.Now(), 100)
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+
+		item, err := repo.GetTOTP(ctx, 1)
+		assert.NoError(t, err)
+		assert.True(t, item.Enabled())
+		assert.Equal(t, int64(100), item.LastUsedStep)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Enrolled", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		item, err := repo.GetTOTP(ctx, 2)
+		assert.ErrorIs(t, err, dbErrors.ErrTwoFactorNotEnrolled)
+		assert.Nil(t, item)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEnableTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTwoFactorRepository(sqlxDB)
+
+	var enable = `UPDATE user_totp SET confirmed_at = NOW(), last_used_step = ?, updated_at = NOW()
+		WHERE user_id = ? AND confirmed_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(enable).WithArgs(int64(100), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM user_recovery_codes WHERE user_id = ?`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`).WithArgs(1, "hash-1").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`).WithArgs(1, "hash-2").WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		err := repo.EnableTOTP(ctx, 1, 100, []string{"hash-1", "hash-2"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Enabled", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(enable).WithArgs(int64(100), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.EnableTOTP(ctx, 1, 100, []string{"hash-1"})
+		assert.ErrorIs(t, err, dbErrors.ErrTwoFactorAlreadyEnabled)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTwoFactorRepository(sqlxDB)
+
+	var query = `UPDATE user_totp SET last_used_step = ?, updated_at = NOW() WHERE user_id = ? AND last_used_step < ?`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(101), 1, int64(101)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UseTOTPStep(ctx, 1, 101)
+		assert.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(101), 1, int64(101)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UseTOTPStep(ctx, 1, 101)
+		assert.ErrorIs(t, err, dbErrors.ErrInvalidTwoFactorCode)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewTwoFactorRepository(sqlxDB)
+
+	var query = `UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, "hash-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UseRecoveryCode(ctx, 1, "hash-1")
+		assert.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Used", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(1, "hash-1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UseRecoveryCode(ctx, 1, "hash-1")
+		assert.ErrorIs(t, err, dbErrors.ErrInvalidTwoFactorCode)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	my "github.com/go-mysql/errors"
 	"github.com/jmoiron/sqlx"
-	cache "kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/core/domain"
 	rPort "kiramishima/m-backend/internal/core/ports/repository"
 	dbErrors "kiramishima/m-backend/pkg/errors"
@@ -16,15 +16,13 @@ var _ rPort.UserRepository = (*UserRepository)(nil)
 
 // UserRepository struct
 type UserRepository struct {
-	db    *sqlx.DB
-	cache *cache.RedisCache
+	db *sqlx.DB
 }
 
 // NewUserRepository Creates a new instance of BondRepository
-func NewUserRepository(conn *sqlx.DB, cache *cache.RedisCache) *UserRepository {
+func NewUserRepository(conn *sqlx.DB) *UserRepository {
 	return &UserRepository{
-		db:    conn,
-		cache: cache,
+		db: conn,
 	}
 }
 
@@ -35,35 +33,28 @@ func (repo *UserRepository) GetProfile(ctx context.Context, uid int) (*domain.Us
 		photo,
 		gender
 	FROM users_profile
-	WHERE user_id = ?`
+	WHERE user_id = ? AND deleted_at IS NULL`
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
-		return nil, dbErrors.ErrExecuteStatement
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, uid)
-
+	var item = &domain.UserProfile{UserID: uid}
+	err = stmt.QueryRowContext(ctx, uid).Scan(&item.UserName, &item.UserPhoto, &item.Gender)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, dbErrors.ErrNoRecords
-		} else {
-			return nil, dbErrors.ErrExecuteStatement
+			return nil, dbErrors.ErrUserNotFound
 		}
-	}
-	var item = &domain.UserProfile{}
-	err = row.Scan(item.UserName, item.UserPhoto, item.Gender)
-
-	if err != nil {
 		return nil, fmt.Errorf("%s: %w", dbErrors.ErrScanData, err)
 	}
+
 	return item, nil
 }
 
-// Register repository method for create a new user.
+// UpdateProfile repository method for saving the username, the photo and the gender.
 func (repo *UserRepository) UpdateProfile(ctx context.Context, data *domain.UserProfile) (*domain.UserProfile, error) {
-
-	var query = `UPDATE users_profile SET username = ?, photo = ?, gender = ?, updated_at = NOW() WHERE user_id = ?`
+	var query = `UPDATE users_profile SET username = ?, photo = ?, gender = ?, updated_at = NOW() WHERE user_id = ? AND deleted_at IS NULL`
 
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
@@ -71,28 +62,43 @@ func (repo *UserRepository) UpdateProfile(ctx context.Context, data *domain.User
 	}
 	defer stmt.Close()
 
-	tx, err := repo.db.BeginTxx(ctx, nil)
+	_, err = stmt.ExecContext(ctx, data.UserName, data.UserPhoto, data.Gender, data.UserID)
 	if err != nil {
-		return nil, dbErrors.ErrBeginTransaction
+		if ok, myerr := my.Error(err); ok && errors.Is(myerr, my.ErrDupeKey) {
+			return nil, dbErrors.ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
 	}
-	// Exec
-	_, err = stmt.ExecContext(ctx, data.UserName, data.UserPhoto, data.Gender, data.UserID)
 
+	return data, nil
+}
+
+// UpdatePhoto repository method for saving the storage key of the profile photo.
+func (repo *UserRepository) UpdatePhoto(ctx context.Context, uid int, photo string) error {
+	var query = `UPDATE users_profile SET photo = ?, updated_at = NOW() WHERE user_id = ? AND deleted_at IS NULL`
+
+	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
-		switch {
-		case errors.Is(err, dbErrors.ErrNoRecords):
-			return nil, dbErrors.ErrNoRecords
-		default:
-			return nil, dbErrors.InternalServerError
-		}
+		return fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
 	}
-	if err := tx.Commit(); err != nil {
-		return nil, dbErrors.ErrCommit
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, photo, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrUserNotFound
 	}
 
-	return nil, nil
+	return nil
 }
 
+// GetBonds repository method for listing the bonds created by the user.
 func (repo *UserRepository) GetBonds(ctx context.Context, uid int) ([]*domain.Bond, error) {
 	var query = `SELECT
     		b.uuid,
@@ -107,7 +113,7 @@ func (repo *UserRepository) GetBonds(ctx context.Context, uid int) ([]*domain.Bo
     	FROM bonds b
 			INNER JOIN currencies c on c.id = b.currency_id
 			INNER JOIN users_profile up on b.created_by = up.user_id
-		WHERE created_by = ?`
+		WHERE b.deleted_at IS NULL AND b.created_by = ?`
 	stmt, err := repo.db.PreparexContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", dbErrors.ErrPrepareStatement, err)
@@ -123,12 +129,14 @@ func (repo *UserRepository) GetBonds(ctx context.Context, uid int) ([]*domain.Bo
 			return nil, dbErrors.ErrExecuteStatement
 		}
 	}
+	defer rows.Close()
+
 	var list = make([]*domain.Bond, 0)
 	for rows.Next() {
 		var createAt sql.NullTime
 		var updatedAt sql.NullTime
 		var item = &domain.Bond{}
-		err = rows.Scan(item.UUID, item.Name, item.Price, item.Currency, item.CreatedBy, item.CreatedByID, item.Status, &createAt, &updatedAt)
+		err = rows.Scan(&item.UUID, &item.Name, &item.Price, &item.Currency, &item.CreatedBy, &item.CreatedByID, &item.Status, &createAt, &updatedAt)
 		if err != nil {
 			break
 		}
@@ -143,8 +151,128 @@ func (repo *UserRepository) GetBonds(ctx context.Context, uid int) ([]*domain.Bo
 		}
 		list = append(list, item)
 	}
+	if err == nil {
+		err = rows.Err()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", dbErrors.ErrScanData, err)
 	}
 	return list, nil
 }
+
+// SearchUsers repository method for finding users by email or username.
+func (repo *UserRepository) SearchUsers(ctx context.Context, search *domain.UserSearchRequest) ([]*domain.AdminUser, error) {
+	var query = `SELECT
+			u.id,
+			u.email,
+			COALESCE(up.username, '') AS username,
+			u.suspended_at,
+			u.suspended_reason,
+			u.created_at
+		FROM users u
+			LEFT JOIN users_profile up ON up.user_id = u.id
+		WHERE u.deleted_at IS NULL AND (u.email LIKE ? OR up.username LIKE ?)
+		ORDER BY u.id
+		LIMIT ? OFFSET ?`
+
+	var pattern = "%" + search.Query + "%"
+	var list = make([]*domain.AdminUser, 0)
+	err := repo.db.SelectContext(ctx, &list, query, pattern, pattern, search.Limit, search.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// suspendQueries run by SetSuspended with the suspension, each one takes the
+// user id. The refresh tokens and the API keys stop working at once.
+var suspendQueries = []string{
+	`UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`,
+	`UPDATE api_keys SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL`,
+}
+
+// SetSuspended repository method for suspending or reinstating a user account.
+// Suspending revokes the sessions and API keys of the user in the same transaction.
+func (repo *UserRepository) SetSuspended(ctx context.Context, uid int, suspended bool, reason string) error {
+	if !suspended {
+		var query = `UPDATE users SET suspended_at = NULL, suspended_reason = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+		stmt, err := repo.db.PreparexContext(ctx, query)
+		if err != nil {
+			return dbErrors.ErrPrepareStatement
+		}
+		defer stmt.Close()
+
+		res, err := stmt.ExecContext(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+		}
+		return userAffected(res)
+	}
+
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return dbErrors.ErrBeginTransaction
+	}
+
+	var query = `UPDATE users SET suspended_at = NOW(), suspended_reason = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, reason, uid)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+	if err := userAffected(res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, query := range suspendQueries {
+		if _, err := tx.ExecContext(ctx, query, uid); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return dbErrors.ErrCommit
+	}
+
+	return nil
+}
+
+// userAffected fails with ErrUserNotFound when the update changed no user
+func userAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// GetTransactions repository method for listing the transactions where the user is the seller or the buyer.
+func (repo *UserRepository) GetTransactions(ctx context.Context, uid int) ([]*domain.Transaction, error) {
+	var query = `SELECT
+			t.id,
+			t.seller_id,
+			t.buyer_id,
+			t.bond_id,
+			b.name AS bond_name,
+			t.total_acquired,
+			t.status,
+			t.created_at
+		FROM transactions t
+			INNER JOIN bonds b ON b.id = t.bond_id
+		WHERE t.deleted_at IS NULL AND (t.seller_id = ? OR t.buyer_id = ?)
+		ORDER BY t.created_at DESC`
+
+	var list = make([]*domain.Transaction, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, uid, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
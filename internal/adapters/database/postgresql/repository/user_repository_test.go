@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
 	"testing"
 	"time"
 )
@@ -29,10 +31,10 @@ func TestGetProfile(t *testing.T) {
 	}
 
 	t.Run("OK", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "password", "created_at", "updated_at"}).
-			AddRow(user.ID, user.Email, user.Password, user.CreatedAt, user.UpdatedAt)
+		rows := sqlmock.NewRows([]string{"id", "email", "password", "suspended", "created_at", "updated_at"}).
+			AddRow(user.ID, user.Email, user.Password, user.Suspended, user.CreatedAt, user.UpdatedAt)
 
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(user.Email).
 			WillReturnRows(rows)
@@ -45,7 +47,7 @@ func TestGetProfile(t *testing.T) {
 	})
 
 	t.Run("Query Failed", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(user.Email).
 			WillReturnError(sql.ErrConnDone)
@@ -58,7 +60,7 @@ func TestGetProfile(t *testing.T) {
 	})
 
 	t.Run("Prepare Failed", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			WillReturnError(sql.ErrConnDone)
 
 		userMock, err := repo.FindByCredentials(ctx, &domain.AuthRequest{Email: user.Email, Password: user.Password})
@@ -69,7 +71,7 @@ func TestGetProfile(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT id, email, password, created_at, updated_at FROM users WHERE email = ").
+		mock.ExpectPrepare("SELECT id, email, password, suspended_at IS NOT NULL AS suspended, created_at, updated_at FROM users WHERE email = ?").
 			ExpectQuery().
 			WithArgs(user.Email).
 			WillReturnError(sql.ErrNoRows)
@@ -82,6 +84,305 @@ func TestGetProfile(t *testing.T) {
 	})
 }
 
-func TestUpdateProfile(t *testing.T) {}
+func TestGetUserProfile(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
+
+	var query = `SELECT
+		username,
+		photo,
+		gender
+	FROM users_profile
+	WHERE user_id = ? AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"username", "photo", "gender"}).
+			AddRow("ginigini", "default_profile.png", "female")
+
+		mock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		profile, err := repo.GetProfile(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.UserProfile{UserID: 1, UserName: "ginigini", UserPhoto: "default_profile.png", Gender: "female"}, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows([]string{"username", "photo", "gender"}))
+
+		profile, err := repo.GetProfile(ctx, 9)
+		assert.ErrorIs(t, err, dbErrors.ErrUserNotFound)
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnError(sql.ErrConnDone)
+
+		profile, err := repo.GetProfile(ctx, 1)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Prepare Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			WillReturnError(sql.ErrConnDone)
+
+		profile, err := repo.GetProfile(ctx, 1)
+		assert.Error(t, err)
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
+
+	var query = `UPDATE users_profile SET username = ?, photo = ?, gender = ?, updated_at = NOW() WHERE user_id = ? AND deleted_at IS NULL`
+	var profile = &domain.UserProfile{UserID: 1, UserName: "ginigini", UserPhoto: "default_profile.png", Gender: "female"}
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs("ginigini", "default_profile.png", "female", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		updated, err := repo.UpdateProfile(ctx, profile)
+		assert.NoError(t, err)
+		assert.Equal(t, profile, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Exec Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs("ginigini", "default_profile.png", "female", 1).
+			WillReturnError(sql.ErrConnDone)
+
+		updated, err := repo.UpdateProfile(ctx, profile)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Nil(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Username Taken", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs("ginigini", "default_profile.png", "female", 1).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+		updated, err := repo.UpdateProfile(ctx, profile)
+		assert.ErrorIs(t, err, dbErrors.ErrUsernameTaken)
+		assert.Nil(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdatePhoto(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
+
+	var query = `UPDATE users_profile SET photo = ?, updated_at = NOW() WHERE user_id = ? AND deleted_at IS NULL`
+
+	t.Run("OK", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs("avatars/1/abc.jpg", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePhoto(ctx, 1, "avatars/1/abc.jpg")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectExec().
+			WithArgs("avatars/9/abc.jpg", 9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdatePhoto(ctx, 9, "avatars/9/abc.jpg")
+		assert.ErrorIs(t, err, dbErrors.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetBonds(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
+
+	var query = `SELECT
+    		b.uuid,
+    		b.name,
+    		b.price,
+    		c.currency,
+    		up.username AS created_by,
+    		b.created_by AS created_by_id,
+    		b.status,
+    		b.created_at,
+    		b.updated_at
+    	FROM bonds b
+			INNER JOIN currencies c on c.id = b.currency_id
+			INNER JOIN users_profile up on b.created_by = up.user_id
+		WHERE b.deleted_at IS NULL AND b.created_by = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"uuid", "name", "price", "currency", "created_by", "created_by_id", "status", "created_at", "updated_at"}).
+			AddRow("7e4b1a4e-7c1f-4d8e-9f5e-1b2c3d4e5f60", "Bond A", 100, 1, "ginigini", 1, "available", time.Now(), nil)
+
+		mock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		list, err := repo.GetBonds(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+		assert.True(t, list[0].IsOwner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query Failed", func(t *testing.T) {
+		mock.ExpectPrepare(query).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnError(sql.ErrConnDone)
+
+		list, err := repo.GetBonds(ctx, 1)
+		assert.Error(t, err)
+		assert.Nil(t, list)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSearchUsers(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
 
-func TestGetBonds(t *testing.T) {}
+	var query = `SELECT
+			u.id,
+			u.email,
+			COALESCE(up.username, '') AS username,
+			u.suspended_at,
+			u.suspended_reason,
+			u.created_at
+		FROM users u
+			LEFT JOIN users_profile up ON up.user_id = u.id
+		WHERE u.deleted_at IS NULL AND (u.email LIKE ? OR up.username LIKE ?)
+		ORDER BY u.id
+		LIMIT ? OFFSET ?`
+
+	t.Run("OK", func(t *testing.T) {
+		var suspendedAt = time.Now()
+		rows := sqlmock.NewRows([]string{"id", "email", "username", "suspended_at", "suspended_reason", "created_at"}).
+			AddRow(1, "gini@mail.com", "ginigini", nil, nil, time.Now()).
+			AddRow(2, "gino@mail.com", "ginogino", suspendedAt, "fraud", time.Now())
+
+		mock.ExpectQuery(query).
+			WithArgs("%gin%", "%gin%", 20, 0).
+			WillReturnRows(rows)
+
+		list, err := repo.SearchUsers(ctx, &domain.UserSearchRequest{Query: "gin", Limit: 20})
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.Nil(t, list[0].SuspendedAt)
+		assert.Equal(t, "fraud", *list[1].SuspendedReason)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query Failed", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("%gin%", "%gin%", 20, 0).
+			WillReturnError(sql.ErrConnDone)
+
+		list, err := repo.SearchUsers(ctx, &domain.UserSearchRequest{Query: "gin", Limit: 20})
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Nil(t, list)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetSuspended(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewUserRepository(sqlxDB)
+
+	t.Run("Suspend", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET suspended_at = NOW(), suspended_reason = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			WithArgs("fraud", 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// the sessions and the API keys are revoked with the suspension
+		for _, query := range suspendQueries {
+			mock.ExpectExec(query).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		err := repo.SetSuspended(ctx, 2, true, "fraud")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unsuspend", func(t *testing.T) {
+		mock.ExpectPrepare("UPDATE users SET suspended_at = NULL, suspended_reason = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			ExpectExec().
+			WithArgs(2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SetSuspended(ctx, 2, false, "")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET suspended_at = NOW(), suspended_reason = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL").
+			WithArgs("", 9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.SetSuspended(ctx, 9, true, "")
+		assert.ErrorIs(t, err, dbErrors.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+)
+
+var _ rPort.WebhookRepository = (*WebhookRepository)(nil)
+
+// WebhookRepository struct
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository Creates a new instance of WebhookRepository
+func NewWebhookRepository(conn *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: conn,
+	}
+}
+
+// webhookRow the events are stored comma separated
+type webhookRow struct {
+	domain.Webhook
+	Events string `db:"events"`
+}
+
+func (row *webhookRow) toDomain() *domain.Webhook {
+	hook := row.Webhook
+	hook.Events = splitList(row.Events)
+	return &hook
+}
+
+// Create repository method for storing a new webhook.
+func (repo *WebhookRepository) Create(ctx context.Context, hook *domain.Webhook) error {
+	var query = `INSERT INTO webhooks (user_id, url, events, secret) VALUES (?, ?, ?, ?)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, hook.UserID, hook.URL, strings.Join(hook.Events, ","), hook.Secret)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	hook.ID = int(id)
+
+	return nil
+}
+
+// ListByUser repository method for listing the webhooks of a user.
+func (repo *WebhookRepository) ListByUser(ctx context.Context, uid int) ([]*domain.Webhook, error) {
+	var query = `SELECT id, user_id, url, events, secret, created_at FROM webhooks WHERE user_id = ? ORDER BY id`
+
+	var rows = make([]*webhookRow, 0)
+	if err := repo.db.SelectContext(ctx, &rows, query, uid); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return webhooksToDomain(rows), nil
+}
+
+// GetByID repository method for loading a webhook of the user.
+func (repo *WebhookRepository) GetByID(ctx context.Context, uid int, id int) (*domain.Webhook, error) {
+	var query = `SELECT id, user_id, url, events, secret, created_at FROM webhooks WHERE id = ? AND user_id = ?`
+
+	var row = &webhookRow{}
+	if err := repo.db.GetContext(ctx, row, query, id, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return row.toDomain(), nil
+}
+
+// FindByID repository method for loading a webhook of any user.
+func (repo *WebhookRepository) FindByID(ctx context.Context, id int) (*domain.Webhook, error) {
+	var query = `SELECT id, user_id, url, events, secret, created_at FROM webhooks WHERE id = ?`
+
+	var row = &webhookRow{}
+	if err := repo.db.GetContext(ctx, row, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return row.toDomain(), nil
+}
+
+// Delete repository method for deleting a webhook of the user and its deliveries.
+func (repo *WebhookRepository) Delete(ctx context.Context, uid int, id int) error {
+	var query = `DELETE FROM webhooks WHERE id = ? AND user_id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	if affected == 0 {
+		return dbErrors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListByUsers repository method for listing the webhooks of several users.
+// The users that turned off the webhook channel are left out.
+func (repo *WebhookRepository) ListByUsers(ctx context.Context, uids []int) ([]*domain.Webhook, error) {
+	if len(uids) == 0 {
+		return make([]*domain.Webhook, 0), nil
+	}
+	query, args, err := sqlx.In(`SELECT w.id, w.user_id, w.url, w.events, w.secret, w.created_at
+		FROM webhooks w
+			LEFT JOIN notification_preferences p ON p.user_id = w.user_id
+		WHERE w.user_id IN (?) AND COALESCE(p.webhook, TRUE)
+		ORDER BY w.id`, uids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	var rows = make([]*webhookRow, 0)
+	if err := repo.db.SelectContext(ctx, &rows, repo.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return webhooksToDomain(rows), nil
+}
+
+// CreateDelivery repository method for storing a delivery. The event is
+// delivered once to each webhook, a repeated event gets the existing id.
+func (repo *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	var query = `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, delivery.WebhookID, delivery.EventID, delivery.EventType, string(delivery.Payload), delivery.Status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return dbErrors.ErrRetrieveRows
+	}
+	delivery.ID = int(id)
+
+	return nil
+}
+
+// GetDelivery repository method for loading a delivery.
+func (repo *WebhookRepository) GetDelivery(ctx context.Context, id int) (*domain.WebhookDelivery, error) {
+	var query = `SELECT id, webhook_id, event_id, event_type, payload, status, attempts, status_code, error, created_at, last_attempt_at, delivered_at
+		FROM webhook_deliveries
+		WHERE id = ?`
+
+	var delivery = &domain.WebhookDelivery{}
+	if err := repo.db.GetContext(ctx, delivery, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, dbErrors.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries repository method for listing the latest deliveries of a webhook.
+func (repo *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*domain.WebhookDelivery, error) {
+	var query = `SELECT id, webhook_id, event_id, event_type, payload, status, attempts, status_code, error, created_at, last_attempt_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?`
+
+	var list = make([]*domain.WebhookDelivery, 0)
+	if err := repo.db.SelectContext(ctx, &list, query, webhookID, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbErrors.ErrExecuteQuery, err)
+	}
+
+	return list, nil
+}
+
+// UpdateDelivery repository method for storing the result of an attempt.
+func (repo *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	var query = `UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, status_code = ?, error = ?, last_attempt_at = ?, delivered_at = ?
+		WHERE id = ?`
+	stmt, err := repo.db.PreparexContext(ctx, query)
+	if err != nil {
+		return dbErrors.ErrPrepareStatement
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.Status, delivery.Attempts, delivery.StatusCode, delivery.Error,
+		delivery.LastAttemptAt, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dbErrors.ErrExecuteStatement, err)
+	}
+
+	return nil
+}
+
+func webhooksToDomain(rows []*webhookRow) []*domain.Webhook {
+	var list = make([]*domain.Webhook, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.toDomain())
+	}
+	return list
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var webhookColumns = []string{"id", "user_id", "url", "events", "secret", "created_at"}
+
+var webhookDeliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "status_code", "error", "created_at", "last_attempt_at", "delivered_at"}
+
+func TestCreateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `INSERT INTO webhooks (user_id, url, events, secret) VALUES (?, ?, ?, ?)`
+
+	t.Run("OK", func(t *testing.T) {
+		hook := &domain.Webhook{UserID: 1, URL: "https://example.com/hook", Events: []string{domain.EventTradeExecuted, domain.EventListingCreated}, Secret: "secret"}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(1, "https://example.com/hook", "trade.executed,listing.created", "secret").
+			WillReturnResult(sqlmock.NewResult(4, 1))
+
+		err := repo.Create(ctx, hook)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, hook.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWebhookByID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `SELECT id, user_id, url, events, secret, created_at FROM webhooks WHERE id = ? AND user_id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(webhookColumns).AddRow(4, 1, "https://example.com/hook", "trade.executed,listing.created", "secret", time.Now())
+		mock.ExpectQuery(query).WithArgs(4, 1).WillReturnRows(rows)
+
+		hook, err := repo.GetByID(ctx, 1, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.EventTradeExecuted, domain.EventListingCreated}, hook.Events)
+		assert.Equal(t, "secret", hook.Secret)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(4, 2).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByID(ctx, 2, 4)
+		assert.ErrorIs(t, err, dbErrors.ErrWebhookNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `DELETE FROM webhooks WHERE id = ? AND user_id = ?`
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(9, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Delete(ctx, 1, 9)
+		assert.ErrorIs(t, err, dbErrors.ErrWebhookNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListWebhooksByUsers(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `SELECT w.id, w.user_id, w.url, w.events, w.secret, w.created_at
+		FROM webhooks w
+			LEFT JOIN notification_preferences p ON p.user_id = w.user_id
+		WHERE w.user_id IN (?, ?) AND COALESCE(p.webhook, TRUE)
+		ORDER BY w.id`
+
+	t.Run("OK", func(t *testing.T) {
+		rows := sqlmock.NewRows(webhookColumns).
+			AddRow(4, 1, "https://example.com/a", "trade.executed", "a", time.Now()).
+			AddRow(5, 2, "https://example.com/b", "listing.created,trade.executed", "b", time.Now())
+		mock.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+
+		list, err := repo.ListByUsers(ctx, []int{1, 2})
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+		assert.True(t, list[1].Subscribed(domain.EventTradeExecuted))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No Users", func(t *testing.T) {
+		list, err := repo.ListByUsers(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestCreateWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`
+
+	t.Run("Repeated Event", func(t *testing.T) {
+		delivery := &domain.WebhookDelivery{WebhookID: 4, EventID: "evt", EventType: domain.EventTradeExecuted, Payload: []byte(`{}`), Status: domain.WebhookDeliveryPending}
+		// the existing row isn't changed, LAST_INSERT_ID returns its id
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(4, "evt", "trade.executed", "{}", "pending").
+			WillReturnResult(sqlmock.NewResult(11, 0))
+
+		err := repo.CreateDelivery(ctx, delivery)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, delivery.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `SELECT id, webhook_id, event_id, event_type, payload, status, attempts, status_code, error, created_at, last_attempt_at, delivered_at
+		FROM webhook_deliveries
+		WHERE id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(webhookDeliveryColumns).
+			AddRow(11, 4, "evt", "trade.executed", []byte(`{"id":"evt"}`), "succeeded", 1, 204, "", now, now, now)
+		mock.ExpectQuery(query).WithArgs(11).WillReturnRows(rows)
+
+		delivery, err := repo.GetDelivery(ctx, 11)
+		assert.NoError(t, err)
+		assert.Equal(t, 204, *delivery.StatusCode)
+		assert.JSONEq(t, `{"id":"evt"}`, string(delivery.Payload))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(12).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetDelivery(ctx, 12)
+		assert.ErrorIs(t, err, dbErrors.ErrWebhookDeliveryNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ctx := context.Background()
+	repo := NewWebhookRepository(sqlxDB)
+
+	var query = `UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, status_code = ?, error = ?, last_attempt_at = ?, delivered_at = ?
+		WHERE id = ?`
+
+	t.Run("OK", func(t *testing.T) {
+		now := time.Now()
+		code := 500
+		delivery := &domain.WebhookDelivery{ID: 11, Status: domain.WebhookDeliveryPending, Attempts: 2, StatusCode: &code, Error: "unexpected status 500", LastAttemptAt: &now}
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs("pending", 2, &code, "unexpected status 500", &now, nil, 11).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdateDelivery(ctx, delivery)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
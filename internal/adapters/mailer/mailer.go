@@ -0,0 +1,135 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ svcport.Mailer = (*SMTPMailer)(nil)
+	_ svcport.Mailer = (*LogMailer)(nil)
+)
+
+// SMTPMailer struct, sends the emails through an SMTP relay
+type SMTPMailer struct {
+	host       string
+	port       int
+	username   string
+	password   string
+	encryption string
+	from       string
+}
+
+// NewSMTPMailer creates a new SMTP mailer. encryption is "tls" for STARTTLS,
+// "ssl" for implicit TLS or empty for plain connections.
+func NewSMTPMailer(cfg domain.Mail) *SMTPMailer {
+	return &SMTPMailer{
+		host:       cfg.Host,
+		port:       cfg.MailPort,
+		username:   cfg.Username,
+		password:   cfg.MailPwd,
+		encryption: strings.ToLower(cfg.Encryption),
+		from:       cfg.FromAddress,
+	}
+}
+
+// Send delivers the email, the context bounds the whole SMTP conversation
+func (m *SMTPMailer) Send(ctx context.Context, email *domain.Email) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(m.host, strconv.Itoa(m.port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to the SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if m.encryption == "ssl" {
+		conn = tls.Client(conn, &tls.Config{ServerName: m.host})
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start the SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if m.encryption == "tls" {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("failed to authenticate with the SMTP server: %w", err)
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return fmt.Errorf("failed to send the email: %w", err)
+	}
+	if err := c.Rcpt(email.To); err != nil {
+		return fmt.Errorf("failed to send the email: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send the email: %w", err)
+	}
+	if _, err := w.Write(m.message(email)); err != nil {
+		return fmt.Errorf("failed to send the email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send the email: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// message builds a plain text RFC 5322 message
+func (m *SMTPMailer) message(email *domain.Email) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", email.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", email.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// LogMailer struct, writes the emails to the logger. Meant for development.
+type LogMailer struct {
+	logger *zap.SugaredLogger
+}
+
+// NewLogMailer creates a new log mailer
+func NewLogMailer(logger *zap.SugaredLogger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send logs the email
+func (m *LogMailer) Send(_ context.Context, email *domain.Email) error {
+	m.logger.Infow("email", "to", email.To, "subject", email.Subject, "body", email.Body)
+	return nil
+}
+
+// Module mailer, MAIL_MAILER selects the implementation
+var Module = fx.Module("mailer",
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger) svcport.Mailer {
+		if cfg.Mailer == "smtp" {
+			return NewSMTPMailer(cfg.Mail)
+		}
+		return NewLogMailer(logger)
+	}),
+)
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts one session and returns the DATA it received
+func fakeSMTPServer(t *testing.T) (string, int, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		write := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		write("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				write("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				data <- b.String()
+				write("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				write("221 bye")
+				return
+			default:
+				write("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, data
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	host, port, data := fakeSMTPServer(t)
+	m := NewSMTPMailer(domain.Mail{Host: host, MailPort: port, FromAddress: "no-reply@mbonds.local"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := m.Send(ctx, &domain.Email{To: "gini@mail.com", Subject: "Unlock", Body: "line 1\nline 2"})
+	assert.NoError(t, err)
+
+	msg := <-data
+	assert.Contains(t, msg, "From: no-reply@mbonds.local\r\n")
+	assert.Contains(t, msg, "To: gini@mail.com\r\n")
+	assert.Contains(t, msg, "Subject: Unlock\r\n")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nline 1\r\nline 2\r\n"))
+}
@@ -0,0 +1,268 @@
+// Package oidctest runs an in-process OpenID Connect provider for tests. It
+// supports discovery, the authorization code flow with S256 PKCE and a JWKS
+// endpoint, enough to exercise the login without a real identity provider.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Identity the user that signs in at the provider
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Server struct, a mock provider. Identity is the user that signs in at the
+// next authorization. Audience and TokenTTL allow issuing invalid ID tokens.
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	Identity     Identity
+	Audience     string
+	TokenTTL     time.Duration
+
+	mu    sync.Mutex
+	key   jwk.Key
+	codes map[string]*authorization
+}
+
+// authorization what the token endpoint checks when the code is redeemed
+type authorization struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	identity      Identity
+}
+
+// NewServer starts a provider that accepts the given client. Close it when done.
+func NewServer(clientID string, clientSecret string) (*Server, error) {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	_ = key.Set(jwk.KeyIDKey, "oidctest")
+	_ = key.Set(jwk.AlgorithmKey, jwa.RS256)
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenTTL:     5 * time.Minute,
+		key:          key,
+		codes:        make(map[string]*authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+
+	return s, nil
+}
+
+// Issuer the issuer identifier of the provider
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// Authorize follows the authorization URL as the browser would and returns
+// the query of the redirect to the client, with the code and the state or
+// with the error.
+func (s *Server) Authorize(authURL string) (url.Values, error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	return location.Query(), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize signs in Identity without a login page
+func (s *Server) authorize(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	reply := redirect.Query()
+	reply.Set("state", q.Get("state"))
+	switch {
+	case q.Get("client_id") != s.ClientID:
+		reply.Set("error", "unauthorized_client")
+	case q.Get("response_type") != "code":
+		reply.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		reply.Set("error", "invalid_request")
+	default:
+		code := randomString()
+		s.mu.Lock()
+		s.codes[code] = &authorization{
+			redirectURI:   q.Get("redirect_uri"),
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+			identity:      s.Identity,
+		}
+		s.mu.Unlock()
+		reply.Set("code", code)
+	}
+
+	redirect.RawQuery = reply.Encode()
+	http.Redirect(w, req, redirect.String(), http.StatusFound)
+}
+
+// token redeems a code once, checking the client, the redirect URI and the PKCE verifier
+func (s *Server) token(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, secret, ok := req.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if req.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	s.mu.Lock()
+	auth, found := s.codes[req.PostForm.Get("code")]
+	delete(s.codes, req.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !found || auth.redirectURI != req.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	idToken, err := s.idToken(auth)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(s.TokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	public, err := s.key.PublicKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	set := jwk.NewSet()
+	_ = set.AddKey(public)
+	writeJSON(w, http.StatusOK, set)
+}
+
+func (s *Server) idToken(auth *authorization) (string, error) {
+	audience := s.Audience
+	if audience == "" {
+		audience = s.ClientID
+	}
+	now := time.Now()
+
+	tok, err := jwt.NewBuilder().
+		Issuer(s.URL).
+		Subject(auth.identity.Subject).
+		Audience([]string{audience}).
+		IssuedAt(now).
+		Expiration(now.Add(s.TokenTTL)).
+		Claim("nonce", auth.nonce).
+		Claim("email", auth.identity.Email).
+		Claim("email_verified", auth.identity.EmailVerified).
+		Claim("name", auth.identity.Name).
+		Build()
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, s.key))
+	if err != nil {
+		return "", err
+	}
+	return string(signed), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
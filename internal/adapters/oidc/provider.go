@@ -0,0 +1,278 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"go.uber.org/fx"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ svcport.IdentityProvider = (*Provider)(nil)
+
+// clockSkew tolerated between the provider and us when validating exp, iat and nbf
+const clockSkew = time.Minute
+
+// Provider struct, an OpenID Connect provider configured through discovery
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     jwk.Set
+}
+
+// metadata the fields of the discovery document we use
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse the response of the token endpoint
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewProvider creates a new provider. The discovery document and the keys are
+// loaded on first use, so the app starts even if the provider is down.
+func NewProvider(cfg domain.OIDC, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{
+		issuer:       strings.TrimSuffix(cfg.OIDCIssuer, "/"),
+		clientID:     cfg.OIDCClientID,
+		clientSecret: cfg.OIDCClientSecret,
+		redirectURL:  cfg.OIDCRedirectURL,
+		scopes:       cfg.OIDCScopes,
+		client:       client,
+	}
+}
+
+// AuthCodeURL returns the authorization URL with the state, the nonce and the S256 PKCE challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.clientID)
+	q.Set("redirect_uri", p.redirectURL)
+	q.Set("scope", strings.Join(p.scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange redeems the code at the token endpoint and verifies the ID token
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OIDCIdentity, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.clientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call the token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token response: %w", err)
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("%w: invalid token response (status %d)", httpErrors.ErrOIDCExchange, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", httpErrors.ErrOIDCExchange, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: the response has no id_token", httpErrors.ErrOIDCExchange)
+	}
+
+	return p.verify(ctx, md, token.IDToken, nonce)
+}
+
+// verify checks the signature, the issuer, the audience, the lifetime and the nonce of the ID token
+func (p *Provider) verify(ctx context.Context, md *metadata, raw string, nonce string) (*domain.OIDCIdentity, error) {
+	msg, err := jws.ParseString(raw)
+	if err != nil || len(msg.Signatures()) != 1 {
+		return nil, fmt.Errorf("%w: malformed id_token", httpErrors.ErrOIDCExchange)
+	}
+	kid := msg.Signatures()[0].ProtectedHeaders().KeyID()
+
+	keys, err := p.keySet(ctx, md, false)
+	if err != nil {
+		return nil, err
+	}
+	// An unknown kid means the provider rotated its keys
+	if _, found := keys.LookupKeyID(kid); !found && kid != "" {
+		if keys, err = p.keySet(ctx, md, true); err != nil {
+			return nil, err
+		}
+	}
+
+	tok, err := jwt.ParseString(raw,
+		jwt.WithKeySet(keys, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithToken(openid.New()),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithAcceptableSkew(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id_token: %v", httpErrors.ErrOIDCExchange, err)
+	}
+
+	claim, _ := tok.Get("nonce")
+	if got, _ := claim.(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: the nonce of the id_token doesn't match", httpErrors.ErrOIDCExchange)
+	}
+
+	idToken := tok.(openid.Token)
+	return &domain.OIDCIdentity{
+		Issuer:        md.Issuer,
+		Subject:       idToken.Subject(),
+		Email:         strings.ToLower(idToken.Email()),
+		EmailVerified: idToken.EmailVerified(),
+		Name:          idToken.Name(),
+	}, nil
+}
+
+// discover loads the discovery document once
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var md = &metadata{}
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", md); err != nil {
+		return nil, fmt.Errorf("failed to load the discovery document: %w", err)
+	}
+	if strings.TrimSuffix(md.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("the discovery document is for issuer %q, expected %q", md.Issuer, p.issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, errors.New("the discovery document is incomplete")
+	}
+
+	p.metadata = md
+	return md, nil
+}
+
+// keySet returns the signing keys of the provider, refresh reloads them
+func (p *Provider) keySet(ctx context.Context, md *metadata, refresh bool) (jwk.Set, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the provider keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to load the provider keys: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the provider keys: %w", err)
+	}
+	keys, err := jwk.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider keys: %w", err)
+	}
+
+	p.keys = keys
+	return keys, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dst)
+}
+
+// Module oidc, provides no identity provider when OIDC_ISSUER is empty
+var Module = fx.Module("oidc",
+	fx.Provide(func(cfg *domain.Configuration) svcport.IdentityProvider {
+		if cfg.OIDCIssuer == "" {
+			return nil
+		}
+		return NewProvider(cfg.OIDC, nil)
+	}),
+)
@@ -0,0 +1,131 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/adapters/oidc/oidctest"
+	"kiramishima/m-backend/internal/core/domain"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const redirectURL = "http://localhost:8080/v1/auth/oidc/callback"
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	srv, err := oidctest.NewServer("m-bonds", "s3cret")
+	assert.NoError(t, err)
+	t.Cleanup(srv.Close)
+	srv.Identity = oidctest.Identity{Subject: "sub-1", Email: "Gini@Mail.com", EmailVerified: true, Name: "Gini"}
+
+	provider := NewProvider(domain.OIDC{
+		OIDCIssuer:       srv.Issuer(),
+		OIDCClientID:     "m-bonds",
+		OIDCClientSecret: "s3cret",
+		OIDCRedirectURL:  redirectURL,
+		OIDCScopes:       []string{"openid", "email"},
+	}, srv.Client())
+	return provider, srv
+}
+
+func challengeOf(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func authorize(t *testing.T, p *Provider, srv *oidctest.Server, verifier string, nonce string) string {
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", nonce, challengeOf(verifier))
+	assert.NoError(t, err)
+	reply, err := srv.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", reply.Get("state"))
+	assert.Empty(t, reply.Get("error"))
+	return reply.Get("code")
+}
+
+func TestProviderExchange(t *testing.T) {
+	ctx := context.Background()
+	verifier := "0123456789abcdef0123456789abcdef0123456789a"
+
+	t.Run("OK", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		identity, err := p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.NoError(t, err)
+		assert.Equal(t, srv.Issuer(), identity.Issuer)
+		assert.Equal(t, "sub-1", identity.Subject)
+		assert.Equal(t, "gini@mail.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("Code Used Twice", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.NoError(t, err)
+		_, err = p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+
+	t.Run("Wrong Verifier", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, "another-verifier-another-verifier-another-v", "nonce-1")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+
+	t.Run("Wrong Nonce", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, verifier, "nonce-2")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+
+	t.Run("Wrong Audience", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		srv.Audience = "another-client"
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+
+	t.Run("Expired ID Token", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		srv.TokenTTL = -2 * clockSkew
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+
+	t.Run("Wrong Client Secret", func(t *testing.T) {
+		p, srv := newTestProvider(t)
+		p.clientSecret = "wrong"
+		code := authorize(t, p, srv, verifier, "nonce-1")
+
+		_, err := p.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+}
+
+func TestProviderDiscovery(t *testing.T) {
+	// The issuer of the document must match the configured one
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issuer": "https://idp.example.com", "authorization_endpoint": "https://idp.example.com/authorize",
+			"token_endpoint": "https://idp.example.com/token", "jwks_uri": "https://idp.example.com/jwks"}`))
+	}))
+	defer srv.Close()
+
+	p := NewProvider(domain.OIDC{OIDCIssuer: srv.URL, OIDCClientID: "m-bonds"}, srv.Client())
+	_, err := p.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.ErrorContains(t, err, "expected")
+}
@@ -1,42 +1,120 @@
 package psnats
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/nats-io/nats.go"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
 )
 
+// NATSPubSub struct, publishes the events as JSON and runs the subscribers of
+// Subscribe
 type NATSPubSub struct {
 	client *nats.Conn
+	js     nats.JetStreamContext
+	logger *zap.SugaredLogger
+	// stopping is closed when Drain starts, the retries waiting a backoff give up
+	stopping chan struct{}
+	closed   chan struct{}
 }
 
 // NewNATSPubSub creates a new instance of NATSPubSub
-func NewNATSPubSub(nats_addr string) (*NATSPubSub, error) {
-	nc, err := nats.Connect(nats_addr)
+func NewNATSPubSub(nats_addr string, logger *zap.SugaredLogger) (*NATSPubSub, error) {
+	closed := make(chan struct{})
+	nc, err := nats.Connect(nats_addr, nats.ClosedHandler(func(*nats.Conn) {
+		close(closed)
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS Server: %w", err)
+	}
 
+	js, err := nc.JetStream()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to NATS Server: %v", err)
+		nc.Close()
+		return nil, fmt.Errorf("failed to create the JetStream context: %w", err)
 	}
 
-	return &NATSPubSub{client: nc}, nil
+	return &NATSPubSub{
+		client:   nc,
+		js:       js,
+		logger:   logger,
+		stopping: make(chan struct{}),
+		closed:   closed,
+	}, nil
 }
 
+// PublishEvent publishes the JSON of event on topic
 func (nc *NATSPubSub) PublishEvent(topic string, event any) error {
-	data, _ := json.Marshal(event)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the event of %s: %w", topic, err)
+	}
 
-	err := nc.client.Publish(topic, data)
+	err = nc.client.Publish(topic, data)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// EnsureStream creates the JetStream stream that stores the subjects, or updates
+// its subjects when it exists. The durable subscribers read from a stream.
+func (nc *NATSPubSub) EnsureStream(name string, subjects ...string) error {
+	cfg := &nats.StreamConfig{Name: name, Subjects: subjects}
+	_, err := nc.js.StreamInfo(name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = nc.js.AddStream(cfg)
+	} else if err == nil {
+		_, err = nc.js.UpdateStream(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to ensure stream %s: %w", name, err)
+	}
+	return nil
+}
+
+// Drain stops the subscriptions, waits for the messages being handled and the
+// pending publications, and closes the connection. When ctx ends first the
+// connection is closed right away.
+func (nc *NATSPubSub) Drain(ctx context.Context) error {
+	select {
+	case <-nc.stopping:
+	default:
+		close(nc.stopping)
+	}
+	if err := nc.client.Drain(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		nc.client.Close()
+		return fmt.Errorf("failed to drain the NATS connection: %w", err)
+	}
+
+	select {
+	case <-nc.closed:
+		return nil
+	case <-ctx.Done():
+		nc.client.Close()
+		return ctx.Err()
+	}
+}
+
+// Close closes the connection without waiting for the subscribers
+func (nc *NATSPubSub) Close() {
+	nc.client.Close()
+}
+
 // Module
 var Module = fx.Module("pubsub",
-	fx.Provide(func(cfg *domain.Configuration) *NATSPubSub {
-		cache, _ := NewNATSPubSub(cfg.NATS_Addr)
-		return cache
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger) (*NATSPubSub, error) {
+		ps, err := NewNATSPubSub(cfg.NATS_Addr, logger)
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStop: ps.Drain,
+		})
+		return ps, nil
 	}),
 )
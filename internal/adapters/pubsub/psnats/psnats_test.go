@@ -0,0 +1,264 @@
+package psnats
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type bondEvent struct {
+	BondID int    `json:"bond_id"`
+	Name   string `json:"name"`
+}
+
+func runNATSServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	assert.NoError(t, err)
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newTestPubSub(t *testing.T, srv *server.Server) *NATSPubSub {
+	ps, err := NewNATSPubSub(srv.ClientURL(), zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	t.Cleanup(ps.Close)
+	return ps
+}
+
+// collect subscribes to the dead-letter subject
+func collect(t *testing.T, ps *NATSPubSub, subject string) chan *nats.Msg {
+	msgs := make(chan *nats.Msg, 10)
+	_, err := ps.client.ChanSubscribe(subject, msgs)
+	assert.NoError(t, err)
+	assert.NoError(t, ps.client.Flush())
+	return msgs
+}
+
+func receive(t *testing.T, msgs chan *nats.Msg) *nats.Msg {
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestNewNATSPubSubUnreachable(t *testing.T) {
+	_, err := NewNATSPubSub("nats://127.0.0.1:1", zap.NewNop().Sugar())
+	assert.Error(t, err)
+}
+
+func TestSubscribeTypedHandler(t *testing.T) {
+	ps := newTestPubSub(t, runNATSServer(t))
+
+	events := make(chan bondEvent, 1)
+	_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+		events <- event
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7, Name: "CETES"}))
+	select {
+	case event := <-events:
+		assert.Equal(t, bondEvent{BondID: 7, Name: "CETES"}, event)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+}
+
+func TestSubscribeQueueGroup(t *testing.T) {
+	ps := newTestPubSub(t, runNATSServer(t))
+
+	var received atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(10)
+	for i := 0; i < 2; i++ {
+		_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+			received.Add(1)
+			wg.Done()
+			return nil
+		}, Queue("workers"))
+		assert.NoError(t, err)
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: i}))
+	}
+	wg.Wait()
+	// Each event goes to one member of the group
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(10), received.Load())
+}
+
+func TestSubscribeRetry(t *testing.T) {
+	testCases := map[string]func(ps *NATSPubSub){
+		"Core": func(ps *NATSPubSub) {},
+		"JetStream": func(ps *NATSPubSub) {
+			assert.NoError(t, ps.EnsureStream("BONDS", "bonds.>"))
+		},
+	}
+
+	for name, setup := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ps := newTestPubSub(t, runNATSServer(t))
+			setup(ps)
+			opts := []SubscribeOption{Backoff(10 * time.Millisecond), DeadLetter("dead.bonds")}
+			if name == "JetStream" {
+				opts = append(opts, Durable("retry"))
+			}
+
+			// Fails twice, then succeeds
+			var calls atomic.Int32
+			done := make(chan struct{})
+			_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+				if calls.Add(1) < 3 {
+					return errors.New("database down")
+				}
+				close(done)
+				return nil
+			}, opts...)
+			assert.NoError(t, err)
+			dead := collect(t, ps, "dead.bonds")
+
+			assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7}))
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("event not handled")
+			}
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, int32(3), calls.Load())
+			assert.Len(t, dead, 0)
+		})
+	}
+}
+
+func TestSubscribeDeadLetter(t *testing.T) {
+	testCases := map[string][]SubscribeOption{
+		"Core":      {MaxDeliver(3), Backoff(time.Millisecond), DeadLetter("dead.bonds")},
+		"JetStream": {MaxDeliver(3), Backoff(time.Millisecond), DeadLetter("dead.bonds"), Durable("dead")},
+	}
+
+	for name, opts := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ps := newTestPubSub(t, runNATSServer(t))
+			assert.NoError(t, ps.EnsureStream("BONDS", "bonds.>"))
+
+			var calls atomic.Int32
+			_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+				calls.Add(1)
+				return errors.New("database down")
+			}, opts...)
+			assert.NoError(t, err)
+			dead := collect(t, ps, "dead.bonds")
+
+			assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7}))
+			msg := receive(t, dead)
+			assert.Equal(t, int32(3), calls.Load())
+			assert.Equal(t, "bonds.created", msg.Header.Get(HeaderOriginalSubject))
+			assert.Equal(t, "database down", msg.Header.Get(HeaderError))
+			assert.Equal(t, "3", msg.Header.Get(HeaderDeliveries))
+			assert.JSONEq(t, `{"bond_id":7,"name":""}`, string(msg.Data))
+
+			// An event that can't be decoded isn't retried
+			assert.NoError(t, ps.client.Publish("bonds.created", []byte("not json")))
+			msg = receive(t, dead)
+			assert.Equal(t, "1", msg.Header.Get(HeaderDeliveries))
+			assert.Equal(t, int32(3), calls.Load())
+		})
+	}
+}
+
+func TestSubscribeDurableKeepsEvents(t *testing.T) {
+	ps := newTestPubSub(t, runNATSServer(t))
+	assert.NoError(t, ps.EnsureStream("BONDS", "bonds.>"))
+
+	// Published before the subscriber starts
+	assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7}))
+
+	events := make(chan bondEvent, 1)
+	_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+		events <- event
+		return nil
+	}, Durable("audit"), Queue("audit"))
+	assert.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, 7, event.BondID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stored event not delivered")
+	}
+
+	// Acked, nothing left for the consumer
+	assert.Eventually(t, func() bool {
+		info, err := ps.js.ConsumerInfo("BONDS", "audit")
+		return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDrain(t *testing.T) {
+	ps := newTestPubSub(t, runNATSServer(t))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var handled atomic.Bool
+	_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+		close(started)
+		<-release
+		handled.Store(true)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7}))
+	<-started
+
+	// Drain waits for the event being handled
+	drained := make(chan error, 1)
+	go func() { drained <- ps.Drain(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, handled.Load())
+	close(release)
+	assert.NoError(t, <-drained)
+	assert.True(t, handled.Load())
+	assert.Error(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 8}))
+}
+
+func TestDrainTimeout(t *testing.T) {
+	ps := newTestPubSub(t, runNATSServer(t))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	_, err := Subscribe(ps, "bonds.created", func(ctx context.Context, event bondEvent) error {
+		close(started)
+		<-release
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ps.PublishEvent("bonds.created", bondEvent{BondID: 7}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, ps.Drain(ctx), context.DeadlineExceeded)
+	assert.True(t, ps.client.IsClosed())
+}
@@ -0,0 +1,200 @@
+package psnats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"strconv"
+	"time"
+)
+
+// Headers of a dead-lettered message, the data is the original one
+const (
+	HeaderOriginalSubject = "X-Original-Subject"
+	HeaderError           = "X-Error"
+	HeaderDeliveries      = "X-Deliveries"
+)
+
+// Handler handles a decoded event. An error delivers the event again after the
+// backoff, up to the max deliveries, then it goes to the dead-letter subject.
+type Handler[T any] func(ctx context.Context, event T) error
+
+type subscribeConfig struct {
+	queue      string
+	durable    string
+	maxDeliver int
+	backoff    []time.Duration
+	deadLetter string
+}
+
+// SubscribeOption configures a subscriber
+type SubscribeOption func(*subscribeConfig)
+
+// Queue joins the queue group, each message goes to one subscriber of the group
+func Queue(group string) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.queue = group
+	}
+}
+
+// Durable reads the subject through the JetStream durable consumer name, the
+// subject must be stored by a stream (EnsureStream). The messages are acked
+// when handled and nacked with the backoff delay when the handler fails, and
+// they survive a restart of the subscriber.
+func Durable(name string) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.durable = name
+	}
+}
+
+// MaxDeliver deliveries of a message before it is dead-lettered, 5 by default
+func MaxDeliver(n int) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.maxDeliver = n
+	}
+}
+
+// Backoff waits before each new delivery, the last delay repeats. 1s, 5s and
+// 30s by default.
+func Backoff(delays ...time.Duration) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.backoff = delays
+	}
+}
+
+// DeadLetter publishes the messages that can't be decoded or that failed every
+// delivery on subject. Without it they are logged and dropped.
+func DeadLetter(subject string) SubscribeOption {
+	return func(cfg *subscribeConfig) {
+		cfg.deadLetter = subject
+	}
+}
+
+type subscriber[T any] struct {
+	ps      *NATSPubSub
+	subject string
+	handler Handler[T]
+	cfg     subscribeConfig
+}
+
+// Subscribe runs handler for the JSON events of subject. Without Durable the
+// events aren't stored (core NATS), a failed event is retried in the subscriber
+// and is lost if the process stops.
+func Subscribe[T any](ps *NATSPubSub, subject string, handler Handler[T], opts ...SubscribeOption) (*nats.Subscription, error) {
+	cfg := subscribeConfig{
+		maxDeliver: 5,
+		backoff:    []time.Duration{time.Second, 5 * time.Second, 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &subscriber[T]{ps: ps, subject: subject, handler: handler, cfg: cfg}
+
+	var sub *nats.Subscription
+	var err error
+	switch {
+	case cfg.durable != "":
+		jsOpts := []nats.SubOpt{nats.Durable(cfg.durable), nats.ManualAck(), nats.AckExplicit(), nats.MaxDeliver(cfg.maxDeliver)}
+		if cfg.queue != "" {
+			sub, err = ps.js.QueueSubscribe(subject, cfg.queue, s.handleJetStream, jsOpts...)
+		} else {
+			sub, err = ps.js.Subscribe(subject, s.handleJetStream, jsOpts...)
+		}
+	case cfg.queue != "":
+		sub, err = ps.client.QueueSubscribe(subject, cfg.queue, s.handleCore)
+	default:
+		sub, err = ps.client.Subscribe(subject, s.handleCore)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	// the messages published after Subscribe returns are delivered
+	if err := ps.client.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	return sub, nil
+}
+
+func (s *subscriber[T]) handleCore(msg *nats.Msg) {
+	var event T
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		s.deadLetter(msg, 1, fmt.Errorf("failed to decode the event: %w", err))
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := s.handle(event)
+		if err == nil {
+			return
+		}
+		if attempt >= s.cfg.maxDeliver {
+			s.deadLetter(msg, attempt, err)
+			return
+		}
+		select {
+		case <-time.After(s.delay(attempt)):
+		case <-s.ps.stopping:
+			s.ps.logger.Warnw("event dropped on shutdown", "subject", msg.Subject, "deliveries", attempt, "error", err.Error())
+			return
+		}
+	}
+}
+
+func (s *subscriber[T]) handleJetStream(msg *nats.Msg) {
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempt = int(meta.NumDelivered)
+	}
+
+	var event T
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		s.deadLetter(msg, attempt, fmt.Errorf("failed to decode the event: %w", err))
+		_ = msg.Term()
+		return
+	}
+
+	if err := s.handle(event); err != nil {
+		if attempt >= s.cfg.maxDeliver {
+			s.deadLetter(msg, attempt, err)
+			_ = msg.Term()
+			return
+		}
+		_ = msg.NakWithDelay(s.delay(attempt))
+		return
+	}
+	_ = msg.Ack()
+}
+
+// handle runs the handler, a panic fails the delivery
+func (s *subscriber[T]) handle(event T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return s.handler(context.Background(), event)
+}
+
+func (s *subscriber[T]) delay(attempt int) time.Duration {
+	if len(s.cfg.backoff) == 0 {
+		return 0
+	}
+	return s.cfg.backoff[min(attempt, len(s.cfg.backoff))-1]
+}
+
+func (s *subscriber[T]) deadLetter(msg *nats.Msg, deliveries int, cause error) {
+	if s.cfg.deadLetter == "" {
+		s.ps.logger.Errorw("event dropped", "subject", msg.Subject, "deliveries", deliveries, "error", cause.Error())
+		return
+	}
+
+	dead := nats.NewMsg(s.cfg.deadLetter)
+	dead.Data = msg.Data
+	dead.Header.Set(HeaderOriginalSubject, msg.Subject)
+	dead.Header.Set(HeaderError, cause.Error())
+	dead.Header.Set(HeaderDeliveries, strconv.Itoa(deliveries))
+	if err := s.ps.client.PublishMsg(dead); err != nil {
+		s.ps.logger.Errorw("failed to dead-letter event", "subject", msg.Subject, "error", err.Error(), "cause", cause.Error())
+	}
+}
@@ -0,0 +1,269 @@
+package psnats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"kiramishima/m-backend/internal/core/domain"
+	rPort "kiramishima/m-backend/internal/core/ports/repository"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"sync"
+	"time"
+)
+
+// Stream, consumer and bucket of the task queue
+const (
+	tasksStream   = "TASKS"
+	tasksSubject  = "tasks."
+	tasksConsumer = "tasks"
+	tasksBucket   = "TASKS"
+)
+
+var _ rPort.TaskRepository = (*TaskRepository)(nil)
+
+// TaskRepository struct, the task queue on JetStream. The ids of the tasks are
+// published on tasks.<type> to a work queue stream read by one durable pull
+// consumer, and the tasks are stored in a key-value bucket. A message not
+// acked within the visibility timeout (AckWait) is delivered again, a failed
+// task is nacked with the delay of its retry. The bucket forgets the tasks
+// after the retention, so Purge has nothing to do.
+type TaskRepository struct {
+	ps   *NATSPubSub
+	kv   nats.KeyValue
+	sub  *nats.Subscription
+	poll time.Duration
+	// fetch one worker fetches at a time
+	fetch    sync.Mutex
+	mu       sync.Mutex
+	inflight map[string]*nats.Msg
+}
+
+// storedTask the task in the bucket, with the fields hidden from the clients
+type storedTask struct {
+	*domain.Task
+	UserID  int             `json:"user_id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NewTaskRepository creates the stream, the consumer and the bucket when they
+// don't exist. Claim waits up to poll for a task.
+func NewTaskRepository(ps *NATSPubSub, visibility time.Duration, retention time.Duration, poll time.Duration) (*TaskRepository, error) {
+	_, err := ps.js.StreamInfo(tasksStream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = ps.js.AddStream(&nats.StreamConfig{
+			Name:      tasksStream,
+			Subjects:  []string{tasksSubject + ">"},
+			Retention: nats.WorkQueuePolicy,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure stream %s: %w", tasksStream, err)
+	}
+
+	kv, err := ps.js.KeyValue(tasksBucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = ps.js.CreateKeyValue(&nats.KeyValueConfig{Bucket: tasksBucket, TTL: retention})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure bucket %s: %w", tasksBucket, err)
+	}
+
+	sub, err := ps.js.PullSubscribe(tasksSubject+">", tasksConsumer, nats.ManualAck(), nats.AckWait(visibility), nats.MaxDeliver(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to the tasks: %w", err)
+	}
+
+	return &TaskRepository{
+		ps:       ps,
+		kv:       kv,
+		sub:      sub,
+		poll:     poll,
+		inflight: make(map[string]*nats.Msg),
+	}, nil
+}
+
+// Create stores the task and publishes its id, the id deduplicates the message
+func (repo *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	if err := repo.put(task, true); err != nil {
+		return err
+	}
+	if _, err := repo.ps.js.Publish(tasksSubject+task.Type, []byte(task.ID), nats.MsgId(task.ID), nats.Context(ctx)); err != nil {
+		_ = repo.kv.Delete(task.ID)
+		return fmt.Errorf("failed to publish the task: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads a task from the bucket
+func (repo *TaskRepository) Get(ctx context.Context, id string) (*domain.Task, error) {
+	entry, err := repo.kv.Get(id)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrInvalidKey) {
+			return nil, dbErrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to read the task: %w", err)
+	}
+
+	stored := storedTask{Task: &domain.Task{}}
+	if err := json.Unmarshal(entry.Value(), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode the task: %w", err)
+	}
+	stored.Task.UserID = stored.UserID
+	stored.Task.Payload = stored.Payload
+
+	return stored.Task, nil
+}
+
+// Claim fetches the next message, skipping the tasks that are already done or
+// expired. The message is kept until Finish or Retry.
+func (repo *TaskRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration) (*domain.Task, error) {
+	repo.fetch.Lock()
+	defer repo.fetch.Unlock()
+
+	for {
+		fctx, cancel := context.WithTimeout(ctx, repo.poll)
+		msgs, err := repo.sub.Fetch(1, nats.Context(fctx))
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout)) {
+				return nil, dbErrors.ErrTaskNotFound
+			}
+			return nil, fmt.Errorf("failed to fetch a task: %w", err)
+		}
+		msg := msgs[0]
+
+		task, err := repo.Get(ctx, string(msg.Data))
+		if errors.Is(err, dbErrors.ErrTaskNotFound) {
+			_ = msg.Term()
+			continue
+		}
+		if err != nil {
+			_ = msg.Nak()
+			return nil, err
+		}
+		if task.Done() {
+			// the ack of a finished task was lost
+			_ = msg.Ack()
+			continue
+		}
+
+		task.Attempts++
+		if meta, err := msg.Metadata(); err == nil {
+			task.Attempts = int(meta.NumDelivered)
+		}
+		until := now.Add(visibility)
+		task.Status = domain.TaskRunning
+		task.LockedUntil = &until
+		task.StartedAt = &now
+		if err := repo.put(task, false); err != nil {
+			_ = msg.Nak()
+			return nil, err
+		}
+
+		repo.mu.Lock()
+		repo.inflight[task.ID] = msg
+		repo.mu.Unlock()
+		return task, nil
+	}
+}
+
+// Finish stores the result and acks the message. Once the task was claimed
+// again it returns ErrTaskLeaseLost and leaves the message to the new claim.
+func (repo *TaskRepository) Finish(ctx context.Context, task *domain.Task) error {
+	if err := repo.update(task); err != nil {
+		return err
+	}
+	if msg := repo.release(task.ID); msg != nil {
+		if err := msg.Ack(); err != nil {
+			return fmt.Errorf("failed to ack the task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Retry stores the error and delivers the message again at the run_at of the
+// task, like Finish it returns ErrTaskLeaseLost when the task was claimed again
+func (repo *TaskRepository) Retry(ctx context.Context, task *domain.Task) error {
+	if err := repo.update(task); err != nil {
+		return err
+	}
+	if msg := repo.release(task.ID); msg != nil {
+		if err := msg.NakWithDelay(time.Until(task.RunAt)); err != nil {
+			return fmt.Errorf("failed to nack the task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Purge the bucket expires the tasks by itself
+func (repo *TaskRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (repo *TaskRepository) put(task *domain.Task, create bool) error {
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+	if create {
+		_, err = repo.kv.Create(task.ID, data)
+	} else {
+		_, err = repo.kv.Put(task.ID, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store the task: %w", err)
+	}
+	return nil
+}
+
+// update stores the outcome of a claim while the stored task is still running
+// the same attempt, the revision of the entry guards against a claim in between
+func (repo *TaskRepository) update(task *domain.Task) error {
+	entry, err := repo.kv.Get(task.ID)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return dbErrors.ErrTaskLeaseLost
+		}
+		return fmt.Errorf("failed to read the task: %w", err)
+	}
+	stored := storedTask{Task: &domain.Task{}}
+	if err := json.Unmarshal(entry.Value(), &stored); err != nil {
+		return fmt.Errorf("failed to decode the task: %w", err)
+	}
+	if stored.Status != domain.TaskRunning || stored.Attempts != task.Attempts {
+		return dbErrors.ErrTaskLeaseLost
+	}
+
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.kv.Update(task.ID, data, entry.Revision()); err != nil {
+		if errors.Is(err, nats.ErrKeyExists) {
+			return dbErrors.ErrTaskLeaseLost
+		}
+		return fmt.Errorf("failed to store the task: %w", err)
+	}
+	return nil
+}
+
+func encodeTask(task *domain.Task) ([]byte, error) {
+	data, err := json.Marshal(storedTask{Task: task, UserID: task.UserID, Payload: task.Payload})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the task: %w", err)
+	}
+	return data, nil
+}
+
+func (repo *TaskRepository) release(id string) *nats.Msg {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	msg := repo.inflight[id]
+	delete(repo.inflight, id)
+	return msg
+}
@@ -0,0 +1,135 @@
+package psnats
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"kiramishima/m-backend/internal/core/domain"
+	dbErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func newTestTaskRepository(t *testing.T, visibility time.Duration) *TaskRepository {
+	ps := newTestPubSub(t, runNATSServer(t))
+	repo, err := NewTaskRepository(ps, visibility, time.Hour, 100*time.Millisecond)
+	assert.NoError(t, err)
+	return repo
+}
+
+func newTestTask(id string) *domain.Task {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &domain.Task{
+		ID:          id,
+		Type:        domain.TaskAccountExport,
+		UserID:      2,
+		Payload:     json.RawMessage(`{"export_id":4}`),
+		Status:      domain.TaskQueued,
+		MaxAttempts: 3,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+}
+
+func TestTaskRepositoryClaimAndFinish(t *testing.T) {
+	repo := newTestTaskRepository(t, time.Minute)
+	ctx := context.Background()
+
+	_, err := repo.Claim(ctx, time.Now(), time.Minute)
+	assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+
+	assert.NoError(t, repo.Create(ctx, newTestTask("task-1")))
+
+	task, err := repo.Claim(ctx, time.Now(), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "task-1", task.ID)
+	assert.Equal(t, 2, task.UserID)
+	assert.JSONEq(t, `{"export_id":4}`, string(task.Payload))
+	assert.Equal(t, domain.TaskRunning, task.Status)
+	assert.Equal(t, 1, task.Attempts)
+
+	finished := time.Now().UTC()
+	task.Status = domain.TaskSucceeded
+	task.Result = json.RawMessage(`{"size":10}`)
+	task.FinishedAt = &finished
+	assert.NoError(t, repo.Finish(ctx, task))
+
+	stored, err := repo.Get(ctx, "task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TaskSucceeded, stored.Status)
+	assert.JSONEq(t, `{"size":10}`, string(stored.Result))
+
+	_, err = repo.Claim(ctx, time.Now(), time.Minute)
+	assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+
+	_, err = repo.Get(ctx, "nope")
+	assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+}
+
+func TestTaskRepositoryRetry(t *testing.T) {
+	repo := newTestTaskRepository(t, time.Minute)
+	ctx := context.Background()
+	assert.NoError(t, repo.Create(ctx, newTestTask("task-1")))
+
+	task, err := repo.Claim(ctx, time.Now(), time.Minute)
+	assert.NoError(t, err)
+	task.Status = domain.TaskQueued
+	task.Error = "boom"
+	task.RunAt = time.Now()
+	assert.NoError(t, repo.Retry(ctx, task))
+
+	task, err = repo.Claim(ctx, time.Now(), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, task.Attempts)
+	assert.Equal(t, "boom", task.Error)
+}
+
+func TestTaskRepositoryVisibilityTimeout(t *testing.T) {
+	repo := newTestTaskRepository(t, 300*time.Millisecond)
+	ctx := context.Background()
+	assert.NoError(t, repo.Create(ctx, newTestTask("task-1")))
+
+	_, err := repo.Claim(ctx, time.Now(), 300*time.Millisecond)
+	assert.NoError(t, err)
+	_, err = repo.Claim(ctx, time.Now(), 300*time.Millisecond)
+	assert.ErrorIs(t, err, dbErrors.ErrTaskNotFound)
+
+	// the worker never finished it, it is delivered again
+	time.Sleep(400 * time.Millisecond)
+	task, err := repo.Claim(ctx, time.Now(), 300*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, "task-1", task.ID)
+	assert.Equal(t, 2, task.Attempts)
+}
+
+func TestTaskRepositoryLeaseLost(t *testing.T) {
+	repo := newTestTaskRepository(t, 300*time.Millisecond)
+	ctx := context.Background()
+	assert.NoError(t, repo.Create(ctx, newTestTask("task-1")))
+
+	stale, err := repo.Claim(ctx, time.Now(), 300*time.Millisecond)
+	assert.NoError(t, err)
+
+	time.Sleep(400 * time.Millisecond)
+	task, err := repo.Claim(ctx, time.Now(), 300*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, task.Attempts)
+
+	// the first worker finishes after its visibility timeout
+	finished := time.Now().UTC()
+	stale.Status = domain.TaskFailed
+	stale.Error = "late"
+	stale.FinishedAt = &finished
+	assert.ErrorIs(t, repo.Finish(ctx, stale), dbErrors.ErrTaskLeaseLost)
+	stale.Status = domain.TaskQueued
+	assert.ErrorIs(t, repo.Retry(ctx, stale), dbErrors.ErrTaskLeaseLost)
+
+	task.Status = domain.TaskSucceeded
+	task.FinishedAt = &finished
+	assert.NoError(t, repo.Finish(ctx, task))
+
+	stored, err := repo.Get(ctx, "task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TaskSucceeded, stored.Status)
+	assert.Empty(t, stored.Error)
+}
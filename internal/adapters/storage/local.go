@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"kiramishima/m-backend/pkg/utils"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var _ svcport.Storage = (*LocalStorage)(nil)
+
+// LocalStorage struct, keeps the files in a directory. The signed URLs point
+// to the files endpoint of the API, which checks the signature.
+type LocalStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLocalStorage creates a new local storage under root
+func NewLocalStorage(root string, baseURL string, secret []byte) *LocalStorage {
+	return &LocalStorage{
+		root:    root,
+		baseURL: baseURL,
+		secret:  secret,
+		now:     time.Now,
+	}
+}
+
+// Put writes the file, it only becomes visible once it is complete
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to create the directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open opens the file, the content type comes from the extension of the key
+func (s *LocalStorage) Open(_ context.Context, key string) (*domain.StoredFile, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, appErr.ErrFileNotFound
+		}
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, appErr.ErrFileNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &domain.StoredFile{Body: f, ContentType: contentType, Size: info.Size()}, nil
+}
+
+// Delete removes the file, deleting a missing file is not an error
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns a link to the files endpoint that works for ttl
+func (s *LocalStorage) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	return utils.SignURL(s.secret, s.baseURL, key, s.now().Add(ttl)), nil
+}
+
+// path maps the key to a file under the root, rejecting keys that escape it
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", appErr.ErrInvalidFileKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// validKey a relative, clean key without dot segments
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
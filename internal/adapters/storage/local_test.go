@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"kiramishima/m-backend/pkg/utils"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocalStorage(t.TempDir(), "http://localhost:8080/v1/files/", []byte("secret"))
+
+	t.Run("Put And Open", func(t *testing.T) {
+		err := s.Put(ctx, "avatars/1/abc_64.png", bytes.NewReader([]byte("png")), 3, "image/png")
+		assert.NoError(t, err)
+
+		f, err := s.Open(ctx, "avatars/1/abc_64.png")
+		assert.NoError(t, err)
+		defer f.Body.Close()
+		body, _ := io.ReadAll(f.Body)
+		assert.Equal(t, "png", string(body))
+		assert.Equal(t, "image/png", f.ContentType)
+		assert.Equal(t, int64(3), f.Size)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, s.Put(ctx, "avatars/1/old.png", strings.NewReader("x"), 1, "image/png"))
+		assert.NoError(t, s.Delete(ctx, "avatars/1/old.png"))
+
+		_, err := s.Open(ctx, "avatars/1/old.png")
+		assert.ErrorIs(t, err, appErr.ErrFileNotFound)
+		assert.NoError(t, s.Delete(ctx, "avatars/1/old.png"))
+	})
+
+	t.Run("Keys Can't Escape The Root", func(t *testing.T) {
+		for _, key := range []string{"../etc/passwd", "/etc/passwd", "avatars/../../x", "avatars//x", "avatars/.hidden", ""} {
+			assert.ErrorIs(t, s.Put(ctx, key, strings.NewReader("x"), 1, ""), appErr.ErrInvalidFileKey, key)
+			_, err := s.Open(ctx, key)
+			assert.ErrorIs(t, err, appErr.ErrInvalidFileKey, key)
+		}
+	})
+
+	t.Run("Signed URL", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+
+		link, err := s.SignedURL(ctx, "avatars/1/abc_64.png", time.Minute)
+		assert.NoError(t, err)
+		u, err := url.Parse(link)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/files/avatars/1/abc_64.png", u.Path)
+
+		q := u.Query()
+		assert.True(t, utils.VerifySignedURL([]byte("secret"), "avatars/1/abc_64.png", q.Get("expires"), q.Get("signature"), now))
+		assert.False(t, utils.VerifySignedURL([]byte("secret"), "avatars/2/abc_64.png", q.Get("expires"), q.Get("signature"), now))
+		assert.False(t, utils.VerifySignedURL([]byte("other"), "avatars/1/abc_64.png", q.Get("expires"), q.Get("signature"), now))
+		assert.False(t, utils.VerifySignedURL([]byte("secret"), "avatars/1/abc_64.png", q.Get("expires"), q.Get("signature"), now.Add(2*time.Minute)))
+	})
+}
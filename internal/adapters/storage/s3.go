@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"time"
+)
+
+var _ svcport.Storage = (*S3Storage)(nil)
+
+// S3Storage struct, keeps the files in a bucket of an S3-compatible server.
+// The signed URLs are presigned GET requests to the server.
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage creates a new S3 storage, the bucket must exist
+func NewS3Storage(cfg domain.Storage) (*S3Storage, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the S3 client: %w", err)
+	}
+
+	return &S3Storage{client: client, bucket: cfg.S3Bucket}, nil
+}
+
+// Put uploads the object
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !validKey(key) {
+		return appErr.ErrInvalidFileKey
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload the object: %w", err)
+	}
+	return nil
+}
+
+// Open downloads the object
+func (s *S3Storage) Open(ctx context.Context, key string) (*domain.StoredFile, error) {
+	if !validKey(key) {
+		return nil, appErr.ErrInvalidFileKey
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	// GetObject is lazy, Stat makes the request
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, s.mapError(err)
+	}
+
+	return &domain.StoredFile{Body: obj, ContentType: info.ContentType, Size: info.Size}, nil
+}
+
+// Delete removes the object, deleting a missing object is not an error
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return appErr.ErrInvalidFileKey
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		if err := s.mapError(err); errors.Is(err, appErr.ErrFileNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete the object: %w", err)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object that works for ttl
+func (s *S3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", appErr.ErrInvalidFileKey
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the url: %w", err)
+	}
+	return u.String(), nil
+}
+
+func (s *S3Storage) mapError(err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return appErr.ErrFileNotFound
+	}
+	return err
+}
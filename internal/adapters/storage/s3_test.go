@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestS3Storage runs an in-memory S3 server with the bucket
+func newTestS3Storage(t *testing.T) *S3Storage {
+	backend := s3mem.New()
+	assert.NoError(t, backend.CreateBucket("m-bonds"))
+	srv := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3Storage(domain.Storage{
+		S3Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		S3Region:    "us-east-1",
+		S3Bucket:    "m-bonds",
+		S3AccessKey: "access",
+		S3SecretKey: "secret",
+	})
+	assert.NoError(t, err)
+	return s
+}
+
+func TestS3Storage(t *testing.T) {
+	ctx := context.Background()
+	s := newTestS3Storage(t)
+
+	t.Run("Put And Open", func(t *testing.T) {
+		err := s.Put(ctx, "avatars/1/abc_64.png", strings.NewReader("png"), 3, "image/png")
+		assert.NoError(t, err)
+
+		f, err := s.Open(ctx, "avatars/1/abc_64.png")
+		assert.NoError(t, err)
+		defer f.Body.Close()
+		body, _ := io.ReadAll(f.Body)
+		assert.Equal(t, "png", string(body))
+		assert.Equal(t, "image/png", f.ContentType)
+		assert.Equal(t, int64(3), f.Size)
+	})
+
+	t.Run("Open Missing", func(t *testing.T) {
+		_, err := s.Open(ctx, "avatars/1/missing.png")
+		assert.ErrorIs(t, err, appErr.ErrFileNotFound)
+	})
+
+	t.Run("Signed URL", func(t *testing.T) {
+		assert.NoError(t, s.Put(ctx, "avatars/1/signed.png", strings.NewReader("signed"), 6, "image/png"))
+
+		link, err := s.SignedURL(ctx, "avatars/1/signed.png", time.Minute)
+		assert.NoError(t, err)
+		assert.Contains(t, link, "X-Amz-Signature=")
+
+		resp, err := http.Get(link)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "signed", string(body))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, s.Put(ctx, "avatars/1/old.png", strings.NewReader("x"), 1, "image/png"))
+		assert.NoError(t, s.Delete(ctx, "avatars/1/old.png"))
+
+		_, err := s.Open(ctx, "avatars/1/old.png")
+		assert.ErrorIs(t, err, appErr.ErrFileNotFound)
+	})
+
+	t.Run("Invalid Key", func(t *testing.T) {
+		err := s.Put(ctx, "../x", strings.NewReader("x"), 1, "")
+		assert.ErrorIs(t, err, appErr.ErrInvalidFileKey)
+	})
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"errors"
+	"go.uber.org/fx"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+)
+
+// Module storage, STORAGE_DRIVER selects the implementation
+var Module = fx.Module("storage",
+	fx.Provide(func(cfg *domain.Configuration) (svcport.Storage, error) {
+		if cfg.StorageDriver == "s3" {
+			return NewS3Storage(cfg.Storage)
+		}
+		// An empty key would let anyone sign the links
+		if cfg.StorageSigningKey == "" {
+			return nil, errors.New("STORAGE_SIGNING_KEY is required by the local storage")
+		}
+		return NewLocalStorage(cfg.StoragePath, cfg.StorageBaseURL, []byte(cfg.StorageSigningKey)), nil
+	}),
+)
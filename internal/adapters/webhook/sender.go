@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/fx"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var _ svcport.WebhookSender = (*HTTPSender)(nil)
+
+// ErrAddressNotAllowed the URL resolves to a private or special-purpose address
+var ErrAddressNotAllowed = errors.New("the webhook URL resolves to a private address")
+
+// deniedPrefixes the special-purpose ranges of the IANA registries, none of
+// them is a public host a webhook can be delivered to. IPv4-mapped addresses
+// are unmapped before the check.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),          // this network
+	netip.MustParsePrefix("10.0.0.0/8"),         // private
+	netip.MustParsePrefix("100.64.0.0/10"),      // shared address space, carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),        // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),     // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),      // private
+	netip.MustParsePrefix("192.0.0.0/24"),       // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),       // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),     // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),     // private
+	netip.MustParsePrefix("198.18.0.0/15"),      // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"),    // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),     // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),        // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),        // reserved
+	netip.MustParsePrefix("255.255.255.255/32"), // limited broadcast
+	netip.MustParsePrefix("::/128"),             // unspecified
+	netip.MustParsePrefix("::1/128"),            // loopback
+	netip.MustParsePrefix("::ffff:0:0/96"),      // IPv4-mapped
+	netip.MustParsePrefix("64:ff9b::/96"),       // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),     // local NAT64
+	netip.MustParsePrefix("100::/64"),           // discard
+	netip.MustParsePrefix("2001::/23"),          // IETF protocol assignments, Teredo
+	netip.MustParsePrefix("2001:db8::/32"),      // documentation
+	netip.MustParsePrefix("2002::/16"),          // 6to4
+	netip.MustParsePrefix("fc00::/7"),           // unique local
+	netip.MustParsePrefix("fe80::/10"),          // link-local
+	netip.MustParsePrefix("ff00::/8"),           // multicast
+}
+
+// maxResponseBody bytes of the response read before closing it, the body is discarded
+const maxResponseBody = 64 << 10
+
+// HTTPSender struct, posts the deliveries. It doesn't follow redirects, and
+// unless allowPrivate it refuses to connect to the internal network, the
+// check runs on the resolved address so DNS can't point a public name inside.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender creates a sender with a timeout per request
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) *HTTPSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = publicOnly
+	}
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 2,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts body to url
+func (s *HTTPSender) Send(ctx context.Context, url string, header http.Header, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the webhook request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "m-backend-webhooks/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	return resp.StatusCode, nil
+}
+
+// publicOnly refuses to dial the addresses of deniedPrefixes
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return ErrAddressNotAllowed
+	}
+	// a prefix never contains a zoned address, fe80::1%eth0 is checked as fe80::1
+	if denied(ip.WithZone("").Unmap()) {
+		return ErrAddressNotAllowed
+	}
+	return nil
+}
+
+// denied reports whether ip is in one of the deniedPrefixes
+func denied(ip netip.Addr) bool {
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Module webhook sender
+var Module = fx.Module("webhook",
+	fx.Provide(func(cfg *domain.Configuration) svcport.WebhookSender {
+		return NewHTTPSender(time.Duration(cfg.WebhookTimeout)*time.Second, cfg.WebhookAllowPrivate)
+	}),
+)
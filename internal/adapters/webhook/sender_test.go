@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPSenderSend(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sender := NewHTTPSender(time.Second, true)
+	header := http.Header{}
+	header.Set("X-Webhook-Event", "ping")
+
+	status, err := sender.Send(context.Background(), srv.URL, header, []byte(`{"type":"ping"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "ping", got.Header.Get("X-Webhook-Event"))
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, `{"type":"ping"}`, string(body))
+}
+
+func TestHTTPSenderNoRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	status, err := NewHTTPSender(time.Second, true).Send(context.Background(), srv.URL, http.Header{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, status)
+}
+
+func TestHTTPSenderPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached the private address")
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPSender(time.Second, false).Send(context.Background(), srv.URL, http.Header{}, nil)
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+}
+
+func TestPublicOnly(t *testing.T) {
+	for address, allowed := range map[string]bool{
+		"93.184.216.34:443":     true,
+		"[2606:4700::1]:443":    true,
+		"127.0.0.1:80":          false,
+		"10.1.2.3:80":           false,
+		"192.168.1.1:80":        false,
+		"169.254.169.254:80":    false,
+		"0.0.0.0:80":            false,
+		"0.1.2.3:80":            false,
+		"100.64.0.1:80":         false,
+		"100.127.255.254:80":    false,
+		"172.16.0.1:80":         false,
+		"192.0.0.8:80":          false,
+		"192.0.2.10:80":         false,
+		"198.18.0.1:80":         false,
+		"203.0.113.5:80":        false,
+		"224.0.0.1:80":          false,
+		"239.255.255.250:80":    false,
+		"240.0.0.1:80":          false,
+		"255.255.255.255:80":    false,
+		"100.63.255.255:80":     true,
+		"100.128.0.1:80":        true,
+		"[::1]:80":              false,
+		"[::]:80":               false,
+		"[::ffff:127.0.0.1]:80": false,
+		"[::ffff:10.0.0.1]:80":  false,
+		"[64:ff9b::a00:1]:80":   false,
+		"[2001:db8::1]:80":      false,
+		"[2002:a00:1::1]:80":    false,
+		"[fd00::1]:80":          false,
+		"[fe80::1]:80":          false,
+		"[fe80::1%eth0]:80":     false,
+		"[fd00::1%eth0]:80":     false,
+		"[ff02::1]:80":          false,
+	} {
+		err := publicOnly("tcp", address, nil)
+		if allowed {
+			assert.NoError(t, err, address)
+		} else {
+			assert.ErrorIs(t, err, ErrAddressNotAllowed, address)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package domain
+
+// Alerts settings. A triggered alert stays quiet for ALERT_COOLDOWN seconds
+// while its condition holds. ALERT_MAX_PER_USER caps the alerts of a user.
+type Alerts struct {
+	AlertCooldown   int `envconfig:"ALERT_COOLDOWN" default:"3600"`
+	AlertMaxPerUser int `envconfig:"ALERT_MAX_PER_USER" default:"50"`
+}
@@ -1,6 +1,26 @@
 package domain
 
+import "time"
+
+// Cache settings. CACHE_DRIVER is "redis" or "memory", an in-process LRU of
+// CACHE_LRU_SIZE entries for tests and single-node deployments. A Redis call
+// that takes longer than CACHE_TIMEOUT fails, and after a failure the cache is
+// skipped for CACHE_RETRY seconds so the queries go straight to the database.
+// The "tiered" driver keeps a local LRU in front of Redis, its entries live at
+// most CACHE_LOCAL_TTL seconds and the writes are broadcast over NATS on
+// CACHE_INVALIDATION_SUBJECT so every instance evicts its stale copies.
 type Cache struct {
-	Addr     string `envconfig:"CACHE_ADDR" required:"true" default:"localhost"`
-	Password string `envconfig:"CACHE_PWD" default:""`
+	Addr         string        `envconfig:"CACHE_ADDR" required:"true" default:"localhost"`
+	Password     string        `envconfig:"CACHE_PWD" default:""`
+	CacheDriver  string        `envconfig:"CACHE_DRIVER" default:"redis"`
+	LRUSize      int           `envconfig:"CACHE_LRU_SIZE" default:"10000"`
+	CacheTimeout time.Duration `envconfig:"CACHE_TIMEOUT" default:"250ms"`
+	CacheRetry   int           `envconfig:"CACHE_RETRY" default:"5"`
+	LocalTTL     int           `envconfig:"CACHE_LOCAL_TTL" default:"30"`
+	// InvalidationSubject NATS subject of the invalidation messages
+	InvalidationSubject string `envconfig:"CACHE_INVALIDATION_SUBJECT" default:"cache.invalidate"`
+	// EntityTTL and ListTTL are the seconds a cached bond or market bond, and a
+	// cached market listing, are served before reading the database again
+	EntityTTL int `envconfig:"CACHE_ENTITY_TTL" default:"300"`
+	ListTTL   int `envconfig:"CACHE_LIST_TTL" default:"30"`
 }
@@ -4,6 +4,20 @@ type Configuration struct {
 	HTTPServer
 	Database
 	Cache
+	PasswordHash
+	TwoFactor
+	LoginProtection
+	RateLimit
+	Mail
+	OIDC
+	Sessions
+	CookieSessions
+	Storage
+	Exports
+	Webhooks
+	Alerts
+	Scheduler
+	Tasks
 	ContextTimeout int    `envconfig:"CONTEXT_TIMEOUT" default:"2"`
 	NATS_Addr      string `envconfig:"NATS_ADDR" default:"nats://localhost:4222"`
 }
@@ -0,0 +1,11 @@
+package domain
+
+// CookieSessions settings of the browser session mode. When it is enabled a
+// client that sends "X-Session-Mode: cookie" gets its tokens in HttpOnly
+// cookies instead of the response body, SameSite is lax, strict or none.
+type CookieSessions struct {
+	CookieSessionsEnabled bool   `envconfig:"COOKIE_SESSIONS_ENABLED" default:"false"`
+	CookieDomain          string `envconfig:"COOKIE_DOMAIN" default:""`
+	CookieSecure          bool   `envconfig:"COOKIE_SECURE" default:"true"`
+	CookieSameSite        string `envconfig:"COOKIE_SAMESITE" default:"lax"`
+}
@@ -0,0 +1,6 @@
+package domain
+
+// Exports personal data export settings, the TTL is in seconds
+type Exports struct {
+	ExportTTL int `envconfig:"EXPORT_TTL" default:"604800"`
+}
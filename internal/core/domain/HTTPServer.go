@@ -8,4 +8,14 @@ type HTTPServer struct {
 	Port          int           `envconfig:"PORT" default:"8080"`
 	ReadTimeout   time.Duration `envconfig:"HTTP_SERVER_READ_TIMEOUT" default:"1s"`
 	WriteTimeout  time.Duration `envconfig:"HTTP_SERVER_WRITE_TIMEOUT" default:"2s"`
+	// ShutdownDelay time between failing the readiness probe and draining the
+	// connections, ShutdownTimeout time given to the requests in flight
+	ShutdownDelay   time.Duration `envconfig:"HTTP_SERVER_SHUTDOWN_DELAY" default:"5s"`
+	ShutdownTimeout time.Duration `envconfig:"HTTP_SERVER_SHUTDOWN_TIMEOUT" default:"20s"`
+	// CORSAllowedOrigins origins allowed to call the API from a browser, credentials
+	// are only allowed when the list has no "*"
+	CORSAllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS" default:""`
+	// TrustedProxies IPs or CIDR ranges of the proxies whose X-Forwarded-For and
+	// X-Real-IP are honored, the headers of any other peer are ignored
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES" default:""`
 }
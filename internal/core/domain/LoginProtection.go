@@ -0,0 +1,13 @@
+package domain
+
+// LoginProtection brute-force counters, backoff and lockout settings. Durations are in seconds.
+type LoginProtection struct {
+	MaxAccountAttempts int    `envconfig:"LOGIN_MAX_ACCOUNT_ATTEMPTS" default:"10"`
+	MaxIPAttempts      int    `envconfig:"LOGIN_MAX_IP_ATTEMPTS" default:"100"`
+	BackoffThreshold   int    `envconfig:"LOGIN_BACKOFF_THRESHOLD" default:"3"`
+	BackoffBase        int    `envconfig:"LOGIN_BACKOFF_BASE" default:"1"`
+	BackoffMax         int    `envconfig:"LOGIN_BACKOFF_MAX" default:"60"`
+	AttemptsWindow     int    `envconfig:"LOGIN_ATTEMPTS_WINDOW" default:"900"`
+	LockoutDuration    int    `envconfig:"LOGIN_LOCKOUT_DURATION" default:"900"`
+	UnlockURL          string `envconfig:"LOGIN_UNLOCK_URL" default:"http://localhost:8080/v1/auth/unlock"`
+}
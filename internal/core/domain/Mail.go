@@ -0,0 +1,12 @@
+package domain
+
+// Mail outgoing email settings. MAIL_MAILER=log writes the emails to the logger.
+type Mail struct {
+	Mailer      string `envconfig:"MAIL_MAILER" default:"log"`
+	Host        string `envconfig:"MAIL_HOST" default:"localhost"`
+	MailPort    int    `envconfig:"MAIL_PORT" default:"25"`
+	Username    string `envconfig:"MAIL_USERNAME" default:""`
+	MailPwd     string `envconfig:"MAIL_PASSWORD" default:""`
+	Encryption  string `envconfig:"MAIL_ENCRYPTION" default:""`
+	FromAddress string `envconfig:"MAIL_FROM_ADDRESS" default:"no-reply@mbonds.local"`
+}
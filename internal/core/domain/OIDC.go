@@ -0,0 +1,11 @@
+package domain
+
+// OIDC OpenID Connect settings, an empty issuer disables the SSO login
+type OIDC struct {
+	OIDCIssuer       string   `envconfig:"OIDC_ISSUER" default:""`
+	OIDCClientID     string   `envconfig:"OIDC_CLIENT_ID" default:""`
+	OIDCClientSecret string   `envconfig:"OIDC_CLIENT_SECRET" default:""`
+	OIDCRedirectURL  string   `envconfig:"OIDC_REDIRECT_URL" default:"http://localhost:8080/v1/auth/oidc/callback"`
+	OIDCScopes       []string `envconfig:"OIDC_SCOPES" default:"openid,email,profile"`
+	OIDCStateTTL     int      `envconfig:"OIDC_STATE_TTL" default:"600"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+// PasswordHash argon2id cost parameters
+type PasswordHash struct {
+	Argon2Memory      uint32 `envconfig:"ARGON2_MEMORY" default:"65536"`
+	Argon2Iterations  uint32 `envconfig:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism uint8  `envconfig:"ARGON2_PARALLELISM" default:"2"`
+	Argon2SaltLength  uint32 `envconfig:"ARGON2_SALT_LENGTH" default:"16"`
+	Argon2KeyLength   uint32 `envconfig:"ARGON2_KEY_LENGTH" default:"32"`
+}
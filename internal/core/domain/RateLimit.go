@@ -0,0 +1,12 @@
+package domain
+
+// RateLimit request budgets per principal and route class, every budget is the
+// number of requests allowed in RATE_LIMIT_WINDOW seconds. FallbackRetry is the
+// seconds the limiter keeps counting in memory after Redis fails.
+type RateLimit struct {
+	RateLimitWindow        int `envconfig:"RATE_LIMIT_WINDOW" default:"60"`
+	RateLimitAuth          int `envconfig:"RATE_LIMIT_AUTH" default:"20"`
+	RateLimitRead          int `envconfig:"RATE_LIMIT_READ" default:"600"`
+	RateLimitTrade         int `envconfig:"RATE_LIMIT_TRADE" default:"60"`
+	RateLimitFallbackRetry int `envconfig:"RATE_LIMIT_FALLBACK_RETRY" default:"5"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+// Scheduler settings. A replica runs a job while it holds its lock, renewed
+// every third of SCHEDULER_LOCK_TTL seconds. A run is cancelled after
+// SCHEDULER_JOB_TIMEOUT seconds unless the job sets its own timeout.
+// JOB_RUNS_RETENTION days of run history are kept.
+type Scheduler struct {
+	SchedulerLockTTL    int `envconfig:"SCHEDULER_LOCK_TTL" default:"30"`
+	SchedulerJobTimeout int `envconfig:"SCHEDULER_JOB_TIMEOUT" default:"600"`
+	JobRunsRetention    int `envconfig:"JOB_RUNS_RETENTION" default:"30"`
+}
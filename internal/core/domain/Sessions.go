@@ -0,0 +1,9 @@
+package domain
+
+// Sessions refresh token settings, the TTL is in seconds and slides on every refresh.
+// The access tokens are checked against their session, the answer is cached
+// CheckTTL seconds.
+type Sessions struct {
+	RefreshTTL int `envconfig:"SESSION_REFRESH_TTL" default:"2592000"`
+	CheckTTL   int `envconfig:"SESSION_CHECK_TTL" default:"10"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+// Storage where the uploaded files are kept. STORAGE_DRIVER is "local" or "s3",
+// the S3_* variables work with AWS and with S3-compatible servers like MinIO.
+type Storage struct {
+	StorageDriver     string `envconfig:"STORAGE_DRIVER" default:"local"`
+	StoragePath       string `envconfig:"STORAGE_PATH" default:"./storage"`
+	StorageBaseURL    string `envconfig:"STORAGE_BASE_URL" default:"http://localhost:8080/v1/files"`
+	StorageSigningKey string `envconfig:"STORAGE_SIGNING_KEY" default:""`
+	StorageURLTTL     int    `envconfig:"STORAGE_URL_TTL" default:"900"`
+	S3Endpoint        string `envconfig:"S3_ENDPOINT" default:"localhost:9000"`
+	S3Region          string `envconfig:"S3_REGION" default:"us-east-1"`
+	S3Bucket          string `envconfig:"S3_BUCKET" default:"m-bonds"`
+	S3AccessKey       string `envconfig:"S3_ACCESS_KEY" default:""`
+	S3SecretKey       string `envconfig:"S3_SECRET_KEY" default:""`
+	S3UseSSL          bool   `envconfig:"S3_USE_SSL" default:"false"`
+	AvatarMaxSize     int64  `envconfig:"AVATAR_MAX_SIZE" default:"5242880"`
+}
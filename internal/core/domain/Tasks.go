@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Tasks settings of the background task queue. TASK_QUEUE_DRIVER is "mysql",
+// the tasks table claimed with SELECT ... FOR UPDATE SKIP LOCKED (MySQL 8), or
+// "nats", a JetStream work queue with the state of the tasks in a key-value
+// bucket. Each instance runs TASK_WORKERS tasks at once. A claimed task is
+// hidden from the other workers for TASK_VISIBILITY_TIMEOUT seconds and is
+// delivered again once it passes. A failed task is retried up to
+// TASK_MAX_ATTEMPTS attempts, waiting TASK_BACKOFF seconds doubled after each
+// attempt. An idle worker looks for tasks every TASK_POLL_INTERVAL. Finished
+// tasks are kept TASK_RETENTION hours.
+type Tasks struct {
+	TaskQueueDriver       string        `envconfig:"TASK_QUEUE_DRIVER" default:"mysql"`
+	TaskWorkers           int           `envconfig:"TASK_WORKERS" default:"4"`
+	TaskVisibilityTimeout int           `envconfig:"TASK_VISIBILITY_TIMEOUT" default:"300"`
+	TaskMaxAttempts       int           `envconfig:"TASK_MAX_ATTEMPTS" default:"5"`
+	TaskBackoff           int           `envconfig:"TASK_BACKOFF" default:"10"`
+	TaskPollInterval      time.Duration `envconfig:"TASK_POLL_INTERVAL" default:"1s"`
+	TaskRetention         int           `envconfig:"TASK_RETENTION" default:"168"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+// TwoFactor TOTP settings
+type TwoFactor struct {
+	TOTPIssuer   string `envconfig:"TOTP_ISSUER" default:"M Bonds"`
+	ChallengeTTL int    `envconfig:"TOTP_CHALLENGE_TTL" default:"300"`
+}
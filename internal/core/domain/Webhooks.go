@@ -0,0 +1,12 @@
+package domain
+
+// Webhooks delivery settings. A failed delivery is retried up to
+// WEBHOOK_MAX_ATTEMPTS times, waiting WEBHOOK_BACKOFF seconds doubled after
+// every attempt. WEBHOOK_ALLOW_PRIVATE lets the URLs point to private and
+// loopback addresses, for local development only.
+type Webhooks struct {
+	WebhookTimeout      int  `envconfig:"WEBHOOK_TIMEOUT" default:"5"`
+	WebhookMaxAttempts  int  `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookBackoff      int  `envconfig:"WEBHOOK_BACKOFF" default:"10"`
+	WebhookAllowPrivate bool `envconfig:"WEBHOOK_ALLOW_PRIVATE" default:"false"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// Account struct, the sign in data of the user included in the data export
+type Account struct {
+	ID        int       `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// AdminUser struct, the user as seen by operations staff
+type AdminUser struct {
+	ID              int        `json:"id" db:"id"`
+	Email           string     `json:"email" db:"email"`
+	UserName        string     `json:"username" db:"username"`
+	SuspendedAt     *time.Time `json:"suspended_at" db:"suspended_at"`
+	SuspendedReason *string    `json:"suspended_reason,omitempty" db:"suspended_reason"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Alert types
+const (
+	// AlertPriceBelow the bond is on sale at Threshold or less
+	AlertPriceBelow = "price_below"
+	// AlertNewListing the bond is put on sale
+	AlertNewListing = "new_listing"
+	// AlertAvailableAbove more than Threshold bonds are on sale
+	AlertAvailableAbove = "available_above"
+)
+
+// WatchlistItem struct, a bond followed by the user and its market
+type WatchlistItem struct {
+	BondUUID  string    `json:"bond_uuid" db:"uuid"`
+	Name      string    `json:"name" db:"name"`
+	Price     float32   `json:"price" db:"price"`
+	Currency  int       `json:"currency" db:"currency"`
+	Available int       `json:"available" db:"available"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Alert struct, a market condition of a bond the user is notified of
+type Alert struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"-" db:"user_id"`
+	BondID          int        `json:"-" db:"bond_id"`
+	BondUUID        string     `json:"bond_uuid" db:"uuid"`
+	BondName        string     `json:"bond_name" db:"name"`
+	Type            string     `json:"type" db:"type"`
+	Threshold       *float64   `json:"threshold,omitempty" db:"threshold"`
+	Active          bool       `json:"active" db:"active"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty" db:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Matches reports if the market of the bond, after the event, meets the
+// condition of the alert. A frozen bond meets none.
+func (a *Alert) Matches(market *BondMarket, eventType string) bool {
+	if market.Frozen {
+		return false
+	}
+	switch a.Type {
+	case AlertPriceBelow:
+		return a.Threshold != nil && market.Available > 0 && float64(market.Price) <= *a.Threshold
+	case AlertNewListing:
+		return eventType == EventListingCreated && market.Available > 0
+	case AlertAvailableAbove:
+		return a.Threshold != nil && float64(market.Available) > *a.Threshold
+	}
+	return false
+}
+
+// BondMarket struct, a bond and the quantity of it on sale
+type BondMarket struct {
+	BondID    int     `json:"-" db:"id"`
+	BondUUID  string  `json:"bond_uuid" db:"uuid"`
+	Name      string  `json:"name" db:"name"`
+	Price     float32 `json:"price" db:"price"`
+	Currency  int     `json:"currency" db:"currency"`
+	Available int     `json:"available" db:"available"`
+	SellerID  int     `json:"-" db:"created_by"`
+	Frozen    bool    `json:"-" db:"frozen"`
+}
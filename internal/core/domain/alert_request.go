@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// WatchlistRequest struct
+type WatchlistRequest struct {
+	BondUUID string `json:"bond_uuid" validate:"required,uuid"`
+}
+
+func (u *WatchlistRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
+
+// AlertRequest struct. price_below and available_above need a threshold,
+// new_listing ignores it.
+type AlertRequest struct {
+	BondUUID  string   `json:"bond_uuid" validate:"required,uuid"`
+	Type      string   `json:"type" validate:"required,oneof=price_below new_listing available_above"`
+	Threshold *float64 `json:"threshold" validate:"required_unless=Type new_listing,omitempty,gte=0,lte=1000000000"`
+	Active    *bool    `json:"active"`
+}
+
+func (u *AlertRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// API key scopes
+const (
+	ScopeMarketRead  = "market:read"
+	ScopeMarketTrade = "market:trade"
+)
+
+// APIKeyScopes the scopes a key can be granted
+var APIKeyScopes = []string{ScopeMarketRead, ScopeMarketTrade}
+
+// APIKey struct, the secret is never stored, only its hash
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"-"`
+	AllowedIPs []string   `json:"allowed_ips" db:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	UsageCount int64      `json:"usage_count" db:"usage_count"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// HasScope reports if the key grants the scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports if the key can't be used anymore at t
+func (k *APIKey) Expired(t time.Time) bool {
+	return k.ExpiresAt != nil && !t.Before(*k.ExpiresAt)
+}
+
+// APIKeyCreated struct, the only response that carries the secret
+type APIKeyCreated struct {
+	*APIKey
+	Key string `json:"key"`
+}
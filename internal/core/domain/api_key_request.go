@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+// APIKeyRequest struct
+type APIKeyRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1,dive,oneof=market:read market:trade"`
+	AllowedIPs []string   `json:"allowed_ips" validate:"max=20,dive,ip|cidr"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+func (u *APIKeyRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Audit actions
+const (
+	AuditSearchUsers          = "users.search"
+	AuditSuspendUser          = "users.suspend"
+	AuditUnsuspendUser        = "users.unsuspend"
+	AuditViewUserBonds        = "users.bonds.view"
+	AuditViewUserTransactions = "users.transactions.view"
+	AuditDelistMarketBond     = "market_bonds.delist"
+	AuditFreezeBond           = "bonds.freeze"
+	AuditUnfreezeBond         = "bonds.unfreeze"
+	AuditSearchAuthEvents     = "auth_events.search"
+	AuditRunJob               = "jobs.run"
+	AuditPauseJob             = "jobs.pause"
+	AuditResumeJob            = "jobs.resume"
+)
+
+// Audit target types
+const (
+	AuditTargetUser       = "user"
+	AuditTargetBond       = "bond"
+	AuditTargetMarketBond = "market_bond"
+	AuditTargetJob        = "job"
+)
+
+// Actor struct, who performs an action and from where
+type Actor struct {
+	ID int
+	IP string
+}
+
+// AuditLog struct
+type AuditLog struct {
+	ID         int       `json:"id" db:"id"`
+	AdminID    int       `json:"admin_id" db:"admin_id"`
+	Action     string    `json:"action" db:"action"`
+	TargetType string    `json:"target_type" db:"target_type"`
+	TargetID   int       `json:"target_id" db:"target_id"`
+	Details    string    `json:"details" db:"details"`
+	IP         string    `json:"ip" db:"ip"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
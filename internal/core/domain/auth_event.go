@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// Auth events
+const (
+	AuthEventAccountLocked   = "account.locked"
+	AuthEventAccountUnlocked = "account.unlocked"
+	AuthEventIPLocked        = "ip.locked"
+	AuthEventSignIn          = "sign_in"
+	AuthEventSignUp          = "sign_up"
+	AuthEventPasswordChange  = "password.change"
+	AuthEventTokenRefresh    = "token.refresh"
+	AuthEventAccountDelete   = "account.delete"
+)
+
+// Auth event outcomes
+const (
+	AuthOutcomeSuccess = "success"
+	AuthOutcomeFailure = "failure"
+	AuthOutcomeBlocked = "blocked"
+)
+
+// AuthEvent struct, security relevant activity of an account
+type AuthEvent struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    *int      `json:"user_id,omitempty" db:"user_id"`
+	Email     string    `json:"email,omitempty" db:"email"`
+	IP        string    `json:"ip" db:"ip"`
+	UserAgent string    `json:"user_agent,omitempty" db:"user_agent"`
+	Event     string    `json:"event" db:"event"`
+	Outcome   string    `json:"outcome" db:"outcome"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Alert reports if the owner of the account is notified of the event
+func (e *AuthEvent) Alert() bool {
+	if e.UserID == nil {
+		return false
+	}
+	switch e.Event {
+	case AuthEventPasswordChange, AuthEventAccountUnlocked:
+		return e.Outcome == AuthOutcomeSuccess
+	case AuthEventAccountLocked:
+		return true
+	}
+	return false
+}
+
+// AuthEventSearch struct, filters of the auth events. Empty fields match every event.
+type AuthEventSearch struct {
+	UserID  int
+	Email   string
+	IP      string
+	Event   string
+	Outcome string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
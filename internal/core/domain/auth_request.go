@@ -1,44 +1,17 @@
 package domain
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/crypto/sha3"
 )
 
 type AuthRequest struct {
-	Email    string `json:"email,omitempty" validate:"required,email"`
-	Password string `json:"password,omitempty" validate:"required"`
-	Token    string `json:"token,omitempty"`
-}
-
-func (u *AuthRequest) Hash256Password(password string) string {
-	buf := []byte(password)
-	pwd := sha3.New256()
-	pwd.Write(buf)
-	return hex.EncodeToString(pwd.Sum(nil))
-}
-
-func (u *AuthRequest) BcryptPassword(password string) (string, error) {
-	buf := []byte(password)
-	hash, err := bcrypt.GenerateFromPassword(buf, bcrypt.DefaultCost)
-	if err != nil {
-		return "", nil
-	}
-	return string(hash), nil
-}
-
-func (u *AuthRequest) ValidateBcryptPassword(password, password2 string) bool {
-	byteHash := []byte(password)
-	buf := []byte(password2)
-	err := bcrypt.CompareHashAndPassword(byteHash, buf)
-	if err != nil {
-		return false
-	}
-	return true
+	Email     string `json:"email,omitempty" validate:"required,email"`
+	Password  string `json:"password,omitempty" validate:"required"`
+	Token     string `json:"token,omitempty"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 func (u *AuthRequest) Validate(v *validator.Validate) error {
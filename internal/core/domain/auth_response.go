@@ -1,5 +1,12 @@
 package domain
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// ChallengeToken is returned instead of Token when the account has 2FA enabled
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	// CSRFToken is returned in the cookie session mode, it goes in the X-CSRF-Token
+	// header of every state-changing request
+	CSRFToken string `json:"csrf_token,omitempty"`
 }
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Avatar the signed URLs of the thumbnails of the profile photo
+type Avatar struct {
+	Small     string    `json:"small"`
+	Medium    string    `json:"medium"`
+	Large     string    `json:"large"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
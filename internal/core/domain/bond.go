@@ -13,6 +13,7 @@ type Bond struct {
 	CreatedBy   string    `json:"created_by"  db:"created_by"`
 	CreatedByID int       `json:"created_by_id" db:"created_by_id"`
 	OnSale      bool      `json:"on_sale" db:"on_sale"`
+	Frozen      bool      `json:"frozen" db:"frozen"`
 	IsOwner     bool      `json:"is_owner"`
 	Status      string    `json:"status" db:"status"`
 	CreatedAt   time.Time `json:"created_at"`
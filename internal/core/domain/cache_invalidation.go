@@ -0,0 +1,9 @@
+package domain
+
+// CacheInvalidation message broadcast by an instance that changed the cache,
+// the other instances evict the keys and the tags from their local tier
+type CacheInvalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
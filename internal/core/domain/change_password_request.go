@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// ChangePasswordRequest struct, the new password follows the rules of the sign up
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,alphanum,gte=6,nefield=CurrentPassword"`
+	IP              string `json:"-"`
+	UserAgent       string `json:"-"`
+	// SessionID the session of the request, it stays signed in
+	SessionID int `json:"-"`
+}
+
+func (u *ChangePasswordRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Data export status
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+	// ExportExpired is never stored, a ready export is expired once ExpiresAt has passed
+	ExportExpired = "expired"
+)
+
+// DataExport struct, an archive with the personal data of the user
+type DataExport struct {
+	ID          int    `json:"id" db:"id"`
+	UserID      int    `json:"-" db:"user_id"`
+	Status      string `json:"status" db:"status"`
+	FileKey     string `json:"-" db:"file_key"`
+	Size        int64  `json:"size,omitempty" db:"size"`
+	DownloadURL string `json:"download_url,omitempty" db:"-"`
+	// TaskID the task building the archive, only set when it is requested
+	TaskID      string     `json:"task_id,omitempty" db:"-"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// Active reports if the export is still waiting or running
+func (e *DataExport) Active() bool {
+	return e.Status == ExportPending || e.Status == ExportRunning
+}
+
+// Expired reports if the archive of a ready export can't be downloaded anymore at t
+func (e *DataExport) Expired(t time.Time) bool {
+	return e.Status == ExportReady && e.ExpiresAt != nil && !e.ExpiresAt.After(t)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// DeleteAccountRequest struct, the password confirms the deletion
+type DeleteAccountRequest struct {
+	Password  string `json:"password" validate:"required"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (r *DeleteAccountRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
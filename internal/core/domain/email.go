@@ -0,0 +1,8 @@
+package domain
+
+// Email struct, a plain text message sent through the mailer
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
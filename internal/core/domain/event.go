@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Domain events published to NATS on EventsSubjectPrefix + type
+const (
+	EventTradeExecuted   = "trade.executed"
+	EventListingCreated  = "listing.created"
+	EventListingDelisted = "listing.delisted"
+	EventListingSoldOut  = "listing.sold_out"
+	EventSecurityAlert   = "security.alert"
+	EventAlertTriggered  = "alert.triggered"
+)
+
+// EventsSubjectPrefix prefix of the subjects of the domain events
+const EventsSubjectPrefix = "events."
+
+// Event struct, a change of the market. UserIDs are the users involved, their
+// webhooks are notified.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserIDs    []int           `json:"user_ids"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewEvent creates an event with the JSON of data
+func NewEvent(eventType string, data any, userIDs ...int) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		UserIDs:    userIDs,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	}, nil
+}
+
+// Subject the NATS subject of the event
+func (e *Event) Subject() string {
+	return EventsSubjectPrefix + e.Type
+}
+
+// TradeExecutedData data of a trade.executed event
+type TradeExecutedData struct {
+	MarketBondID int     `json:"market_bond_id"`
+	BondUUID     string  `json:"bond_uuid"`
+	BondName     string  `json:"bond_name"`
+	Price        float32 `json:"price"`
+	Currency     int     `json:"currency"`
+	Quantity     int     `json:"quantity"`
+	SellerID     int     `json:"seller_id"`
+	BuyerID      int     `json:"buyer_id"`
+}
+
+// ListingCreatedData data of a listing.created event
+type ListingCreatedData struct {
+	BondID   int `json:"bond_id"`
+	SellerID int `json:"seller_id"`
+	Quantity int `json:"quantity"`
+}
+
+// ListingDelistedData data of a listing.delisted event
+type ListingDelistedData struct {
+	MarketBondID int    `json:"market_bond_id"`
+	BondUUID     string `json:"bond_uuid"`
+	BondName     string `json:"bond_name"`
+	SellerID     int    `json:"seller_id"`
+	Reason       string `json:"reason"`
+}
+
+// ListingSoldOutData data of a listing.sold_out event
+type ListingSoldOutData struct {
+	MarketBondID int    `json:"market_bond_id"`
+	BondUUID     string `json:"bond_uuid"`
+	BondName     string `json:"bond_name"`
+	SellerID     int    `json:"seller_id"`
+}
+
+// SecurityAlertData data of a security.alert event, an auth event of the account
+type SecurityAlertData struct {
+	Event     string `json:"event"`
+	Outcome   string `json:"outcome"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// AlertTriggeredData data of an alert.triggered event, the market of the bond
+// when the alert was triggered
+type AlertTriggeredData struct {
+	AlertID   int      `json:"alert_id"`
+	AlertType string   `json:"alert_type"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	BondUUID  string   `json:"bond_uuid"`
+	BondName  string   `json:"bond_name"`
+	Price     float32  `json:"price"`
+	Currency  int      `json:"currency"`
+	Available int      `json:"available"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Job run status
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Job run sources
+const (
+	// JobSourceSchedule the run was due by the schedule of the job
+	JobSourceSchedule = "schedule"
+	// JobSourceManual an admin asked for the run
+	JobSourceManual = "manual"
+)
+
+// JobRun struct, a run of a scheduled job and the replica that ran it
+type JobRun struct {
+	ID         int        `json:"id" db:"id"`
+	Job        string     `json:"job" db:"job"`
+	Instance   string     `json:"instance" db:"instance"`
+	Source     string     `json:"source" db:"source"`
+	Status     string     `json:"status" db:"status"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// ScheduledJob struct, a registered job and its state
+type ScheduledJob struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Paused   bool       `json:"paused"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *JobRun    `json:"last_run,omitempty"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// LoginAttemptStatus struct, the brute-force state of an account or an IP
+type LoginAttemptStatus struct {
+	Failures   int64
+	LockedFor  time.Duration
+	DelayedFor time.Duration
+}
+
+// Blocked reports if sign in attempts must be rejected now
+func (s *LoginAttemptStatus) Blocked() bool {
+	return s != nil && (s.LockedFor > 0 || s.DelayedFor > 0)
+}
+
+// RetryAfter the time until the next attempt is allowed
+func (s *LoginAttemptStatus) RetryAfter() time.Duration {
+	if s == nil {
+		return 0
+	}
+	if s.LockedFor > s.DelayedFor {
+		return s.LockedFor
+	}
+	return s.DelayedFor
+}
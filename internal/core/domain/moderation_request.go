@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// ModerationRequest struct
+type ModerationRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+func (u *ModerationRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification struct, an entry of the inbox of the user. The type is the
+// domain event it comes from.
+type Notification struct {
+	ID        int             `json:"id" db:"id"`
+	UserID    int             `json:"-" db:"user_id"`
+	EventID   string          `json:"-" db:"event_id"`
+	Type      string          `json:"type" db:"type"`
+	Title     string          `json:"title" db:"title"`
+	Body      string          `json:"body" db:"body"`
+	Data      json.RawMessage `json:"data" db:"data"`
+	Read      bool            `json:"read" db:"-"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty" db:"read_at"`
+}
+
+// NotificationSearch struct, a page of the inbox. A nil Read lists every entry.
+type NotificationSearch struct {
+	UserID int
+	Read   *bool
+	Limit  int
+	Offset int
+}
+
+// NotificationList struct, a page of the inbox and the unread entries of the user
+type NotificationList struct {
+	Items  []*Notification `json:"items"`
+	Unread int             `json:"unread"`
+}
+
+// NotificationPreferences struct, the channels the user is notified on.
+// Every channel is on until the user changes it.
+type NotificationPreferences struct {
+	InApp   bool `json:"in_app" db:"in_app"`
+	Email   bool `json:"email" db:"email"`
+	Webhook bool `json:"webhook" db:"webhook"`
+}
+
+// DefaultNotificationPreferences the preferences of a user that never saved them
+func DefaultNotificationPreferences() *NotificationPreferences {
+	return &NotificationPreferences{InApp: true, Email: true, Webhook: true}
+}
+
+// NotificationRecipient struct, the email address and the preferences of a user
+type NotificationRecipient struct {
+	UserID  int    `db:"id"`
+	Address string `db:"address"`
+	NotificationPreferences
+}
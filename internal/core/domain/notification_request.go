@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// NotificationPreferencesRequest struct, every channel is required
+type NotificationPreferencesRequest struct {
+	InApp   *bool `json:"in_app" validate:"required"`
+	Email   *bool `json:"email" validate:"required"`
+	Webhook *bool `json:"webhook" validate:"required"`
+}
+
+func (u *NotificationPreferencesRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
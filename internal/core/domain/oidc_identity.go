@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// OIDCIdentity the claims of a verified ID token
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCState what the callback needs from the login that started the flow
+type OIDCState struct {
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+// UserIdentity struct, links an account of the identity provider to a user
+type UserIdentity struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Issuer    string    `db:"issuer"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Route classes of the rate limiter, every class has its own budget
+const (
+	RateLimitClassAuth  = "auth"
+	RateLimitClassRead  = "read"
+	RateLimitClassTrade = "trade"
+)
+
+// RateLimitHit state of a budget after counting a request
+type RateLimitHit struct {
+	Count int64
+	// Reset time left until the window starts over
+	Reset time.Duration
+}
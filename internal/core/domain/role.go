@@ -0,0 +1,23 @@
+package domain
+
+// Roles
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+)
+
+// Permissions
+const (
+	PermissionApproveListings  = "listings:approve"
+	PermissionManageUsers      = "users:manage"
+	PermissionManageCurrencies = "currencies:manage"
+	PermissionManageJobs       = "jobs:manage"
+)
+
+// Role struct
+type Role struct {
+	ID          int      `json:"id" db:"id"`
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions,omitempty"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// SellerProfile struct, the public profile of a user. Rating is null until
+// the seller gets the first rating.
+type SellerProfile struct {
+	UserID          int       `json:"-" db:"user_id"`
+	UserName        string    `json:"username" db:"username"`
+	MemberSince     time.Time `json:"member_since" db:"member_since"`
+	Listings        int       `json:"listings" db:"listings"`
+	CompletedTrades int       `json:"completed_trades" db:"completed_trades"`
+	Rating          *float64  `json:"rating" db:"rating"`
+	Ratings         int       `json:"ratings" db:"ratings"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+// SellerRating struct, the score a buyer gives to the seller of a settled transaction
+type SellerRating struct {
+	ID            int       `json:"id" db:"id"`
+	TransactionID int       `json:"transaction_id" db:"transaction_id"`
+	SellerID      int       `json:"-" db:"seller_id"`
+	BuyerID       int       `json:"-" db:"buyer_id"`
+	Score         int       `json:"score" db:"score"`
+	Comment       string    `json:"comment" db:"comment"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// SellerRatingRequest struct
+type SellerRatingRequest struct {
+	TransactionID *int   `json:"transaction_id" validate:"required,gte=1"`
+	Score         *int   `json:"score" validate:"required,gte=1,lte=5"`
+	Comment       string `json:"comment" validate:"lte=500"`
+}
+
+func (r *SellerRatingRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(r)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
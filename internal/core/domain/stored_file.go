@@ -0,0 +1,10 @@
+package domain
+
+import "io"
+
+// StoredFile a file opened from the storage, close the body when done
+type StoredFile struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
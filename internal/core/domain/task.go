@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Task status
+const (
+	TaskQueued    = "queued"
+	TaskRunning   = "running"
+	TaskSucceeded = "succeeded"
+	TaskFailed    = "failed"
+)
+
+// Task types
+const (
+	TaskAccountExport = "account.export"
+)
+
+// Task struct, work queued for the background workers. The owner polls it
+// until it is done, the payload stays private.
+type Task struct {
+	ID          string          `json:"id" db:"id"`
+	Type        string          `json:"type" db:"type"`
+	UserID      int             `json:"-" db:"user_id"`
+	Payload     json.RawMessage `json:"-" db:"payload"`
+	Status      string          `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	Result      json.RawMessage `json:"result,omitempty" db:"result"`
+	Error       string          `json:"error,omitempty" db:"error"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LockedUntil *time.Time      `json:"-" db:"locked_until"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// Done reports if the task won't run again
+func (t *Task) Done() bool {
+	return t.Status == TaskSucceeded || t.Status == TaskFailed
+}
+
+// AccountExportPayload payload of an account.export task
+type AccountExportPayload struct {
+	ExportID int `json:"export_id"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Transaction status
+const (
+	TransactionPending = 0
+	TransactionSettled = 1
+)
+
+// Transaction struct
+type Transaction struct {
+	ID            int       `json:"id" db:"id"`
+	SellerID      int       `json:"seller_id" db:"seller_id"`
+	BuyerID       int       `json:"buyer_id" db:"buyer_id"`
+	BondID        int       `json:"bond_id" db:"bond_id"`
+	BondName      string    `json:"bond_name" db:"bond_name"`
+	TotalAcquired int       `json:"total_acquired" db:"total_acquired"`
+	Status        int       `json:"status" db:"status"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// UserTOTP struct, the TOTP enrollment of a user
+type UserTOTP struct {
+	UserID       int        `db:"user_id"`
+	Secret       string     `db:"secret"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+}
+
+// Enabled the enrollment was confirmed with a valid code
+func (t *UserTOTP) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// TwoFactorEnrollment struct, returned once when the enrollment starts
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse struct, the codes are only shown once
+type RecoveryCodesResponse struct {
+	Codes []string `json:"recovery_codes"`
+}
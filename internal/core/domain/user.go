@@ -11,6 +11,8 @@ type User struct {
 	ID        string    `json:"id" db:"id"`
 	Email     string    `json:"email" db:"email"`
 	Password  string    `json:"-" db:"password"`
+	Roles     []string  `json:"roles,omitempty"`
+	Suspended bool      `json:"-" db:"suspended"`
 	CreatedAt time.Time `json:"-" db:"created_at"`
 	UpdatedAt time.Time `json:"-" db:"updated_at"`
 }
@@ -1,8 +1,27 @@
 package domain
 
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// UserProfileRequest struct, the fields of the profile to change. The photo
+// is changed with the upload endpoint.
 type UserProfileRequest struct {
-	UserID    *int    `json:"-" db:"user_id"`
-	UserName  *string `json:"username,omitempty" db:"username"`
-	UserPhoto *string `json:"photo,omitempty" db:"photo"`
-	Gender    *string `json:"gender,omitempty" db:"gender"`
+	UserID   *int    `json:"-" db:"user_id"`
+	UserName *string `json:"username,omitempty" db:"username" validate:"omitempty,gte=6,lte=25,excludesall= "`
+	Gender   *string `json:"gender,omitempty" db:"gender" validate:"omitempty,oneof=female male non_binary undisclosed"`
+}
+
+func (u *UserProfileRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
 }
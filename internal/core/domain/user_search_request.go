@@ -0,0 +1,8 @@
+package domain
+
+// UserSearchRequest struct
+type UserSearchRequest struct {
+	Query  string `json:"q"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// UserSession struct, a signed in device. The refresh token is never stored, only its hash.
+type UserSession struct {
+	ID           int        `json:"id" db:"id"`
+	UserID       int        `json:"-" db:"user_id"`
+	TokenHash    string     `json:"-" db:"token_hash"`
+	PreviousHash string     `json:"-" db:"previous_hash"`
+	Device       string     `json:"device" db:"-"`
+	UserAgent    string     `json:"user_agent" db:"user_agent"`
+	IP           string     `json:"ip" db:"ip"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt    *time.Time `json:"-" db:"revoked_at"`
+	Current      bool       `json:"current" db:"-"`
+}
+
+// Expired reports if the session can't be refreshed anymore at t
+func (s *UserSession) Expired(t time.Time) bool {
+	return !s.ExpiresAt.After(t)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventPing the event of the test deliveries
+const WebhookEventPing = "ping"
+
+// WebhookEvents the events a webhook can subscribe to
+var WebhookEvents = []string{EventTradeExecuted, EventListingCreated, EventListingDelisted, EventListingSoldOut, EventAlertTriggered}
+
+// WebhookDeliverSubject NATS subject of the deliveries waiting to be sent
+const WebhookDeliverSubject = "webhooks.deliver"
+
+// Webhook delivery status
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook struct, an URL of the user notified of the events it subscribes to.
+// The secret signs the deliveries.
+type Webhook struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	URL       string    `json:"url" db:"url"`
+	Events    []string  `json:"events" db:"-"`
+	Secret    string    `json:"-" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Subscribed reports if the webhook is notified of the event
+func (w *Webhook) Subscribed(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookCreated struct, the only response that carries the secret
+type WebhookCreated struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookDelivery struct, a notification of an event to a webhook and the
+// result of its last attempt
+type WebhookDelivery struct {
+	ID            int             `json:"id" db:"id"`
+	WebhookID     int             `json:"webhook_id" db:"webhook_id"`
+	EventID       string          `json:"event_id" db:"event_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	StatusCode    *int            `json:"status_code,omitempty" db:"status_code"`
+	Error         string          `json:"error,omitempty" db:"error"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	LastAttemptAt *time.Time      `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// WebhookPayload the body of a delivery
+type WebhookPayload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WebhookJob a delivery waiting to be sent
+type WebhookJob struct {
+	DeliveryID int `json:"delivery_id"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+// WebhookRequest struct
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,max=2048,http_url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=trade.executed listing.created listing.delisted listing.sold_out alert.triggered"`
+}
+
+func (u *WebhookRequest) Validate(v *validator.Validate) error {
+	err := v.Struct(u)
+	if err != nil {
+		errormsg := ""
+		for _, err := range err.(validator.ValidationErrors) {
+			errormsg = fmt.Sprintf("Field: %s, Error: %s", err.Field(), err.Tag())
+		}
+
+		return fmt.Errorf(errormsg)
+	}
+	return nil
+}
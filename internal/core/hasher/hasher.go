@@ -0,0 +1,51 @@
+package hasher
+
+import (
+	"go.uber.org/fx"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"strings"
+)
+
+var _ svcport.PasswordHasher = (*Hasher)(nil)
+
+// Hasher creates argon2id hashes and verifies both argon2id and legacy hashes
+type Hasher struct {
+	argon2id *Argon2idHasher
+	legacy   *LegacyHasher
+}
+
+// NewHasher creates a new password hasher
+func NewHasher(params Argon2idParams) *Hasher {
+	return &Hasher{
+		argon2id: NewArgon2idHasher(params),
+		legacy:   &LegacyHasher{},
+	}
+}
+
+// Hash always produces an argon2id hash
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.argon2id.Hash(password)
+}
+
+// Verify checks the password with the algorithm of the encoded hash. Legacy
+// hashes always need a rehash.
+func (h *Hasher) Verify(password, encoded string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, argon2idPrefix):
+		return h.argon2id.Verify(password, encoded)
+	case isLegacyHash(encoded):
+		match, err := h.legacy.Verify(password, encoded)
+		return match, match, err
+	default:
+		return false, false, appErr.ErrUnknownHashFormat
+	}
+}
+
+// Module hasher
+var Module = fx.Module("hasher",
+	fx.Provide(func(cfg *domain.Configuration) *Hasher {
+		return NewHasher(NewArgon2idParams(cfg.PasswordHash))
+	}),
+)
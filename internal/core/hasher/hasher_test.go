@@ -0,0 +1,101 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+)
+
+var testParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func legacyHash(t *testing.T, password string) string {
+	sum := sha3.Sum256([]byte(password))
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(sum[:])), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func TestHash(t *testing.T) {
+	h := NewHasher(testParams)
+
+	t.Run("PHC format", func(t *testing.T) {
+		encoded, err := h.Hash("123456")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.Len(t, strings.Split(encoded, "$"), 6)
+	})
+
+	t.Run("Random salt", func(t *testing.T) {
+		a, _ := h.Hash("123456")
+		b, _ := h.Hash("123456")
+		assert.NotEqual(t, a, b)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	h := NewHasher(testParams)
+
+	t.Run("Argon2id OK", func(t *testing.T) {
+		encoded, _ := h.Hash("123456")
+		match, rehash, err := h.Verify("123456", encoded)
+		assert.NoError(t, err)
+		assert.True(t, match)
+		assert.False(t, rehash)
+	})
+
+	t.Run("Argon2id Wrong password", func(t *testing.T) {
+		encoded, _ := h.Hash("123456")
+		match, rehash, err := h.Verify("654321", encoded)
+		assert.NoError(t, err)
+		assert.False(t, match)
+		assert.False(t, rehash)
+	})
+
+	t.Run("Argon2id Outdated params", func(t *testing.T) {
+		old := NewHasher(Argon2idParams{Memory: 512, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+		encoded, _ := old.Hash("123456")
+		match, rehash, err := h.Verify("123456", encoded)
+		assert.NoError(t, err)
+		assert.True(t, match)
+		assert.True(t, rehash)
+	})
+
+	t.Run("Legacy OK", func(t *testing.T) {
+		match, rehash, err := h.Verify("123456", legacyHash(t, "123456"))
+		assert.NoError(t, err)
+		assert.True(t, match)
+		assert.True(t, rehash)
+	})
+
+	t.Run("Legacy Wrong password", func(t *testing.T) {
+		match, rehash, err := h.Verify("654321", legacyHash(t, "123456"))
+		assert.NoError(t, err)
+		assert.False(t, match)
+		assert.False(t, rehash)
+	})
+
+	t.Run("Invalid hash", func(t *testing.T) {
+		_, _, err := h.Verify("123456", "$argon2id$v=19$m=1024,t=1,p=1$!!!$!!!")
+		assert.ErrorIs(t, err, appErr.ErrInvalidHash)
+	})
+
+	t.Run("Incompatible version", func(t *testing.T) {
+		_, _, err := h.Verify("123456", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA")
+		assert.ErrorIs(t, err, appErr.ErrIncompatibleVersion)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, _, err := h.Verify("123456", "plain-text")
+		assert.ErrorIs(t, err, appErr.ErrUnknownHashFormat)
+	})
+}
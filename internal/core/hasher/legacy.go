@@ -0,0 +1,32 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
+	appErr "kiramishima/m-backend/pkg/errors"
+)
+
+// LegacyHasher verifies the old sha3-256 + bcrypt hashes. It is only used to
+// validate existing passwords, which are rehashed on successful login.
+type LegacyHasher struct{}
+
+// Verify compares bcrypt(hex(sha3-256(password))) with the stored hash
+func (h *LegacyHasher) Verify(password, encoded string) (bool, error) {
+	sum := sha3.Sum256([]byte(password))
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(hex.EncodeToString(sum[:])))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, appErr.ErrInvalidHash
+	}
+	return true, nil
+}
+
+// isLegacyHash reports whether the encoded hash is a bcrypt hash
+func isLegacyHash(encoded string) bool {
+	_, err := bcrypt.Cost([]byte(encoded))
+	return err == nil
+}
@@ -0,0 +1,135 @@
+// Package imaging decodes the uploaded photos and makes their thumbnails. The
+// content type is sniffed from the bytes, never taken from the upload. Since
+// the thumbnails are encoded from the decoded pixels they carry no metadata,
+// which strips the EXIF data (GPS position, camera...) of the original.
+package imaging
+
+import (
+	"bytes"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"sort"
+)
+
+// Allowed content types
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	WebP = "image/webp"
+)
+
+// maxPixels bounds the decoded size, so a small file can't expand into gigabytes
+const maxPixels = 50 * 1000 * 1000
+
+// Image a decoded upload
+type Image struct {
+	ContentType string
+	img         image.Image
+	orientation int
+}
+
+// Sniff returns the content type of the bytes, NotAllowedImageHeader when it
+// is not JPEG, PNG or WebP
+func Sniff(data []byte) (string, error) {
+	switch contentType := http.DetectContentType(data); contentType {
+	case JPEG, PNG, WebP:
+		return contentType, nil
+	default:
+		return "", appErr.NotAllowedImageHeader
+	}
+}
+
+// Decode sniffs and decodes the image
+func Decode(data []byte) (*Image, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var decodeConfig func(io.Reader) (image.Config, error)
+	var decode func(io.Reader) (image.Image, error)
+	switch contentType {
+	case JPEG:
+		decodeConfig, decode = jpeg.DecodeConfig, jpeg.Decode
+	case PNG:
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	default:
+		decodeConfig, decode = webp.DecodeConfig, webp.Decode
+	}
+
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, appErr.ErrInvalidImage
+	}
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, appErr.ErrInvalidImage
+	}
+
+	orientation := 1
+	if contentType == JPEG {
+		orientation = jpegOrientation(data)
+	}
+
+	return &Image{ContentType: contentType, img: img, orientation: orientation}, nil
+}
+
+// OutputType the content type of the thumbnails: JPEG for photos, PNG for the
+// formats that may be transparent
+func (i *Image) OutputType() string {
+	if i.ContentType == JPEG {
+		return JPEG
+	}
+	return PNG
+}
+
+// Thumbnails crops the center square of the image and scales it to each size,
+// upright. The result is in the order of the sizes.
+func (i *Image) Thumbnails(sizes ...int) []image.Image {
+	// Each thumbnail is scaled from the previous bigger one
+	order := make([]int, len(sizes))
+	for n := range order {
+		order[n] = n
+	}
+	sort.Slice(order, func(a, b int) bool { return sizes[order[a]] > sizes[order[b]] })
+
+	var thumbs = make([]image.Image, len(sizes))
+	var src = i.img
+	var rect = centerSquare(i.img.Bounds())
+	for _, n := range order {
+		dst := image.NewNRGBA(image.Rect(0, 0, sizes[n], sizes[n]))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, rect, draw.Src, nil)
+		src, rect = dst, dst.Bounds()
+		thumbs[n] = dst
+	}
+	// The crop is a square around the center, so orienting after scaling is the same
+	for n := range thumbs {
+		thumbs[n] = orient(thumbs[n].(*image.NRGBA), i.orientation)
+	}
+
+	return thumbs
+}
+
+// Encode writes the image in the content type returned by OutputType
+func Encode(w io.Writer, img image.Image, contentType string) error {
+	if contentType == JPEG {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+	return png.Encode(w, img)
+}
+
+func centerSquare(b image.Rectangle) image.Rectangle {
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
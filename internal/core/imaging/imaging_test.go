@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	appErr "kiramishima/m-backend/pkg/errors"
+	"os"
+	"testing"
+)
+
+// halves a w×h image, red on the left half and blue on the right one
+func halves(w int, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the orientation after the SOI marker
+func withOrientation(jpg []byte, orientation byte) []byte {
+	tiff := []byte{
+		'I', 'I', 42, 0, 8, 0, 0, 0,
+		1, 0,
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, orientation, 0, 0, 0,
+		0, 0, 0, 0,
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	var pngBuf, gifBuf bytes.Buffer
+	assert.NoError(t, png.Encode(&pngBuf, halves(4, 4)))
+	assert.NoError(t, gif.Encode(&gifBuf, halves(4, 4), nil))
+	webpData, err := os.ReadFile("testdata/sample.webp")
+	assert.NoError(t, err)
+
+	testCases := map[string]struct {
+		data        []byte
+		contentType string
+		err         error
+	}{
+		"JPEG":   {data: encodeJPEG(t, halves(4, 4)), contentType: JPEG},
+		"PNG":    {data: pngBuf.Bytes(), contentType: PNG},
+		"WebP":   {data: webpData, contentType: WebP},
+		"GIF":    {data: gifBuf.Bytes(), err: appErr.NotAllowedImageHeader},
+		"HTML":   {data: []byte("<html><script>alert(1)</script></html>"), err: appErr.NotAllowedImageHeader},
+		"SVG":    {data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), err: appErr.NotAllowedImageHeader},
+		"Binary": {data: []byte{0x00, 0x01, 0x02}, err: appErr.NotAllowedImageHeader},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			contentType, err := Sniff(tc.data)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.contentType, contentType)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("WebP", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/sample.webp")
+		assert.NoError(t, err)
+
+		img, err := Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, WebP, img.ContentType)
+		assert.Equal(t, PNG, img.OutputType())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		data := encodeJPEG(t, halves(64, 64))
+
+		_, err := Decode(data[:len(data)/2])
+		assert.ErrorIs(t, err, appErr.ErrInvalidImage)
+	})
+
+	t.Run("Too Many Pixels", func(t *testing.T) {
+		// A PNG header claiming 100000×100000 pixels
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+		data := buf.Bytes()
+		copy(data[16:24], []byte{0, 1, 0x86, 0xA0, 0, 1, 0x86, 0xA0})
+
+		_, err := Decode(data)
+		assert.ErrorIs(t, err, appErr.ErrInvalidImage)
+	})
+}
+
+func TestThumbnails(t *testing.T) {
+	t.Run("Sizes", func(t *testing.T) {
+		img, err := Decode(encodeJPEG(t, halves(120, 60)))
+		assert.NoError(t, err)
+
+		thumbs := img.Thumbnails(16, 48, 32)
+		assert.Len(t, thumbs, 3)
+		assert.Equal(t, image.Rect(0, 0, 16, 16), thumbs[0].Bounds())
+		assert.Equal(t, image.Rect(0, 0, 48, 48), thumbs[1].Bounds())
+		assert.Equal(t, image.Rect(0, 0, 32, 32), thumbs[2].Bounds())
+	})
+
+	t.Run("EXIF Orientation", func(t *testing.T) {
+		// Rotated 90° clockwise, the red half ends on top
+		img, err := Decode(withOrientation(encodeJPEG(t, halves(64, 32)), 6))
+		assert.NoError(t, err)
+
+		thumb := img.Thumbnails(16)[0]
+		r, _, b, _ := thumb.At(8, 2).RGBA()
+		assert.Greater(t, r, b)
+		r, _, b, _ = thumb.At(8, 13).RGBA()
+		assert.Greater(t, b, r)
+	})
+
+	t.Run("Strips EXIF", func(t *testing.T) {
+		data := withOrientation(encodeJPEG(t, halves(32, 32)), 1)
+		assert.True(t, bytes.Contains(data, []byte("Exif")))
+
+		img, err := Decode(data)
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, Encode(&buf, img.Thumbnails(16)[0], img.OutputType()))
+		assert.False(t, bytes.Contains(buf.Bytes(), []byte("Exif")))
+		_, err = jpeg.Decode(&buf)
+		assert.NoError(t, err)
+	})
+}
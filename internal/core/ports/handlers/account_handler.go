@@ -0,0 +1,9 @@
+package handlers
+
+import "net/http"
+
+type AccountHandlers interface {
+	RequestExportHandler(w http.ResponseWriter, req *http.Request)
+	GetExportHandler(w http.ResponseWriter, req *http.Request)
+	DeleteAccountHandler(w http.ResponseWriter, req *http.Request)
+}
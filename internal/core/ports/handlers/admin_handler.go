@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+type AdminHandlers interface {
+	SearchUsersHandler(w http.ResponseWriter, req *http.Request)
+	SuspendUserHandler(w http.ResponseWriter, req *http.Request)
+	UnsuspendUserHandler(w http.ResponseWriter, req *http.Request)
+	GetUserBondsHandler(w http.ResponseWriter, req *http.Request)
+	GetUserTransactionsHandler(w http.ResponseWriter, req *http.Request)
+	SearchAuthEventsHandler(w http.ResponseWriter, req *http.Request)
+	DelistMarketBondHandler(w http.ResponseWriter, req *http.Request)
+	FreezeBondHandler(w http.ResponseWriter, req *http.Request)
+	UnfreezeBondHandler(w http.ResponseWriter, req *http.Request)
+	ListJobsHandler(w http.ResponseWriter, req *http.Request)
+	ListJobRunsHandler(w http.ResponseWriter, req *http.Request)
+	RunJobHandler(w http.ResponseWriter, req *http.Request)
+	PauseJobHandler(w http.ResponseWriter, req *http.Request)
+	ResumeJobHandler(w http.ResponseWriter, req *http.Request)
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import "net/http"
+
+type AlertHandlers interface {
+	ListWatchlistHandler(w http.ResponseWriter, req *http.Request)
+	AddToWatchlistHandler(w http.ResponseWriter, req *http.Request)
+	RemoveFromWatchlistHandler(w http.ResponseWriter, req *http.Request)
+	CreateAlertHandler(w http.ResponseWriter, req *http.Request)
+	ListAlertsHandler(w http.ResponseWriter, req *http.Request)
+	GetAlertHandler(w http.ResponseWriter, req *http.Request)
+	UpdateAlertHandler(w http.ResponseWriter, req *http.Request)
+	DeleteAlertHandler(w http.ResponseWriter, req *http.Request)
+}
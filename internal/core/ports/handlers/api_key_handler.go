@@ -0,0 +1,9 @@
+package handlers
+
+import "net/http"
+
+type APIKeyHandlers interface {
+	CreateAPIKeyHandler(w http.ResponseWriter, req *http.Request)
+	ListAPIKeysHandler(w http.ResponseWriter, req *http.Request)
+	RevokeAPIKeyHandler(w http.ResponseWriter, req *http.Request)
+}
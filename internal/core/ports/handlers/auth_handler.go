@@ -5,4 +5,8 @@ import "net/http"
 type AuthHandlers interface {
 	SignInHandler(w http.ResponseWriter, req *http.Request)
 	SignUpHandler(w http.ResponseWriter, req *http.Request)
+	TwoFactorHandler(w http.ResponseWriter, req *http.Request)
+	RefreshHandler(w http.ResponseWriter, req *http.Request)
+	UnlockHandler(w http.ResponseWriter, req *http.Request)
+	ChangePasswordHandler(w http.ResponseWriter, req *http.Request)
 }
@@ -0,0 +1,8 @@
+package handlers
+
+import "net/http"
+
+type AvatarHandlers interface {
+	UploadAvatarHandler(w http.ResponseWriter, req *http.Request)
+	GetAvatarHandler(w http.ResponseWriter, req *http.Request)
+}
@@ -0,0 +1,7 @@
+package handlers
+
+import "net/http"
+
+type FileHandlers interface {
+	GetFileHandler(w http.ResponseWriter, req *http.Request)
+}
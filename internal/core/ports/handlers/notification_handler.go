@@ -0,0 +1,11 @@
+package handlers
+
+import "net/http"
+
+type NotificationHandlers interface {
+	ListNotificationsHandler(w http.ResponseWriter, req *http.Request)
+	MarkReadHandler(w http.ResponseWriter, req *http.Request)
+	MarkAllReadHandler(w http.ResponseWriter, req *http.Request)
+	GetPreferencesHandler(w http.ResponseWriter, req *http.Request)
+	UpdatePreferencesHandler(w http.ResponseWriter, req *http.Request)
+}
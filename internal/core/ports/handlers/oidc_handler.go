@@ -0,0 +1,8 @@
+package handlers
+
+import "net/http"
+
+type OIDCHandlers interface {
+	LoginHandler(w http.ResponseWriter, req *http.Request)
+	CallbackHandler(w http.ResponseWriter, req *http.Request)
+}
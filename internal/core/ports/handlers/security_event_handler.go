@@ -0,0 +1,7 @@
+package handlers
+
+import "net/http"
+
+type SecurityEventHandlers interface {
+	ListSecurityEventsHandler(w http.ResponseWriter, req *http.Request)
+}
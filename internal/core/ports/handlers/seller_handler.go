@@ -0,0 +1,8 @@
+package handlers
+
+import "net/http"
+
+type SellerHandlers interface {
+	GetSellerProfileHandler(w http.ResponseWriter, req *http.Request)
+	RateSellerHandler(w http.ResponseWriter, req *http.Request)
+}
@@ -0,0 +1,10 @@
+package handlers
+
+import "net/http"
+
+type SessionHandlers interface {
+	ListSessionsHandler(w http.ResponseWriter, req *http.Request)
+	RevokeSessionHandler(w http.ResponseWriter, req *http.Request)
+	RevokeAllSessionsHandler(w http.ResponseWriter, req *http.Request)
+	SignOutHandler(w http.ResponseWriter, req *http.Request)
+}
@@ -0,0 +1,7 @@
+package handlers
+
+import "net/http"
+
+type TaskHandlers interface {
+	GetTaskHandler(w http.ResponseWriter, req *http.Request)
+}
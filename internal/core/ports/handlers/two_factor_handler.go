@@ -0,0 +1,10 @@
+package handlers
+
+import "net/http"
+
+type TwoFactorHandlers interface {
+	EnrollHandler(w http.ResponseWriter, req *http.Request)
+	ConfirmHandler(w http.ResponseWriter, req *http.Request)
+	RecoveryCodesHandler(w http.ResponseWriter, req *http.Request)
+	DisableHandler(w http.ResponseWriter, req *http.Request)
+}
@@ -6,4 +6,5 @@ type UserHandlers interface {
 	GetProfileHandler(w http.ResponseWriter, req *http.Request)
 	UpdateProfileHandler(w http.ResponseWriter, req *http.Request)
 	GetUserBondsHandler(w http.ResponseWriter, req *http.Request)
+	GetUserTransactionsHandler(w http.ResponseWriter, req *http.Request)
 }
@@ -0,0 +1,12 @@
+package handlers
+
+import "net/http"
+
+type WebhookHandlers interface {
+	CreateWebhookHandler(w http.ResponseWriter, req *http.Request)
+	ListWebhooksHandler(w http.ResponseWriter, req *http.Request)
+	DeleteWebhookHandler(w http.ResponseWriter, req *http.Request)
+	ListDeliveriesHandler(w http.ResponseWriter, req *http.Request)
+	RedeliverHandler(w http.ResponseWriter, req *http.Request)
+	PingWebhookHandler(w http.ResponseWriter, req *http.Request)
+}
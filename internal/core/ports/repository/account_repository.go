@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AccountRepository interface, personal data export and account deletion
+type AccountRepository interface {
+	GetAccount(ctx context.Context, uid int) (*domain.Account, error)
+	GetListings(ctx context.Context, uid int) ([]*domain.MarketBond, error)
+	CreateExport(ctx context.Context, export *domain.DataExport) error
+	UpdateExport(ctx context.Context, export *domain.DataExport) error
+	GetLatestExport(ctx context.Context, uid int) (*domain.DataExport, error)
+	ListExportKeys(ctx context.Context, uid int) ([]string, error)
+	Anonymize(ctx context.Context, uid int) error
+}
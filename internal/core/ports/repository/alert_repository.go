@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// AlertRepository interface
+type AlertRepository interface {
+	ListWatchlist(ctx context.Context, uid int) ([]*domain.WatchlistItem, error)
+	// AddToWatchlist keeps a bond already in the watchlist as it is
+	AddToWatchlist(ctx context.Context, uid int, bondID int) error
+	RemoveFromWatchlist(ctx context.Context, uid int, bondUUID string) error
+	// GetBondMarket returns the bond and the quantity of it on sale
+	GetBondMarket(ctx context.Context, bondID int) (*domain.BondMarket, error)
+	GetBondMarketByUUID(ctx context.Context, uuid string) (*domain.BondMarket, error)
+	Create(ctx context.Context, alert *domain.Alert) error
+	List(ctx context.Context, uid int) ([]*domain.Alert, error)
+	CountByUser(ctx context.Context, uid int) (int, error)
+	GetByID(ctx context.Context, uid int, id int) (*domain.Alert, error)
+	Update(ctx context.Context, alert *domain.Alert) error
+	Delete(ctx context.Context, uid int, id int) error
+	ListActiveByBond(ctx context.Context, bondID int) ([]*domain.Alert, error)
+	// MarkTriggered sets the trigger date of the alert unless it was
+	// triggered after quietSince, it reports whether it was set
+	MarkTriggered(ctx context.Context, id int, at time.Time, quietSince time.Time) (bool, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// APIKeyRepository interface
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	ListByUser(ctx context.Context, uid int) ([]*domain.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	Revoke(ctx context.Context, uid int, id int) error
+	TrackUsage(ctx context.Context, id int, ip string) error
+}
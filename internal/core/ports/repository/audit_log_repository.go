@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AuditLogRepository interface
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AuthEventRepository interface
+type AuthEventRepository interface {
+	Create(ctx context.Context, event *domain.AuthEvent) error
+	// Search lists the events matching the filters, newest first
+	Search(ctx context.Context, search *domain.AuthEventSearch) ([]*domain.AuthEvent, error)
+}
@@ -7,5 +7,7 @@ import (
 
 type AuthRepository interface {
 	FindByCredentials(ctx context.Context, data *domain.AuthRequest) (*domain.User, error)
+	FindByID(ctx context.Context, uid int) (*domain.User, error)
 	Register(ctx context.Context, registerReq *domain.RegisterRequest) error
+	UpdatePassword(ctx context.Context, uid string, hash string) error
 }
@@ -11,4 +11,5 @@ type BondRepository interface {
 	CreateBond(ctx context.Context, data *domain.BondRequest) error
 	UpdateBond(ctx context.Context, udata *domain.Bond) error
 	DeleteBond(ctx context.Context, bond_id int) error
+	SetFrozen(ctx context.Context, bond_id int, frozen bool) error
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// IdentityRepository interface, the accounts of identity providers linked to users
+type IdentityRepository interface {
+	FindUserID(ctx context.Context, issuer string, subject string) (int, error)
+	Link(ctx context.Context, identity *domain.UserIdentity) error
+}
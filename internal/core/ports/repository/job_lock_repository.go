@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// JobLockRepository interface, leases shared by the replicas so a job runs
+// on one of them at a time. The token identifies the holder.
+type JobLockRepository interface {
+	// Acquire takes the lock for ttl, it reports false when another holder has it
+	Acquire(ctx context.Context, job string, token string, ttl time.Duration) (bool, error)
+	// Renew extends the lease, it reports false when the lock was lost
+	Renew(ctx context.Context, job string, token string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, job string, token string) error
+	// ClaimTick takes the scheduled run of the job at tick for ttl, it reports
+	// false when a replica took it first. The claim isn't released, it expires.
+	ClaimTick(ctx context.Context, job string, tick time.Time, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// JobRepository interface, the run history and the paused jobs
+type JobRepository interface {
+	CreateRun(ctx context.Context, run *domain.JobRun) error
+	FinishRun(ctx context.Context, run *domain.JobRun) error
+	ListRuns(ctx context.Context, job string, limit int) ([]*domain.JobRun, error)
+	// ListLatestRuns returns the last run of every job
+	ListLatestRuns(ctx context.Context) ([]*domain.JobRun, error)
+	// FailStaleRuns fails the runs still running that started before the
+	// date, their replica stopped without finishing them
+	FailStaleRuns(ctx context.Context, before time.Time) (int64, error)
+	PurgeRuns(ctx context.Context, before time.Time) (int64, error)
+	ListPaused(ctx context.Context) ([]string, error)
+	IsPaused(ctx context.Context, job string) (bool, error)
+	SetPaused(ctx context.Context, job string, paused bool) error
+}
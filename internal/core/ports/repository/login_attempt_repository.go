@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// LoginAttemptRepository interface, a subject is an account or an IP
+type LoginAttemptRepository interface {
+	Status(ctx context.Context, subject string) (*domain.LoginAttemptStatus, error)
+	RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error)
+	Delay(ctx context.Context, subject string, d time.Duration) error
+	// Lock returns false when the subject was already locked
+	Lock(ctx context.Context, subject string, d time.Duration) (bool, error)
+	Reset(ctx context.Context, subject string) error
+	SaveUnlockToken(ctx context.Context, tokenHash string, subject string, ttl time.Duration) error
+	// ConsumeUnlockToken returns the subject of the token and deletes it
+	ConsumeUnlockToken(ctx context.Context, tokenHash string) (string, error)
+}
@@ -9,6 +9,10 @@ import (
 type MarketBondRepository interface {
 	ListMarketBonds(ctx context.Context, uid int) ([]*domain.MarketBond, error)
 	GetMarketBondByID(ctx context.Context, market_bond_id int) (*domain.MarketBond, error)
-	BuyMarketBond(ctx context.Context, order *domain.MarketBondRequest) error
+	// BuyMarketBond returns the bonds left on sale after the order
+	BuyMarketBond(ctx context.Context, order *domain.MarketBondRequest) (int, error)
 	SellMarketBond(ctx context.Context, data *domain.MarketSellRequest) error
+	DelistMarketBond(ctx context.Context, market_bond_id int) error
+	// FindMarketBond returns the bond and the seller of a market bond in any status
+	FindMarketBond(ctx context.Context, market_bond_id int) (*domain.MarketBond, error)
 }
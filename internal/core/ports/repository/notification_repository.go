@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// NotificationRepository interface
+type NotificationRepository interface {
+	// Create stores the notification, it reports false when the user already
+	// has the notification of the event
+	Create(ctx context.Context, notification *domain.Notification) (bool, error)
+	List(ctx context.Context, search *domain.NotificationSearch) ([]*domain.Notification, error)
+	CountUnread(ctx context.Context, uid int) (int, error)
+	MarkRead(ctx context.Context, uid int, id int) error
+	MarkAllRead(ctx context.Context, uid int) error
+	// GetPreferences returns the defaults when the user never saved them
+	GetPreferences(ctx context.Context, uid int) (*domain.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, uid int, preferences *domain.NotificationPreferences) error
+	// ListRecipients returns the email and the preferences of the active users
+	ListRecipients(ctx context.Context, uids []int) ([]*domain.NotificationRecipient, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// OIDCStateRepository interface, keeps the PKCE verifier and the nonce between the login and the callback
+type OIDCStateRepository interface {
+	Save(ctx context.Context, state string, data *domain.OIDCState, ttl time.Duration) error
+	// Consume returns the data of the state and deletes it, a state is only valid once
+	Consume(ctx context.Context, state string) (*domain.OIDCState, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// RateLimitRepository interface, counts the requests of a key in fixed windows
+type RateLimitRepository interface {
+	// Hit counts a request of the key in the current window
+	Hit(ctx context.Context, key string, window time.Duration) (*domain.RateLimitHit, error)
+}
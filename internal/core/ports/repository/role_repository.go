@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+)
+
+// RoleRepository interface
+type RoleRepository interface {
+	GetUserRoles(ctx context.Context, uid int) ([]string, error)
+	GetRolesPermissions(ctx context.Context, roles []string) ([]string, error)
+	AssignRole(ctx context.Context, uid int, role string) error
+	CountUsersWithRole(ctx context.Context, role string) (int, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// SellerRepository interface
+type SellerRepository interface {
+	GetSellerProfile(ctx context.Context, username string) (*domain.SellerProfile, error)
+	CreateRating(ctx context.Context, rating *domain.SellerRating) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// SessionRepository interface, the refresh token store
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.UserSession) error
+	// FindByToken returns the active session whose current or previous token has the hash
+	FindByToken(ctx context.Context, tokenHash string) (*domain.UserSession, error)
+	// Rotate replaces the token of the session, it fails if oldHash is not the current token anymore
+	Rotate(ctx context.Context, id int, oldHash string, newHash string, ip string, userAgent string, expiresAt time.Time) error
+	ListActive(ctx context.Context, uid int) ([]*domain.UserSession, error)
+	Revoke(ctx context.Context, uid int, id int) error
+	RevokeAll(ctx context.Context, uid int) (int64, error)
+	// RevokeOthers revokes every session of the user but keep
+	RevokeOthers(ctx context.Context, uid int, keep int) (int64, error)
+	// IsActive reports whether the session of the user isn't revoked and the
+	// user isn't suspended or deleted
+	IsActive(ctx context.Context, uid int, id int) (bool, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// TaskRepository interface, the storage of the task queue. Claim hands a due
+// task to one worker and hides it from the others until its visibility timeout
+// passes, then it can be claimed again.
+type TaskRepository interface {
+	Create(ctx context.Context, task *domain.Task) error
+	Get(ctx context.Context, id string) (*domain.Task, error)
+	Claim(ctx context.Context, now time.Time, visibility time.Duration) (*domain.Task, error)
+	Finish(ctx context.Context, task *domain.Task) error
+	Retry(ctx context.Context, task *domain.Task) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
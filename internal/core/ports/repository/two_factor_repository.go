@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// TwoFactorRepository interface
+type TwoFactorRepository interface {
+	GetTOTP(ctx context.Context, uid int) (*domain.UserTOTP, error)
+	SaveTOTP(ctx context.Context, uid int, secret string) error
+	EnableTOTP(ctx context.Context, uid int, step int64, recoveryCodes []string) error
+	UseTOTPStep(ctx context.Context, uid int, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, uid int, recoveryCodes []string) error
+	UseRecoveryCode(ctx context.Context, uid int, recoveryCode string) error
+	DeleteTOTP(ctx context.Context, uid int) error
+}
@@ -8,5 +8,9 @@ import (
 type UserRepository interface {
 	GetProfile(ctx context.Context, uid int) (*domain.UserProfile, error)
 	UpdateProfile(ctx context.Context, data *domain.UserProfile) (*domain.UserProfile, error)
+	UpdatePhoto(ctx context.Context, uid int, photo string) error
 	GetBonds(ctx context.Context, uid int) ([]*domain.Bond, error)
+	SearchUsers(ctx context.Context, search *domain.UserSearchRequest) ([]*domain.AdminUser, error)
+	SetSuspended(ctx context.Context, uid int, suspended bool, reason string) error
+	GetTransactions(ctx context.Context, uid int) ([]*domain.Transaction, error)
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// WebhookRepository interface
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	ListByUser(ctx context.Context, uid int) ([]*domain.Webhook, error)
+	GetByID(ctx context.Context, uid int, id int) (*domain.Webhook, error)
+	Delete(ctx context.Context, uid int, id int) error
+	// FindByID returns the webhook whatever its user
+	FindByID(ctx context.Context, id int) (*domain.Webhook, error)
+	// ListByUsers returns the webhooks of the users with the webhook channel on
+	ListByUsers(ctx context.Context, uids []int) ([]*domain.Webhook, error)
+	// CreateDelivery stores the delivery, an event already delivered to the
+	// webhook keeps its delivery and gets its id
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int) (*domain.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID int, limit int) ([]*domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
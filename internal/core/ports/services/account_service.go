@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AccountService interface
+type AccountService interface {
+	RequestExport(c context.Context, uid int) (*domain.DataExport, error)
+	GetExport(c context.Context, uid int) (*domain.DataExport, error)
+	DeleteAccount(c context.Context, uid int, data *domain.DeleteAccountRequest) error
+}
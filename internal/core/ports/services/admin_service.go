@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AdminService interface
+type AdminService interface {
+	SearchUsers(c context.Context, actor *domain.Actor, search *domain.UserSearchRequest) ([]*domain.AdminUser, error)
+	SuspendUser(c context.Context, actor *domain.Actor, uid int, reason string) error
+	UnsuspendUser(c context.Context, actor *domain.Actor, uid int) error
+	GetUserBonds(c context.Context, actor *domain.Actor, uid int) ([]*domain.Bond, error)
+	GetUserTransactions(c context.Context, actor *domain.Actor, uid int) ([]*domain.Transaction, error)
+	DelistMarketBond(c context.Context, actor *domain.Actor, market_bond_id int, reason string) error
+	FreezeBond(c context.Context, actor *domain.Actor, bond_id int, reason string) error
+	UnfreezeBond(c context.Context, actor *domain.Actor, bond_id int) error
+	SearchAuthEvents(c context.Context, actor *domain.Actor, search *domain.AuthEventSearch) ([]*domain.AuthEvent, error)
+	ListJobs(c context.Context) ([]*domain.ScheduledJob, error)
+	ListJobRuns(c context.Context, name string) ([]*domain.JobRun, error)
+	RunJob(c context.Context, actor *domain.Actor, name string) error
+	PauseJob(c context.Context, actor *domain.Actor, name string) error
+	ResumeJob(c context.Context, actor *domain.Actor, name string) error
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AlertService interface
+type AlertService interface {
+	ListWatchlist(ctx context.Context, uid int) ([]*domain.WatchlistItem, error)
+	AddToWatchlist(ctx context.Context, uid int, data *domain.WatchlistRequest) error
+	RemoveFromWatchlist(ctx context.Context, uid int, bondUUID string) error
+	Create(ctx context.Context, uid int, data *domain.AlertRequest) (*domain.Alert, error)
+	List(ctx context.Context, uid int) ([]*domain.Alert, error)
+	Get(ctx context.Context, uid int, id int) (*domain.Alert, error)
+	Update(ctx context.Context, uid int, id int, data *domain.AlertRequest) (*domain.Alert, error)
+	Delete(ctx context.Context, uid int, id int) error
+	// HandleEvent evaluates the alerts of the bond of a market event
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
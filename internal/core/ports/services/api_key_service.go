@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// APIKeyService interface
+type APIKeyService interface {
+	Create(ctx context.Context, uid int, data *domain.APIKeyRequest) (*domain.APIKeyCreated, error)
+	List(ctx context.Context, uid int) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, uid int, id int) error
+	// Authenticate checks the key, its allowlist and its scope and counts the request
+	Authenticate(ctx context.Context, key string, ip string, scope string) (*domain.APIKey, error)
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// AuthEventService interface, the security history of the accounts
+type AuthEventService interface {
+	// Record stores the event, a failure is only logged so it never blocks the request
+	Record(ctx context.Context, event *domain.AuthEvent)
+	ListByUser(ctx context.Context, uid int, limit int, offset int) ([]*domain.AuthEvent, error)
+}
@@ -7,5 +7,10 @@ import (
 
 type AuthService interface {
 	FindByCredentials(ctx context.Context, data *domain.AuthRequest) (*domain.AuthResponse, error)
+	CompleteTwoFactor(ctx context.Context, data *domain.TwoFactorLoginRequest) (*domain.AuthResponse, error)
+	Refresh(ctx context.Context, data *domain.RefreshRequest) (*domain.AuthResponse, error)
+	Unlock(ctx context.Context, token string, ip string) error
 	Register(ctx context.Context, registerReq *domain.RegisterRequest) error
+	ChangePassword(ctx context.Context, uid int, data *domain.ChangePasswordRequest) error
+	CreateAdmin(ctx context.Context, registerReq *domain.RegisterRequest) error
 }
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+type AvatarService interface {
+	Upload(ctx context.Context, uid int, file io.Reader) (*domain.Avatar, error)
+	Get(ctx context.Context, uid int) (*domain.Avatar, error)
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// Cache interface, keeps JSON encoded values under string keys. Get returns
+// errors.ErrCacheMiss for a missing or expired key. A value can be tagged on
+// Set, InvalidateTags deletes every key of the tags.
+type Cache interface {
+	Get(ctx context.Context, key string, value interface{}) error
+	// MGet returns the JSON of the keys that were found
+	MGet(ctx context.Context, keys ...string) (map[string][]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
@@ -0,0 +1,6 @@
+package services
+
+// EventPublisher interface, publishes the JSON of event on topic
+type EventPublisher interface {
+	PublishEvent(topic string, event any) error
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// IdentityProvider interface, an OpenID Connect provider using the authorization code flow with PKCE
+type IdentityProvider interface {
+	// AuthCodeURL returns the URL of the provider's login page
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// Exchange redeems the code and returns the claims of the verified ID token
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OIDCIdentity, error)
+}
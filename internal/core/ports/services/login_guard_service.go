@@ -0,0 +1,12 @@
+package services
+
+import "context"
+
+// LoginGuardService interface, brute-force protection of the sign in
+type LoginGuardService interface {
+	// Check returns a *errors.RetryAfterError when the account or the IP can't sign in yet
+	Check(ctx context.Context, email string, ip string) error
+	Fail(ctx context.Context, email string, ip string)
+	Succeed(ctx context.Context, email string)
+	Unlock(ctx context.Context, token string, ip string) error
+}
@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// Mailer interface
+type Mailer interface {
+	Send(ctx context.Context, email *domain.Email) error
+}
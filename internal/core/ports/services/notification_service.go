@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// NotificationService interface
+type NotificationService interface {
+	List(ctx context.Context, search *domain.NotificationSearch) (*domain.NotificationList, error)
+	MarkRead(ctx context.Context, uid int, id int) error
+	MarkAllRead(ctx context.Context, uid int) error
+	GetPreferences(ctx context.Context, uid int) (*domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, uid int, data *domain.NotificationPreferencesRequest) (*domain.NotificationPreferences, error)
+	// HandleEvent notifies the users of the event on their channels
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
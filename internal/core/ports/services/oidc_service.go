@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// OIDCService interface
+type OIDCService interface {
+	// Begin starts a login and returns the URL to redirect the user to and the
+	// state the browser has to bring back to the callback
+	Begin(ctx context.Context) (authURL string, state string, err error)
+	// Callback finishes the login with the state and the code sent by the provider
+	Callback(ctx context.Context, state string, code string, ip string, userAgent string) (*domain.AuthResponse, error)
+}
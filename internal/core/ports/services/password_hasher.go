@@ -0,0 +1,10 @@
+package services
+
+// PasswordHasher interface
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password
+	Hash(password string) (string, error)
+	// Verify checks the password against an encoded hash. rehash is true when
+	// the hash was produced by an outdated algorithm or cost parameters.
+	Verify(password, encoded string) (match bool, rehash bool, err error)
+}
@@ -0,0 +1,8 @@
+package services
+
+import "context"
+
+// RoleService interface
+type RoleService interface {
+	HasPermission(ctx context.Context, roles []string, permission string) (bool, error)
+}
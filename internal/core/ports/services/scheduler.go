@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// Scheduler interface, runs the registered jobs on their schedule
+type Scheduler interface {
+	Jobs(ctx context.Context) ([]*domain.ScheduledJob, error)
+	Runs(ctx context.Context, name string, limit int) ([]*domain.JobRun, error)
+	// Trigger starts a run of the job now, even when it is paused
+	Trigger(ctx context.Context, name string) error
+	Pause(ctx context.Context, name string) error
+	Resume(ctx context.Context, name string) error
+}
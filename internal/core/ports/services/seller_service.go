@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// SellerService interface
+type SellerService interface {
+	GetProfile(c context.Context, username string) (*domain.SellerProfile, error)
+	Rate(c context.Context, buyerID int, username string, data *domain.SellerRatingRequest) (*domain.SellerRating, error)
+}
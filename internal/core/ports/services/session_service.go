@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// SessionService interface, issues and rotates the refresh tokens
+type SessionService interface {
+	// Create starts a session and returns it with its refresh token
+	Create(ctx context.Context, uid int, ip string, userAgent string) (*domain.UserSession, string, error)
+	// Rotate exchanges a refresh token for a new one of the same session
+	Rotate(ctx context.Context, refreshToken string, ip string, userAgent string) (*domain.UserSession, string, error)
+	List(ctx context.Context, uid int, currentID int) ([]*domain.UserSession, error)
+	Revoke(ctx context.Context, uid int, id int) error
+	// RevokeAll logs the user out everywhere
+	RevokeAll(ctx context.Context, uid int) error
+	// RevokeOthers logs the user out of every session but keep, 0 keeps none
+	RevokeOthers(ctx context.Context, uid int, keep int) error
+	// Active reports whether the access tokens of the session are still valid
+	Active(ctx context.Context, uid int, id int) (bool, error)
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	"time"
+)
+
+// Storage interface, keeps the uploaded files under slash separated keys
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (*domain.StoredFile, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
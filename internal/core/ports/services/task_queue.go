@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// TaskQueue interface, queues work for the background workers
+type TaskQueue interface {
+	Enqueue(ctx context.Context, uid int, taskType string, payload any) (*domain.Task, error)
+	Get(ctx context.Context, id string) (*domain.Task, error)
+}
@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// TaskService interface
+type TaskService interface {
+	GetTask(c context.Context, uid int, id string) (*domain.Task, error)
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// TwoFactorService interface
+type TwoFactorService interface {
+	Enroll(ctx context.Context, uid int) (*domain.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, uid int, code string) (*domain.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, uid int, code string) (*domain.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, uid int, code string) error
+	IsEnabled(ctx context.Context, uid int) (bool, error)
+	Verify(ctx context.Context, uid int, code string) error
+}
@@ -9,4 +9,5 @@ type UserService interface {
 	GetProfile(c context.Context, uid int) (*domain.UserProfile, error)
 	UpdateProfile(c context.Context, data *domain.UserProfileRequest) (*domain.UserProfile, error)
 	GetBonds(c context.Context, uid int) ([]*domain.Bond, error)
+	GetTransactions(c context.Context, uid int) ([]*domain.Transaction, error)
 }
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"net/http"
+)
+
+// WebhookSender interface, posts a delivery. The error is for the requests
+// without response, any response returns its status code.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, header http.Header, body []byte) (int, error)
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"kiramishima/m-backend/internal/core/domain"
+)
+
+// WebhookService interface
+type WebhookService interface {
+	Create(ctx context.Context, uid int, data *domain.WebhookRequest) (*domain.WebhookCreated, error)
+	List(ctx context.Context, uid int) ([]*domain.Webhook, error)
+	Delete(ctx context.Context, uid int, id int) error
+	ListDeliveries(ctx context.Context, uid int, id int) ([]*domain.WebhookDelivery, error)
+	// Redeliver queues the delivery again with a new set of attempts
+	Redeliver(ctx context.Context, uid int, id int, deliveryID int) (*domain.WebhookDelivery, error)
+	// Ping sends a test delivery right away and returns its result
+	Ping(ctx context.Context, uid int, id int) (*domain.WebhookDelivery, error)
+	// HandleEvent queues a delivery for every webhook of the event
+	HandleEvent(ctx context.Context, event domain.Event) error
+	// Deliver sends a queued delivery, an error retries it
+	Deliver(ctx context.Context, job domain.WebhookJob) error
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/queue"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+const (
+	// exportPrefix the storage keys of the data exports start with it
+	exportPrefix = "exports/"
+	// exportTimeout time a worker has to build and store one archive
+	exportTimeout = time.Minute
+	// exportAbandonAfter an export still pending or running after it was lost
+	// with its task is replaced when the user asks again
+	exportAbandonAfter = 24 * time.Hour
+)
+
+var _ svcport.AccountService = (*AccountService)(nil)
+
+// AccountService struct, exports the personal data in the background through
+// the task queue and deletes accounts
+type AccountService struct {
+	logger         *zap.SugaredLogger
+	accounts       repport.AccountRepository
+	users          repport.UserRepository
+	auth           repport.AuthRepository
+	hasher         svcport.PasswordHasher
+	guard          svcport.LoginGuardService
+	events         svcport.AuthEventService
+	storage        svcport.Storage
+	tasks          svcport.TaskQueue
+	exportTTL      time.Duration
+	urlTTL         time.Duration
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewAccountService creates a new account service, RunExport handles its export tasks
+func NewAccountService(logger *zap.SugaredLogger, accounts repport.AccountRepository, users repport.UserRepository, auth repport.AuthRepository, hasher svcport.PasswordHasher, guard svcport.LoginGuardService, events svcport.AuthEventService, storage svcport.Storage, tasks svcport.TaskQueue, exportTTL time.Duration, urlTTL time.Duration, timeout time.Duration) *AccountService {
+	return &AccountService{
+		logger:         logger,
+		accounts:       accounts,
+		users:          users,
+		auth:           auth,
+		hasher:         hasher,
+		guard:          guard,
+		events:         events,
+		storage:        storage,
+		tasks:          tasks,
+		exportTTL:      exportTTL,
+		urlTTL:         urlTTL,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// RequestExport queues a new data export, or returns the one in progress
+func (svc *AccountService) RequestExport(c context.Context, uid int) (*domain.DataExport, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	now := svc.now().UTC().Truncate(time.Second)
+	latest, err := svc.accounts.GetLatestExport(ctx, uid)
+	if err == nil && latest.Active() {
+		if now.Sub(latest.CreatedAt) < exportAbandonAfter {
+			return latest, nil
+		}
+		svc.fail(latest)
+	}
+	if err != nil && !errors.Is(err, httpErrors.ErrExportNotFound) {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	export := &domain.DataExport{UserID: uid, Status: domain.ExportPending, CreatedAt: now}
+	if err := svc.accounts.CreateExport(ctx, export); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	task, err := svc.tasks.Enqueue(ctx, uid, domain.TaskAccountExport, domain.AccountExportPayload{ExportID: export.ID})
+	if err != nil {
+		svc.fail(export)
+		return nil, svc.handleError(ctx, err)
+	}
+	export.TaskID = task.ID
+
+	return export, nil
+}
+
+// GetExport returns the last data export, with a download link once it is ready
+func (svc *AccountService) GetExport(c context.Context, uid int) (*domain.DataExport, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	export, err := svc.accounts.GetLatestExport(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	now := svc.now()
+	if export.Expired(now) {
+		export.Status = domain.ExportExpired
+		return export, nil
+	}
+	if export.Status == domain.ExportReady {
+		ttl := svc.urlTTL
+		if left := export.ExpiresAt.Sub(now); left < ttl {
+			ttl = left
+		}
+		u, err := svc.storage.SignedURL(ctx, export.FileKey, ttl)
+		if err != nil {
+			return nil, svc.handleError(ctx, err)
+		}
+		export.DownloadURL = u
+	}
+
+	return export, nil
+}
+
+// DeleteAccount checks the password, anonymizes the user and removes the stored
+// files. The password failures count for the login guard like a sign in.
+func (svc *AccountService) DeleteAccount(c context.Context, uid int, data *domain.DeleteAccountRequest) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	user, err := svc.auth.FindByID(ctx, uid)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	event := &domain.AuthEvent{
+		UserID:    &uid,
+		Email:     user.Email,
+		IP:        data.IP,
+		UserAgent: data.UserAgent,
+		Event:     domain.AuthEventAccountDelete,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	if err := svc.guard.Check(ctx, user.Email, data.IP); err != nil {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, err.Error()
+		return err
+	}
+
+	match, _, err := svc.hasher.Verify(data.Password, user.Password)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+	if !match {
+		event.Reason = "bad password"
+		svc.guard.Fail(ctx, user.Email, data.IP)
+		return httpErrors.ErrBadPassword
+	}
+	svc.guard.Succeed(ctx, user.Email)
+
+	keys, err := svc.accounts.ListExportKeys(ctx, uid)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	profile, err := svc.users.GetProfile(ctx, uid)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	if isAvatar(profile.UserPhoto) {
+		keys = append(keys, avatarKeys(profile.UserPhoto)...)
+	}
+
+	if err := svc.accounts.Anonymize(ctx, uid); err != nil {
+		return svc.handleError(ctx, err)
+	}
+	svc.deleteKeys(keys)
+
+	// The anonymized account keeps no email, IP or device in its events
+	event.Email, event.IP, event.UserAgent = "", "", ""
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, ""
+	return nil
+}
+
+// RunExport handles the account.export tasks. An export that was replaced or
+// deleted with the account is skipped. A failure is retried by the queue, the
+// export is marked as failed on the last attempt.
+func (svc *AccountService) RunExport(ctx context.Context, task *domain.Task) (any, error) {
+	var payload domain.AccountExportPayload
+	if err := json.Unmarshal(task.Payload, &payload); err != nil {
+		return nil, queue.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	export, err := svc.accounts.GetLatestExport(ctx, task.UserID)
+	if errors.Is(err, httpErrors.ErrExportNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if export.ID != payload.ExportID || !export.Active() {
+		return nil, nil
+	}
+
+	if err := svc.export(ctx, export); err != nil {
+		if task.Attempts >= task.MaxAttempts {
+			svc.fail(export)
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// export builds the archive of one export and stores it, the previous archives are removed
+func (svc *AccountService) export(c context.Context, export *domain.DataExport) error {
+	ctx, cancel := context.WithTimeout(c, exportTimeout)
+	defer cancel()
+
+	export.Status = domain.ExportRunning
+	if err := svc.accounts.UpdateExport(ctx, export); err != nil {
+		return fmt.Errorf("failed to start the data export: %w", err)
+	}
+
+	data, err := svc.archive(ctx, export.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to build the data export: %w", err)
+	}
+
+	previous, err := svc.accounts.ListExportKeys(ctx, export.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list the data exports: %w", err)
+	}
+
+	id, err := randomURLString(12)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%d/%s.zip", exportPrefix, export.UserID, id)
+	if err := svc.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/zip"); err != nil {
+		return fmt.Errorf("failed to store the data export: %w", err)
+	}
+
+	completedAt := svc.now().UTC().Truncate(time.Second)
+	expiresAt := completedAt.Add(svc.exportTTL)
+	export.Status = domain.ExportReady
+	export.FileKey = key
+	export.Size = int64(len(data))
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+	if err := svc.accounts.UpdateExport(ctx, export); err != nil {
+		svc.deleteKeys([]string{key})
+		return fmt.Errorf("failed to save the data export: %w", err)
+	}
+	svc.deleteKeys(previous)
+
+	return nil
+}
+
+// archive builds a ZIP with one JSON file for each kind of data
+func (svc *AccountService) archive(ctx context.Context, uid int) ([]byte, error) {
+	account, err := svc.accounts.GetAccount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := svc.users.GetProfile(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	bonds, err := svc.users.GetBonds(ctx, uid)
+	if err != nil && !errors.Is(err, httpErrors.ErrNoRecords) {
+		return nil, err
+	}
+	if bonds == nil {
+		bonds = make([]*domain.Bond, 0)
+	}
+	listings, err := svc.accounts.GetListings(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := svc.users.GetTransactions(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	modified := svc.now()
+	for _, file := range []struct {
+		name string
+		data any
+	}{
+		{"account.json", account},
+		{"profile.json", profile},
+		{"bonds.json", bonds},
+		{"listings.json", listings},
+		{"transactions.json", transactions},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fail marks the export as failed, it runs even when the request or the worker is ending
+func (svc *AccountService) fail(export *domain.DataExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), svc.contextTimeOut)
+	defer cancel()
+
+	completedAt := svc.now().UTC().Truncate(time.Second)
+	export.Status = domain.ExportFailed
+	export.CompletedAt = &completedAt
+	if err := svc.accounts.UpdateExport(ctx, export); err != nil {
+		svc.logger.Errorw("failed to save the data export", "export", export.ID, "error", err)
+	}
+}
+
+// deleteKeys removes files that are no longer referenced, failures only leave orphans
+func (svc *AccountService) deleteKeys(keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), svc.contextTimeOut)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := svc.storage.Delete(ctx, key); err != nil {
+			svc.logger.Warnw("failed to delete the file", "key", key, "error", err)
+		}
+	}
+}
+
+// handleError maps repository and storage errors to service errors
+func (svc *AccountService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		for _, known := range []error{httpErrors.ErrUserNotFound, httpErrors.ErrExportNotFound} {
+			if errors.Is(err, known) {
+				return known
+			}
+		}
+		return httpErrors.InternalServerError
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccountService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	accounts := mock.NewMockAccountRepository(mockCtrl)
+	users := mock.NewMockUserRepository(mockCtrl)
+	auth := mock.NewMockAuthRepository(mockCtrl)
+	storage := mock.NewMockStorage(mockCtrl)
+	tasks := mock.NewMockTaskQueue(mockCtrl)
+	guard := mock.NewMockLoginGuardService(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewAccountService(slogger, accounts, users, auth, testHasher, guard, events, storage, tasks, 7*24*time.Hour, 15*time.Minute, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("Request Export", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 1, UserID: 1, Status: domain.ExportReady}, nil)
+		accounts.EXPECT().CreateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+			e.ID = 2
+			return nil
+		})
+		tasks.EXPECT().Enqueue(gomock.Any(), 1, domain.TaskAccountExport, domain.AccountExportPayload{ExportID: 2}).
+			Return(&domain.Task{ID: "task-1"}, nil)
+
+		export, err := uc.RequestExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, export.ID)
+		assert.Equal(t, domain.ExportPending, export.Status)
+		assert.Equal(t, "task-1", export.TaskID)
+	})
+
+	t.Run("Request Export In Progress", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, UserID: 1, Status: domain.ExportRunning, CreatedAt: now.Add(-time.Hour)}, nil)
+		accounts.EXPECT().CreateExport(gomock.Any(), gomock.Any()).Times(0)
+		tasks.EXPECT().Enqueue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		export, err := uc.RequestExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, export.ID)
+	})
+
+	t.Run("Request Export Abandoned", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, UserID: 1, Status: domain.ExportRunning, CreatedAt: now.Add(-exportAbandonAfter)}, nil)
+		gomock.InOrder(
+			accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+				assert.Equal(t, 2, e.ID)
+				assert.Equal(t, domain.ExportFailed, e.Status)
+				return nil
+			}),
+			accounts.EXPECT().CreateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+				e.ID = 3
+				return nil
+			}),
+		)
+		tasks.EXPECT().Enqueue(gomock.Any(), 1, domain.TaskAccountExport, domain.AccountExportPayload{ExportID: 3}).
+			Return(&domain.Task{ID: "task-2"}, nil)
+
+		export, err := uc.RequestExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, export.ID)
+	})
+
+	t.Run("Request Export Enqueue Failed", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(nil, httpErrors.ErrExportNotFound)
+		accounts.EXPECT().CreateExport(gomock.Any(), gomock.Any()).Return(nil)
+		tasks.EXPECT().Enqueue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+		accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+			assert.Equal(t, domain.ExportFailed, e.Status)
+			return nil
+		})
+
+		_, err := uc.RequestExport(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("Run Export Replaced", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 3, UserID: 1, Status: domain.ExportPending}, nil)
+		accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.RunExport(ctx, &domain.Task{UserID: 1, Payload: []byte(`{"export_id":2}`), Attempts: 1, MaxAttempts: 3})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Build Export", func(t *testing.T) {
+		var archive []byte
+		gomock.InOrder(
+			accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+				assert.Equal(t, domain.ExportRunning, e.Status)
+				return nil
+			}),
+			accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+				assert.Equal(t, domain.ExportReady, e.Status)
+				assert.True(t, strings.HasPrefix(e.FileKey, "exports/1/"))
+				assert.Equal(t, now.Add(7*24*time.Hour), *e.ExpiresAt)
+				return nil
+			}),
+		)
+		accounts.EXPECT().GetAccount(gomock.Any(), 1).Return(&domain.Account{ID: 1, Email: "gini@mail.com"}, nil)
+		users.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserName: "ginigini"}, nil)
+		users.EXPECT().GetBonds(gomock.Any(), 1).Return(nil, httpErrors.ErrNoRecords)
+		accounts.EXPECT().GetListings(gomock.Any(), 1).Return([]*domain.MarketBond{{ID: 3, Name: "Bond A"}}, nil)
+		users.EXPECT().GetTransactions(gomock.Any(), 1).Return([]*domain.Transaction{{ID: 9}}, nil)
+		accounts.EXPECT().ListExportKeys(gomock.Any(), 1).Return([]string{"exports/1/old.zip"}, nil)
+		storage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "application/zip").
+			DoAndReturn(func(_ context.Context, _ string, r io.Reader, _ int64, _ string) error {
+				archive, _ = io.ReadAll(r)
+				return nil
+			})
+		storage.EXPECT().Delete(gomock.Any(), "exports/1/old.zip").Return(nil)
+
+		err := uc.export(ctx, &domain.DataExport{ID: 2, UserID: 1, Status: domain.ExportPending})
+		assert.NoError(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		assert.NoError(t, err)
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+			if f.Name == "account.json" {
+				rc, _ := f.Open()
+				data, _ := io.ReadAll(rc)
+				assert.Contains(t, string(data), "gini@mail.com")
+			}
+			if f.Name == "bonds.json" {
+				rc, _ := f.Open()
+				data, _ := io.ReadAll(rc)
+				assert.Equal(t, "[]\n", string(data))
+			}
+		}
+		assert.Equal(t, []string{"account.json", "profile.json", "bonds.json", "listings.json", "transactions.json"}, names)
+	})
+
+	t.Run("Build Export Failed", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, UserID: 1, Status: domain.ExportPending}, nil)
+		accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).Return(nil)
+		accounts.EXPECT().GetAccount(gomock.Any(), 1).Return(nil, httpErrors.ErrUserNotFound)
+		storage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		// the queue retries it, the export keeps running
+		_, err := uc.RunExport(ctx, &domain.Task{UserID: 1, Payload: []byte(`{"export_id":2}`), Attempts: 1, MaxAttempts: 3})
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
+	})
+
+	t.Run("Build Export Failed On The Last Attempt", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, UserID: 1, Status: domain.ExportRunning}, nil)
+		gomock.InOrder(
+			accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).Return(nil),
+			accounts.EXPECT().UpdateExport(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.DataExport) error {
+				assert.Equal(t, domain.ExportFailed, e.Status)
+				return nil
+			}),
+		)
+		accounts.EXPECT().GetAccount(gomock.Any(), 1).Return(nil, httpErrors.ErrUserNotFound)
+
+		_, err := uc.RunExport(ctx, &domain.Task{UserID: 1, Payload: []byte(`{"export_id":2}`), Attempts: 3, MaxAttempts: 3})
+		assert.Error(t, err)
+	})
+
+	t.Run("Get Export Ready", func(t *testing.T) {
+		expiresAt := now.Add(5 * time.Minute)
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, Status: domain.ExportReady, FileKey: "exports/1/a.zip", ExpiresAt: &expiresAt}, nil)
+		storage.EXPECT().SignedURL(gomock.Any(), "exports/1/a.zip", 5*time.Minute).Return("http://localhost/v1/files/exports/1/a.zip", nil)
+
+		export, err := uc.GetExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost/v1/files/exports/1/a.zip", export.DownloadURL)
+	})
+
+	t.Run("Get Export Expired", func(t *testing.T) {
+		expiresAt := now.Add(-time.Minute)
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(&domain.DataExport{ID: 2, Status: domain.ExportReady, FileKey: "exports/1/a.zip", ExpiresAt: &expiresAt}, nil)
+		storage.EXPECT().SignedURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		export, err := uc.GetExport(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ExportExpired, export.Status)
+		assert.Empty(t, export.DownloadURL)
+	})
+
+	t.Run("Get Export Not Found", func(t *testing.T) {
+		accounts.EXPECT().GetLatestExport(gomock.Any(), 1).Return(nil, httpErrors.ErrExportNotFound)
+
+		_, err := uc.GetExport(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.ErrExportNotFound)
+	})
+
+	hash, err := testHasher.Hash("secret123")
+	assert.NoError(t, err)
+
+	t.Run("Delete Account", func(t *testing.T) {
+		auth.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "192.0.2.1").Return(nil)
+		guard.EXPECT().Succeed(gomock.Any(), "gini@mail.com")
+		accounts.EXPECT().ListExportKeys(gomock.Any(), 1).Return([]string{"exports/1/a.zip"}, nil)
+		users.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserPhoto: "avatars/1/abc.png"}, nil)
+		accounts.EXPECT().Anonymize(gomock.Any(), 1).Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "exports/1/a.zip").Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/abc_64.png").Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/abc_256.png").Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/abc_512.png").Return(nil)
+
+		err := uc.DeleteAccount(ctx, 1, &domain.DeleteAccountRequest{Password: "secret123", IP: "192.0.2.1", UserAgent: "Mozilla/5.0"})
+		assert.NoError(t, err)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventAccountDelete, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Empty(t, event.Email)
+		assert.Empty(t, event.IP)
+		assert.Empty(t, event.UserAgent)
+	})
+
+	t.Run("Delete Account Wrong Password", func(t *testing.T) {
+		auth.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "192.0.2.1").Return(nil)
+		guard.EXPECT().Fail(gomock.Any(), "gini@mail.com", "192.0.2.1")
+		accounts.EXPECT().Anonymize(gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.DeleteAccount(ctx, 1, &domain.DeleteAccountRequest{Password: "wrong-password", IP: "192.0.2.1"})
+		assert.ErrorIs(t, err, httpErrors.ErrBadPassword)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "bad password", event.Reason)
+	})
+
+	t.Run("Delete Account Locked", func(t *testing.T) {
+		auth.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "192.0.2.1").
+			Return(&httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: time.Minute})
+		accounts.EXPECT().Anonymize(gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.DeleteAccount(ctx, 1, &domain.DeleteAccountRequest{Password: "secret123", IP: "192.0.2.1"})
+		assert.ErrorIs(t, err, httpErrors.ErrAccountLocked)
+		assert.Equal(t, domain.AuthOutcomeBlocked, lastEvent(t, recorded).Outcome)
+	})
+
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"time"
+)
+
+var _ svcport.AdminService = (*AdminService)(nil)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// defaultJobRunsLimit runs of a job listed to the admins
+	defaultJobRunsLimit = 50
+)
+
+// AdminService struct
+type AdminService struct {
+	logger         *zap.SugaredLogger
+	users          repport.UserRepository
+	bonds          repport.BondRepository
+	marketBonds    repport.MarketBondRepository
+	audit          repport.AuditLogRepository
+	events         repport.AuthEventRepository
+	publisher      svcport.EventPublisher
+	scheduler      svcport.Scheduler
+	contextTimeOut time.Duration
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(logger *zap.SugaredLogger, users repport.UserRepository, bonds repport.BondRepository, marketBonds repport.MarketBondRepository, audit repport.AuditLogRepository, events repport.AuthEventRepository, publisher svcport.EventPublisher, scheduler svcport.Scheduler, timeout time.Duration) *AdminService {
+	return &AdminService{
+		logger:         logger,
+		users:          users,
+		bonds:          bonds,
+		marketBonds:    marketBonds,
+		audit:          audit,
+		events:         events,
+		publisher:      publisher,
+		scheduler:      scheduler,
+		contextTimeOut: timeout,
+	}
+}
+
+// SearchUsers finds users by email or username
+func (svc *AdminService) SearchUsers(c context.Context, actor *domain.Actor, search *domain.UserSearchRequest) ([]*domain.AdminUser, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if search.Limit <= 0 || search.Limit > maxSearchLimit {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Offset < 0 {
+		search.Offset = 0
+	}
+
+	list, err := svc.users.SearchUsers(ctx, search)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	if err := svc.record(ctx, actor, domain.AuditSearchUsers, domain.AuditTargetUser, 0, search.Query); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SuspendUser blocks the sign in of a user and revokes its sessions and API keys
+func (svc *AdminService) SuspendUser(c context.Context, actor *domain.Actor, uid int, reason string) error {
+	if actor.ID == uid {
+		return httpErrors.ErrSelfModeration
+	}
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.users.SetSuspended(ctx, uid, true, reason); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditSuspendUser, domain.AuditTargetUser, uid, reason)
+}
+
+// UnsuspendUser reinstates a suspended user
+func (svc *AdminService) UnsuspendUser(c context.Context, actor *domain.Actor, uid int) error {
+	if actor.ID == uid {
+		return httpErrors.ErrSelfModeration
+	}
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.users.SetSuspended(ctx, uid, false, ""); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditUnsuspendUser, domain.AuditTargetUser, uid, "")
+}
+
+// GetUserBonds lists the bonds created by any user
+func (svc *AdminService) GetUserBonds(c context.Context, actor *domain.Actor, uid int) ([]*domain.Bond, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.users.GetBonds(ctx, uid)
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrNoRecords) {
+			list = make([]*domain.Bond, 0)
+		} else {
+			return nil, svc.handleError(ctx, err)
+		}
+	}
+
+	if err := svc.record(ctx, actor, domain.AuditViewUserBonds, domain.AuditTargetUser, uid, ""); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetUserTransactions lists the transactions of any user
+func (svc *AdminService) GetUserTransactions(c context.Context, actor *domain.Actor, uid int) ([]*domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.users.GetTransactions(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	if err := svc.record(ctx, actor, domain.AuditViewUserTransactions, domain.AuditTargetUser, uid, ""); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DelistMarketBond withdraws a bond on sale from the market
+func (svc *AdminService) DelistMarketBond(c context.Context, actor *domain.Actor, market_bond_id int, reason string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	// loaded first, the seller is notified
+	item, err := svc.marketBonds.FindMarketBond(ctx, market_bond_id)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	if err := svc.marketBonds.DelistMarketBond(ctx, market_bond_id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	// the listing is delisted even when the audit fails, the seller is told
+	err = svc.record(ctx, actor, domain.AuditDelistMarketBond, domain.AuditTargetMarketBond, market_bond_id, reason)
+	publishEvent(svc.logger, svc.publisher, domain.EventListingDelisted, domain.ListingDelistedData{
+		MarketBondID: item.ID,
+		BondUUID:     item.UUID,
+		BondName:     item.Name,
+		SellerID:     item.CreatedByID,
+		Reason:       reason,
+	}, item.CreatedByID)
+	return err
+}
+
+// FreezeBond blocks a bond from being sold or bought
+func (svc *AdminService) FreezeBond(c context.Context, actor *domain.Actor, bond_id int, reason string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.bonds.SetFrozen(ctx, bond_id, true); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditFreezeBond, domain.AuditTargetBond, bond_id, reason)
+}
+
+// UnfreezeBond allows trading a frozen bond again
+func (svc *AdminService) UnfreezeBond(c context.Context, actor *domain.Actor, bond_id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.bonds.SetFrozen(ctx, bond_id, false); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditUnfreezeBond, domain.AuditTargetBond, bond_id, "")
+}
+
+// SearchAuthEvents lists the sign in activity of any account, newest first
+func (svc *AdminService) SearchAuthEvents(c context.Context, actor *domain.Actor, search *domain.AuthEventSearch) ([]*domain.AuthEvent, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if search.Limit <= 0 || search.Limit > maxSearchLimit {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Offset < 0 {
+		search.Offset = 0
+	}
+
+	list, err := svc.events.Search(ctx, search)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	if err := svc.record(ctx, actor, domain.AuditSearchAuthEvents, domain.AuditTargetUser, search.UserID, authEventFilters(search)); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListJobs lists the scheduled jobs with their last run
+func (svc *AdminService) ListJobs(c context.Context) ([]*domain.ScheduledJob, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.scheduler.Jobs(ctx)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	return list, nil
+}
+
+// ListJobRuns lists the latest runs of a job, newest first
+func (svc *AdminService) ListJobRuns(c context.Context, name string) ([]*domain.JobRun, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.scheduler.Runs(ctx, name, defaultJobRunsLimit)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	return list, nil
+}
+
+// RunJob starts a job now, even when it is paused
+func (svc *AdminService) RunJob(c context.Context, actor *domain.Actor, name string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.scheduler.Trigger(ctx, name); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditRunJob, domain.AuditTargetJob, 0, name)
+}
+
+// PauseJob stops the scheduled runs of a job
+func (svc *AdminService) PauseJob(c context.Context, actor *domain.Actor, name string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.scheduler.Pause(ctx, name); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditPauseJob, domain.AuditTargetJob, 0, name)
+}
+
+// ResumeJob schedules a paused job again
+func (svc *AdminService) ResumeJob(c context.Context, actor *domain.Actor, name string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.scheduler.Resume(ctx, name); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return svc.record(ctx, actor, domain.AuditResumeJob, domain.AuditTargetJob, 0, name)
+}
+
+// record writes the action to the audit log. When the entry can't be written
+// the admin gets an error instead of a silent success and retries the action,
+// the retry writes the entry.
+func (svc *AdminService) record(ctx context.Context, actor *domain.Actor, action string, targetType string, targetID int, details string) error {
+	entry := &domain.AuditLog{
+		AdminID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+		IP:         actor.IP,
+	}
+	if err := svc.audit.Create(ctx, entry); err != nil {
+		svc.logger.Errorw("failed to write the audit log", "action", action, "admin_id", actor.ID, "target_id", targetID, "error", err)
+		return httpErrors.InternalServerError
+	}
+	return nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *AdminService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return httpErrors.ErrUserNotFound
+		} else if errors.Is(err, httpErrors.ErrBondNotExist) {
+			return httpErrors.ErrBondNotExist
+		} else if errors.Is(err, httpErrors.ErrMarketBondNotExist) {
+			return httpErrors.ErrMarketBondNotExist
+		} else if errors.Is(err, httpErrors.ErrJobNotFound) {
+			return httpErrors.ErrJobNotFound
+		} else if errors.Is(err, httpErrors.ErrJobRunning) {
+			return httpErrors.ErrJobRunning
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+// authEventFilters the text filters of the search, for the audit log
+func authEventFilters(search *domain.AuthEventSearch) string {
+	var filters []string
+	for _, f := range []struct{ name, value string }{
+		{"email", search.Email},
+		{"ip", search.IP},
+		{"event", search.Event},
+		{"outcome", search.Outcome},
+	} {
+		if f.value != "" {
+			filters = append(filters, f.name+"="+f.value)
+		}
+	}
+	return strings.Join(filters, " ")
+}
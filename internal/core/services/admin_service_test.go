@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestAdminService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	users := mock.NewMockUserRepository(mockCtrl)
+	bonds := mock.NewMockBondRepository(mockCtrl)
+	marketBonds := mock.NewMockMarketBondRepository(mockCtrl)
+	audit := mock.NewMockAuditLogRepository(mockCtrl)
+	events := mock.NewMockAuthEventRepository(mockCtrl)
+	publisher := mock.NewMockEventPublisher(mockCtrl)
+	scheduler := mock.NewMockScheduler(mockCtrl)
+
+	uc := NewAdminService(slogger, users, bonds, marketBonds, audit, events, publisher, scheduler, 2*time.Second)
+	actor := &domain.Actor{ID: 1, IP: "127.0.0.1"}
+	ctx := context.Background()
+
+	t.Run("SearchUsers applies the default limit", func(t *testing.T) {
+		users.EXPECT().SearchUsers(gomock.Any(), &domain.UserSearchRequest{Query: "gini", Limit: defaultSearchLimit}).
+			Return([]*domain.AdminUser{{ID: 2, Email: "gini@mail.com"}}, nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditSearchUsers, TargetType: domain.AuditTargetUser, Details: "gini", IP: "127.0.0.1"}).
+			Return(nil)
+
+		list, err := uc.SearchUsers(ctx, actor, &domain.UserSearchRequest{Query: "gini", Limit: 1000})
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("SuspendUser", func(t *testing.T) {
+		users.EXPECT().SetSuspended(gomock.Any(), 2, true, "fraud").Return(nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditSuspendUser, TargetType: domain.AuditTargetUser, TargetID: 2, Details: "fraud", IP: "127.0.0.1"}).
+			Return(nil)
+
+		err := uc.SuspendUser(ctx, actor, 2, "fraud")
+		assert.NoError(t, err)
+	})
+
+	t.Run("SuspendUser rejects the own account", func(t *testing.T) {
+		err := uc.SuspendUser(ctx, actor, 1, "")
+		assert.ErrorIs(t, err, httpErrors.ErrSelfModeration)
+	})
+
+	t.Run("SuspendUser not found is not audited", func(t *testing.T) {
+		users.EXPECT().SetSuspended(gomock.Any(), 3, true, "").Return(httpErrors.ErrUserNotFound)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.SuspendUser(ctx, actor, 3, "")
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
+	})
+
+	t.Run("UnsuspendUser audit failure fails the action", func(t *testing.T) {
+		users.EXPECT().SetSuspended(gomock.Any(), 2, false, "").Return(nil)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(httpErrors.ErrExecuteStatement)
+
+		err := uc.UnsuspendUser(ctx, actor, 2)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("GetUserBonds", func(t *testing.T) {
+		users.EXPECT().GetBonds(gomock.Any(), 2).Return([]*domain.Bond{{ID: 5}}, nil)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		list, err := uc.GetUserBonds(ctx, actor, 2)
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("GetUserTransactions", func(t *testing.T) {
+		users.EXPECT().GetTransactions(gomock.Any(), 2).Return(nil, httpErrors.ErrExecuteQuery)
+
+		list, err := uc.GetUserTransactions(ctx, actor, 2)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+		assert.Nil(t, list)
+	})
+
+	t.Run("DelistMarketBond", func(t *testing.T) {
+		marketBonds.EXPECT().FindMarketBond(gomock.Any(), 7).
+			Return(&domain.MarketBond{ID: 7, UUID: "bond-uuid", Name: "Bond", CreatedByID: 3}, nil)
+		marketBonds.EXPECT().DelistMarketBond(gomock.Any(), 7).Return(nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditDelistMarketBond, TargetType: domain.AuditTargetMarketBond, TargetID: 7, Details: "spam", IP: "127.0.0.1"}).
+			Return(nil)
+		publisher.EXPECT().PublishEvent("events.listing.delisted", gomock.Any()).
+			DoAndReturn(func(subject string, event any) error {
+				e := event.(*domain.Event)
+				assert.Equal(t, []int{3}, e.UserIDs)
+				assert.JSONEq(t, `{"market_bond_id":7,"bond_uuid":"bond-uuid","bond_name":"Bond","seller_id":3,"reason":"spam"}`, string(e.Data))
+				return nil
+			})
+
+		err := uc.DelistMarketBond(ctx, actor, 7, "spam")
+		assert.NoError(t, err)
+	})
+
+	t.Run("DelistMarketBond audit failure", func(t *testing.T) {
+		marketBonds.EXPECT().FindMarketBond(gomock.Any(), 7).
+			Return(&domain.MarketBond{ID: 7, UUID: "bond-uuid", Name: "Bond", CreatedByID: 3}, nil)
+		marketBonds.EXPECT().DelistMarketBond(gomock.Any(), 7).Return(nil)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Return(httpErrors.ErrExecuteStatement)
+		// the listing is gone, the seller is still told
+		publisher.EXPECT().PublishEvent("events.listing.delisted", gomock.Any()).Return(nil)
+
+		err := uc.DelistMarketBond(ctx, actor, 7, "spam")
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("DelistMarketBond not found", func(t *testing.T) {
+		marketBonds.EXPECT().FindMarketBond(gomock.Any(), 8).Return(nil, httpErrors.ErrMarketBondNotExist)
+
+		err := uc.DelistMarketBond(ctx, actor, 8, "spam")
+		assert.ErrorIs(t, err, httpErrors.ErrMarketBondNotExist)
+	})
+
+	t.Run("FreezeBond", func(t *testing.T) {
+		bonds.EXPECT().SetFrozen(gomock.Any(), 5, true).Return(nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditFreezeBond, TargetType: domain.AuditTargetBond, TargetID: 5, IP: "127.0.0.1"}).
+			Return(nil)
+
+		err := uc.FreezeBond(ctx, actor, 5, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("UnfreezeBond not found", func(t *testing.T) {
+		bonds.EXPECT().SetFrozen(gomock.Any(), 6, false).Return(httpErrors.ErrBondNotExist)
+
+		err := uc.UnfreezeBond(ctx, actor, 6)
+		assert.ErrorIs(t, err, httpErrors.ErrBondNotExist)
+	})
+
+	t.Run("SearchAuthEvents", func(t *testing.T) {
+		events.EXPECT().Search(gomock.Any(), &domain.AuthEventSearch{UserID: 2, Outcome: domain.AuthOutcomeFailure, IP: "10.0.0.1", Limit: defaultSearchLimit}).
+			Return([]*domain.AuthEvent{{ID: 9, Event: domain.AuthEventSignIn, Outcome: domain.AuthOutcomeFailure}}, nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditSearchAuthEvents, TargetType: domain.AuditTargetUser, TargetID: 2, Details: "ip=10.0.0.1 outcome=failure", IP: "127.0.0.1"}).
+			Return(nil)
+
+		list, err := uc.SearchAuthEvents(ctx, actor, &domain.AuthEventSearch{UserID: 2, Outcome: domain.AuthOutcomeFailure, IP: "10.0.0.1", Offset: -5})
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("SearchAuthEvents failure is not audited", func(t *testing.T) {
+		events.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.SearchAuthEvents(ctx, actor, &domain.AuthEventSearch{})
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("ListJobRuns", func(t *testing.T) {
+		scheduler.EXPECT().Runs(gomock.Any(), "job_runs.purge", defaultJobRunsLimit).
+			Return([]*domain.JobRun{{ID: 3, Job: "job_runs.purge", Status: domain.JobRunSucceeded}}, nil)
+
+		list, err := uc.ListJobRuns(ctx, "job_runs.purge")
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("RunJob", func(t *testing.T) {
+		scheduler.EXPECT().Trigger(gomock.Any(), "job_runs.purge").Return(nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditRunJob, TargetType: domain.AuditTargetJob, Details: "job_runs.purge", IP: "127.0.0.1"}).
+			Return(nil)
+
+		err := uc.RunJob(ctx, actor, "job_runs.purge")
+		assert.NoError(t, err)
+	})
+
+	t.Run("RunJob already running is not audited", func(t *testing.T) {
+		scheduler.EXPECT().Trigger(gomock.Any(), "job_runs.purge").Return(httpErrors.ErrJobRunning)
+		audit.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.RunJob(ctx, actor, "job_runs.purge")
+		assert.ErrorIs(t, err, httpErrors.ErrJobRunning)
+	})
+
+	t.Run("PauseJob not found", func(t *testing.T) {
+		scheduler.EXPECT().Pause(gomock.Any(), "nope").Return(httpErrors.ErrJobNotFound)
+
+		err := uc.PauseJob(ctx, actor, "nope")
+		assert.ErrorIs(t, err, httpErrors.ErrJobNotFound)
+	})
+
+	t.Run("ResumeJob", func(t *testing.T) {
+		scheduler.EXPECT().Resume(gomock.Any(), "job_runs.purge").Return(nil)
+		audit.EXPECT().Create(gomock.Any(), &domain.AuditLog{AdminID: 1, Action: domain.AuditResumeJob, TargetType: domain.AuditTargetJob, Details: "job_runs.purge", IP: "127.0.0.1"}).
+			Return(nil)
+
+		err := uc.ResumeJob(ctx, actor, "job_runs.purge")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ svcport.AlertService = (*AlertService)(nil)
+
+// AlertService struct. Watchlists and alerts of the users; the market events
+// trigger the alerts, which reach the users as alert.triggered events.
+type AlertService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.AlertRepository
+	publisher      svcport.EventPublisher
+	cooldown       time.Duration
+	maxPerUser     int
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewAlertService creates a new alert service
+func NewAlertService(logger *zap.SugaredLogger, repo repport.AlertRepository, publisher svcport.EventPublisher, cfg domain.Alerts, timeout time.Duration) *AlertService {
+	return &AlertService{
+		logger:         logger,
+		repository:     repo,
+		publisher:      publisher,
+		cooldown:       time.Duration(cfg.AlertCooldown) * time.Second,
+		maxPerUser:     cfg.AlertMaxPerUser,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// ListWatchlist returns the bonds followed by the user
+func (svc *AlertService) ListWatchlist(c context.Context, uid int) ([]*domain.WatchlistItem, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.ListWatchlist(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// AddToWatchlist follows a bond
+func (svc *AlertService) AddToWatchlist(c context.Context, uid int, data *domain.WatchlistRequest) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	market, err := svc.repository.GetBondMarketByUUID(ctx, data.BondUUID)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	if err := svc.repository.AddToWatchlist(ctx, uid, market.BondID); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// RemoveFromWatchlist unfollows a bond
+func (svc *AlertService) RemoveFromWatchlist(c context.Context, uid int, bondUUID string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.RemoveFromWatchlist(ctx, uid, bondUUID); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// Create sets an alert on a bond
+func (svc *AlertService) Create(c context.Context, uid int, data *domain.AlertRequest) (*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	count, err := svc.repository.CountByUser(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if count >= svc.maxPerUser {
+		return nil, httpErrors.ErrTooManyAlerts
+	}
+
+	alert := &domain.Alert{UserID: uid, Active: true, CreatedAt: svc.now()}
+	if err := svc.apply(ctx, alert, data); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.repository.Create(ctx, alert); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return alert, nil
+}
+
+// List returns the alerts of the user
+func (svc *AlertService) List(c context.Context, uid int) ([]*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.List(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// Get returns an alert of the user
+func (svc *AlertService) Get(c context.Context, uid int, id int) (*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	alert, err := svc.repository.GetByID(ctx, uid, id)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return alert, nil
+}
+
+// Update replaces the bond, the condition and the state of an alert of the user
+func (svc *AlertService) Update(c context.Context, uid int, id int, data *domain.AlertRequest) (*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	alert, err := svc.repository.GetByID(ctx, uid, id)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.apply(ctx, alert, data); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.repository.Update(ctx, alert); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return alert, nil
+}
+
+// Delete deletes an alert of the user
+func (svc *AlertService) Delete(c context.Context, uid int, id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.Delete(ctx, uid, id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// HandleEvent evaluates the alerts of the bond against its market after the
+// event. A triggered alert stays quiet during the cooldown, so a redelivered
+// event or another instance doesn't notify it again.
+func (svc *AlertService) HandleEvent(c context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	var market *domain.BondMarket
+	var err error
+	switch event.Type {
+	case domain.EventListingCreated:
+		var data domain.ListingCreatedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		market, err = svc.repository.GetBondMarket(ctx, data.BondID)
+	case domain.EventTradeExecuted, domain.EventListingDelisted, domain.EventListingSoldOut:
+		var data struct {
+			BondUUID string `json:"bond_uuid"`
+		}
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		market, err = svc.repository.GetBondMarketByUUID(ctx, data.BondUUID)
+	default:
+		return nil
+	}
+	if err != nil {
+		// the bond was deleted since
+		if errors.Is(err, httpErrors.ErrBondNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	alerts, err := svc.repository.ListActiveByBond(ctx, market.BondID)
+	if err != nil {
+		return err
+	}
+	now := svc.now()
+	for _, alert := range alerts {
+		if alert.UserID == market.SellerID || !alert.Matches(market, event.Type) {
+			continue
+		}
+		triggered, err := svc.repository.MarkTriggered(ctx, alert.ID, now, now.Add(-svc.cooldown))
+		if err != nil {
+			return err
+		}
+		if !triggered {
+			continue
+		}
+		publishEvent(svc.logger, svc.publisher, domain.EventAlertTriggered, domain.AlertTriggeredData{
+			AlertID:   alert.ID,
+			AlertType: alert.Type,
+			Threshold: alert.Threshold,
+			BondUUID:  market.BondUUID,
+			BondName:  market.Name,
+			Price:     market.Price,
+			Currency:  market.Currency,
+			Available: market.Available,
+		}, alert.UserID)
+	}
+
+	return nil
+}
+
+// apply sets the request on the alert, the bond must exist
+func (svc *AlertService) apply(ctx context.Context, alert *domain.Alert, data *domain.AlertRequest) error {
+	market, err := svc.repository.GetBondMarketByUUID(ctx, data.BondUUID)
+	if err != nil {
+		return err
+	}
+
+	alert.BondID = market.BondID
+	alert.BondUUID = market.BondUUID
+	alert.BondName = market.Name
+	alert.Type = data.Type
+	alert.Threshold = data.Threshold
+	if data.Type == domain.AlertNewListing {
+		alert.Threshold = nil
+	}
+	if data.Active != nil {
+		alert.Active = *data.Active
+	}
+
+	return nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *AlertService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrAlertNotFound) {
+			return httpErrors.ErrAlertNotFound
+		} else if errors.Is(err, httpErrors.ErrWatchlistItemNotFound) {
+			return httpErrors.ErrWatchlistItemNotFound
+		} else if errors.Is(err, httpErrors.ErrBondNotExist) {
+			return httpErrors.ErrBondNotExist
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestAlertService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAlertRepository(mockCtrl)
+	publisher := mock.NewMockEventPublisher(mockCtrl)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewAlertService(slogger, repo, publisher, domain.Alerts{AlertCooldown: 3600, AlertMaxPerUser: 2}, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	bondUUID := "0b6f2d3e-4c1a-4f7e-9a8b-1c2d3e4f5a6b"
+	market := &domain.BondMarket{BondID: 4, BondUUID: bondUUID, Name: "Bond", Price: 9.5, Currency: 1, Available: 30, SellerID: 2}
+	threshold := 10.0
+
+	t.Run("AddToWatchlist", func(t *testing.T) {
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(market, nil)
+		repo.EXPECT().AddToWatchlist(gomock.Any(), 1, 4).Return(nil)
+
+		err := uc.AddToWatchlist(ctx, 1, &domain.WatchlistRequest{BondUUID: bondUUID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("AddToWatchlist Bond Not Found", func(t *testing.T) {
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(nil, httpErrors.ErrBondNotExist)
+
+		err := uc.AddToWatchlist(ctx, 1, &domain.WatchlistRequest{BondUUID: bondUUID})
+		assert.ErrorIs(t, err, httpErrors.ErrBondNotExist)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		repo.EXPECT().CountByUser(gomock.Any(), 1).Return(0, nil)
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(market, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *domain.Alert) error {
+			assert.Equal(t, 4, a.BondID)
+			assert.True(t, a.Active)
+			assert.Nil(t, a.Threshold)
+			a.ID = 8
+			return nil
+		})
+
+		alert, err := uc.Create(ctx, 1, &domain.AlertRequest{BondUUID: bondUUID, Type: domain.AlertNewListing, Threshold: &threshold})
+		assert.NoError(t, err)
+		assert.Equal(t, 8, alert.ID)
+		assert.Equal(t, "Bond", alert.BondName)
+	})
+
+	t.Run("Create over the limit", func(t *testing.T) {
+		repo.EXPECT().CountByUser(gomock.Any(), 1).Return(2, nil)
+
+		_, err := uc.Create(ctx, 1, &domain.AlertRequest{BondUUID: bondUUID, Type: domain.AlertNewListing})
+		assert.ErrorIs(t, err, httpErrors.ErrTooManyAlerts)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		inactive := false
+		repo.EXPECT().GetByID(gomock.Any(), 1, 8).Return(&domain.Alert{ID: 8, UserID: 1, BondID: 4, Type: domain.AlertNewListing, Active: true}, nil)
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(market, nil)
+		repo.EXPECT().Update(gomock.Any(), &domain.Alert{ID: 8, UserID: 1, BondID: 4, BondUUID: bondUUID, BondName: "Bond", Type: domain.AlertPriceBelow, Threshold: &threshold, Active: false}).Return(nil)
+
+		alert, err := uc.Update(ctx, 1, 8, &domain.AlertRequest{BondUUID: bondUUID, Type: domain.AlertPriceBelow, Threshold: &threshold, Active: &inactive})
+		assert.NoError(t, err)
+		assert.False(t, alert.Active)
+	})
+
+	t.Run("Update Not Found", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), 2, 8).Return(nil, httpErrors.ErrAlertNotFound)
+
+		_, err := uc.Update(ctx, 2, 8, &domain.AlertRequest{BondUUID: bondUUID, Type: domain.AlertNewListing})
+		assert.ErrorIs(t, err, httpErrors.ErrAlertNotFound)
+	})
+
+	t.Run("HandleEvent triggers the matching alerts", func(t *testing.T) {
+		event, _ := domain.NewEvent(domain.EventListingCreated, domain.ListingCreatedData{BondID: 4, SellerID: 2, Quantity: 30}, 2)
+		high, low, many := 10.0, 9.0, 50.0
+		repo.EXPECT().GetBondMarket(gomock.Any(), 4).Return(market, nil)
+		repo.EXPECT().ListActiveByBond(gomock.Any(), 4).Return([]*domain.Alert{
+			{ID: 1, UserID: 1, Type: domain.AlertPriceBelow, Threshold: &high},
+			{ID: 2, UserID: 1, Type: domain.AlertPriceBelow, Threshold: &low},
+			{ID: 3, UserID: 3, Type: domain.AlertNewListing},
+			{ID: 4, UserID: 3, Type: domain.AlertAvailableAbove, Threshold: &many},
+			{ID: 5, UserID: 2, Type: domain.AlertNewListing},
+		}, nil)
+		repo.EXPECT().MarkTriggered(gomock.Any(), 1, now, now.Add(-time.Hour)).Return(true, nil)
+		repo.EXPECT().MarkTriggered(gomock.Any(), 3, now, now.Add(-time.Hour)).Return(false, nil)
+		publisher.EXPECT().PublishEvent("events.alert.triggered", gomock.Any()).
+			DoAndReturn(func(subject string, event any) error {
+				e := event.(*domain.Event)
+				assert.Equal(t, []int{1}, e.UserIDs)
+				assert.JSONEq(t, `{"alert_id":1,"alert_type":"price_below","threshold":10,"bond_uuid":"`+bondUUID+`","bond_name":"Bond","price":9.5,"currency":1,"available":30}`, string(e.Data))
+				return nil
+			})
+
+		assert.NoError(t, uc.HandleEvent(ctx, *event))
+	})
+
+	t.Run("HandleEvent trade doesn't trigger new listings", func(t *testing.T) {
+		event, _ := domain.NewEvent(domain.EventTradeExecuted, domain.TradeExecutedData{BondUUID: bondUUID, SellerID: 2, BuyerID: 1}, 1, 2)
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(market, nil)
+		repo.EXPECT().ListActiveByBond(gomock.Any(), 4).Return([]*domain.Alert{{ID: 3, UserID: 3, Type: domain.AlertNewListing}}, nil)
+
+		assert.NoError(t, uc.HandleEvent(ctx, *event))
+	})
+
+	t.Run("HandleEvent deleted bond", func(t *testing.T) {
+		event, _ := domain.NewEvent(domain.EventListingDelisted, domain.ListingDelistedData{BondUUID: bondUUID}, 2)
+		repo.EXPECT().GetBondMarketByUUID(gomock.Any(), bondUUID).Return(nil, httpErrors.ErrBondNotExist)
+
+		assert.NoError(t, uc.HandleEvent(ctx, *event))
+	})
+
+	t.Run("HandleEvent ignores other events", func(t *testing.T) {
+		event := domain.Event{ID: "evt", Type: domain.EventSecurityAlert, Data: json.RawMessage(`{}`)}
+
+		assert.NoError(t, uc.HandleEvent(ctx, event))
+	})
+}
+
+func TestAlertMatches(t *testing.T) {
+	threshold := 10.0
+	market := &domain.BondMarket{Price: 10, Available: 10}
+
+	assert.True(t, (&domain.Alert{Type: domain.AlertPriceBelow, Threshold: &threshold}).Matches(market, domain.EventTradeExecuted))
+	assert.False(t, (&domain.Alert{Type: domain.AlertAvailableAbove, Threshold: &threshold}).Matches(market, domain.EventListingCreated))
+	assert.True(t, (&domain.Alert{Type: domain.AlertNewListing}).Matches(market, domain.EventListingCreated))
+	assert.False(t, (&domain.Alert{Type: domain.AlertPriceBelow, Threshold: &threshold}).Matches(&domain.BondMarket{Price: 1}, domain.EventListingCreated))
+	assert.False(t, (&domain.Alert{Type: domain.AlertNewListing}).Matches(&domain.BondMarket{Available: 10, Frozen: true}, domain.EventListingCreated))
+}
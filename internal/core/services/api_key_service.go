@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// apiKeyPrefix identifies the keys of the app, e.g. in secret scanners
+const apiKeyPrefix = "mbk"
+
+var _ svcport.APIKeyService = (*APIKeyService)(nil)
+
+// APIKeyService struct
+type APIKeyService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.APIKeyRepository
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewAPIKeyService creates a new api key service
+func NewAPIKeyService(logger *zap.SugaredLogger, repo repport.APIKeyRepository, timeout time.Duration) *APIKeyService {
+	return &APIKeyService{
+		logger:         logger,
+		repository:     repo,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// Create generates a new key, the secret is only returned here
+func (svc *APIKeyService) Create(c context.Context, uid int, data *domain.APIKeyRequest) (*domain.APIKeyCreated, error) {
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(svc.now()) {
+		return nil, httpErrors.ErrAPIKeyExpiryInPast
+	}
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	prefix, secret, err := newAPIKeySecret()
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	raw := strings.Join([]string{apiKeyPrefix, prefix, secret}, "_")
+
+	key := &domain.APIKey{
+		UserID:     uid,
+		Name:       data.Name,
+		Prefix:     prefix,
+		KeyHash:    hashAPIKey(raw),
+		Scopes:     uniqueStrings(data.Scopes),
+		AllowedIPs: uniqueStrings(data.AllowedIPs),
+		ExpiresAt:  data.ExpiresAt,
+		CreatedAt:  svc.now(),
+	}
+	if err := svc.repository.Create(ctx, key); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.APIKeyCreated{APIKey: key, Key: raw}, nil
+}
+
+// List returns the active keys of the user
+func (svc *APIKeyService) List(c context.Context, uid int) ([]*domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// Revoke disables a key of the user
+func (svc *APIKeyService) Revoke(c context.Context, uid int, id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.Revoke(ctx, uid, id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// Authenticate resolves the key of a request
+func (svc *APIKeyService) Authenticate(c context.Context, raw string, ip string, scope string) (*domain.APIKey, error) {
+	parts := strings.Split(raw, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, httpErrors.ErrInvalidAPIKey
+	}
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	key, err := svc.repository.FindByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrAPIKeyNotFound) {
+			return nil, httpErrors.ErrInvalidAPIKey
+		}
+		return nil, svc.handleError(ctx, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(raw))) != 1 {
+		return nil, httpErrors.ErrInvalidAPIKey
+	}
+	if key.Expired(svc.now()) {
+		return nil, httpErrors.ErrInvalidAPIKey
+	}
+	if !ipAllowed(key.AllowedIPs, ip) {
+		return nil, httpErrors.ErrAPIKeyIPNotAllowed
+	}
+	if !key.HasScope(scope) {
+		return nil, httpErrors.ErrAPIKeyScopeDenied
+	}
+
+	// Usage tracking must not reject a valid request
+	if err := svc.repository.TrackUsage(ctx, key.ID, ip); err != nil {
+		svc.logger.Errorw("failed to track api key usage", "api_key_id", key.ID, "error", err)
+	}
+
+	return key, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *APIKeyService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrAPIKeyNotFound) {
+			return httpErrors.ErrAPIKeyNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+// newAPIKeySecret returns the public prefix used for the lookup and the secret part
+func newAPIKeySecret() (string, string, error) {
+	b := make([]byte, 6+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:6]), base64.RawURLEncoding.EncodeToString(b[6:]), nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// ipAllowed an empty allowlist allows every IP. Entries are IPs or CIDR ranges.
+func ipAllowed(allowlist []string, ip string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range allowlist {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(list []string) []string {
+	var seen = make(map[string]bool, len(list))
+	var result = make([]string, 0, len(list))
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
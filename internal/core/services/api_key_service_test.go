@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAPIKeyRepository(mockCtrl)
+
+	uc := NewAPIKeyService(slogger, repo, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		resp, err := uc.Create(ctx, 1, &domain.APIKeyRequest{
+			Name:   "bot",
+			Scopes: []string{domain.ScopeMarketRead, domain.ScopeMarketRead},
+		})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(resp.Key, "mbk_"+resp.Prefix+"_"))
+		assert.Equal(t, hashAPIKey(resp.Key), resp.KeyHash)
+		assert.Equal(t, []string{domain.ScopeMarketRead}, resp.Scopes)
+	})
+
+	t.Run("Expiry In Past", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := uc.Create(ctx, 1, &domain.APIKeyRequest{Name: "bot", Scopes: []string{domain.ScopeMarketRead}, ExpiresAt: &past})
+		assert.ErrorIs(t, err, httpErrors.ErrAPIKeyExpiryInPast)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAPIKeyRepository(mockCtrl)
+
+	uc := NewAPIKeyService(slogger, repo, 2*time.Second)
+	now := time.Unix(1700000000, 0)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	const raw = "mbk_a1b2c3d4e5f6_c2VjcmV0"
+	newKey := func() *domain.APIKey {
+		return &domain.APIKey{ID: 7, UserID: 1, Prefix: "a1b2c3d4e5f6", KeyHash: hashAPIKey(raw), Scopes: []string{domain.ScopeMarketRead}}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		key := newKey()
+		key.AllowedIPs = []string{"10.0.0.0/8"}
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(key, nil)
+		repo.EXPECT().TrackUsage(gomock.Any(), 7, "10.1.2.3").Return(nil)
+
+		resp, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketRead)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.UserID)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		repo.EXPECT().FindByPrefix(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.Authenticate(ctx, "not-a-key", "10.1.2.3", domain.ScopeMarketRead)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Unknown Prefix", func(t *testing.T) {
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(nil, httpErrors.ErrAPIKeyNotFound)
+
+		_, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketRead)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(newKey(), nil)
+
+		_, err := uc.Authenticate(ctx, "mbk_a1b2c3d4e5f6_b3RoZXI", "10.1.2.3", domain.ScopeMarketRead)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		key := newKey()
+		expired := now.Add(-time.Minute)
+		key.ExpiresAt = &expired
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(key, nil)
+
+		_, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketRead)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("IP Not Allowed", func(t *testing.T) {
+		key := newKey()
+		key.AllowedIPs = []string{"192.168.1.10"}
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(key, nil)
+
+		_, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketRead)
+		assert.ErrorIs(t, err, httpErrors.ErrAPIKeyIPNotAllowed)
+	})
+
+	t.Run("Scope Denied", func(t *testing.T) {
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(newKey(), nil)
+
+		_, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketTrade)
+		assert.ErrorIs(t, err, httpErrors.ErrAPIKeyScopeDenied)
+	})
+
+	t.Run("Tracking Failure", func(t *testing.T) {
+		repo.EXPECT().FindByPrefix(gomock.Any(), "a1b2c3d4e5f6").Return(newKey(), nil)
+		repo.EXPECT().TrackUsage(gomock.Any(), 7, "10.1.2.3").Return(httpErrors.InternalServerError)
+
+		resp, err := uc.Authenticate(ctx, raw, "10.1.2.3", domain.ScopeMarketRead)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+// maxAuthEventText size of the email and reason columns
+const maxAuthEventText = 255
+
+var _ svcport.AuthEventService = (*AuthEventService)(nil)
+
+// AuthEventService struct
+type AuthEventService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.AuthEventRepository
+	publisher      svcport.EventPublisher
+	contextTimeOut time.Duration
+}
+
+// NewAuthEventService creates a new auth event service
+func NewAuthEventService(logger *zap.SugaredLogger, repo repport.AuthEventRepository, publisher svcport.EventPublisher, timeout time.Duration) *AuthEventService {
+	return &AuthEventService{
+		logger:         logger,
+		repository:     repo,
+		publisher:      publisher,
+		contextTimeOut: timeout,
+	}
+}
+
+// Record writes the event and alerts the owner of the account of the
+// sensitive ones. It doesn't inherit the cancellation of the request, a
+// client that disconnects right after a failed sign in still leaves a trace.
+func (svc *AuthEventService) Record(c context.Context, event *domain.AuthEvent) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c), svc.contextTimeOut)
+	defer cancel()
+
+	event.Email = truncate(event.Email, maxAuthEventText)
+	event.UserAgent = truncate(event.UserAgent, maxUserAgentLength)
+	event.Reason = truncate(event.Reason, maxAuthEventText)
+	if err := svc.repository.Create(ctx, event); err != nil {
+		svc.logger.Errorw("failed to record auth event", "event", event.Event, "outcome", event.Outcome, "error", err)
+	}
+	publishSecurityAlert(svc.logger, svc.publisher, event)
+}
+
+// ListByUser returns the security history of the user, newest first
+func (svc *AuthEventService) ListByUser(c context.Context, uid int, limit int, offset int) ([]*domain.AuthEvent, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	list, err := svc.repository.Search(ctx, &domain.AuthEventSearch{UserID: uid, Limit: limit, Offset: offset})
+	if err != nil {
+		svc.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, httpErrors.ErrTimeout
+		default:
+			return nil, httpErrors.InternalServerError
+		}
+	}
+
+	return list, nil
+}
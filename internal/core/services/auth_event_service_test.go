@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthEventService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthEventRepository(mockCtrl)
+	publisher := mock.NewMockEventPublisher(mockCtrl)
+
+	uc := NewAuthEventService(slogger, repo, publisher, 2*time.Second)
+
+	t.Run("Record truncates the user agent", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *domain.AuthEvent) error {
+			assert.Len(t, event.UserAgent, maxUserAgentLength)
+			return nil
+		})
+
+		uc.Record(context.Background(), &domain.AuthEvent{Event: domain.AuthEventSignIn, Outcome: domain.AuthOutcomeSuccess, UserAgent: strings.Repeat("a", 300)})
+	})
+
+	t.Run("Record outlives the request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ *domain.AuthEvent) error {
+			assert.NoError(t, ctx.Err())
+			return nil
+		})
+
+		uc.Record(ctx, &domain.AuthEvent{Event: domain.AuthEventSignIn, Outcome: domain.AuthOutcomeFailure})
+	})
+
+	t.Run("Record failure is only logged", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(sql.ErrConnDone)
+
+		uc.Record(context.Background(), &domain.AuthEvent{Event: domain.AuthEventSignIn, Outcome: domain.AuthOutcomeFailure})
+	})
+
+	t.Run("Record alerts the owner of a password change", func(t *testing.T) {
+		uid := 2
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		publisher.EXPECT().PublishEvent("events.security.alert", gomock.Any()).DoAndReturn(func(_ string, event any) error {
+			e := event.(*domain.Event)
+			assert.Equal(t, []int{2}, e.UserIDs)
+			assert.JSONEq(t, `{"event":"password.change","outcome":"success","ip":"10.0.0.1","user_agent":"curl"}`, string(e.Data))
+			return nil
+		})
+
+		uc.Record(context.Background(), &domain.AuthEvent{UserID: &uid, Event: domain.AuthEventPasswordChange, Outcome: domain.AuthOutcomeSuccess, IP: "10.0.0.1", UserAgent: "curl"})
+	})
+
+	t.Run("Record of a failed password change isn't an alert", func(t *testing.T) {
+		uid := 2
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		uc.Record(context.Background(), &domain.AuthEvent{UserID: &uid, Event: domain.AuthEventPasswordChange, Outcome: domain.AuthOutcomeFailure})
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		repo.EXPECT().Search(gomock.Any(), &domain.AuthEventSearch{UserID: 1, Limit: defaultSearchLimit}).
+			Return([]*domain.AuthEvent{{ID: 1, Event: domain.AuthEventSignIn}}, nil)
+
+		list, err := uc.ListByUser(context.Background(), 1, 0, 0)
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("ListByUser failure", func(t *testing.T) {
+		repo.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+
+		_, err := uc.ListByUser(context.Background(), 1, 10, 0)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+}
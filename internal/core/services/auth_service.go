@@ -11,6 +11,7 @@ import (
 	svcport "kiramishima/m-backend/internal/core/ports/services"
 	httpErrors "kiramishima/m-backend/pkg/errors"
 	"kiramishima/m-backend/pkg/utils"
+	"strconv"
 	"time"
 )
 
@@ -19,24 +20,55 @@ var _ svcport.AuthService = (*AuthService)(nil)
 type AuthService struct {
 	logger         *zap.SugaredLogger
 	repository     repport.AuthRepository
+	roles          repport.RoleRepository
+	twoFactor      svcport.TwoFactorService
+	guard          svcport.LoginGuardService
+	sessions       svcport.SessionService
+	events         svcport.AuthEventService
+	hasher         svcport.PasswordHasher
+	challengeTTL   time.Duration
 	contextTimeOut time.Duration
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(logger *zap.SugaredLogger, repo repport.AuthRepository, timeout time.Duration) *AuthService {
+func NewAuthService(logger *zap.SugaredLogger, repo repport.AuthRepository, roles repport.RoleRepository, twoFactor svcport.TwoFactorService, guard svcport.LoginGuardService, sessions svcport.SessionService, events svcport.AuthEventService, hasher svcport.PasswordHasher, challengeTTL time.Duration, timeout time.Duration) *AuthService {
 	return &AuthService{
 		logger:         logger,
 		repository:     repo,
+		roles:          roles,
+		twoFactor:      twoFactor,
+		guard:          guard,
+		sessions:       sessions,
+		events:         events,
+		hasher:         hasher,
+		challengeTTL:   challengeTTL,
 		contextTimeOut: timeout,
 	}
 }
 
 // FindByCredentials To Login users
 func (svc *AuthService) FindByCredentials(c context.Context, data *domain.AuthRequest) (*domain.AuthResponse, error) {
-	data.Password = data.Hash256Password(data.Password)
 	// context
 	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
 	defer cancel()
+
+	// The event is written when the sign in returns, the reason is replaced on every known outcome
+	event := &domain.AuthEvent{
+		Email:     data.Email,
+		IP:        data.IP,
+		UserAgent: data.UserAgent,
+		Event:     domain.AuthEventSignIn,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	// Brute-force protection
+	if err := svc.guard.Check(ctx, data.Email, data.IP); err != nil {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, err.Error()
+		return nil, err
+	}
+
 	user, err := svc.repository.FindByCredentials(ctx, data)
 
 	if err != nil {
@@ -49,41 +81,222 @@ func (svc *AuthService) FindByCredentials(c context.Context, data *domain.AuthRe
 			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
 				return nil, httpErrors.BadQueryParams
 			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
+				event.Reason = "unknown email"
+				svc.guard.Fail(ctx, data.Email, data.IP)
 				return nil, httpErrors.ErrBadEmailOrPassword
 			} else {
 				return nil, httpErrors.InternalServerError
 			}
 		}
 	}
+	event.UserID = userID(user)
 
 	// Check Password
-	if !data.ValidateBcryptPassword(user.Password, data.Password) {
+	match, rehash, err := svc.hasher.Verify(data.Password, user.Password)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	if !match {
+		event.Reason = "bad password"
+		svc.guard.Fail(ctx, data.Email, data.IP)
 		return nil, httpErrors.ErrBadPassword
 	}
+	if user.Suspended {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, "suspended"
+		return nil, httpErrors.ErrUserSuspended
+	}
+
+	// Upgrade legacy or outdated hashes, the login must not fail because of it
+	if rehash {
+		svc.rehashPassword(ctx, user.ID, data.Password)
+	}
+
+	// Accounts with 2FA finish the sign in at CompleteTwoFactor
+	uid, _ := strconv.Atoi(user.ID)
+	enabled, err := svc.twoFactor.IsEnabled(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := utils.GenerateChallengeJWT(uid, svc.challengeTTL)
+		if err != nil {
+			svc.logger.Error(err.Error())
+			return nil, httpErrors.InternalServerError
+		}
+		event.Outcome, event.Reason = domain.AuthOutcomeSuccess, "2fa required"
+		return &domain.AuthResponse{ChallengeToken: challenge, TwoFactorRequired: true}, nil
+	}
+	// With 2FA the counters are only cleared once the second factor is valid
+	svc.guard.Succeed(ctx, data.Email)
+
+	resp, err := issueSessionToken(ctx, svc.logger, svc.roles, svc.sessions, user, data.IP, data.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, "password"
+	return resp, nil
+}
+
+// CompleteTwoFactor finishes a sign in started with FindByCredentials
+func (svc *AuthService) CompleteTwoFactor(c context.Context, data *domain.TwoFactorLoginRequest) (*domain.AuthResponse, error) {
+	uid, err := utils.ParseChallengeJWT(data.ChallengeToken)
+	if err != nil {
+		return nil, httpErrors.ErrInvalidChallengeToken
+	}
+
+	// context
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	user, err := svc.repository.FindByID(ctx, uid)
+	if err != nil {
+		svc.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, httpErrors.ErrTimeout
+		default:
+			if errors.Is(err, httpErrors.ErrUserNotFound) {
+				return nil, httpErrors.ErrInvalidChallengeToken
+			} else {
+				return nil, httpErrors.InternalServerError
+			}
+		}
+	}
+
+	event := &domain.AuthEvent{
+		UserID:    &uid,
+		Email:     user.Email,
+		IP:        data.IP,
+		UserAgent: data.UserAgent,
+		Event:     domain.AuthEventSignIn,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	// The second factor shares the counters of the password
+	if err := svc.guard.Check(ctx, user.Email, data.IP); err != nil {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, err.Error()
+		return nil, err
+	}
+	if err := svc.twoFactor.Verify(ctx, uid, data.Code); err != nil {
+		event.Reason = err.Error()
+		if errors.Is(err, httpErrors.ErrInvalidTwoFactorCode) {
+			svc.guard.Fail(ctx, user.Email, data.IP)
+		}
+		return nil, err
+	}
+	svc.guard.Succeed(ctx, user.Email)
+	if user.Suspended {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, "suspended"
+		return nil, httpErrors.ErrUserSuspended
+	}
+
+	resp, err := issueSessionToken(ctx, svc.logger, svc.roles, svc.sessions, user, data.IP, data.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, "2fa"
+	return resp, nil
+}
+
+// Refresh rotates the refresh token and signs a new access token. The roles
+// and the suspension are read again, so they apply on the next refresh.
+func (svc *AuthService) Refresh(c context.Context, data *domain.RefreshRequest) (*domain.AuthResponse, error) {
+	// context
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	event := &domain.AuthEvent{
+		IP:        data.IP,
+		UserAgent: data.UserAgent,
+		Event:     domain.AuthEventTokenRefresh,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	session, refreshToken, err := svc.sessions.Rotate(ctx, data.RefreshToken, data.IP, data.UserAgent)
+	if err != nil {
+		event.Reason = err.Error()
+		return nil, err
+	}
+	event.UserID = &session.UserID
+
+	user, err := svc.repository.FindByID(ctx, session.UserID)
+	if err != nil {
+		svc.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, httpErrors.ErrTimeout
+		default:
+			if errors.Is(err, httpErrors.ErrUserNotFound) {
+				event.Reason = "deleted user"
+				return nil, httpErrors.ErrInvalidRefreshToken
+			} else {
+				return nil, httpErrors.InternalServerError
+			}
+		}
+	}
+	event.Email = user.Email
+	if user.Suspended {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, "suspended"
+		if err := svc.sessions.Revoke(ctx, session.UserID, session.ID); err != nil {
+			svc.logger.Error(err.Error())
+		}
+		return nil, httpErrors.ErrUserSuspended
+	}
 
-	// Generate Token
-	token, err := utils.GenerateJWT(user)
+	roles, err := svc.roles.GetUserRoles(ctx, session.UserID)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	user.Roles = roles
+
+	token, err := utils.GenerateJWT(user, session.ID)
 	if err != nil {
 		svc.logger.Error(err.Error(), fmt.Sprintf("%T", err))
 		return nil, jwt.ErrSignatureInvalid
 	}
 
-	return &domain.AuthResponse{Token: token}, nil
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, ""
+	return &domain.AuthResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// Unlock lifts the lockout of an account with the token of the unlock email
+func (svc *AuthService) Unlock(ctx context.Context, token string, ip string) error {
+	return svc.guard.Unlock(ctx, token, ip)
 }
 
 // Register repository method for create a new user.
 func (svc *AuthService) Register(c context.Context, registerReq *domain.RegisterRequest) error {
 	// Hash password
-	// todo agregar el secret
-	registerReq.Password = registerReq.Hash256Password(registerReq.Password)
-	// Hash Bcrypt
-	registerReq.Password, _ = registerReq.BcryptPassword(registerReq.Password)
+	hash, err := svc.hasher.Hash(registerReq.Password)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+	registerReq.Password = hash
 
 	// context
 	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
 	defer cancel()
+
+	event := &domain.AuthEvent{
+		Email:     registerReq.Email,
+		IP:        registerReq.IP,
+		UserAgent: registerReq.UserAgent,
+		Event:     domain.AuthEventSignUp,
+		Outcome:   domain.AuthOutcomeFailure,
+	}
+	defer svc.events.Record(ctx, event)
+
 	// Call repository
-	err := svc.repository.Register(ctx, registerReq)
+	err = svc.repository.Register(ctx, registerReq)
 
 	if err != nil {
 		svc.logger.Error(err.Error())
@@ -93,6 +306,7 @@ func (svc *AuthService) Register(c context.Context, registerReq *domain.Register
 			return httpErrors.ErrTimeout
 		default:
 			if errors.Is(err, httpErrors.ErrAlreadyExists) {
+				event.Reason = "already exists"
 				return httpErrors.ErrAlreadyExists
 			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
 				return httpErrors.ErrUserNotFound
@@ -102,5 +316,126 @@ func (svc *AuthService) Register(c context.Context, registerReq *domain.Register
 		}
 	}
 
+	event.Outcome = domain.AuthOutcomeSuccess
+	if user, err := svc.repository.FindByCredentials(ctx, &domain.AuthRequest{Email: registerReq.Email}); err == nil {
+		event.UserID = userID(user)
+	}
+	return nil
+}
+
+// ChangePassword replaces the password of the user, the current one is required
+// and its failures count for the login guard like a sign in. Every other
+// session of the user is signed out.
+func (svc *AuthService) ChangePassword(c context.Context, uid int, data *domain.ChangePasswordRequest) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	user, err := svc.repository.FindByID(ctx, uid)
+	if err != nil {
+		svc.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			return httpErrors.ErrTimeout
+		default:
+			if errors.Is(err, httpErrors.ErrUserNotFound) {
+				return httpErrors.ErrUserNotFound
+			} else {
+				return httpErrors.InternalServerError
+			}
+		}
+	}
+
+	event := &domain.AuthEvent{
+		UserID:    &uid,
+		Email:     user.Email,
+		IP:        data.IP,
+		UserAgent: data.UserAgent,
+		Event:     domain.AuthEventPasswordChange,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	if err := svc.guard.Check(ctx, user.Email, data.IP); err != nil {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, err.Error()
+		return err
+	}
+
+	match, _, err := svc.hasher.Verify(data.CurrentPassword, user.Password)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+	if !match {
+		event.Reason = "bad password"
+		svc.guard.Fail(ctx, user.Email, data.IP)
+		return httpErrors.ErrBadPassword
+	}
+	svc.guard.Succeed(ctx, user.Email)
+
+	hash, err := svc.hasher.Hash(data.NewPassword)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+	if err := svc.repository.UpdatePassword(ctx, user.ID, hash); err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+
+	if err := svc.sessions.RevokeOthers(ctx, uid, data.SessionID); err != nil {
+		svc.logger.Error(err.Error())
+		event.Reason = "sessions not revoked"
+		return httpErrors.InternalServerError
+	}
+
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, ""
 	return nil
 }
+
+// CreateAdmin grants the admin role to the user, registering it when the
+// email is unknown. It fails once any admin exists.
+func (svc *AuthService) CreateAdmin(c context.Context, registerReq *domain.RegisterRequest) error {
+	// context
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	total, err := svc.roles.CountUsersWithRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return httpErrors.ErrAdminAlreadyExists
+	}
+
+	user, err := svc.repository.FindByCredentials(ctx, &domain.AuthRequest{Email: registerReq.Email})
+	if errors.Is(err, httpErrors.ErrUserNotFound) {
+		if err = svc.Register(ctx, registerReq); err != nil {
+			return err
+		}
+		user, err = svc.repository.FindByCredentials(ctx, &domain.AuthRequest{Email: registerReq.Email})
+	}
+	if err != nil {
+		return err
+	}
+
+	uid, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return httpErrors.ErrUserNotFound
+	}
+
+	return svc.roles.AssignRole(ctx, uid, domain.RoleAdmin)
+}
+
+// rehashPassword stores a new argon2id hash for the user
+func (svc *AuthService) rehashPassword(ctx context.Context, uid string, password string) {
+	hash, err := svc.hasher.Hash(password)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return
+	}
+	if err := svc.repository.UpdatePassword(ctx, uid, hash); err != nil {
+		svc.logger.Errorw("failed to rehash password", "user_id", uid, "error", err)
+	}
+}
@@ -2,17 +2,51 @@ package services
 
 import (
 	"context"
+	"encoding/hex"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
 	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/hasher"
 	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"kiramishima/m-backend/pkg/utils"
+	"strings"
 	"testing"
 	"time"
 )
 
-func setup() {
+var testHasher = hasher.NewHasher(hasher.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+})
 
+func legacyHash(t *testing.T, password string) string {
+	sum := sha3.Sum256([]byte(password))
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(sum[:])), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+// recordedEvents collects the auth events written by the service
+func recordedEvents(mockCtrl *gomock.Controller, recorded *[]*domain.AuthEvent) *mock.MockAuthEventService {
+	events := mock.NewMockAuthEventService(mockCtrl)
+	events.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *domain.AuthEvent) {
+		*recorded = append(*recorded, event)
+	}).AnyTimes()
+	return events
+}
+
+func lastEvent(t *testing.T, recorded []*domain.AuthEvent) *domain.AuthEvent {
+	if !assert.NotEmpty(t, recorded) {
+		return &domain.AuthEvent{}
+	}
+	return recorded[len(recorded)-1]
 }
 
 func TestLogin(t *testing.T) {
@@ -21,30 +55,432 @@ func TestLogin(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 	repo := mock.NewMockAuthRepository(mockCtrl)
-	repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{
-		ID:        "1",
-		Email:     "gini@mail.com",
-		Password:  "12356",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Time{},
-	}, nil)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil).AnyTimes()
+	twoFactor := mock.NewMockTwoFactorService(mockCtrl)
+	twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(false, nil).AnyTimes()
+	guard := mock.NewMockLoginGuardService(mockCtrl)
+	guard.EXPECT().Check(gomock.Any(), "gini@mail.com", gomock.Any()).Return(nil).AnyTimes()
+	guard.EXPECT().Succeed(gomock.Any(), "gini@mail.com").AnyTimes()
+	sessions := mock.NewMockSessionService(mockCtrl)
+	sessions.EXPECT().Create(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return(&domain.UserSession{ID: 1}, "refresh", nil).AnyTimes()
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
 
-	uc := NewAuthService(slogger, repo, 2)
+	uc := NewAuthService(slogger, repo, roles, twoFactor, guard, sessions, events, testHasher, 5*time.Minute, 2*time.Second)
 
 	t.Run("OK", func(t *testing.T) {
+		hash, _ := testHasher.Hash("123456")
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{
+			ID:        "1",
+			Email:     "gini@mail.com",
+			Password:  hash,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Time{},
+		}, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		ctx := context.Background()
+		data := &domain.AuthRequest{Email: "gini@mail.com", Password: "123456", IP: "127.0.0.1", UserAgent: "Mozilla/5.0"}
+		b, err := uc.FindByCredentials(ctx, data)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, b.Token)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventSignIn, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, "password", event.Reason)
+		assert.Equal(t, 1, *event.UserID)
+		assert.Equal(t, "127.0.0.1", event.IP)
+		assert.Equal(t, "Mozilla/5.0", event.UserAgent)
+	})
+
+	t.Run("Legacy hash is rehashed", func(t *testing.T) {
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{
+			ID:       "1",
+			Email:    "gini@mail.com",
+			Password: legacyHash(t, "123456"),
+		}, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), "1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string) error {
+				assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+				return nil
+			})
+
+		ctx := context.Background()
+		data := &domain.AuthRequest{Email: "gini@mail.com", Password: "123456"}
+		b, err := uc.FindByCredentials(ctx, data)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, b.Token)
+	})
+
+	t.Run("Rehash failure does not block login", func(t *testing.T) {
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{
+			ID:       "1",
+			Email:    "gini@mail.com",
+			Password: legacyHash(t, "123456"),
+		}, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), "1", gomock.Any()).Return(httpErrors.ErrUpdatingRecord)
+
 		ctx := context.Background()
 		data := &domain.AuthRequest{Email: "gini@mail.com", Password: "123456"}
 		b, err := uc.FindByCredentials(ctx, data)
-		t.Log("B", b)
 		assert.NoError(t, err)
-		assert.Equal(t, "gini@mail.com", b.Token)
+		assert.NotEmpty(t, b.Token)
 	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{
+			ID:       "1",
+			Email:    "gini@mail.com",
+			Password: legacyHash(t, "123456"),
+		}, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		guard.EXPECT().Fail(gomock.Any(), "gini@mail.com", "127.0.0.1").Times(1)
+
+		ctx := context.Background()
+		data := &domain.AuthRequest{Email: "gini@mail.com", Password: "", IP: "127.0.0.1"}
+		b, err := uc.FindByCredentials(ctx, data)
+		assert.ErrorIs(t, err, httpErrors.ErrBadPassword)
+		assert.Nil(t, b)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "bad password", event.Reason)
+		assert.Equal(t, 1, *event.UserID)
+	})
+
 	t.Run("Not Found", func(t *testing.T) {
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrUserNotFound)
+		guard.EXPECT().Fail(gomock.Any(), "gini@mail.com", "127.0.0.1").Times(1)
+
 		ctx := context.Background()
-		data := &domain.AuthRequest{Email: "gini@mail.com", Password: ""}
+		data := &domain.AuthRequest{Email: "gini@mail.com", Password: "123456", IP: "127.0.0.1"}
 		b, err := uc.FindByCredentials(ctx, data)
-		t.Log(b)
-		t.Log(err)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, httpErrors.ErrBadEmailOrPassword)
+		assert.Nil(t, b)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "unknown email", event.Reason)
+		assert.Equal(t, "gini@mail.com", event.Email)
+		assert.Nil(t, event.UserID)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		guard := mock.NewMockLoginGuardService(mockCtrl)
+		guard.EXPECT().Check(gomock.Any(), "locked@mail.com", "127.0.0.1").
+			Return(&httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: time.Minute})
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(0)
+		uc := NewAuthService(slogger, repo, roles, twoFactor, guard, sessions, events, testHasher, 5*time.Minute, 2*time.Second)
+
+		ctx := context.Background()
+		data := &domain.AuthRequest{Email: "locked@mail.com", Password: "123456", IP: "127.0.0.1"}
+		b, err := uc.FindByCredentials(ctx, data)
+		assert.ErrorIs(t, err, httpErrors.ErrAccountLocked)
+		assert.Nil(t, b)
+		assert.Equal(t, domain.AuthOutcomeBlocked, lastEvent(t, recorded).Outcome)
+	})
+}
+
+func TestRegisterService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewAuthService(slogger, repo, roles, mock.NewMockTwoFactorService(mockCtrl), mock.NewMockLoginGuardService(mockCtrl), mock.NewMockSessionService(mockCtrl), events, testHasher, 5*time.Minute, 2*time.Second)
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().Register(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *domain.RegisterRequest) error {
+				match, rehash, err := testHasher.Verify("1234567", req.Password)
+				assert.NoError(t, err)
+				assert.True(t, match)
+				assert.False(t, rehash)
+				return nil
+			})
+		repo.EXPECT().FindByCredentials(gomock.Any(), &domain.AuthRequest{Email: "gini@mail.com"}).Return(&domain.User{ID: "3", Email: "gini@mail.com"}, nil)
+
+		err := uc.Register(context.Background(), &domain.RegisterRequest{Email: "gini@mail.com", Password: "1234567", Name: "gini1234", IP: "127.0.0.1"})
+		assert.NoError(t, err)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventSignUp, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, 3, *event.UserID)
+		assert.Equal(t, "127.0.0.1", event.IP)
+	})
+
+	t.Run("Already exists", func(t *testing.T) {
+		repo.EXPECT().Register(gomock.Any(), gomock.Any()).Return(httpErrors.ErrAlreadyExists)
+
+		err := uc.Register(context.Background(), &domain.RegisterRequest{Email: "gini@mail.com", Password: "1234567", Name: "gini1234"})
+		assert.ErrorIs(t, err, httpErrors.ErrAlreadyExists)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "already exists", event.Reason)
+	})
+}
+
+func TestCreateAdmin(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewAuthService(slogger, repo, roles, mock.NewMockTwoFactorService(mockCtrl), mock.NewMockLoginGuardService(mockCtrl), mock.NewMockSessionService(mockCtrl), events, testHasher, 5*time.Minute, 2*time.Second)
+	form := func() *domain.RegisterRequest {
+		return &domain.RegisterRequest{Email: "admin@mail.com", Password: "1234567", Name: "admin123"}
+	}
+
+	t.Run("Promote existing user", func(t *testing.T) {
+		roles.EXPECT().CountUsersWithRole(gomock.Any(), domain.RoleAdmin).Return(0, nil)
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{ID: "7", Email: "admin@mail.com"}, nil)
+		repo.EXPECT().Register(gomock.Any(), gomock.Any()).Times(0)
+		roles.EXPECT().AssignRole(gomock.Any(), 7, domain.RoleAdmin).Return(nil)
+
+		assert.NoError(t, uc.CreateAdmin(context.Background(), form()))
+	})
+
+	t.Run("Register new user", func(t *testing.T) {
+		roles.EXPECT().CountUsersWithRole(gomock.Any(), domain.RoleAdmin).Return(0, nil)
+		gomock.InOrder(
+			repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrUserNotFound),
+			repo.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil),
+			repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{ID: "8", Email: "admin@mail.com"}, nil).Times(2),
+		)
+		roles.EXPECT().AssignRole(gomock.Any(), 8, domain.RoleAdmin).Return(nil)
+
+		assert.NoError(t, uc.CreateAdmin(context.Background(), form()))
+	})
+
+	t.Run("Admin already exists", func(t *testing.T) {
+		roles.EXPECT().CountUsersWithRole(gomock.Any(), domain.RoleAdmin).Return(1, nil)
+
+		err := uc.CreateAdmin(context.Background(), form())
+		assert.ErrorIs(t, err, httpErrors.ErrAdminAlreadyExists)
+	})
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	twoFactor := mock.NewMockTwoFactorService(mockCtrl)
+	guard := mock.NewMockLoginGuardService(mockCtrl)
+	guard.EXPECT().Check(gomock.Any(), "gini@mail.com", gomock.Any()).Return(nil).AnyTimes()
+	sessions := mock.NewMockSessionService(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewAuthService(slogger, repo, roles, twoFactor, guard, sessions, events, testHasher, 5*time.Minute, 2*time.Second)
+	hash, _ := testHasher.Hash("123456")
+	ctx := context.Background()
+
+	var challenge string
+	t.Run("Sign in returns a challenge", func(t *testing.T) {
+		repo.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(true, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), gomock.Any()).Times(0)
+		guard.EXPECT().Succeed(gomock.Any(), gomock.Any()).Times(0)
+
+		b, err := uc.FindByCredentials(ctx, &domain.AuthRequest{Email: "gini@mail.com", Password: "123456"})
+		assert.NoError(t, err)
+		assert.Empty(t, b.Token)
+		assert.True(t, b.TwoFactorRequired)
+		assert.NotEmpty(t, b.ChallengeToken)
+		challenge = b.ChallengeToken
+		assert.Equal(t, "2fa required", lastEvent(t, recorded).Reason)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		twoFactor.EXPECT().Verify(gomock.Any(), 1, "000000").Return(httpErrors.ErrInvalidTwoFactorCode)
+		guard.EXPECT().Fail(gomock.Any(), "gini@mail.com", gomock.Any()).Times(1)
+
+		b, err := uc.CompleteTwoFactor(ctx, &domain.TwoFactorLoginRequest{ChallengeToken: challenge, Code: "000000"})
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidTwoFactorCode)
+		assert.Nil(t, b)
+		assert.Equal(t, domain.AuthOutcomeFailure, lastEvent(t, recorded).Outcome)
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		twoFactor.EXPECT().Verify(gomock.Any(), 1, "123456").Return(nil)
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil)
+		sessions.EXPECT().Create(gomock.Any(), 1, gomock.Any(), "Mozilla/5.0").Return(&domain.UserSession{ID: 1}, "refresh", nil)
+		guard.EXPECT().Succeed(gomock.Any(), "gini@mail.com").Times(1)
+
+		b, err := uc.CompleteTwoFactor(ctx, &domain.TwoFactorLoginRequest{ChallengeToken: challenge, Code: "123456", UserAgent: "Mozilla/5.0"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, b.Token)
+		assert.Equal(t, "refresh", b.RefreshToken)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, "2fa", event.Reason)
+	})
+
+	t.Run("Access token is not a challenge", func(t *testing.T) {
+		token, err := utils.GenerateJWT(&domain.User{ID: "1"}, 0)
+		assert.NoError(t, err)
+
+		b, err := uc.CompleteTwoFactor(ctx, &domain.TwoFactorLoginRequest{ChallengeToken: token, Code: "123456"})
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidChallengeToken)
+		assert.Nil(t, b)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	sessions := mock.NewMockSessionService(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewAuthService(slogger, repo, roles, mock.NewMockTwoFactorService(mockCtrl), mock.NewMockLoginGuardService(mockCtrl), sessions, events, testHasher, 5*time.Minute, 2*time.Second)
+	ctx := context.Background()
+	data := &domain.RefreshRequest{RefreshToken: "old", IP: "127.0.0.1", UserAgent: "Mozilla/5.0"}
+
+	t.Run("OK", func(t *testing.T) {
+		sessions.EXPECT().Rotate(gomock.Any(), "old", "127.0.0.1", "Mozilla/5.0").Return(&domain.UserSession{ID: 7, UserID: 1}, "new", nil)
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil)
+
+		b, err := uc.Refresh(ctx, data)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, b.Token)
+		assert.Equal(t, "new", b.RefreshToken)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventTokenRefresh, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, 1, *event.UserID)
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		sessions.EXPECT().Rotate(gomock.Any(), "old", gomock.Any(), gomock.Any()).Return(nil, "", httpErrors.ErrInvalidRefreshToken)
+		repo.EXPECT().FindByID(gomock.Any(), gomock.Any()).Times(0)
+
+		b, err := uc.Refresh(ctx, data)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+		assert.Nil(t, b)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Nil(t, event.UserID)
+	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		sessions.EXPECT().Rotate(gomock.Any(), "old", gomock.Any(), gomock.Any()).Return(&domain.UserSession{ID: 7, UserID: 1}, "new", nil)
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Suspended: true}, nil)
+		sessions.EXPECT().Revoke(gomock.Any(), 1, 7).Return(nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), gomock.Any()).Times(0)
+
+		b, err := uc.Refresh(ctx, data)
+		assert.ErrorIs(t, err, httpErrors.ErrUserSuspended)
+		assert.Nil(t, b)
+		assert.Equal(t, domain.AuthOutcomeBlocked, lastEvent(t, recorded).Outcome)
+	})
+
+	t.Run("Deleted User", func(t *testing.T) {
+		sessions.EXPECT().Rotate(gomock.Any(), "old", gomock.Any(), gomock.Any()).Return(&domain.UserSession{ID: 7, UserID: 1}, "new", nil)
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(nil, httpErrors.ErrUserNotFound)
+
+		b, err := uc.Refresh(ctx, data)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+		assert.Nil(t, b)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockAuthRepository(mockCtrl)
+	guard := mock.NewMockLoginGuardService(mockCtrl)
+	sessions := mock.NewMockSessionService(mockCtrl)
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewAuthService(slogger, repo, mock.NewMockRoleRepository(mockCtrl), mock.NewMockTwoFactorService(mockCtrl), guard, sessions, events, testHasher, 5*time.Minute, 2*time.Second)
+	hash, _ := testHasher.Hash("123456")
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "127.0.0.1").Return(nil)
+		guard.EXPECT().Succeed(gomock.Any(), "gini@mail.com")
+		repo.EXPECT().UpdatePassword(gomock.Any(), "1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, hash string) error {
+				match, _, err := testHasher.Verify("abcdef", hash)
+				assert.NoError(t, err)
+				assert.True(t, match)
+				return nil
+			})
+		// the session of the request stays, the others are signed out
+		sessions.EXPECT().RevokeOthers(gomock.Any(), 1, 9).Return(nil)
+
+		err := uc.ChangePassword(ctx, 1, &domain.ChangePasswordRequest{CurrentPassword: "123456", NewPassword: "abcdef", IP: "127.0.0.1", SessionID: 9})
+		assert.NoError(t, err)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventPasswordChange, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, 1, *event.UserID)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "127.0.0.1").Return(nil)
+		guard.EXPECT().Fail(gomock.Any(), "gini@mail.com", "127.0.0.1")
+		repo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		sessions.EXPECT().RevokeOthers(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.ChangePassword(ctx, 1, &domain.ChangePasswordRequest{CurrentPassword: "nope", NewPassword: "abcdef", IP: "127.0.0.1"})
+		assert.ErrorIs(t, err, httpErrors.ErrBadPassword)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "bad password", event.Reason)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com", Password: hash}, nil)
+		guard.EXPECT().Check(gomock.Any(), "gini@mail.com", "127.0.0.1").
+			Return(&httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: time.Minute})
+		repo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := uc.ChangePassword(ctx, 1, &domain.ChangePasswordRequest{CurrentPassword: "123456", NewPassword: "abcdef", IP: "127.0.0.1"})
+		var retry *httpErrors.RetryAfterError
+		assert.ErrorAs(t, err, &retry)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeBlocked, event.Outcome)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), 2).Return(nil, httpErrors.ErrUserNotFound)
+
+		err := uc.ChangePassword(ctx, 2, &domain.ChangePasswordRequest{CurrentPassword: "123456", NewPassword: "abcdef"})
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
 	})
 }
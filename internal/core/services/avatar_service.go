@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/imaging"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"time"
+)
+
+// avatarPrefix the storage keys of the profile photos start with it
+const avatarPrefix = "avatars/"
+
+// avatarSizes the thumbnails of a profile photo, in pixels
+var avatarSizes = struct{ Small, Medium, Large int }{Small: 64, Medium: 256, Large: 512}
+
+var _ svcport.AvatarService = (*AvatarService)(nil)
+
+// AvatarService struct, stores the profile photos. The photo column keeps the
+// base key, each thumbnail is stored next to it with its size as suffix.
+type AvatarService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.UserRepository
+	storage        svcport.Storage
+	maxSize        int64
+	urlTTL         time.Duration
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewAvatarService creates a new avatar service
+func NewAvatarService(logger *zap.SugaredLogger, repo repport.UserRepository, storage svcport.Storage, maxSize int64, urlTTL time.Duration, timeout time.Duration) *AvatarService {
+	return &AvatarService{
+		logger:         logger,
+		repository:     repo,
+		storage:        storage,
+		maxSize:        maxSize,
+		urlTTL:         urlTTL,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// Upload replaces the profile photo with the thumbnails of the image
+func (svc *AvatarService) Upload(c context.Context, uid int, file io.Reader) (*domain.Avatar, error) {
+	data, err := io.ReadAll(io.LimitReader(file, svc.maxSize+1))
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.ErrInvalidRequestBody
+	}
+	if int64(len(data)) > svc.maxSize {
+		return nil, httpErrors.ErrAvatarTooLarge
+	}
+
+	// Decoding happens before the timeout starts, it is CPU bound
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	sizes := []int{avatarSizes.Small, avatarSizes.Medium, avatarSizes.Large}
+	thumbs := img.Thumbnails(sizes...)
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	profile, err := svc.repository.GetProfile(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	id, err := randomURLString(12)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	contentType := img.OutputType()
+	ext := ".png"
+	if contentType == imaging.JPEG {
+		ext = ".jpg"
+	}
+	photo := fmt.Sprintf("%s%d/%s%s", avatarPrefix, uid, id, ext)
+
+	var stored []string
+	for n, thumb := range thumbs {
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, thumb, contentType); err != nil {
+			svc.logger.Error(err.Error())
+			svc.deleteKeys(stored)
+			return nil, httpErrors.InternalServerError
+		}
+		key := avatarKey(photo, sizes[n])
+		if err := svc.storage.Put(ctx, key, &buf, int64(buf.Len()), contentType); err != nil {
+			svc.deleteKeys(stored)
+			return nil, svc.handleError(ctx, err)
+		}
+		stored = append(stored, key)
+	}
+
+	if err := svc.repository.UpdatePhoto(ctx, uid, photo); err != nil {
+		svc.deleteKeys(stored)
+		return nil, svc.handleError(ctx, err)
+	}
+	if isAvatar(profile.UserPhoto) {
+		svc.deleteKeys(avatarKeys(profile.UserPhoto))
+	}
+
+	return svc.signedAvatar(ctx, photo)
+}
+
+// Get returns the signed URLs of the profile photo
+func (svc *AvatarService) Get(c context.Context, uid int) (*domain.Avatar, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	profile, err := svc.repository.GetProfile(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if !isAvatar(profile.UserPhoto) {
+		return nil, httpErrors.ErrAvatarNotFound
+	}
+
+	return svc.signedAvatar(ctx, profile.UserPhoto)
+}
+
+func (svc *AvatarService) signedAvatar(ctx context.Context, photo string) (*domain.Avatar, error) {
+	var avatar = &domain.Avatar{ExpiresAt: svc.now().Add(svc.urlTTL).UTC().Truncate(time.Second)}
+	for _, field := range []struct {
+		dst  *string
+		size int
+	}{
+		{&avatar.Small, avatarSizes.Small},
+		{&avatar.Medium, avatarSizes.Medium},
+		{&avatar.Large, avatarSizes.Large},
+	} {
+		u, err := svc.storage.SignedURL(ctx, avatarKey(photo, field.size), svc.urlTTL)
+		if err != nil {
+			return nil, svc.handleError(ctx, err)
+		}
+		*field.dst = u
+	}
+
+	return avatar, nil
+}
+
+// deleteKeys removes files that are no longer referenced, failures only leave orphans
+func (svc *AvatarService) deleteKeys(keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), svc.contextTimeOut)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := svc.storage.Delete(ctx, key); err != nil {
+			svc.logger.Warnw("failed to delete the file", "key", key, "error", err)
+		}
+	}
+}
+
+// handleError maps repository and storage errors to service errors
+func (svc *AvatarService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return httpErrors.ErrUserNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+// isAvatar whether the photo is an upload and not the default photo
+func isAvatar(photo string) bool {
+	return strings.HasPrefix(photo, avatarPrefix)
+}
+
+// avatarKey the key of the thumbnail of the given size, avatars/1/abc.jpg becomes avatars/1/abc_64.jpg
+func avatarKey(photo string, size int) string {
+	dot := strings.LastIndex(photo, ".")
+	if dot < 0 {
+		return fmt.Sprintf("%s_%d", photo, size)
+	}
+	return fmt.Sprintf("%s_%d%s", photo[:dot], size, photo[dot:])
+}
+
+func avatarKeys(photo string) []string {
+	return []string{
+		avatarKey(photo, avatarSizes.Small),
+		avatarKey(photo, avatarSizes.Medium),
+		avatarKey(photo, avatarSizes.Large),
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"image"
+	"image/color"
+	"image/png"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func samplePNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestAvatarService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockUserRepository(mockCtrl)
+	storage := mock.NewMockStorage(mockCtrl)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewAvatarService(slogger, repo, storage, 1<<20, 15*time.Minute, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	signed := func(_ context.Context, key string, _ time.Duration) (string, error) {
+		return "http://localhost/v1/files/" + key, nil
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		var keys []string
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserPhoto: "avatars/1/old.png"}, nil)
+		storage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").Times(3).
+			DoAndReturn(func(_ context.Context, key string, _ interface{}, size int64, _ string) error {
+				keys = append(keys, key)
+				assert.Greater(t, size, int64(0))
+				return nil
+			})
+		repo.EXPECT().UpdatePhoto(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, photo string) error {
+			assert.True(t, strings.HasPrefix(photo, "avatars/1/"))
+			assert.True(t, strings.HasSuffix(photo, ".png"))
+			return nil
+		})
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/old_64.png").Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/old_256.png").Return(nil)
+		storage.EXPECT().Delete(gomock.Any(), "avatars/1/old_512.png").Return(errors.New("gone"))
+		storage.EXPECT().SignedURL(gomock.Any(), gomock.Any(), 15*time.Minute).Times(3).DoAndReturn(signed)
+
+		avatar, err := uc.Upload(ctx, 1, bytes.NewReader(samplePNG(t, 600, 400)))
+		assert.NoError(t, err)
+		assert.Len(t, keys, 3)
+		assert.Equal(t, "http://localhost/v1/files/"+keys[0], avatar.Small)
+		assert.True(t, strings.HasSuffix(avatar.Large, "_512.png"))
+		assert.Equal(t, now.Add(15*time.Minute), avatar.ExpiresAt)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		small := NewAvatarService(slogger, repo, storage, 16, time.Minute, 2*time.Second)
+
+		_, err := small.Upload(ctx, 1, bytes.NewReader(samplePNG(t, 8, 8)))
+		assert.ErrorIs(t, err, httpErrors.ErrAvatarTooLarge)
+	})
+
+	t.Run("Not An Image", func(t *testing.T) {
+		_, err := uc.Upload(ctx, 1, strings.NewReader("GIF89a not really"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Storage Failure Rolls Back", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1}, nil)
+		gomock.InOrder(
+			storage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+			storage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("disk full")),
+		)
+		storage.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		repo.EXPECT().UpdatePhoto(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.Upload(ctx, 1, bytes.NewReader(samplePNG(t, 100, 100)))
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserPhoto: "avatars/1/abc.jpg"}, nil)
+		storage.EXPECT().SignedURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).DoAndReturn(signed)
+
+		avatar, err := uc.Get(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost/v1/files/avatars/1/abc_256.jpg", avatar.Medium)
+	})
+
+	t.Run("Get Without Upload", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserPhoto: "default.png"}, nil)
+
+		_, err := uc.Get(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.ErrAvatarNotFound)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 2).Return(nil, httpErrors.ErrUserNotFound)
+
+		_, err := uc.Get(ctx, 2)
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+)
+
+// publishEvent publishes a domain event. The change is already stored, so a
+// failure is only logged.
+func publishEvent(logger *zap.SugaredLogger, publisher svcport.EventPublisher, eventType string, data any, userIDs ...int) {
+	event, err := domain.NewEvent(eventType, data, userIDs...)
+	if err == nil {
+		err = publisher.PublishEvent(event.Subject(), event)
+	}
+	if err != nil {
+		logger.Errorw("failed to publish event", "type", eventType, "error", err.Error())
+	}
+}
+
+// publishSecurityAlert tells the owner of the account about the auth event
+func publishSecurityAlert(logger *zap.SugaredLogger, publisher svcport.EventPublisher, event *domain.AuthEvent) {
+	if !event.Alert() {
+		return
+	}
+	publishEvent(logger, publisher, domain.EventSecurityAlert, domain.SecurityAlertData{
+		Event:     event.Event,
+		Outcome:   event.Outcome,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+	}, *event.UserID)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ svcport.LoginGuardService = (*LoginGuardService)(nil)
+
+// LoginGuardService struct, counts failed sign ins per account and per IP.
+// Redis errors are logged and the attempt is allowed, an outage of the
+// counters must not block every sign in.
+type LoginGuardService struct {
+	logger         *zap.SugaredLogger
+	attempts       repport.LoginAttemptRepository
+	users          repport.AuthRepository
+	events         repport.AuthEventRepository
+	mailer         svcport.Mailer
+	publisher      svcport.EventPublisher
+	cfg            domain.LoginProtection
+	contextTimeOut time.Duration
+}
+
+// NewLoginGuardService creates a new login guard service
+func NewLoginGuardService(logger *zap.SugaredLogger, attempts repport.LoginAttemptRepository, users repport.AuthRepository, events repport.AuthEventRepository, mailer svcport.Mailer, publisher svcport.EventPublisher, cfg domain.LoginProtection, timeout time.Duration) *LoginGuardService {
+	return &LoginGuardService{
+		logger:         logger,
+		attempts:       attempts,
+		users:          users,
+		events:         events,
+		mailer:         mailer,
+		publisher:      publisher,
+		cfg:            cfg,
+		contextTimeOut: timeout,
+	}
+}
+
+// Check rejects the sign in while the account or the IP is locked or in backoff
+func (svc *LoginGuardService) Check(c context.Context, email string, ip string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	account, err := svc.attempts.Status(ctx, accountSubject(email))
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil
+	}
+	if account.LockedFor > 0 {
+		return &httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: account.LockedFor}
+	}
+
+	client, err := svc.attempts.Status(ctx, ipSubject(ip))
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil
+	}
+	if account.Blocked() || client.Blocked() {
+		retry := account.RetryAfter()
+		if client.RetryAfter() > retry {
+			retry = client.RetryAfter()
+		}
+		return &httpErrors.RetryAfterError{Err: httpErrors.ErrTooManyAttempts, RetryAfter: retry}
+	}
+
+	return nil
+}
+
+// Fail registers a failed sign in. The account gets an exponential backoff once
+// it passes the threshold and both the account and the IP get locked at their limit.
+func (svc *LoginGuardService) Fail(c context.Context, email string, ip string) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	subject := accountSubject(email)
+	failures, err := svc.attempts.RegisterFailure(ctx, subject, svc.seconds(svc.cfg.AttemptsWindow))
+	if err != nil {
+		svc.logger.Error(err.Error())
+	} else if failures >= int64(svc.cfg.MaxAccountAttempts) {
+		svc.lockAccount(ctx, email, ip, failures)
+	} else if delay := svc.backoff(failures); delay > 0 {
+		if err := svc.attempts.Delay(ctx, subject, delay); err != nil {
+			svc.logger.Error(err.Error())
+		}
+	}
+
+	subject = ipSubject(ip)
+	failures, err = svc.attempts.RegisterFailure(ctx, subject, svc.seconds(svc.cfg.AttemptsWindow))
+	if err != nil {
+		svc.logger.Error(err.Error())
+	} else if failures >= int64(svc.cfg.MaxIPAttempts) {
+		locked, err := svc.attempts.Lock(ctx, subject, svc.seconds(svc.cfg.LockoutDuration))
+		if err != nil {
+			svc.logger.Error(err.Error())
+		} else if locked {
+			svc.record(ctx, &domain.AuthEvent{
+				IP:      ip,
+				Event:   domain.AuthEventIPLocked,
+				Outcome: domain.AuthOutcomeBlocked,
+				Reason:  fmt.Sprintf("%d failed sign in attempts", failures),
+			})
+		}
+	}
+}
+
+// Succeed clears the counters of the account. The IP counters are kept, a
+// valid account must not reset the budget of an attacker.
+func (svc *LoginGuardService) Succeed(c context.Context, email string) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.attempts.Reset(ctx, accountSubject(email)); err != nil {
+		svc.logger.Error(err.Error())
+	}
+}
+
+// Unlock lifts the lockout of the account that received the token
+func (svc *LoginGuardService) Unlock(c context.Context, token string, ip string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	subject, err := svc.attempts.ConsumeUnlockToken(ctx, hashUnlockToken(token))
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrInvalidUnlockToken) {
+			return httpErrors.ErrInvalidUnlockToken
+		}
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+	if err := svc.attempts.Reset(ctx, subject); err != nil {
+		svc.logger.Error(err.Error())
+		return httpErrors.InternalServerError
+	}
+
+	email := strings.TrimPrefix(subject, "account:")
+	event := &domain.AuthEvent{
+		Email:   email,
+		IP:      ip,
+		Event:   domain.AuthEventAccountUnlocked,
+		Outcome: domain.AuthOutcomeSuccess,
+		Reason:  "unlock link",
+	}
+	if user, err := svc.users.FindByCredentials(ctx, &domain.AuthRequest{Email: email}); err == nil {
+		event.UserID = userID(user)
+	}
+	svc.record(ctx, event)
+
+	return nil
+}
+
+// lockAccount locks the account and emails the unlock link, only once per lockout
+func (svc *LoginGuardService) lockAccount(ctx context.Context, email string, ip string, failures int64) {
+	lockout := svc.seconds(svc.cfg.LockoutDuration)
+	locked, err := svc.attempts.Lock(ctx, accountSubject(email), lockout)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return
+	}
+	if !locked {
+		return
+	}
+
+	event := &domain.AuthEvent{
+		Email:   email,
+		IP:      ip,
+		Event:   domain.AuthEventAccountLocked,
+		Outcome: domain.AuthOutcomeBlocked,
+		Reason:  fmt.Sprintf("%d failed sign in attempts", failures),
+	}
+	defer svc.record(ctx, event)
+
+	// Unknown emails are locked too, so the response doesn't reveal if the account exists
+	user, err := svc.users.FindByCredentials(ctx, &domain.AuthRequest{Email: email})
+	if err != nil {
+		if !errors.Is(err, httpErrors.ErrUserNotFound) {
+			svc.logger.Error(err.Error())
+		}
+		return
+	}
+	event.UserID = userID(user)
+
+	token, err := newUnlockToken()
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return
+	}
+	if err := svc.attempts.SaveUnlockToken(ctx, hashUnlockToken(token), accountSubject(email), lockout); err != nil {
+		svc.logger.Error(err.Error())
+		return
+	}
+
+	link := svc.cfg.UnlockURL + "?token=" + url.QueryEscape(token)
+	err = svc.mailer.Send(ctx, &domain.Email{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("We blocked the sign in to your account after %d failed attempts.\n\n"+
+			"It unlocks automatically in %s, or you can unlock it now with this link:\n%s\n\n"+
+			"If it wasn't you, change your password after signing in.", failures, lockout, link),
+	})
+	if err != nil {
+		svc.logger.Errorw("failed to send the unlock email", "email", email, "error", err)
+	}
+}
+
+// backoff the delay after the failure number n, it doubles from the threshold on
+func (svc *LoginGuardService) backoff(n int64) time.Duration {
+	if svc.cfg.BackoffThreshold <= 0 || n < int64(svc.cfg.BackoffThreshold) {
+		return 0
+	}
+	max := svc.seconds(svc.cfg.BackoffMax)
+	delay := svc.seconds(svc.cfg.BackoffBase)
+	for i := int64(svc.cfg.BackoffThreshold); i < n && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// record writes the auth event, a failure is only logged
+func (svc *LoginGuardService) record(ctx context.Context, event *domain.AuthEvent) {
+	if err := svc.events.Create(ctx, event); err != nil {
+		svc.logger.Errorw("failed to record auth event", "event", event.Event, "email", event.Email, "error", err)
+	}
+	publishSecurityAlert(svc.logger, svc.publisher, event)
+}
+
+func (svc *LoginGuardService) seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+func userID(user *domain.User) *int {
+	uid, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return nil
+	}
+	return &uid
+}
+
+// newUnlockToken random token sent by email, only its hash is stored
+func newUnlockToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
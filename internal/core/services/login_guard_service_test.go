@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLoginProtection = domain.LoginProtection{
+	MaxAccountAttempts: 10,
+	MaxIPAttempts:      100,
+	BackoffThreshold:   3,
+	BackoffBase:        1,
+	BackoffMax:         60,
+	AttemptsWindow:     900,
+	LockoutDuration:    900,
+	UnlockURL:          "http://localhost:8080/v1/auth/unlock",
+}
+
+type loginGuardMocks struct {
+	attempts  *mock.MockLoginAttemptRepository
+	users     *mock.MockAuthRepository
+	events    *mock.MockAuthEventRepository
+	mailer    *mock.MockMailer
+	publisher *mock.MockEventPublisher
+}
+
+func newTestLoginGuard(t *testing.T) (*LoginGuardService, *loginGuardMocks) {
+	logger, _ := zap.NewProduction()
+	mockCtrl := gomock.NewController(t)
+	m := &loginGuardMocks{
+		attempts:  mock.NewMockLoginAttemptRepository(mockCtrl),
+		users:     mock.NewMockAuthRepository(mockCtrl),
+		events:    mock.NewMockAuthEventRepository(mockCtrl),
+		mailer:    mock.NewMockMailer(mockCtrl),
+		publisher: mock.NewMockEventPublisher(mockCtrl),
+	}
+	svc := NewLoginGuardService(logger.Sugar(), m.attempts, m.users, m.events, m.mailer, m.publisher, testLoginProtection, 2*time.Second)
+	return svc, m
+}
+
+func TestLoginGuardCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Allowed", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().Status(gomock.Any(), "account:gini@mail.com").Return(&domain.LoginAttemptStatus{Failures: 2}, nil)
+		m.attempts.EXPECT().Status(gomock.Any(), "ip:127.0.0.1").Return(&domain.LoginAttemptStatus{Failures: 2}, nil)
+
+		assert.NoError(t, svc.Check(ctx, "Gini@mail.com", "127.0.0.1"))
+	})
+
+	t.Run("Account Locked", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().Status(gomock.Any(), "account:gini@mail.com").Return(&domain.LoginAttemptStatus{LockedFor: time.Minute}, nil)
+
+		err := svc.Check(ctx, "gini@mail.com", "127.0.0.1")
+		assert.ErrorIs(t, err, httpErrors.ErrAccountLocked)
+		var retry *httpErrors.RetryAfterError
+		assert.True(t, errors.As(err, &retry))
+		assert.Equal(t, time.Minute, retry.RetryAfter)
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().Status(gomock.Any(), "account:gini@mail.com").Return(&domain.LoginAttemptStatus{DelayedFor: 2 * time.Second}, nil)
+		m.attempts.EXPECT().Status(gomock.Any(), "ip:127.0.0.1").Return(&domain.LoginAttemptStatus{}, nil)
+
+		err := svc.Check(ctx, "gini@mail.com", "127.0.0.1")
+		assert.ErrorIs(t, err, httpErrors.ErrTooManyAttempts)
+	})
+
+	t.Run("IP Locked", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().Status(gomock.Any(), "account:gini@mail.com").Return(&domain.LoginAttemptStatus{}, nil)
+		m.attempts.EXPECT().Status(gomock.Any(), "ip:127.0.0.1").Return(&domain.LoginAttemptStatus{LockedFor: time.Hour}, nil)
+
+		err := svc.Check(ctx, "gini@mail.com", "127.0.0.1")
+		assert.ErrorIs(t, err, httpErrors.ErrTooManyAttempts)
+	})
+
+	t.Run("Redis Down", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().Status(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		assert.NoError(t, svc.Check(ctx, "gini@mail.com", "127.0.0.1"))
+	})
+}
+
+func TestLoginGuardFail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Below Threshold", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "account:gini@mail.com", 900*time.Second).Return(int64(2), nil)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", 900*time.Second).Return(int64(2), nil)
+		m.attempts.EXPECT().Delay(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		svc.Fail(ctx, "gini@mail.com", "127.0.0.1")
+	})
+
+	t.Run("Exponential Backoff", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "account:gini@mail.com", gomock.Any()).Return(int64(5), nil)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", gomock.Any()).Return(int64(5), nil)
+		m.attempts.EXPECT().Delay(gomock.Any(), "account:gini@mail.com", 4*time.Second).Return(nil)
+
+		svc.Fail(ctx, "gini@mail.com", "127.0.0.1")
+	})
+
+	t.Run("Lockout", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "account:gini@mail.com", gomock.Any()).Return(int64(10), nil)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", gomock.Any()).Return(int64(10), nil)
+		m.attempts.EXPECT().Lock(gomock.Any(), "account:gini@mail.com", 900*time.Second).Return(true, nil)
+		m.users.EXPECT().FindByCredentials(gomock.Any(), &domain.AuthRequest{Email: "gini@mail.com"}).
+			Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+
+		var tokenHash string
+		m.attempts.EXPECT().SaveUnlockToken(gomock.Any(), gomock.Any(), "account:gini@mail.com", 900*time.Second).
+			DoAndReturn(func(_ context.Context, hash string, _ string, _ time.Duration) error {
+				tokenHash = hash
+				return nil
+			})
+		m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, email *domain.Email) error {
+			assert.Equal(t, "gini@mail.com", email.To)
+			i := strings.Index(email.Body, testLoginProtection.UnlockURL)
+			assert.NotEqual(t, -1, i)
+			link, err := url.Parse(strings.Fields(email.Body[i:])[0])
+			assert.NoError(t, err)
+			assert.Equal(t, tokenHash, hashUnlockToken(link.Query().Get("token")))
+			return nil
+		})
+		m.events.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *domain.AuthEvent) error {
+			assert.Equal(t, domain.AuthEventAccountLocked, event.Event)
+			assert.Equal(t, domain.AuthOutcomeBlocked, event.Outcome)
+			assert.Equal(t, 1, *event.UserID)
+			assert.Equal(t, "127.0.0.1", event.IP)
+			return nil
+		})
+		m.publisher.EXPECT().PublishEvent("events.security.alert", gomock.Any()).DoAndReturn(func(_ string, event any) error {
+			e := event.(*domain.Event)
+			assert.Equal(t, []int{1}, e.UserIDs)
+			assert.JSONEq(t, `{"event":"account.locked","outcome":"blocked","ip":"127.0.0.1"}`, string(e.Data))
+			return nil
+		})
+
+		svc.Fail(ctx, "gini@mail.com", "127.0.0.1")
+	})
+
+	t.Run("Already Locked", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(11), nil)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(11), nil)
+		m.attempts.EXPECT().Lock(gomock.Any(), "account:gini@mail.com", gomock.Any()).Return(false, nil)
+		m.mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+		m.events.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		svc.Fail(ctx, "gini@mail.com", "127.0.0.1")
+	})
+
+	t.Run("IP Lockout", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "account:gini@mail.com", gomock.Any()).Return(int64(1), nil)
+		m.attempts.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", gomock.Any()).Return(int64(100), nil)
+		m.attempts.EXPECT().Lock(gomock.Any(), "ip:127.0.0.1", 900*time.Second).Return(true, nil)
+		m.events.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *domain.AuthEvent) error {
+			assert.Equal(t, domain.AuthEventIPLocked, event.Event)
+			assert.Nil(t, event.UserID)
+			return nil
+		})
+
+		svc.Fail(ctx, "gini@mail.com", "127.0.0.1")
+	})
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	svc, _ := newTestLoginGuard(t)
+
+	assert.Equal(t, time.Duration(0), svc.backoff(2))
+	assert.Equal(t, 1*time.Second, svc.backoff(3))
+	assert.Equal(t, 2*time.Second, svc.backoff(4))
+	assert.Equal(t, 32*time.Second, svc.backoff(8))
+	assert.Equal(t, 60*time.Second, svc.backoff(9))
+	assert.Equal(t, 60*time.Second, svc.backoff(50))
+}
+
+func TestLoginGuardUnlock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().ConsumeUnlockToken(gomock.Any(), hashUnlockToken("token")).Return("account:gini@mail.com", nil)
+		m.attempts.EXPECT().Reset(gomock.Any(), "account:gini@mail.com").Return(nil)
+		m.users.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		m.events.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		m.publisher.EXPECT().PublishEvent("events.security.alert", gomock.Any()).Return(nil)
+
+		assert.NoError(t, svc.Unlock(ctx, "token", "127.0.0.1"))
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		svc, m := newTestLoginGuard(t)
+		m.attempts.EXPECT().ConsumeUnlockToken(gomock.Any(), gomock.Any()).Return("", httpErrors.ErrInvalidUnlockToken)
+		m.attempts.EXPECT().Reset(gomock.Any(), gomock.Any()).Times(0)
+
+		assert.ErrorIs(t, svc.Unlock(ctx, "token", "127.0.0.1"), httpErrors.ErrInvalidUnlockToken)
+	})
+}
@@ -16,14 +16,16 @@ var _ svcport.MarketBondsService = (*MarketBondsService)(nil)
 type MarketBondsService struct {
 	logger         *zap.SugaredLogger
 	repository     repport.MarketBondRepository
+	publisher      svcport.EventPublisher
 	contextTimeOut time.Duration
 }
 
 // NewMarketBondsService creates a new auth service
-func NewMarketBondsService(logger *zap.SugaredLogger, repo repport.MarketBondRepository, timeout time.Duration) *MarketBondsService {
+func NewMarketBondsService(logger *zap.SugaredLogger, repo repport.MarketBondRepository, publisher svcport.EventPublisher, timeout time.Duration) *MarketBondsService {
 	return &MarketBondsService{
 		logger:         logger,
 		repository:     repo,
+		publisher:      publisher,
 		contextTimeOut: timeout,
 	}
 }
@@ -114,8 +116,10 @@ func (svc *MarketBondsService) BuyMarketBond(c context.Context, order *domain.Ma
 	if data.CreatedByID == order.BuyerID {
 		return httpErrors.ErrNoAvailableBonds
 	}
+	// the seller is the owner of the listing, never the one sent by the client
+	order.SellerID = data.CreatedByID
 
-	err = svc.repository.BuyMarketBond(ctx, order)
+	available, err := svc.repository.BuyMarketBond(ctx, order)
 	if err != nil {
 		svc.logger.Error(err.Error())
 
@@ -125,6 +129,8 @@ func (svc *MarketBondsService) BuyMarketBond(c context.Context, order *domain.Ma
 		default:
 			if errors.Is(err, httpErrors.ErrNoRecords) {
 				return httpErrors.ErrNoRecords
+			} else if errors.Is(err, httpErrors.ErrBondFrozen) {
+				return httpErrors.ErrBondFrozen
 			} else if errors.Is(err, httpErrors.ErrExecuteStatement) {
 				return httpErrors.ErrExecuteStatement
 			} else {
@@ -133,6 +139,25 @@ func (svc *MarketBondsService) BuyMarketBond(c context.Context, order *domain.Ma
 		}
 	}
 
+	publishEvent(svc.logger, svc.publisher, domain.EventTradeExecuted, domain.TradeExecutedData{
+		MarketBondID: data.ID,
+		BondUUID:     data.UUID,
+		BondName:     data.Name,
+		Price:        data.Price,
+		Currency:     data.Currency,
+		Quantity:     *order.Order,
+		SellerID:     data.CreatedByID,
+		BuyerID:      order.BuyerID,
+	}, order.BuyerID, data.CreatedByID)
+	if available == 0 {
+		publishEvent(svc.logger, svc.publisher, domain.EventListingSoldOut, domain.ListingSoldOutData{
+			MarketBondID: data.ID,
+			BondUUID:     data.UUID,
+			BondName:     data.Name,
+			SellerID:     data.CreatedByID,
+		}, data.CreatedByID)
+	}
+
 	return nil
 }
 
@@ -153,6 +178,8 @@ func (svc *MarketBondsService) SellMarketBond(c context.Context, data *domain.Ma
 		default:
 			if errors.Is(err, httpErrors.ErrNoRecords) {
 				return httpErrors.ErrNoRecords
+			} else if errors.Is(err, httpErrors.ErrBondFrozen) {
+				return httpErrors.ErrBondFrozen
 			} else if errors.Is(err, httpErrors.ErrExecuteStatement) {
 				return httpErrors.ErrExecuteStatement
 			} else {
@@ -161,5 +188,11 @@ func (svc *MarketBondsService) SellMarketBond(c context.Context, data *domain.Ma
 		}
 	}
 
+	publishEvent(svc.logger, svc.publisher, domain.EventListingCreated, domain.ListingCreatedData{
+		BondID:   *data.BondID,
+		SellerID: data.SellerID,
+		Quantity: *data.Num,
+	}, data.SellerID)
+
 	return nil
 }
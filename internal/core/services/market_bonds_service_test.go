@@ -1 +1,114 @@
 package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestMarketBondsServiceEvents(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockMarketBondRepository(mockCtrl)
+	publisher := mock.NewMockEventPublisher(mockCtrl)
+
+	uc := NewMarketBondsService(slogger, repo, publisher, 2*time.Second)
+	ctx := context.Background()
+	id, num := 7, 3
+
+	t.Run("Buy publishes trade.executed", func(t *testing.T) {
+		order := &domain.MarketBondRequest{MarketBondID: &id, BuyerID: 1, Order: &num}
+		repo.EXPECT().GetMarketBondByID(gomock.Any(), 7).
+			Return(&domain.MarketBond{ID: 7, UUID: "bond-uuid", Name: "Bond", Price: 10.5, Currency: 1, CreatedByID: 2}, nil)
+		repo.EXPECT().BuyMarketBond(gomock.Any(), order).Return(2, nil)
+		publisher.EXPECT().PublishEvent("events.trade.executed", gomock.Any()).
+			DoAndReturn(func(subject string, event any) error {
+				e := event.(*domain.Event)
+				assert.Equal(t, domain.EventTradeExecuted, e.Type)
+				assert.Equal(t, []int{1, 2}, e.UserIDs)
+				assert.JSONEq(t, `{"market_bond_id":7,"bond_uuid":"bond-uuid","bond_name":"Bond","price":10.5,"currency":1,"quantity":3,"seller_id":2,"buyer_id":1}`, string(e.Data))
+				return nil
+			})
+
+		err := uc.BuyMarketBond(ctx, order)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Buy ignores the seller sent by the client", func(t *testing.T) {
+		order := &domain.MarketBondRequest{MarketBondID: &id, SellerID: 99, BuyerID: 1, Order: &num}
+		repo.EXPECT().GetMarketBondByID(gomock.Any(), 7).Return(&domain.MarketBond{ID: 7, CreatedByID: 2}, nil)
+		repo.EXPECT().BuyMarketBond(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, o *domain.MarketBondRequest) (int, error) {
+				assert.Equal(t, 2, o.SellerID)
+				return 2, nil
+			})
+		publisher.EXPECT().PublishEvent("events.trade.executed", gomock.Any()).Return(nil)
+
+		err := uc.BuyMarketBond(ctx, order)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Buy of the last bonds publishes listing.sold_out", func(t *testing.T) {
+		order := &domain.MarketBondRequest{MarketBondID: &id, BuyerID: 1, Order: &num}
+		repo.EXPECT().GetMarketBondByID(gomock.Any(), 7).
+			Return(&domain.MarketBond{ID: 7, UUID: "bond-uuid", Name: "Bond", CreatedByID: 2}, nil)
+		repo.EXPECT().BuyMarketBond(gomock.Any(), order).Return(0, nil)
+		gomock.InOrder(
+			publisher.EXPECT().PublishEvent("events.trade.executed", gomock.Any()).Return(nil),
+			publisher.EXPECT().PublishEvent("events.listing.sold_out", gomock.Any()).
+				DoAndReturn(func(subject string, event any) error {
+					e := event.(*domain.Event)
+					assert.Equal(t, []int{2}, e.UserIDs)
+					assert.JSONEq(t, `{"market_bond_id":7,"bond_uuid":"bond-uuid","bond_name":"Bond","seller_id":2}`, string(e.Data))
+					return nil
+				}),
+		)
+
+		err := uc.BuyMarketBond(ctx, order)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Buy failure publishes nothing", func(t *testing.T) {
+		order := &domain.MarketBondRequest{MarketBondID: &id, BuyerID: 1, Order: &num}
+		repo.EXPECT().GetMarketBondByID(gomock.Any(), 7).Return(&domain.MarketBond{ID: 7, CreatedByID: 2}, nil)
+		repo.EXPECT().BuyMarketBond(gomock.Any(), order).Return(0, httpErrors.ErrBondFrozen)
+
+		err := uc.BuyMarketBond(ctx, order)
+		assert.ErrorIs(t, err, httpErrors.ErrBondFrozen)
+	})
+
+	t.Run("Sell publishes listing.created", func(t *testing.T) {
+		bondID := 4
+		data := &domain.MarketSellRequest{BondID: &bondID, SellerID: 2, Num: &num}
+		repo.EXPECT().SellMarketBond(gomock.Any(), data).Return(nil)
+		publisher.EXPECT().PublishEvent("events.listing.created", gomock.Any()).
+			DoAndReturn(func(subject string, event any) error {
+				e := event.(*domain.Event)
+				assert.Equal(t, []int{2}, e.UserIDs)
+				assert.JSONEq(t, `{"bond_id":4,"seller_id":2,"quantity":3}`, string(e.Data))
+				return nil
+			})
+
+		err := uc.SellMarketBond(ctx, data)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Publish failure keeps the sale", func(t *testing.T) {
+		bondID := 4
+		data := &domain.MarketSellRequest{BondID: &bondID, SellerID: 2, Num: &num}
+		repo.EXPECT().SellMarketBond(gomock.Any(), data).Return(nil)
+		publisher.EXPECT().PublishEvent("events.listing.created", gomock.Any()).Return(errors.New("nats: connection closed"))
+
+		err := uc.SellMarketBond(ctx, data)
+		assert.NoError(t, err)
+	})
+}
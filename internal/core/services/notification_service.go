@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+// notificationFooter closes the notification emails
+const notificationFooter = "\n\nYou can turn off these emails in the notification preferences of your account."
+
+var _ svcport.NotificationService = (*NotificationService)(nil)
+
+// NotificationService struct. The domain events become entries of the inbox
+// and emails, depending on the preferences of every user.
+type NotificationService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.NotificationRepository
+	mailer         svcport.Mailer
+	contextTimeOut time.Duration
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(logger *zap.SugaredLogger, repo repport.NotificationRepository, mailer svcport.Mailer, timeout time.Duration) *NotificationService {
+	return &NotificationService{
+		logger:         logger,
+		repository:     repo,
+		mailer:         mailer,
+		contextTimeOut: timeout,
+	}
+}
+
+// List returns a page of the inbox and the unread notifications of the user
+func (svc *NotificationService) List(c context.Context, search *domain.NotificationSearch) (*domain.NotificationList, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if search.Limit <= 0 || search.Limit > maxSearchLimit {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Offset < 0 {
+		search.Offset = 0
+	}
+
+	items, err := svc.repository.List(ctx, search)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	unread, err := svc.repository.CountUnread(ctx, search.UserID)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.NotificationList{Items: items, Unread: unread}, nil
+}
+
+// MarkRead marks a notification of the user as read
+func (svc *NotificationService) MarkRead(c context.Context, uid int, id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.MarkRead(ctx, uid, id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks the whole inbox of the user as read
+func (svc *NotificationService) MarkAllRead(c context.Context, uid int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.MarkAllRead(ctx, uid); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// GetPreferences returns the channels of the user
+func (svc *NotificationService) GetPreferences(c context.Context, uid int) (*domain.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	preferences, err := svc.repository.GetPreferences(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences saves the channels of the user
+func (svc *NotificationService) UpdatePreferences(c context.Context, uid int, data *domain.NotificationPreferencesRequest) (*domain.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	preferences := &domain.NotificationPreferences{InApp: *data.InApp, Email: *data.Email, Webhook: *data.Webhook}
+	if err := svc.repository.SavePreferences(ctx, uid, preferences); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return preferences, nil
+}
+
+// HandleEvent notifies the users of the event. The inbox keeps a single entry
+// per event, so a redelivered event doesn't notify twice; without the in-app
+// channel a redelivery can repeat the email.
+func (svc *NotificationService) HandleEvent(c context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	recipients, err := svc.repository.ListRecipients(ctx, event.UserIDs)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		notification, email, err := newNotification(event, recipient.UserID)
+		if err != nil {
+			return err
+		}
+		if notification == nil {
+			continue
+		}
+
+		created := true
+		if recipient.InApp {
+			if created, err = svc.repository.Create(ctx, notification); err != nil {
+				return err
+			}
+		}
+		if recipient.Email && email && created {
+			err := svc.mailer.Send(ctx, &domain.Email{
+				To:      recipient.Address,
+				Subject: notification.Title,
+				Body:    notification.Body + notificationFooter,
+			})
+			if err != nil {
+				svc.logger.Errorw("failed to send the notification email", "type", event.Type, "user_id", recipient.UserID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *NotificationService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrNotificationNotFound) {
+			return httpErrors.ErrNotificationNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+// newNotification the notification of the event for the user and whether it
+// goes by email too, nil when the event doesn't notify anyone
+func newNotification(event domain.Event, uid int) (*domain.Notification, bool, error) {
+	notification := &domain.Notification{
+		UserID:    uid,
+		EventID:   event.ID,
+		Type:      event.Type,
+		Data:      event.Data,
+		CreatedAt: event.OccurredAt,
+	}
+	email := true
+
+	switch event.Type {
+	case domain.EventTradeExecuted:
+		var data domain.TradeExecutedData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, false, err
+		}
+		if uid == data.SellerID {
+			notification.Title = "Your bonds were sold"
+			notification.Body = fmt.Sprintf("%d of %s were sold at %.2f each.", data.Quantity, data.BondName, data.Price)
+		} else {
+			notification.Title = "Purchase completed"
+			notification.Body = fmt.Sprintf("You bought %d of %s at %.2f each.", data.Quantity, data.BondName, data.Price)
+		}
+	case domain.EventListingSoldOut:
+		var data domain.ListingSoldOutData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, false, err
+		}
+		notification.Title = "Your listing sold out"
+		notification.Body = fmt.Sprintf("Every bond of %s on sale was sold.", data.BondName)
+	case domain.EventSecurityAlert:
+		var data domain.SecurityAlertData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, false, err
+		}
+		notification.Title = "Security alert"
+		switch data.Event {
+		case domain.AuthEventPasswordChange:
+			notification.Body = fmt.Sprintf("Your password was changed from %s. If it wasn't you, contact support.", data.IP)
+		case domain.AuthEventAccountLocked:
+			notification.Body = fmt.Sprintf("Your account was locked after failed sign in attempts from %s.", data.IP)
+			// the login guard already emails the unlock link
+			email = false
+		case domain.AuthEventAccountUnlocked:
+			notification.Body = fmt.Sprintf("Your account was unlocked from %s.", data.IP)
+		default:
+			notification.Body = fmt.Sprintf("New %s activity on your account from %s.", data.Event, data.IP)
+		}
+	case domain.EventAlertTriggered:
+		var data domain.AlertTriggeredData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, false, err
+		}
+		switch {
+		case data.AlertType == domain.AlertPriceBelow && data.Threshold != nil:
+			notification.Title = "Price alert"
+			notification.Body = fmt.Sprintf("%s is on sale at %.2f, at or below your target of %.2f.", data.BondName, data.Price, *data.Threshold)
+		case data.AlertType == domain.AlertAvailableAbove && data.Threshold != nil:
+			notification.Title = "Availability alert"
+			notification.Body = fmt.Sprintf("%d of %s are on sale, above your target of %.0f.", data.Available, data.BondName, *data.Threshold)
+		default:
+			notification.Title = "New listing"
+			notification.Body = fmt.Sprintf("%s was put on sale, %d available at %.2f.", data.BondName, data.Available, data.Price)
+		}
+	default:
+		return nil, false, nil
+	}
+
+	return notification, email, nil
+}
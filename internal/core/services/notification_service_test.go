@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotificationService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockNotificationRepository(mockCtrl)
+	mailer := mock.NewMockMailer(mockCtrl)
+
+	uc := NewNotificationService(slogger, repo, mailer, 2*time.Second)
+	ctx := context.Background()
+	trade, _ := domain.NewEvent(domain.EventTradeExecuted, domain.TradeExecutedData{MarketBondID: 7, BondName: "Bond", Price: 10, Quantity: 3, SellerID: 2, BuyerID: 1}, 1, 2)
+
+	t.Run("List applies the default limit", func(t *testing.T) {
+		repo.EXPECT().List(gomock.Any(), &domain.NotificationSearch{UserID: 1, Limit: defaultSearchLimit}).
+			Return([]*domain.Notification{{ID: 3}}, nil)
+		repo.EXPECT().CountUnread(gomock.Any(), 1).Return(4, nil)
+
+		list, err := uc.List(ctx, &domain.NotificationSearch{UserID: 1, Limit: 1000, Offset: -1})
+		assert.NoError(t, err)
+		assert.Len(t, list.Items, 1)
+		assert.Equal(t, 4, list.Unread)
+	})
+
+	t.Run("MarkRead Not Found", func(t *testing.T) {
+		repo.EXPECT().MarkRead(gomock.Any(), 1, 9).Return(httpErrors.ErrNotificationNotFound)
+
+		err := uc.MarkRead(ctx, 1, 9)
+		assert.ErrorIs(t, err, httpErrors.ErrNotificationNotFound)
+	})
+
+	t.Run("UpdatePreferences", func(t *testing.T) {
+		on, off := true, false
+		repo.EXPECT().SavePreferences(gomock.Any(), 1, &domain.NotificationPreferences{InApp: true, Email: false, Webhook: true}).Return(nil)
+
+		preferences, err := uc.UpdatePreferences(ctx, 1, &domain.NotificationPreferencesRequest{InApp: &on, Email: &off, Webhook: &on})
+		assert.NoError(t, err)
+		assert.False(t, preferences.Email)
+	})
+
+	t.Run("HandleEvent notifies the buyer and the seller", func(t *testing.T) {
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1, 2}).Return([]*domain.NotificationRecipient{
+			{UserID: 1, Address: "buyer@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: true, Email: false}},
+			{UserID: 2, Address: "seller@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: true, Email: true}},
+		}, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *domain.Notification) (bool, error) {
+			assert.Equal(t, 1, n.UserID)
+			assert.Equal(t, trade.ID, n.EventID)
+			assert.Equal(t, "Purchase completed", n.Title)
+			assert.Equal(t, "You bought 3 of Bond at 10.00 each.", n.Body)
+			return true, nil
+		})
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *domain.Notification) (bool, error) {
+			assert.Equal(t, 2, n.UserID)
+			assert.Equal(t, "Your bonds were sold", n.Title)
+			return true, nil
+		})
+		mailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, email *domain.Email) error {
+			assert.Equal(t, "seller@mail.com", email.To)
+			assert.Equal(t, "Your bonds were sold", email.Subject)
+			assert.True(t, strings.HasPrefix(email.Body, "3 of Bond were sold at 10.00 each."))
+			return nil
+		})
+
+		assert.NoError(t, uc.HandleEvent(ctx, *trade))
+	})
+
+	t.Run("HandleEvent redelivered sends no email", func(t *testing.T) {
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1, 2}).Return([]*domain.NotificationRecipient{
+			{UserID: 2, Address: "seller@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: true, Email: true}},
+		}, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		assert.NoError(t, uc.HandleEvent(ctx, *trade))
+	})
+
+	t.Run("HandleEvent email only", func(t *testing.T) {
+		soldOut, _ := domain.NewEvent(domain.EventListingSoldOut, domain.ListingSoldOutData{MarketBondID: 7, BondName: "Bond", SellerID: 2}, 2)
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{2}).Return([]*domain.NotificationRecipient{
+			{UserID: 2, Address: "seller@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: false, Email: true}},
+		}, nil)
+		mailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, email *domain.Email) error {
+			assert.Equal(t, "Your listing sold out", email.Subject)
+			return errors.New("smtp: unavailable")
+		})
+
+		assert.NoError(t, uc.HandleEvent(ctx, *soldOut))
+	})
+
+	t.Run("HandleEvent lockout isn't emailed", func(t *testing.T) {
+		locked, _ := domain.NewEvent(domain.EventSecurityAlert, domain.SecurityAlertData{Event: domain.AuthEventAccountLocked, Outcome: domain.AuthOutcomeBlocked, IP: "10.0.0.1"}, 1)
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1}).Return([]*domain.NotificationRecipient{
+			{UserID: 1, Address: "gini@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: true, Email: true}},
+		}, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *domain.Notification) (bool, error) {
+			assert.Equal(t, "Security alert", n.Title)
+			assert.Contains(t, n.Body, "10.0.0.1")
+			return true, nil
+		})
+
+		assert.NoError(t, uc.HandleEvent(ctx, *locked))
+	})
+
+	t.Run("HandleEvent price alert", func(t *testing.T) {
+		target := 10.0
+		triggered, _ := domain.NewEvent(domain.EventAlertTriggered, domain.AlertTriggeredData{AlertID: 8, AlertType: domain.AlertPriceBelow, Threshold: &target, BondName: "Bond", Price: 9.5, Available: 30}, 1)
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1}).Return([]*domain.NotificationRecipient{
+			{UserID: 1, Address: "gini@mail.com", NotificationPreferences: domain.NotificationPreferences{InApp: true, Email: true}},
+		}, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *domain.Notification) (bool, error) {
+			assert.Equal(t, "Price alert", n.Title)
+			assert.Equal(t, "Bond is on sale at 9.50, at or below your target of 10.00.", n.Body)
+			return true, nil
+		})
+		mailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.NoError(t, uc.HandleEvent(ctx, *triggered))
+	})
+
+	t.Run("HandleEvent ignores other events", func(t *testing.T) {
+		listed, _ := domain.NewEvent(domain.EventListingCreated, domain.ListingCreatedData{BondID: 4, SellerID: 2, Quantity: 1}, 2)
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{2}).Return([]*domain.NotificationRecipient{
+			{UserID: 2, Address: "seller@mail.com", NotificationPreferences: *domain.DefaultNotificationPreferences()},
+		}, nil)
+
+		assert.NoError(t, uc.HandleEvent(ctx, *listed))
+	})
+
+	t.Run("HandleEvent store failure is retried", func(t *testing.T) {
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1, 2}).Return(nil, errors.New("connection refused"))
+
+		assert.Error(t, uc.HandleEvent(ctx, *trade))
+	})
+
+	t.Run("HandleEvent bad data", func(t *testing.T) {
+		event := domain.Event{ID: "evt", Type: domain.EventListingSoldOut, UserIDs: []int{1}, Data: json.RawMessage(`[]`)}
+		repo.EXPECT().ListRecipients(gomock.Any(), []int{1}).Return([]*domain.NotificationRecipient{{UserID: 1}}, nil)
+
+		assert.Error(t, uc.HandleEvent(ctx, event))
+	})
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"kiramishima/m-backend/pkg/utils"
+	"strconv"
+	"time"
+)
+
+var _ svcport.OIDCService = (*OIDCService)(nil)
+
+// OIDCService struct, signs in existing users through an OpenID Connect provider
+type OIDCService struct {
+	logger         *zap.SugaredLogger
+	provider       svcport.IdentityProvider
+	states         repport.OIDCStateRepository
+	identities     repport.IdentityRepository
+	users          repport.AuthRepository
+	roles          repport.RoleRepository
+	twoFactor      svcport.TwoFactorService
+	sessions       svcport.SessionService
+	events         svcport.AuthEventService
+	stateTTL       time.Duration
+	challengeTTL   time.Duration
+	contextTimeOut time.Duration
+}
+
+// NewOIDCService creates a new oidc service, a nil provider disables the SSO login
+func NewOIDCService(logger *zap.SugaredLogger, provider svcport.IdentityProvider, states repport.OIDCStateRepository, identities repport.IdentityRepository, users repport.AuthRepository, roles repport.RoleRepository, twoFactor svcport.TwoFactorService, sessions svcport.SessionService, events svcport.AuthEventService, stateTTL time.Duration, challengeTTL time.Duration, timeout time.Duration) *OIDCService {
+	return &OIDCService{
+		logger:         logger,
+		provider:       provider,
+		states:         states,
+		identities:     identities,
+		users:          users,
+		roles:          roles,
+		twoFactor:      twoFactor,
+		sessions:       sessions,
+		events:         events,
+		stateTTL:       stateTTL,
+		challengeTTL:   challengeTTL,
+		contextTimeOut: timeout,
+	}
+}
+
+// Begin creates the state, the nonce and the PKCE verifier of a login
+func (svc *OIDCService) Begin(c context.Context) (string, string, error) {
+	if svc.provider == nil {
+		return "", "", httpErrors.ErrOIDCDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	state, err := randomURLString(32)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return "", "", httpErrors.InternalServerError
+	}
+	nonce, err := randomURLString(32)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return "", "", httpErrors.InternalServerError
+	}
+	verifier, err := randomURLString(32)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return "", "", httpErrors.InternalServerError
+	}
+
+	if err := svc.states.Save(ctx, state, &domain.OIDCState{CodeVerifier: verifier, Nonce: nonce}, svc.stateTTL); err != nil {
+		return "", "", svc.handleError(ctx, err)
+	}
+
+	authURL, err := svc.provider.AuthCodeURL(ctx, state, nonce, codeChallenge(verifier))
+	if err != nil {
+		return "", "", svc.handleError(ctx, err)
+	}
+
+	return authURL, state, nil
+}
+
+// Callback redeems the code and signs in the user linked to the identity. An
+// identity is linked on its first login to the user with the same email, only
+// if the provider verified that email.
+func (svc *OIDCService) Callback(c context.Context, state string, code string, ip string, userAgent string) (*domain.AuthResponse, error) {
+	if svc.provider == nil {
+		return nil, httpErrors.ErrOIDCDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	event := &domain.AuthEvent{
+		IP:        ip,
+		UserAgent: userAgent,
+		Event:     domain.AuthEventSignIn,
+		Outcome:   domain.AuthOutcomeFailure,
+		Reason:    "sso: internal error",
+	}
+	defer svc.events.Record(ctx, event)
+
+	data, err := svc.states.Consume(ctx, state)
+	if err != nil {
+		err = svc.handleError(ctx, err)
+		event.Reason = "sso: " + err.Error()
+		return nil, err
+	}
+
+	identity, err := svc.provider.Exchange(ctx, code, data.CodeVerifier, data.Nonce)
+	if err != nil {
+		err = svc.handleError(ctx, err)
+		event.Reason = "sso: " + err.Error()
+		return nil, err
+	}
+	event.Email = identity.Email
+
+	user, err := svc.findUser(ctx, identity)
+	if err != nil {
+		err = svc.handleError(ctx, err)
+		event.Reason = "sso: " + err.Error()
+		return nil, err
+	}
+	event.UserID, event.Email = userID(user), user.Email
+	if user.Suspended {
+		event.Outcome, event.Reason = domain.AuthOutcomeBlocked, "suspended"
+		return nil, httpErrors.ErrUserSuspended
+	}
+
+	// The second factor of the account is still required
+	uid, _ := strconv.Atoi(user.ID)
+	enabled, err := svc.twoFactor.IsEnabled(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := utils.GenerateChallengeJWT(uid, svc.challengeTTL)
+		if err != nil {
+			svc.logger.Error(err.Error())
+			return nil, httpErrors.InternalServerError
+		}
+		event.Outcome, event.Reason = domain.AuthOutcomeSuccess, "sso: 2fa required"
+		return &domain.AuthResponse{ChallengeToken: challenge, TwoFactorRequired: true}, nil
+	}
+
+	resp, err := issueSessionToken(ctx, svc.logger, svc.roles, svc.sessions, user, ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	event.Outcome, event.Reason = domain.AuthOutcomeSuccess, "sso"
+	return resp, nil
+}
+
+// findUser resolves the user of an identity, linking it on the first login
+func (svc *OIDCService) findUser(ctx context.Context, identity *domain.OIDCIdentity) (*domain.User, error) {
+	uid, err := svc.identities.FindUserID(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		user, err := svc.users.FindByID(ctx, uid)
+		if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return nil, httpErrors.ErrOIDCUserNotFound
+		}
+		return user, err
+	}
+	if !errors.Is(err, httpErrors.ErrIdentityNotLinked) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, httpErrors.ErrOIDCEmailNotVerified
+	}
+	user, err := svc.users.FindByCredentials(ctx, &domain.AuthRequest{Email: identity.Email})
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return nil, httpErrors.ErrOIDCUserNotFound
+		}
+		return nil, err
+	}
+
+	uid, _ = strconv.Atoi(user.ID)
+	err = svc.identities.Link(ctx, &domain.UserIdentity{
+		UserID:  uid,
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	svc.logger.Infow("linked identity", "user_id", uid, "issuer", identity.Issuer)
+
+	return user, nil
+}
+
+// handleError maps repository and provider errors to service errors
+func (svc *OIDCService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		for _, known := range []error{
+			httpErrors.ErrInvalidOIDCState,
+			httpErrors.ErrOIDCExchange,
+			httpErrors.ErrOIDCEmailNotVerified,
+			httpErrors.ErrOIDCUserNotFound,
+		} {
+			if errors.Is(err, known) {
+				return known
+			}
+		}
+		return httpErrors.InternalServerError
+	}
+}
+
+// codeChallenge the S256 PKCE challenge of the verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/adapters/oidc"
+	"kiramishima/m-backend/internal/adapters/oidc/oidctest"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestOIDCBegin(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	provider := mock.NewMockIdentityProvider(mockCtrl)
+	states := mock.NewMockOIDCStateRepository(mockCtrl)
+
+	uc := NewOIDCService(slogger, provider, states, nil, nil, nil, nil, nil, nil, 10*time.Minute, 5*time.Minute, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		var saved *domain.OIDCState
+		var savedState string
+		states.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), 10*time.Minute).
+			DoAndReturn(func(_ context.Context, state string, data *domain.OIDCState, _ time.Duration) error {
+				savedState, saved = state, data
+				return nil
+			})
+		provider.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, state string, nonce string, challenge string) (string, error) {
+				assert.Equal(t, savedState, state)
+				assert.Equal(t, saved.Nonce, nonce)
+				assert.Equal(t, codeChallenge(saved.CodeVerifier), challenge)
+				assert.NotEqual(t, saved.CodeVerifier, challenge)
+				return "https://idp.example.com/authorize?state=" + state, nil
+			})
+
+		authURL, state, err := uc.Begin(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, savedState, state)
+		assert.Contains(t, authURL, savedState)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := NewOIDCService(slogger, nil, states, nil, nil, nil, nil, nil, nil, 10*time.Minute, 5*time.Minute, 2*time.Second)
+
+		_, _, err := disabled.Begin(ctx)
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCDisabled)
+	})
+}
+
+func TestOIDCCallback(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	provider := mock.NewMockIdentityProvider(mockCtrl)
+	states := mock.NewMockOIDCStateRepository(mockCtrl)
+	identities := mock.NewMockIdentityRepository(mockCtrl)
+	users := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	twoFactor := mock.NewMockTwoFactorService(mockCtrl)
+	sessions := mock.NewMockSessionService(mockCtrl)
+	sessions.EXPECT().Create(gomock.Any(), 1, "127.0.0.1", "Mozilla/5.0").Return(&domain.UserSession{ID: 1}, "refresh", nil).AnyTimes()
+	var recorded []*domain.AuthEvent
+	events := recordedEvents(mockCtrl, &recorded)
+
+	uc := NewOIDCService(slogger, provider, states, identities, users, roles, twoFactor, sessions, events, 10*time.Minute, 5*time.Minute, 2*time.Second)
+	ctx := context.Background()
+	state := &domain.OIDCState{CodeVerifier: "verifier", Nonce: "nonce"}
+	identity := &domain.OIDCIdentity{Issuer: "https://idp.example.com", Subject: "sub-1", Email: "gini@mail.com", EmailVerified: true}
+
+	t.Run("Linked Identity", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), "https://idp.example.com", "sub-1").Return(1, nil)
+		users.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(false, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil)
+
+		resp, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthEventSignIn, event.Event)
+		assert.Equal(t, domain.AuthOutcomeSuccess, event.Outcome)
+		assert.Equal(t, "sso", event.Reason)
+		assert.Equal(t, 1, *event.UserID)
+	})
+
+	t.Run("Links By Verified Email", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, httpErrors.ErrIdentityNotLinked)
+		users.EXPECT().FindByCredentials(gomock.Any(), &domain.AuthRequest{Email: "gini@mail.com"}).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		identities.EXPECT().Link(gomock.Any(), &domain.UserIdentity{UserID: 1, Issuer: "https://idp.example.com", Subject: "sub-1", Email: "gini@mail.com"}).Return(nil)
+		twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(false, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil)
+
+		resp, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+	})
+
+	t.Run("Unverified Email", func(t *testing.T) {
+		unverified := *identity
+		unverified.EmailVerified = false
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(&unverified, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, httpErrors.ErrIdentityNotLinked)
+		users.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCEmailNotVerified)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("Unknown Email", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, httpErrors.ErrIdentityNotLinked)
+		users.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrUserNotFound)
+		identities.EXPECT().Link(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCUserNotFound)
+
+		event := lastEvent(t, recorded)
+		assert.Equal(t, domain.AuthOutcomeFailure, event.Outcome)
+		assert.Equal(t, "gini@mail.com", event.Email)
+		assert.Nil(t, event.UserID)
+	})
+
+	t.Run("Two Factor Enabled", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
+		users.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(true, nil)
+		roles.EXPECT().GetUserRoles(gomock.Any(), gomock.Any()).Times(0)
+
+		resp, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Token)
+		assert.True(t, resp.TwoFactorRequired)
+	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+		identities.EXPECT().FindUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
+		users.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Suspended: true}, nil)
+
+		_, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.ErrorIs(t, err, httpErrors.ErrUserSuspended)
+		assert.Equal(t, domain.AuthOutcomeBlocked, lastEvent(t, recorded).Outcome)
+	})
+
+	t.Run("Invalid State", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "forged").Return(nil, httpErrors.ErrInvalidOIDCState)
+		provider.EXPECT().Exchange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.Callback(ctx, "forged", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidOIDCState)
+	})
+
+	t.Run("Rejected Code", func(t *testing.T) {
+		states.EXPECT().Consume(gomock.Any(), "state").Return(state, nil)
+		provider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(nil, httpErrors.ErrOIDCExchange)
+
+		_, err := uc.Callback(ctx, "state", "code", "127.0.0.1", "Mozilla/5.0")
+		assert.ErrorIs(t, err, httpErrors.ErrOIDCExchange)
+	})
+}
+
+// TestOIDCLoginWithMockProvider runs the whole flow against the in-process provider
+func TestOIDCLoginWithMockProvider(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	identities := mock.NewMockIdentityRepository(mockCtrl)
+	users := mock.NewMockAuthRepository(mockCtrl)
+	roles := mock.NewMockRoleRepository(mockCtrl)
+	twoFactor := mock.NewMockTwoFactorService(mockCtrl)
+	sessions := mock.NewMockSessionService(mockCtrl)
+
+	srv, err := oidctest.NewServer("m-bonds", "s3cret")
+	assert.NoError(t, err)
+	defer srv.Close()
+	srv.Identity = oidctest.Identity{Subject: "sub-1", Email: "gini@mail.com", EmailVerified: true}
+
+	redisSrv := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: redisSrv.Addr()})
+	defer client.Close()
+
+	provider := oidc.NewProvider(domain.OIDC{
+		OIDCIssuer:       srv.Issuer(),
+		OIDCClientID:     "m-bonds",
+		OIDCClientSecret: "s3cret",
+		OIDCRedirectURL:  "http://localhost:8080/v1/auth/oidc/callback",
+		OIDCScopes:       []string{"openid", "email"},
+	}, srv.Client())
+	events := mock.NewMockAuthEventService(mockCtrl)
+	events.EXPECT().Record(gomock.Any(), gomock.Any()).AnyTimes()
+	uc := NewOIDCService(slogger, provider, cache.NewOIDCStateRepository(client), identities, users, roles, twoFactor, sessions, events, 10*time.Minute, 5*time.Minute, 2*time.Second)
+	ctx := context.Background()
+
+	authURL, _, err := uc.Begin(ctx)
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+	reply, err := srv.Authorize(authURL)
+	assert.NoError(t, err)
+
+	identities.EXPECT().FindUserID(gomock.Any(), srv.Issuer(), "sub-1").Return(0, httpErrors.ErrIdentityNotLinked)
+	users.EXPECT().FindByCredentials(gomock.Any(), &domain.AuthRequest{Email: "gini@mail.com"}).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+	identities.EXPECT().Link(gomock.Any(), gomock.Any()).Return(nil)
+	twoFactor.EXPECT().IsEnabled(gomock.Any(), 1).Return(false, nil)
+	roles.EXPECT().GetUserRoles(gomock.Any(), 1).Return([]string{domain.RoleCustomer}, nil)
+	sessions.EXPECT().Create(gomock.Any(), 1, "127.0.0.1", "Mozilla/5.0").Return(&domain.UserSession{ID: 1}, "refresh", nil)
+
+	resp, err := uc.Callback(ctx, reply.Get("state"), reply.Get("code"), "127.0.0.1", "Mozilla/5.0")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	// The state is single use
+	_, err = uc.Callback(ctx, reply.Get("state"), reply.Get("code"), "127.0.0.1", "Mozilla/5.0")
+	assert.ErrorIs(t, err, httpErrors.ErrInvalidOIDCState)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"go.uber.org/zap"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ svcport.RoleService = (*RoleService)(nil)
+
+// RoleService struct
+type RoleService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.RoleRepository
+	contextTimeOut time.Duration
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(logger *zap.SugaredLogger, repo repport.RoleRepository, timeout time.Duration) *RoleService {
+	return &RoleService{
+		logger:         logger,
+		repository:     repo,
+		contextTimeOut: timeout,
+	}
+}
+
+// HasPermission checks if any of the roles grants the permission
+func (svc *RoleService) HasPermission(c context.Context, roles []string, permission string) (bool, error) {
+	if len(roles) == 0 {
+		return false, nil
+	}
+	// context
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	permissions, err := svc.repository.GetRolesPermissions(ctx, roles)
+	if err != nil {
+		svc.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			return false, httpErrors.ErrTimeout
+		default:
+			return false, httpErrors.InternalServerError
+		}
+	}
+
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestHasPermission(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockRoleRepository(mockCtrl)
+
+	uc := NewRoleService(slogger, repo, 2*time.Second)
+
+	t.Run("Granted", func(t *testing.T) {
+		repo.EXPECT().GetRolesPermissions(gomock.Any(), []string{domain.RoleAdmin}).
+			Return([]string{domain.PermissionApproveListings, domain.PermissionManageUsers}, nil)
+
+		ok, err := uc.HasPermission(context.Background(), []string{domain.RoleAdmin}, domain.PermissionManageUsers)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		repo.EXPECT().GetRolesPermissions(gomock.Any(), []string{domain.RoleCustomer}).Return([]string{}, nil)
+
+		ok, err := uc.HasPermission(context.Background(), []string{domain.RoleCustomer}, domain.PermissionManageUsers)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Without roles", func(t *testing.T) {
+		ok, err := uc.HasPermission(context.Background(), nil, domain.PermissionManageUsers)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repo.EXPECT().GetRolesPermissions(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrExecuteQuery)
+
+		ok, err := uc.HasPermission(context.Background(), []string{domain.RoleAdmin}, domain.PermissionManageUsers)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+		assert.False(t, ok)
+	})
+}
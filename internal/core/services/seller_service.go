@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"time"
+)
+
+var _ svcport.SellerService = (*SellerService)(nil)
+
+// SellerService struct
+type SellerService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.SellerRepository
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewSellerService creates a new seller service
+func NewSellerService(logger *zap.SugaredLogger, repo repport.SellerRepository, timeout time.Duration) *SellerService {
+	return &SellerService{
+		logger:         logger,
+		repository:     repo,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// GetProfile returns the public profile of a seller
+func (svc *SellerService) GetProfile(c context.Context, username string) (*domain.SellerProfile, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	profile, err := svc.repository.GetSellerProfile(ctx, username)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return profile, nil
+}
+
+// Rate stores the rating of the buyer for a settled transaction with the seller
+func (svc *SellerService) Rate(c context.Context, buyerID int, username string, data *domain.SellerRatingRequest) (*domain.SellerRating, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	seller, err := svc.repository.GetSellerProfile(ctx, username)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if seller.UserID == buyerID {
+		return nil, httpErrors.ErrTransactionNotRateable
+	}
+
+	rating := &domain.SellerRating{
+		TransactionID: *data.TransactionID,
+		SellerID:      seller.UserID,
+		BuyerID:       buyerID,
+		Score:         *data.Score,
+		Comment:       strings.TrimSpace(data.Comment),
+		CreatedAt:     svc.now().UTC().Truncate(time.Second),
+	}
+	if err := svc.repository.CreateRating(ctx, rating); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return rating, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *SellerService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		for _, known := range []error{httpErrors.ErrSellerNotFound, httpErrors.ErrTransactionNotRateable, httpErrors.ErrAlreadyRated} {
+			if errors.Is(err, known) {
+				return known
+			}
+		}
+		return httpErrors.InternalServerError
+	}
+}
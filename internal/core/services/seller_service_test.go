@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestSellerService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockSellerRepository(mockCtrl)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewSellerService(slogger, repo, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	transactionID, score := 9, 5
+
+	t.Run("Get Profile", func(t *testing.T) {
+		rating := 4.5
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "ginigini").Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini", Rating: &rating, Ratings: 2}, nil)
+
+		profile, err := uc.GetProfile(ctx, "ginigini")
+		assert.NoError(t, err)
+		assert.Equal(t, 4.5, *profile.Rating)
+	})
+
+	t.Run("Get Profile Not Found", func(t *testing.T) {
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "nobody").Return(nil, httpErrors.ErrSellerNotFound)
+
+		_, err := uc.GetProfile(ctx, "nobody")
+		assert.ErrorIs(t, err, httpErrors.ErrSellerNotFound)
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "ginigini").Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini"}, nil)
+		repo.EXPECT().CreateRating(gomock.Any(), &domain.SellerRating{TransactionID: 9, SellerID: 2, BuyerID: 1, Score: 5, Comment: "fast", CreatedAt: now}).
+			DoAndReturn(func(_ context.Context, r *domain.SellerRating) error {
+				r.ID = 4
+				return nil
+			})
+
+		rating, err := uc.Rate(ctx, 1, "ginigini", &domain.SellerRatingRequest{TransactionID: &transactionID, Score: &score, Comment: "  fast "})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, rating.ID)
+	})
+
+	t.Run("Rate Yourself", func(t *testing.T) {
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "ginigini").Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini"}, nil)
+		repo.EXPECT().CreateRating(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := uc.Rate(ctx, 2, "ginigini", &domain.SellerRatingRequest{TransactionID: &transactionID, Score: &score})
+		assert.ErrorIs(t, err, httpErrors.ErrTransactionNotRateable)
+	})
+
+	t.Run("Rate Twice", func(t *testing.T) {
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "ginigini").Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini"}, nil)
+		repo.EXPECT().CreateRating(gomock.Any(), gomock.Any()).Return(httpErrors.ErrAlreadyRated)
+
+		_, err := uc.Rate(ctx, 1, "ginigini", &domain.SellerRatingRequest{TransactionID: &transactionID, Score: &score})
+		assert.ErrorIs(t, err, httpErrors.ErrAlreadyRated)
+	})
+
+	t.Run("Rate Failed", func(t *testing.T) {
+		repo.EXPECT().GetSellerProfile(gomock.Any(), "ginigini").Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini"}, nil)
+		repo.EXPECT().CreateRating(gomock.Any(), gomock.Any()).Return(httpErrors.ErrExecuteStatement)
+
+		_, err := uc.Rate(ctx, 1, "ginigini", &domain.SellerRatingRequest{TransactionID: &transactionID, Score: &score})
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+}
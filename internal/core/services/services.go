@@ -1,26 +1,151 @@
 package services
 
 import (
+	"context"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/hasher"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
 	"time"
 
+	"kiramishima/m-backend/internal/adapters/cache/cached"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
 	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/queue"
+	"kiramishima/m-backend/internal/scheduler"
 )
 
 // Module services
 var Module = fx.Module("services",
-	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, authrepo *repository.AuthRepository) *AuthService {
-		return NewAuthService(logger, authrepo, time.Duration(cfg.ContextTimeout)*time.Second)
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, authrepo *repository.AuthRepository, rolerepo *repository.RoleRepository, twofactor *TwoFactorService, guard *LoginGuardService, sessions *SessionService, events *AuthEventService, hasher *hasher.Hasher) *AuthService {
+		return NewAuthService(logger, authrepo, rolerepo, twofactor, guard, sessions, events, hasher, time.Duration(cfg.ChallengeTTL)*time.Second, time.Duration(cfg.ContextTimeout)*time.Second)
 	}),
-	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, bondrepo *repository.BondRepository) *BondService {
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, attempts *cache.LoginAttemptRepository, authrepo *repository.AuthRepository, eventrepo *repository.AuthEventRepository, mailer svcport.Mailer, ps *psnats.NATSPubSub) *LoginGuardService {
+		return NewLoginGuardService(logger, attempts, authrepo, eventrepo, mailer, ps, cfg.LoginProtection, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, twofactorrepo *repository.TwoFactorRepository, authrepo *repository.AuthRepository) *TwoFactorService {
+		return NewTwoFactorService(logger, twofactorrepo, authrepo, cfg.TOTPIssuer, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, provider svcport.IdentityProvider, states *cache.OIDCStateRepository, identities *repository.IdentityRepository, authrepo *repository.AuthRepository, rolerepo *repository.RoleRepository, twofactor *TwoFactorService, sessions *SessionService, events *AuthEventService) *OIDCService {
+		return NewOIDCService(logger, provider, states, identities, authrepo, rolerepo, twofactor, sessions, events, time.Duration(cfg.OIDCStateTTL)*time.Second, time.Duration(cfg.ChallengeTTL)*time.Second, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, sessionrepo *repository.SessionRepository, c svcport.Cache) *SessionService {
+		return NewSessionService(logger, sessionrepo, c, time.Duration(cfg.RefreshTTL)*time.Second, time.Duration(cfg.CheckTTL)*time.Second, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, eventrepo *repository.AuthEventRepository, ps *psnats.NATSPubSub) *AuthEventService {
+		return NewAuthEventService(logger, eventrepo, ps, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, apikeyrepo *repository.APIKeyRepository) *APIKeyService {
+		return NewAPIKeyService(logger, apikeyrepo, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, rolerepo *repository.RoleRepository) *RoleService {
+		return NewRoleService(logger, rolerepo, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, bondrepo *cached.BondRepository) *BondService {
 		return NewBondService(logger, bondrepo, time.Duration(cfg.ContextTimeout)*time.Second)
 	}),
-	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, mbondrepo *repository.MarketBondRepository) *MarketBondsService {
-		return NewMarketBondsService(logger, mbondrepo, time.Duration(cfg.ContextTimeout)*time.Second)
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, mbondrepo *cached.MarketBondRepository, ps *psnats.NATSPubSub) *MarketBondsService {
+		return NewMarketBondsService(logger, mbondrepo, ps, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, srepo *repository.SellerRepository) *SellerService {
+		return NewSellerService(logger, srepo, time.Duration(cfg.ContextTimeout)*time.Second)
 	}),
 	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, urepo *repository.UserRepository) *UserService {
 		return NewUserService(logger, urepo, time.Duration(cfg.ContextTimeout)*time.Second)
 	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, urepo *repository.UserRepository, storage svcport.Storage) *AvatarService {
+		return NewAvatarService(logger, urepo, storage, cfg.AvatarMaxSize, time.Duration(cfg.StorageURLTTL)*time.Second, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, accountrepo *cached.AccountRepository, urepo *repository.UserRepository, authrepo *repository.AuthRepository, hasher *hasher.Hasher, guard *LoginGuardService, events *AuthEventService, storage svcport.Storage, tasks *queue.Queue) *AccountService {
+		svc := NewAccountService(logger, accountrepo, urepo, authrepo, hasher, guard, events, storage, tasks, time.Duration(cfg.ExportTTL)*time.Second, time.Duration(cfg.StorageURLTTL)*time.Second, time.Duration(cfg.ContextTimeout)*time.Second)
+		tasks.Handle(domain.TaskAccountExport, svc.RunExport)
+		return svc
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, tasks *queue.Queue) *TaskService {
+		return NewTaskService(logger, tasks, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, urepo *repository.UserRepository, bondrepo *cached.BondRepository, mbondrepo *cached.MarketBondRepository, auditrepo *repository.AuditLogRepository, eventrepo *repository.AuthEventRepository, ps *psnats.NATSPubSub, jobs *scheduler.Scheduler) *AdminService {
+		return NewAdminService(logger, urepo, bondrepo, mbondrepo, auditrepo, eventrepo, ps, jobs, time.Duration(cfg.ContextTimeout)*time.Second)
+	}),
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger, webhookrepo *repository.WebhookRepository, sender svcport.WebhookSender, ps *psnats.NATSPubSub) *WebhookService {
+		svc := NewWebhookService(logger, webhookrepo, sender, ps, cfg.WebhookMaxAttempts, time.Duration(cfg.ContextTimeout)*time.Second)
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return subscribeWebhooks(ps, svc, cfg.Webhooks)
+			},
+		})
+		return svc
+	}),
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger, notificationrepo *repository.NotificationRepository, mailer svcport.Mailer, ps *psnats.NATSPubSub) *NotificationService {
+		svc := NewNotificationService(logger, notificationrepo, mailer, time.Duration(cfg.ContextTimeout)*time.Second)
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return subscribeNotifications(ps, svc)
+			},
+		})
+		return svc
+	}),
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger, alertrepo *repository.AlertRepository, ps *psnats.NATSPubSub) *AlertService {
+		svc := NewAlertService(logger, alertrepo, ps, cfg.Alerts, time.Duration(cfg.ContextTimeout)*time.Second)
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return subscribeAlerts(ps, svc)
+			},
+		})
+		return svc
+	}),
 )
+
+// Streams of the domain events and of the queued webhook deliveries
+const (
+	eventsStream   = "EVENTS"
+	webhooksStream = "WEBHOOKS"
+	// deadEvents subject of the domain events no consumer could handle
+	deadEvents = "dead.events"
+)
+
+// subscribeWebhooks consumes the domain events and the queued deliveries.
+// Both go through JetStream so the events published while the consumers
+// are down are delivered when they come back.
+func subscribeWebhooks(ps *psnats.NATSPubSub, svc *WebhookService, cfg domain.Webhooks) error {
+	if err := ps.EnsureStream(eventsStream, domain.EventsSubjectPrefix+">"); err != nil {
+		return err
+	}
+	if err := ps.EnsureStream(webhooksStream, domain.WebhookDeliverSubject); err != nil {
+		return err
+	}
+	if _, err := psnats.Subscribe(ps, domain.EventsSubjectPrefix+">", svc.HandleEvent,
+		psnats.Durable("webhooks"), psnats.Queue("webhooks"), psnats.DeadLetter(deadEvents)); err != nil {
+		return err
+	}
+	// the first delivery is an attempt, the backoff waits between the rest
+	_, err := psnats.Subscribe(ps, domain.WebhookDeliverSubject, svc.Deliver,
+		psnats.Durable("webhook-deliveries"), psnats.Queue("webhook-deliveries"),
+		psnats.MaxDeliver(cfg.WebhookMaxAttempts+1),
+		psnats.Backoff(webhookBackoff(time.Duration(cfg.WebhookBackoff)*time.Second, cfg.WebhookMaxAttempts)...))
+	return err
+}
+
+// subscribeNotifications fills the inbox from the domain events, each
+// instance of the service takes a share of them
+func subscribeNotifications(ps *psnats.NATSPubSub, svc *NotificationService) error {
+	if err := ps.EnsureStream(eventsStream, domain.EventsSubjectPrefix+">"); err != nil {
+		return err
+	}
+	_, err := psnats.Subscribe(ps, domain.EventsSubjectPrefix+">", svc.HandleEvent,
+		psnats.Durable("notifications"), psnats.Queue("notifications"), psnats.DeadLetter(deadEvents))
+	return err
+}
+
+// subscribeAlerts evaluates the alerts on the market events, each instance of
+// the service takes a share of them
+func subscribeAlerts(ps *psnats.NATSPubSub, svc *AlertService) error {
+	if err := ps.EnsureStream(eventsStream, domain.EventsSubjectPrefix+">"); err != nil {
+		return err
+	}
+	_, err := psnats.Subscribe(ps, domain.EventsSubjectPrefix+">", svc.HandleEvent,
+		psnats.Durable("alerts"), psnats.Queue("alerts"), psnats.DeadLetter(deadEvents))
+	return err
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"kiramishima/m-backend/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxUserAgentLength size of the user_agent column
+const maxUserAgentLength = 255
+
+var _ svcport.SessionService = (*SessionService)(nil)
+
+// SessionService struct
+type SessionService struct {
+	logger     *zap.SugaredLogger
+	repository repport.SessionRepository
+	// cache keeps the answer of Active for checkTTL
+	cache          svcport.Cache
+	refreshTTL     time.Duration
+	checkTTL       time.Duration
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(logger *zap.SugaredLogger, repo repport.SessionRepository, cache svcport.Cache, refreshTTL time.Duration, checkTTL time.Duration, timeout time.Duration) *SessionService {
+	return &SessionService{
+		logger:         logger,
+		repository:     repo,
+		cache:          cache,
+		refreshTTL:     refreshTTL,
+		checkTTL:       checkTTL,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// Create starts a new session for a sign in
+func (svc *SessionService) Create(c context.Context, uid int, ip string, userAgent string) (*domain.UserSession, string, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	token, err := randomURLString(32)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, "", httpErrors.InternalServerError
+	}
+
+	now := svc.now()
+	session := &domain.UserSession{
+		UserID:     uid,
+		TokenHash:  hashRefreshToken(token),
+		UserAgent:  truncate(userAgent, maxUserAgentLength),
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(svc.refreshTTL),
+	}
+	if err := svc.repository.Create(ctx, session); err != nil {
+		return nil, "", svc.handleError(ctx, err)
+	}
+
+	return session, token, nil
+}
+
+// Rotate replaces the refresh token of the session. A token that was already
+// rotated means it leaked or was replayed, so the whole session is revoked.
+func (svc *SessionService) Rotate(c context.Context, refreshToken string, ip string, userAgent string) (*domain.UserSession, string, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	hash := hashRefreshToken(refreshToken)
+	session, err := svc.repository.FindByToken(ctx, hash)
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrSessionNotFound) {
+			return nil, "", httpErrors.ErrInvalidRefreshToken
+		}
+		return nil, "", svc.handleError(ctx, err)
+	}
+	if session.TokenHash != hash {
+		svc.logger.Warnw("refresh token reused, revoking the session", "user_id", session.UserID, "session_id", session.ID, "ip", ip)
+		if err := svc.repository.Revoke(ctx, session.UserID, session.ID); err != nil {
+			svc.logger.Error(err.Error())
+		} else {
+			svc.forget(ctx, session.UserID, sessionKey(session.ID))
+		}
+		return nil, "", httpErrors.ErrInvalidRefreshToken
+	}
+	if session.Expired(svc.now()) {
+		return nil, "", httpErrors.ErrInvalidRefreshToken
+	}
+
+	token, err := randomURLString(32)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, "", httpErrors.InternalServerError
+	}
+
+	now := svc.now()
+	session.TokenHash = hashRefreshToken(token)
+	session.IP = ip
+	session.UserAgent = truncate(userAgent, maxUserAgentLength)
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(svc.refreshTTL)
+
+	err = svc.repository.Rotate(ctx, session.ID, hash, session.TokenHash, session.IP, session.UserAgent, session.ExpiresAt)
+	if err != nil {
+		// Another request rotated it first
+		if errors.Is(err, httpErrors.ErrSessionNotFound) {
+			return nil, "", httpErrors.ErrInvalidRefreshToken
+		}
+		return nil, "", svc.handleError(ctx, err)
+	}
+
+	return session, token, nil
+}
+
+// List returns the active sessions of the user, currentID marks the session of the request
+func (svc *SessionService) List(c context.Context, uid int, currentID int) ([]*domain.UserSession, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.ListActive(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	for _, session := range list {
+		session.Device = utils.DeviceName(session.UserAgent)
+		session.Current = session.ID == currentID
+	}
+
+	return list, nil
+}
+
+// Revoke signs out one session of the user
+func (svc *SessionService) Revoke(c context.Context, uid int, id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.Revoke(ctx, uid, id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+	svc.forget(ctx, uid, sessionKey(id))
+
+	return nil
+}
+
+// RevokeAll signs out every session of the user
+func (svc *SessionService) RevokeAll(c context.Context, uid int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	total, err := svc.repository.RevokeAll(ctx, uid)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	svc.logger.Infow("revoked all sessions", "user_id", uid, "total", total)
+	svc.forget(ctx, uid)
+
+	return nil
+}
+
+// RevokeOthers signs out every session of the user but keep
+func (svc *SessionService) RevokeOthers(c context.Context, uid int, keep int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	total, err := svc.repository.RevokeOthers(ctx, uid, keep)
+	if err != nil {
+		return svc.handleError(ctx, err)
+	}
+	svc.logger.Infow("revoked the other sessions", "user_id", uid, "kept", keep, "total", total)
+	svc.forget(ctx, uid)
+
+	return nil
+}
+
+// Active reports whether the session of an access token is still valid, the
+// answer is cached checkTTL. The revocations evict it, so they take effect at
+// once; a suspension takes effect within checkTTL. A cache that fails is skipped.
+func (svc *SessionService) Active(c context.Context, uid int, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	key := sessionKey(id)
+	var active bool
+	if err := svc.cache.Get(ctx, key, &active); err == nil {
+		return active, nil
+	}
+
+	active, err := svc.repository.IsActive(ctx, uid, id)
+	if err != nil {
+		return false, svc.handleError(ctx, err)
+	}
+	if err := svc.cache.Set(ctx, key, active, svc.checkTTL, sessionsTag(uid)); err != nil {
+		svc.logger.Warnw("failed to cache the session check", "session_id", id, "error", err)
+	}
+
+	return active, nil
+}
+
+// forget evicts the cached checks of the sessions, every session of the user
+// when keys is empty. A check left behind expires with checkTTL.
+func (svc *SessionService) forget(ctx context.Context, uid int, keys ...string) {
+	var err error
+	if len(keys) == 0 {
+		err = svc.cache.InvalidateTags(ctx, sessionsTag(uid))
+	} else {
+		err = svc.cache.Delete(ctx, keys...)
+	}
+	if err != nil {
+		svc.logger.Warnw("failed to evict the session check", "user_id", uid, "error", err)
+	}
+}
+
+// issueSessionToken starts a session and signs the access token with the roles of the user
+func issueSessionToken(ctx context.Context, logger *zap.SugaredLogger, roles repport.RoleRepository, sessions svcport.SessionService, user *domain.User, ip string, userAgent string) (*domain.AuthResponse, error) {
+	uid, _ := strconv.Atoi(user.ID)
+	list, err := roles.GetUserRoles(ctx, uid)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	user.Roles = list
+
+	session, refreshToken, err := sessions.Create(ctx, uid, ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateJWT(user, session.ID)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+
+	return &domain.AuthResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *SessionService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrSessionNotFound) {
+			return httpErrors.ErrSessionNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+func sessionKey(id int) string {
+	return "session_active:" + strconv.Itoa(id)
+}
+
+func sessionsTag(uid int) string {
+	return "sessions:" + strconv.Itoa(uid)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
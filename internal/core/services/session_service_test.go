@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/cache/memory"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestSessionService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockSessionRepository(mockCtrl)
+	cache, err := memory.NewLRUCache(100)
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewSessionService(slogger, repo, cache, 24*time.Hour, time.Minute, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("Create", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s *domain.UserSession) error {
+			s.ID = 1
+			return nil
+		})
+
+		session, token, err := uc.Create(ctx, 1, "127.0.0.1", "Mozilla/5.0")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, 1, session.ID)
+		assert.Equal(t, hashRefreshToken(token), session.TokenHash)
+		assert.Equal(t, now.Add(24*time.Hour), session.ExpiresAt)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		hash := hashRefreshToken("old")
+		repo.EXPECT().FindByToken(gomock.Any(), hash).Return(&domain.UserSession{ID: 1, UserID: 1, TokenHash: hash, ExpiresAt: now.Add(time.Hour)}, nil)
+		repo.EXPECT().Rotate(gomock.Any(), 1, hash, gomock.Any(), "10.0.0.1", "curl/8.0", now.Add(24*time.Hour)).Return(nil)
+
+		session, token, err := uc.Rotate(ctx, "old", "10.0.0.1", "curl/8.0")
+		assert.NoError(t, err)
+		assert.NotEqual(t, "old", token)
+		assert.Equal(t, hashRefreshToken(token), session.TokenHash)
+	})
+
+	t.Run("Reused Token Revokes The Session", func(t *testing.T) {
+		hash := hashRefreshToken("old")
+		repo.EXPECT().FindByToken(gomock.Any(), hash).Return(&domain.UserSession{ID: 1, UserID: 1, TokenHash: hashRefreshToken("new"), PreviousHash: hash, ExpiresAt: now.Add(time.Hour)}, nil)
+		repo.EXPECT().Revoke(gomock.Any(), 1, 1).Return(nil)
+		repo.EXPECT().Rotate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, _, err := uc.Rotate(ctx, "old", "10.0.0.1", "curl/8.0")
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		hash := hashRefreshToken("old")
+		repo.EXPECT().FindByToken(gomock.Any(), hash).Return(&domain.UserSession{ID: 1, UserID: 1, TokenHash: hash, ExpiresAt: now.Add(-time.Second)}, nil)
+
+		_, _, err := uc.Rotate(ctx, "old", "10.0.0.1", "curl/8.0")
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+	})
+
+	t.Run("Unknown Token", func(t *testing.T) {
+		repo.EXPECT().FindByToken(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrSessionNotFound)
+
+		_, _, err := uc.Rotate(ctx, "unknown", "10.0.0.1", "curl/8.0")
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+	})
+
+	t.Run("Concurrent Rotate", func(t *testing.T) {
+		hash := hashRefreshToken("old")
+		repo.EXPECT().FindByToken(gomock.Any(), hash).Return(&domain.UserSession{ID: 1, UserID: 1, TokenHash: hash, ExpiresAt: now.Add(time.Hour)}, nil)
+		repo.EXPECT().Rotate(gomock.Any(), 1, hash, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(httpErrors.ErrSessionNotFound)
+
+		_, _, err := uc.Rotate(ctx, "old", "10.0.0.1", "curl/8.0")
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidRefreshToken)
+	})
+
+	t.Run("List Marks The Current Session", func(t *testing.T) {
+		repo.EXPECT().ListActive(gomock.Any(), 1).Return([]*domain.UserSession{
+			{ID: 1, UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0"},
+			{ID: 2},
+		}, nil)
+
+		list, err := uc.List(ctx, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "Firefox on Windows", list[0].Device)
+		assert.False(t, list[0].Current)
+		assert.True(t, list[1].Current)
+	})
+
+	t.Run("Revoke Not Found", func(t *testing.T) {
+		repo.EXPECT().Revoke(gomock.Any(), 1, 9).Return(httpErrors.ErrSessionNotFound)
+
+		err := uc.Revoke(ctx, 1, 9)
+		assert.ErrorIs(t, err, httpErrors.ErrSessionNotFound)
+	})
+
+	t.Run("Active Is Cached", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 1, 4).Times(1).Return(true, nil)
+
+		for i := 0; i < 2; i++ {
+			active, err := uc.Active(ctx, 1, 4)
+			assert.NoError(t, err)
+			assert.True(t, active)
+		}
+	})
+
+	t.Run("Revoked Session Is Not Active", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 1, 5).Times(1).Return(false, nil)
+
+		active, err := uc.Active(ctx, 1, 5)
+		assert.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("Active Failed", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 1, 6).Return(false, httpErrors.ErrExecuteQuery)
+
+		_, err := uc.Active(ctx, 1, 6)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+
+	t.Run("Revoke Ends The Session At Once", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 1, 7).Times(1).Return(true, nil)
+		active, err := uc.Active(ctx, 1, 7)
+		assert.NoError(t, err)
+		assert.True(t, active)
+
+		repo.EXPECT().Revoke(gomock.Any(), 1, 7).Return(nil)
+		assert.NoError(t, uc.Revoke(ctx, 1, 7))
+
+		repo.EXPECT().IsActive(gomock.Any(), 1, 7).Times(1).Return(false, nil)
+		active, err = uc.Active(ctx, 1, 7)
+		assert.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("RevokeAll Ends Every Session At Once", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 2, 10).Times(1).Return(true, nil)
+		repo.EXPECT().IsActive(gomock.Any(), 2, 11).Times(1).Return(true, nil)
+		repo.EXPECT().IsActive(gomock.Any(), 3, 12).Times(1).Return(true, nil)
+		for _, s := range [][2]int{{2, 10}, {2, 11}, {3, 12}} {
+			_, err := uc.Active(ctx, s[0], s[1])
+			assert.NoError(t, err)
+		}
+
+		repo.EXPECT().RevokeAll(gomock.Any(), 2).Return(int64(2), nil)
+		assert.NoError(t, uc.RevokeAll(ctx, 2))
+
+		repo.EXPECT().IsActive(gomock.Any(), 2, 10).Times(1).Return(false, nil)
+		repo.EXPECT().IsActive(gomock.Any(), 2, 11).Times(1).Return(false, nil)
+		for _, id := range []int{10, 11} {
+			active, err := uc.Active(ctx, 2, id)
+			assert.NoError(t, err)
+			assert.False(t, active)
+		}
+		// Another user's check stays cached
+		active, err := uc.Active(ctx, 3, 12)
+		assert.NoError(t, err)
+		assert.True(t, active)
+	})
+
+	t.Run("RevokeOthers Ends The Other Sessions At Once", func(t *testing.T) {
+		repo.EXPECT().IsActive(gomock.Any(), 4, 20).Times(1).Return(true, nil)
+		_, err := uc.Active(ctx, 4, 20)
+		assert.NoError(t, err)
+
+		repo.EXPECT().RevokeOthers(gomock.Any(), 4, 21).Return(int64(1), nil)
+		assert.NoError(t, uc.RevokeOthers(ctx, 4, 21))
+
+		repo.EXPECT().IsActive(gomock.Any(), 4, 20).Times(1).Return(false, nil)
+		active, err := uc.Active(ctx, 4, 20)
+		assert.NoError(t, err)
+		assert.False(t, active)
+	})
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ svcport.TaskService = (*TaskService)(nil)
+
+// TaskService struct, lets the users poll their background tasks
+type TaskService struct {
+	logger         *zap.SugaredLogger
+	tasks          svcport.TaskQueue
+	contextTimeOut time.Duration
+}
+
+// NewTaskService creates a new task service
+func NewTaskService(logger *zap.SugaredLogger, tasks svcport.TaskQueue, timeout time.Duration) *TaskService {
+	return &TaskService{
+		logger:         logger,
+		tasks:          tasks,
+		contextTimeOut: timeout,
+	}
+}
+
+// GetTask returns a task of the user, the tasks of other users are not found
+func (svc *TaskService) GetTask(c context.Context, uid int, id string) (*domain.Task, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	task, err := svc.tasks.Get(ctx, id)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if task.UserID != uid {
+		return nil, httpErrors.ErrTaskNotFound
+	}
+
+	return task, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *TaskService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrTaskNotFound) {
+			return httpErrors.ErrTaskNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
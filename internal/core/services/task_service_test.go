@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestTaskService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	tasks := mock.NewMockTaskQueue(mockCtrl)
+
+	uc := NewTaskService(logger.Sugar(), tasks, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		tasks.EXPECT().Get(gomock.Any(), "task-1").Return(&domain.Task{ID: "task-1", UserID: 1, Status: domain.TaskRunning}, nil)
+
+		task, err := uc.GetTask(ctx, 1, "task-1")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TaskRunning, task.Status)
+	})
+
+	t.Run("Task Of Another User", func(t *testing.T) {
+		tasks.EXPECT().Get(gomock.Any(), "task-1").Return(&domain.Task{ID: "task-1", UserID: 2}, nil)
+
+		_, err := uc.GetTask(ctx, 1, "task-1")
+		assert.ErrorIs(t, err, httpErrors.ErrTaskNotFound)
+	})
+
+	t.Run("Storage Error", func(t *testing.T) {
+		tasks.EXPECT().Get(gomock.Any(), "task-1").Return(nil, sql.ErrConnDone)
+
+		_, err := uc.GetTask(ctx, 1, "task-1")
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+	})
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/core/totp"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"time"
+)
+
+var _ svcport.TwoFactorService = (*TwoFactorService)(nil)
+
+// TwoFactorService struct
+type TwoFactorService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.TwoFactorRepository
+	users          repport.AuthRepository
+	params         totp.Params
+	issuer         string
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(logger *zap.SugaredLogger, repo repport.TwoFactorRepository, users repport.AuthRepository, issuer string, timeout time.Duration) *TwoFactorService {
+	return &TwoFactorService{
+		logger:         logger,
+		repository:     repo,
+		users:          users,
+		params:         totp.DefaultParams,
+		issuer:         issuer,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// Enroll creates a new secret for the user. The enrollment stays pending until it's confirmed.
+func (svc *TwoFactorService) Enroll(c context.Context, uid int) (*domain.TwoFactorEnrollment, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	current, err := svc.repository.GetTOTP(ctx, uid)
+	if err != nil && !errors.Is(err, httpErrors.ErrTwoFactorNotEnrolled) {
+		return nil, svc.handleError(ctx, err)
+	}
+	if current.Enabled() {
+		return nil, httpErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	user, err := svc.users.FindByID(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+	if err := svc.repository.SaveTOTP(ctx, uid, secret); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: svc.params.ProvisioningURI(svc.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves the app is set up
+func (svc *TwoFactorService) Confirm(c context.Context, uid int, code string) (*domain.RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	current, err := svc.repository.GetTOTP(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if current.Enabled() {
+		return nil, httpErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	step, err := svc.validateCode(current, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := svc.recoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.repository.EnableTOTP(ctx, uid, step, hashes); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.RecoveryCodesResponse{Codes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the previous ones stop working
+func (svc *TwoFactorService) RegenerateRecoveryCodes(c context.Context, uid int, code string) (*domain.RecoveryCodesResponse, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.verifyTOTP(ctx, uid, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := svc.recoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.repository.ReplaceRecoveryCodes(ctx, uid, hashes); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.RecoveryCodesResponse{Codes: codes}, nil
+}
+
+// Disable turns off two-factor authentication, it requires a TOTP or a recovery code
+func (svc *TwoFactorService) Disable(c context.Context, uid int, code string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.Verify(ctx, uid, code); err != nil {
+		return err
+	}
+	if err := svc.repository.DeleteTOTP(ctx, uid); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// IsEnabled reports if the sign in of the user needs a second factor
+func (svc *TwoFactorService) IsEnabled(c context.Context, uid int) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	current, err := svc.repository.GetTOTP(ctx, uid)
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrTwoFactorNotEnrolled) {
+			return false, nil
+		}
+		return false, svc.handleError(ctx, err)
+	}
+
+	return current.Enabled(), nil
+}
+
+// Verify checks and consumes a TOTP code or a recovery code
+func (svc *TwoFactorService) Verify(c context.Context, uid int, code string) error {
+	if len(code) == svc.params.Digits {
+		return svc.verifyTOTP(c, uid, code)
+	}
+
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	enabled, err := svc.IsEnabled(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return httpErrors.ErrTwoFactorNotEnabled
+	}
+	if err := svc.repository.UseRecoveryCode(ctx, uid, totp.HashRecoveryCode(code)); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// verifyTOTP checks a TOTP code of an enabled enrollment and marks its time step as used
+func (svc *TwoFactorService) verifyTOTP(c context.Context, uid int, code string) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	current, err := svc.repository.GetTOTP(ctx, uid)
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrTwoFactorNotEnrolled) {
+			return httpErrors.ErrTwoFactorNotEnabled
+		}
+		return svc.handleError(ctx, err)
+	}
+	if !current.Enabled() {
+		return httpErrors.ErrTwoFactorNotEnabled
+	}
+
+	step, err := svc.validateCode(current, code)
+	if err != nil {
+		return err
+	}
+	if err := svc.repository.UseTOTPStep(ctx, uid, step); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// validateCode checks the code against the secret without consuming it
+func (svc *TwoFactorService) validateCode(current *domain.UserTOTP, code string) (int64, error) {
+	secret, err := totp.DecodeSecret(current.Secret)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return 0, httpErrors.InternalServerError
+	}
+	step, ok := svc.params.Validate(secret, code, svc.now(), current.LastUsedStep)
+	if !ok {
+		return 0, httpErrors.ErrInvalidTwoFactorCode
+	}
+	return step, nil
+}
+
+// recoveryCodes generates the codes shown to the user and the hashes to store
+func (svc *TwoFactorService) recoveryCodes() ([]string, []string, error) {
+	codes, err := totp.GenerateRecoveryCodes(totp.RecoveryCodesCount)
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, nil, httpErrors.InternalServerError
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, totp.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *TwoFactorService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrTwoFactorNotEnrolled) {
+			return httpErrors.ErrTwoFactorNotEnrolled
+		} else if errors.Is(err, httpErrors.ErrTwoFactorAlreadyEnabled) {
+			return httpErrors.ErrTwoFactorAlreadyEnabled
+		} else if errors.Is(err, httpErrors.ErrInvalidTwoFactorCode) {
+			return httpErrors.ErrInvalidTwoFactorCode
+		} else if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return httpErrors.ErrUserNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/totp"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrollTwoFactor(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockTwoFactorRepository(mockCtrl)
+	users := mock.NewMockAuthRepository(mockCtrl)
+
+	uc := NewTwoFactorService(slogger, repo, users, "M Bonds", 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(nil, httpErrors.ErrTwoFactorNotEnrolled)
+		users.EXPECT().FindByID(gomock.Any(), 1).Return(&domain.User{ID: "1", Email: "gini@mail.com"}, nil)
+		repo.EXPECT().SaveTOTP(gomock.Any(), 1, gomock.Any()).Return(nil)
+
+		enrollment, err := uc.Enroll(ctx, 1)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/"))
+	})
+
+	t.Run("Already Enabled", func(t *testing.T) {
+		now := time.Now()
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, ConfirmedAt: &now}, nil)
+
+		enrollment, err := uc.Enroll(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.ErrTwoFactorAlreadyEnabled)
+		assert.Nil(t, enrollment)
+	})
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockTwoFactorRepository(mockCtrl)
+	users := mock.NewMockAuthRepository(mockCtrl)
+
+	uc := NewTwoFactorService(slogger, repo, users, "M Bonds", 2*time.Second)
+	now := time.Unix(1700000000, 0)
+	uc.now = func() time.Time { return now }
+
+	encoded, _ := totp.GenerateSecret()
+	secret, _ := totp.DecodeSecret(encoded)
+	code := totp.DefaultParams.Code(secret, now)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, Secret: encoded}, nil)
+		repo.EXPECT().EnableTOTP(gomock.Any(), 1, totp.DefaultParams.Step(now), gomock.Len(totp.RecoveryCodesCount)).Return(nil)
+
+		resp, err := uc.Confirm(ctx, 1, code)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Codes, totp.RecoveryCodesCount)
+	})
+
+	t.Run("Invalid Code", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, Secret: encoded}, nil)
+		repo.EXPECT().EnableTOTP(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		wrong := string('0'+('1'+code[0]-'0')%10) + code[1:]
+		resp, err := uc.Confirm(ctx, 1, wrong)
+		assert.ErrorIs(t, err, httpErrors.ErrInvalidTwoFactorCode)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockTwoFactorRepository(mockCtrl)
+	users := mock.NewMockAuthRepository(mockCtrl)
+
+	uc := NewTwoFactorService(slogger, repo, users, "M Bonds", 2*time.Second)
+	now := time.Unix(1700000000, 0)
+	uc.now = func() time.Time { return now }
+
+	encoded, _ := totp.GenerateSecret()
+	secret, _ := totp.DecodeSecret(encoded)
+	code := totp.DefaultParams.Code(secret, now)
+	step := totp.DefaultParams.Step(now)
+	confirmed := now.Add(-time.Hour)
+	ctx := context.Background()
+
+	t.Run("TOTP", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, Secret: encoded, ConfirmedAt: &confirmed}, nil)
+		repo.EXPECT().UseTOTPStep(gomock.Any(), 1, step).Return(nil)
+
+		assert.NoError(t, uc.Verify(ctx, 1, code))
+	})
+
+	t.Run("Replayed TOTP", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, Secret: encoded, ConfirmedAt: &confirmed, LastUsedStep: step + 1}, nil)
+
+		assert.ErrorIs(t, uc.Verify(ctx, 1, code), httpErrors.ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("Recovery Code", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&domain.UserTOTP{UserID: 1, Secret: encoded, ConfirmedAt: &confirmed}, nil)
+		repo.EXPECT().UseRecoveryCode(gomock.Any(), 1, totp.HashRecoveryCode("abcde-12345")).Return(nil)
+
+		assert.NoError(t, uc.Verify(ctx, 1, "abcde-12345"))
+	})
+
+	t.Run("Not Enabled", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), 1).Return(nil, httpErrors.ErrTwoFactorNotEnrolled)
+
+		assert.ErrorIs(t, uc.Verify(ctx, 1, code), httpErrors.ErrTwoFactorNotEnabled)
+	})
+}
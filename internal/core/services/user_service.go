@@ -13,7 +13,7 @@ import (
 
 var _ svcport.UserService = (*UserService)(nil)
 
-// UserProfile struct
+// UserService struct
 type UserService struct {
 	logger         *zap.SugaredLogger
 	repository     repport.UserRepository
@@ -34,100 +34,85 @@ func (svc *UserService) GetProfile(c context.Context, uid int) (*domain.UserProf
 	// context
 	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
 	defer cancel()
-	up, err := svc.repository.GetProfile(ctx, uid)
 
+	up, err := svc.repository.GetProfile(ctx, uid)
 	if err != nil {
-		svc.logger.Error(err.Error())
-
-		select {
-		case <-ctx.Done():
-			return nil, httpErrors.ErrTimeout
-		default:
-			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
-				return nil, httpErrors.BadQueryParams
-			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
-				return nil, httpErrors.ErrBadEmailOrPassword
-			} else {
-				return nil, httpErrors.InternalServerError
-			}
-		}
+		return nil, svc.handleError(ctx, err)
 	}
 
 	return up, nil
 }
 
-// UpdateProfile repository method.
+// UpdateProfile changes only the fields present in the request.
 func (svc *UserService) UpdateProfile(c context.Context, data *domain.UserProfileRequest) (*domain.UserProfile, error) {
 	// context
 	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
 	defer cancel()
 
-	// data
-	var up, err = svc.repository.GetProfile(ctx, *data.UserID)
+	up, err := svc.repository.GetProfile(ctx, *data.UserID)
 	if err != nil {
-		switch {
-		case errors.Is(err, httpErrors.ErrNoRecords):
-			return nil, httpErrors.ErrNoRecords
-		default:
-			return nil, httpErrors.InternalServerError
-		}
+		return nil, svc.handleError(ctx, err)
 	}
 	if data.UserName != nil {
 		up.UserName = *data.UserName
 	}
-	if data.UserPhoto != nil {
-		up.UserPhoto = *data.UserPhoto
-	}
 	if data.Gender != nil {
 		up.Gender = *data.Gender
 	}
 
-	// Call repository
-	_, err = svc.repository.UpdateProfile(ctx, up)
-
+	up, err = svc.repository.UpdateProfile(ctx, up)
 	if err != nil {
-		svc.logger.Error(err.Error())
-
-		select {
-		case <-ctx.Done():
-			return nil, httpErrors.ErrTimeout
-		default:
-			if errors.Is(err, httpErrors.ErrAlreadyExists) {
-				return nil, httpErrors.ErrAlreadyExists
-			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
-				return nil, httpErrors.ErrUserNotFound
-			} else {
-				return nil, httpErrors.ErrBadEmailOrPassword
-			}
-		}
+		return nil, svc.handleError(ctx, err)
 	}
 
 	return up, nil
 }
 
-// GetBonds repository method.
+// GetBonds lists the bonds created by the user.
 func (svc *UserService) GetBonds(c context.Context, uid int) ([]*domain.Bond, error) {
 	// context
 	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
 	defer cancel()
-	list, err := svc.repository.GetBonds(ctx, uid)
 
+	list, err := svc.repository.GetBonds(ctx, uid)
 	if err != nil {
-		svc.logger.Error(err.Error())
-
-		select {
-		case <-ctx.Done():
-			return nil, httpErrors.ErrTimeout
-		default:
-			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
-				return nil, httpErrors.BadQueryParams
-			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
-				return nil, httpErrors.ErrBadEmailOrPassword
-			} else {
-				return nil, httpErrors.InternalServerError
-			}
+		if errors.Is(err, httpErrors.ErrNoRecords) {
+			return make([]*domain.Bond, 0), nil
 		}
+		return nil, svc.handleError(ctx, err)
 	}
 
 	return list, nil
 }
+
+// GetTransactions lists the transactions where the user is the seller or the buyer.
+func (svc *UserService) GetTransactions(c context.Context, uid int) ([]*domain.Transaction, error) {
+	// context
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.GetTransactions(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *UserService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrUserNotFound) {
+			return httpErrors.ErrUserNotFound
+		} else if errors.Is(err, httpErrors.ErrUsernameTaken) {
+			return httpErrors.ErrUsernameTaken
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
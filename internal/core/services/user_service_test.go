@@ -1 +1,156 @@
 package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGetProfile(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockUserRepository(mockCtrl)
+
+	uc := NewUserService(slogger, repo, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserName: "ginigini"}, nil)
+
+		profile, err := uc.GetProfile(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "ginigini", profile.UserName)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 9).Return(nil, httpErrors.ErrUserNotFound)
+
+		profile, err := uc.GetProfile(ctx, 9)
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
+		assert.Nil(t, profile)
+	})
+
+	t.Run("Repository Failed", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(nil, httpErrors.ErrScanData)
+
+		profile, err := uc.GetProfile(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+		assert.Nil(t, profile)
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockUserRepository(mockCtrl)
+
+	uc := NewUserService(slogger, repo, 2*time.Second)
+	ctx := context.Background()
+	uid := 1
+
+	t.Run("Only Changes The Fields Sent", func(t *testing.T) {
+		gender := "non_binary"
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1, UserName: "ginigini", UserPhoto: "default_profile.png", Gender: "undisclosed"}, nil)
+		repo.EXPECT().UpdateProfile(gomock.Any(), &domain.UserProfile{UserID: 1, UserName: "ginigini", UserPhoto: "default_profile.png", Gender: "non_binary"}).
+			DoAndReturn(func(_ context.Context, up *domain.UserProfile) (*domain.UserProfile, error) {
+				return up, nil
+			})
+
+		profile, err := uc.UpdateProfile(ctx, &domain.UserProfileRequest{UserID: &uid, Gender: &gender})
+		assert.NoError(t, err)
+		assert.Equal(t, "non_binary", profile.Gender)
+		assert.Equal(t, "ginigini", profile.UserName)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(nil, httpErrors.ErrUserNotFound)
+		repo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Times(0)
+
+		profile, err := uc.UpdateProfile(ctx, &domain.UserProfileRequest{UserID: &uid})
+		assert.ErrorIs(t, err, httpErrors.ErrUserNotFound)
+		assert.Nil(t, profile)
+	})
+
+	t.Run("Update Failed", func(t *testing.T) {
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1}, nil)
+		repo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrExecuteStatement)
+
+		profile, err := uc.UpdateProfile(ctx, &domain.UserProfileRequest{UserID: &uid})
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+		assert.Nil(t, profile)
+	})
+
+	t.Run("Username Taken", func(t *testing.T) {
+		username := "takenname"
+		repo.EXPECT().GetProfile(gomock.Any(), 1).Return(&domain.UserProfile{UserID: 1}, nil)
+		repo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Return(nil, httpErrors.ErrUsernameTaken)
+
+		profile, err := uc.UpdateProfile(ctx, &domain.UserProfileRequest{UserID: &uid, UserName: &username})
+		assert.ErrorIs(t, err, httpErrors.ErrUsernameTaken)
+		assert.Nil(t, profile)
+	})
+}
+
+func TestGetUserBonds(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockUserRepository(mockCtrl)
+
+	uc := NewUserService(slogger, repo, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().GetBonds(gomock.Any(), 1).Return([]*domain.Bond{{Name: "Bond A", IsOwner: true}}, nil)
+
+		list, err := uc.GetBonds(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("No Records", func(t *testing.T) {
+		repo.EXPECT().GetBonds(gomock.Any(), 1).Return(nil, httpErrors.ErrNoRecords)
+
+		list, err := uc.GetBonds(ctx, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestGetUserTransactions(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockUserRepository(mockCtrl)
+
+	uc := NewUserService(slogger, repo, 2*time.Second)
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		repo.EXPECT().GetTransactions(gomock.Any(), 1).Return([]*domain.Transaction{{ID: 9, SellerID: 2, BuyerID: 1, Status: domain.TransactionSettled}}, nil)
+
+		list, err := uc.GetTransactions(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("Repository Failed", func(t *testing.T) {
+		repo.EXPECT().GetTransactions(gomock.Any(), 1).Return(nil, httpErrors.ErrExecuteQuery)
+
+		list, err := uc.GetTransactions(ctx, 1)
+		assert.ErrorIs(t, err, httpErrors.InternalServerError)
+		assert.Nil(t, list)
+	})
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// deliveriesLimit deliveries listed per webhook, newest first
+	deliveriesLimit = 50
+	// maxDeliveryError characters of the error kept with a delivery
+	maxDeliveryError = 255
+)
+
+var _ svcport.WebhookService = (*WebhookService)(nil)
+
+// WebhookService struct. The domain events are turned into a delivery per
+// webhook and queued on NATS, Deliver sends them.
+type WebhookService struct {
+	logger         *zap.SugaredLogger
+	repository     repport.WebhookRepository
+	sender         svcport.WebhookSender
+	publisher      svcport.EventPublisher
+	maxAttempts    int
+	now            func() time.Time
+	contextTimeOut time.Duration
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(logger *zap.SugaredLogger, repo repport.WebhookRepository, sender svcport.WebhookSender, publisher svcport.EventPublisher, maxAttempts int, timeout time.Duration) *WebhookService {
+	return &WebhookService{
+		logger:         logger,
+		repository:     repo,
+		sender:         sender,
+		publisher:      publisher,
+		maxAttempts:    maxAttempts,
+		now:            time.Now,
+		contextTimeOut: timeout,
+	}
+}
+
+// Create registers a webhook, the secret is only returned here
+func (svc *WebhookService) Create(c context.Context, uid int, data *domain.WebhookRequest) (*domain.WebhookCreated, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		svc.logger.Error(err.Error())
+		return nil, httpErrors.InternalServerError
+	}
+
+	hook := &domain.Webhook{
+		UserID:    uid,
+		URL:       data.URL,
+		Events:    uniqueStrings(data.Events),
+		Secret:    secret,
+		CreatedAt: svc.now(),
+	}
+	if err := svc.repository.Create(ctx, hook); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return &domain.WebhookCreated{Webhook: hook, Secret: secret}, nil
+}
+
+// List returns the webhooks of the user
+func (svc *WebhookService) List(c context.Context, uid int) ([]*domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	list, err := svc.repository.ListByUser(ctx, uid)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// Delete deletes a webhook of the user with its deliveries
+func (svc *WebhookService) Delete(c context.Context, uid int, id int) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if err := svc.repository.Delete(ctx, uid, id); err != nil {
+		return svc.handleError(ctx, err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook of the user
+func (svc *WebhookService) ListDeliveries(c context.Context, uid int, id int) ([]*domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if _, err := svc.repository.GetByID(ctx, uid, id); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	list, err := svc.repository.ListDeliveries(ctx, id, deliveriesLimit)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return list, nil
+}
+
+// Redeliver resets a delivery of a webhook of the user and queues it again
+func (svc *WebhookService) Redeliver(c context.Context, uid int, id int, deliveryID int) (*domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	if _, err := svc.repository.GetByID(ctx, uid, id); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	delivery, err := svc.repository.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if delivery.WebhookID != id {
+		return nil, httpErrors.ErrWebhookDeliveryNotFound
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.StatusCode = nil
+	delivery.Error = ""
+	delivery.DeliveredAt = nil
+	if err := svc.repository.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.publisher.PublishEvent(domain.WebhookDeliverSubject, domain.WebhookJob{DeliveryID: delivery.ID}); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return delivery, nil
+}
+
+// Ping sends a test delivery once, without retries, and returns its result.
+// The request has the timeout of the sender.
+func (svc *WebhookService) Ping(ctx context.Context, uid int, id int) (*domain.WebhookDelivery, error) {
+	hook, err := svc.repository.GetByID(ctx, uid, id)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	data, _ := json.Marshal(map[string]int{"webhook_id": hook.ID})
+	delivery, err := svc.newDelivery(hook, uuid.NewString(), domain.WebhookEventPing, svc.now().UTC(), data)
+	if err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.repository.CreateDelivery(ctx, delivery); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+	if err := svc.send(ctx, hook, delivery, 1); err != nil {
+		return nil, svc.handleError(ctx, err)
+	}
+
+	return delivery, nil
+}
+
+// HandleEvent queues a delivery of the event for every webhook of its users
+// subscribed to it. It can run again for the same event, each webhook keeps a
+// single delivery of an event.
+func (svc *WebhookService) HandleEvent(c context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(c, svc.contextTimeOut)
+	defer cancel()
+
+	hooks, err := svc.repository.ListByUsers(ctx, event.UserIDs)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if !hook.Subscribed(event.Type) {
+			continue
+		}
+		delivery, err := svc.newDelivery(hook, event.ID, event.Type, event.OccurredAt, event.Data)
+		if err != nil {
+			return err
+		}
+		if err := svc.repository.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		if err := svc.publisher.PublishEvent(domain.WebhookDeliverSubject, domain.WebhookJob{DeliveryID: delivery.ID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver sends a pending delivery. It returns an error while the delivery
+// has attempts left, so it is retried with the backoff of the queue.
+func (svc *WebhookService) Deliver(ctx context.Context, job domain.WebhookJob) error {
+	delivery, err := svc.repository.GetDelivery(ctx, job.DeliveryID)
+	if errors.Is(err, httpErrors.ErrWebhookDeliveryNotFound) {
+		// the webhook was deleted
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if delivery.Status != domain.WebhookDeliveryPending {
+		return nil
+	}
+
+	hook, err := svc.repository.FindByID(ctx, delivery.WebhookID)
+	if errors.Is(err, httpErrors.ErrWebhookNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return svc.send(ctx, hook, delivery, svc.maxAttempts)
+}
+
+func (svc *WebhookService) newDelivery(hook *domain.Webhook, eventID string, eventType string, occurredAt time.Time, data json.RawMessage) (*domain.WebhookDelivery, error) {
+	payload, err := json.Marshal(domain.WebhookPayload{ID: eventID, Type: eventType, OccurredAt: occurredAt, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   payload,
+		Status:    domain.WebhookDeliveryPending,
+		CreatedAt: svc.now(),
+	}, nil
+}
+
+// send makes an attempt and stores its result. The delivery fails for good
+// after maxAttempts, before that a failed attempt returns an error.
+func (svc *WebhookService) send(ctx context.Context, hook *domain.Webhook, delivery *domain.WebhookDelivery, maxAttempts int) error {
+	now := svc.now()
+	header := http.Header{}
+	header.Set(httpUtils.WebhookEventHeader, delivery.EventType)
+	header.Set(httpUtils.WebhookDeliveryHeader, strconv.Itoa(delivery.ID))
+	header.Set(httpUtils.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(httpUtils.WebhookSignatureHeader, httpUtils.SignWebhook(hook.Secret, now.Unix(), delivery.Payload))
+
+	status, err := svc.sender.Send(ctx, hook.URL, header, delivery.Payload)
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = nil
+	if status > 0 {
+		delivery.StatusCode = &status
+	}
+	switch {
+	case err != nil:
+		delivery.Error = truncate(err.Error(), maxDeliveryError)
+	case status < 200 || status > 299:
+		delivery.Error = fmt.Sprintf("unexpected status %d", status)
+	default:
+		delivery.Error = ""
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	}
+	if delivery.Status == domain.WebhookDeliveryPending && delivery.Attempts >= maxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+	}
+
+	if err := svc.repository.UpdateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		return fmt.Errorf("webhook delivery %d failed: %s", delivery.ID, delivery.Error)
+	}
+	return nil
+}
+
+// handleError maps repository errors to service errors
+func (svc *WebhookService) handleError(ctx context.Context, err error) error {
+	svc.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		return httpErrors.ErrTimeout
+	default:
+		if errors.Is(err, httpErrors.ErrWebhookNotFound) {
+			return httpErrors.ErrWebhookNotFound
+		} else if errors.Is(err, httpErrors.ErrWebhookDeliveryNotFound) {
+			return httpErrors.ErrWebhookDeliveryNotFound
+		} else {
+			return httpErrors.InternalServerError
+		}
+	}
+}
+
+// webhookBackoff the waits between the attempts of a delivery, doubled after each one
+func webhookBackoff(base time.Duration, attempts int) []time.Duration {
+	var delays = make([]time.Duration, 0, attempts)
+	for i := 0; i < attempts; i++ {
+		delays = append(delays, base<<i)
+	}
+	return delays
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
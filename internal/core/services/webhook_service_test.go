@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWebhookService(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	slogger := logger.Sugar()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	repo := mock.NewMockWebhookRepository(mockCtrl)
+	sender := mock.NewMockWebhookSender(mockCtrl)
+	publisher := mock.NewMockEventPublisher(mockCtrl)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewWebhookService(slogger, repo, sender, publisher, 3, 2*time.Second)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	hook := &domain.Webhook{ID: 4, UserID: 1, URL: "https://example.com/hook", Events: []string{domain.EventTradeExecuted}, Secret: "secret"}
+	payload := json.RawMessage(`{"id":"evt","type":"trade.executed"}`)
+
+	t.Run("Create", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, w *domain.Webhook) error {
+			w.ID = 4
+			return nil
+		})
+
+		created, err := uc.Create(ctx, 1, &domain.WebhookRequest{URL: "https://example.com/hook", Events: []string{"trade.executed", "trade.executed", "listing.created"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, created.ID)
+		assert.Equal(t, []string{"trade.executed", "listing.created"}, created.Events)
+		assert.Len(t, created.Secret, 64)
+		assert.Equal(t, created.Secret, created.Webhook.Secret)
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		repo.EXPECT().Delete(gomock.Any(), 1, 9).Return(httpErrors.ErrWebhookNotFound)
+
+		err := uc.Delete(ctx, 1, 9)
+		assert.ErrorIs(t, err, httpErrors.ErrWebhookNotFound)
+	})
+
+	t.Run("HandleEvent queues the subscribed webhooks", func(t *testing.T) {
+		event := domain.Event{ID: "evt", Type: domain.EventTradeExecuted, UserIDs: []int{1, 2}, OccurredAt: now, Data: json.RawMessage(`{"market_bond_id":7}`)}
+		other := &domain.Webhook{ID: 5, UserID: 2, Events: []string{domain.EventListingCreated}}
+		repo.EXPECT().ListByUsers(gomock.Any(), []int{1, 2}).Return([]*domain.Webhook{hook, other}, nil)
+		repo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Equal(t, 4, d.WebhookID)
+			assert.Equal(t, "evt", d.EventID)
+			assert.Equal(t, domain.WebhookDeliveryPending, d.Status)
+			assert.JSONEq(t, `{"id":"evt","type":"trade.executed","occurred_at":"2024-01-01T12:00:00Z","data":{"market_bond_id":7}}`, string(d.Payload))
+			d.ID = 11
+			return nil
+		})
+		publisher.EXPECT().PublishEvent(domain.WebhookDeliverSubject, domain.WebhookJob{DeliveryID: 11}).Return(nil)
+
+		err := uc.HandleEvent(ctx, event)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Deliver signs the payload", func(t *testing.T) {
+		delivery := &domain.WebhookDelivery{ID: 11, WebhookID: 4, EventType: domain.EventTradeExecuted, Payload: payload, Status: domain.WebhookDeliveryPending}
+		repo.EXPECT().GetDelivery(gomock.Any(), 11).Return(delivery, nil)
+		repo.EXPECT().FindByID(gomock.Any(), 4).Return(hook, nil)
+		sender.EXPECT().Send(gomock.Any(), hook.URL, gomock.Any(), []byte(payload)).
+			DoAndReturn(func(_ context.Context, _ string, header http.Header, body []byte) (int, error) {
+				assert.Equal(t, "trade.executed", header.Get(httpUtils.WebhookEventHeader))
+				assert.Equal(t, "11", header.Get(httpUtils.WebhookDeliveryHeader))
+				assert.Equal(t, "1704110400", header.Get(httpUtils.WebhookTimestampHeader))
+				assert.Equal(t, httpUtils.SignWebhook("secret", now.Unix(), body), header.Get(httpUtils.WebhookSignatureHeader))
+				return http.StatusNoContent, nil
+			})
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		err := uc.Deliver(ctx, domain.WebhookJob{DeliveryID: 11})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, *delivery.StatusCode)
+		assert.Equal(t, now, *delivery.DeliveredAt)
+	})
+
+	t.Run("Deliver failure is retried", func(t *testing.T) {
+		delivery := &domain.WebhookDelivery{ID: 12, WebhookID: 4, Payload: payload, Status: domain.WebhookDeliveryPending}
+		repo.EXPECT().GetDelivery(gomock.Any(), 12).Return(delivery, nil)
+		repo.EXPECT().FindByID(gomock.Any(), 4).Return(hook, nil)
+		sender.EXPECT().Send(gomock.Any(), hook.URL, gomock.Any(), gomock.Any()).Return(http.StatusBadGateway, nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		err := uc.Deliver(ctx, domain.WebhookJob{DeliveryID: 12})
+		assert.Error(t, err)
+		assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, "unexpected status 502", delivery.Error)
+		assert.Nil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("Deliver last attempt fails the delivery", func(t *testing.T) {
+		delivery := &domain.WebhookDelivery{ID: 13, WebhookID: 4, Payload: payload, Status: domain.WebhookDeliveryPending, Attempts: 2}
+		repo.EXPECT().GetDelivery(gomock.Any(), 13).Return(delivery, nil)
+		repo.EXPECT().FindByID(gomock.Any(), 4).Return(hook, nil)
+		sender.EXPECT().Send(gomock.Any(), hook.URL, gomock.Any(), gomock.Any()).Return(0, errors.New("connection refused"))
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		err := uc.Deliver(ctx, domain.WebhookJob{DeliveryID: 13})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Nil(t, delivery.StatusCode)
+		assert.Equal(t, "connection refused", delivery.Error)
+	})
+
+	t.Run("Deliver skips finished deliveries", func(t *testing.T) {
+		repo.EXPECT().GetDelivery(gomock.Any(), 14).Return(&domain.WebhookDelivery{ID: 14, Status: domain.WebhookDeliverySucceeded}, nil)
+		repo.EXPECT().GetDelivery(gomock.Any(), 15).Return(nil, httpErrors.ErrWebhookDeliveryNotFound)
+
+		assert.NoError(t, uc.Deliver(ctx, domain.WebhookJob{DeliveryID: 14}))
+		assert.NoError(t, uc.Deliver(ctx, domain.WebhookJob{DeliveryID: 15}))
+	})
+
+	t.Run("Redeliver", func(t *testing.T) {
+		code := http.StatusInternalServerError
+		delivery := &domain.WebhookDelivery{ID: 13, WebhookID: 4, Status: domain.WebhookDeliveryFailed, Attempts: 3, StatusCode: &code, Error: "unexpected status 500"}
+		repo.EXPECT().GetByID(gomock.Any(), 1, 4).Return(hook, nil)
+		repo.EXPECT().GetDelivery(gomock.Any(), 13).Return(delivery, nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+		publisher.EXPECT().PublishEvent(domain.WebhookDeliverSubject, domain.WebhookJob{DeliveryID: 13}).Return(nil)
+
+		result, err := uc.Redeliver(ctx, 1, 4, 13)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WebhookDeliveryPending, result.Status)
+		assert.Equal(t, 0, result.Attempts)
+		assert.Nil(t, result.StatusCode)
+		assert.Empty(t, result.Error)
+	})
+
+	t.Run("Redeliver of another webhook", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), 1, 4).Return(hook, nil)
+		repo.EXPECT().GetDelivery(gomock.Any(), 20).Return(&domain.WebhookDelivery{ID: 20, WebhookID: 5}, nil)
+
+		_, err := uc.Redeliver(ctx, 1, 4, 20)
+		assert.ErrorIs(t, err, httpErrors.ErrWebhookDeliveryNotFound)
+	})
+
+	t.Run("Ping is sent once", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), 1, 4).Return(hook, nil)
+		repo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+			assert.Equal(t, domain.WebhookEventPing, d.EventType)
+			d.ID = 30
+			return nil
+		})
+		sender.EXPECT().Send(gomock.Any(), hook.URL, gomock.Any(), gomock.Any()).Return(http.StatusNotFound, nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil)
+
+		delivery, err := uc.Ping(ctx, 1, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, http.StatusNotFound, *delivery.StatusCode)
+		var body domain.WebhookPayload
+		assert.NoError(t, json.Unmarshal(delivery.Payload, &body))
+		assert.JSONEq(t, `{"webhook_id":4}`, string(body.Data))
+	})
+
+	t.Run("Ping Not Found", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), 1, 9).Return(nil, httpErrors.ErrWebhookNotFound)
+
+		_, err := uc.Ping(ctx, 1, 9)
+		assert.ErrorIs(t, err, httpErrors.ErrWebhookNotFound)
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second}, webhookBackoff(10*time.Second, 3))
+}
@@ -0,0 +1,136 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm HMAC function used to derive the codes
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+const secretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Params TOTP parameters (RFC 6238)
+type Params struct {
+	Digits    int
+	Period    int64
+	Algorithm Algorithm
+	// Skew number of time steps accepted before and after the current one
+	Skew int64
+}
+
+// DefaultParams the parameters understood by every authenticator app
+var DefaultParams = Params{
+	Digits:    6,
+	Period:    30,
+	Algorithm: SHA1,
+	Skew:      1,
+}
+
+// GenerateSecret returns a random base32 encoded secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// DecodeSecret decodes a base32 secret, padded or not
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	return encoding.DecodeString(secret)
+}
+
+// Step returns the time step of t
+func (p Params) Step(t time.Time) int64 {
+	return t.Unix() / p.Period
+}
+
+// Code returns the code of t
+func (p Params) Code(secret []byte, t time.Time) string {
+	return p.hotp(secret, p.Step(t))
+}
+
+// Validate checks the code against the time steps around t. Steps up to
+// lastStep were already used and are rejected to prevent replays.
+// It returns the matched step.
+func (p Params) Validate(secret []byte, code string, t time.Time, lastStep int64) (int64, bool) {
+	if len(code) != p.Digits {
+		return 0, false
+	}
+	current := p.Step(t)
+	for step := current - p.Skew; step <= current+p.Skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(p.hotp(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI returns the otpauth URI encoded in the enrollment QR code
+func (p Params) ProvisioningURI(issuer, account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", string(p.Algorithm))
+	v.Set("digits", strconv.Itoa(p.Digits))
+	v.Set("period", strconv.FormatInt(p.Period, 10))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, v.Encode())
+}
+
+// hotp computes the RFC 4226 code of the counter
+func (p Params) hotp(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(p.hash(), secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < p.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", p.Digits, value%mod)
+}
+
+func (p Params) hash() func() hash.Hash {
+	switch p.Algorithm {
+	case SHA256:
+		return sha256.New
+	case SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238, Appendix B
+func TestCodeRFC6238(t *testing.T) {
+	seeds := map[Algorithm][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	testCases := []struct {
+		unix      int64
+		algorithm Algorithm
+		code      string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.algorithm)+"/"+time.Unix(tc.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			p := Params{Digits: 8, Period: 30, Algorithm: tc.algorithm}
+			assert.Equal(t, tc.code, p.Code(seeds[tc.algorithm], time.Unix(tc.unix, 0)))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	p := DefaultParams
+	now := time.Unix(1111111111, 0)
+	code := p.Code(secret, now)
+
+	t.Run("Current step", func(t *testing.T) {
+		step, ok := p.Validate(secret, code, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, p.Step(now), step)
+	})
+
+	t.Run("Clock skew", func(t *testing.T) {
+		_, ok := p.Validate(secret, code, now.Add(30*time.Second), 0)
+		assert.True(t, ok)
+		_, ok = p.Validate(secret, code, now.Add(-30*time.Second), 0)
+		assert.True(t, ok)
+		_, ok = p.Validate(secret, code, now.Add(90*time.Second), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		_, ok := p.Validate(secret, code, now, p.Step(now))
+		assert.False(t, ok)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		_, ok := p.Validate(secret, "000000", now, 0)
+		assert.False(t, ok)
+		_, ok = p.Validate(secret, code[:5], now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	raw, err := DecodeSecret(strings.ToLower(secret))
+	assert.NoError(t, err)
+	assert.Len(t, raw, secretSize)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := DefaultParams.ProvisioningURI("M Bonds", "gini@mail.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/M Bonds:gini@mail.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "M Bonds", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodesCount)
+	assert.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodesCount)
+	assert.Len(t, codes[0], 11)
+	assert.NotEqual(t, codes[0], codes[1])
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+}
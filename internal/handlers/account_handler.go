@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+var _ handlerPort.AccountHandlers = (*AccountHandlers)(nil)
+
+// NewAccountHandlers creates an instance of account handlers. It has to run
+// after NewUserHandlers, mounting /v1/me later would replace DELETE /v1/me.
+func NewAccountHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AccountService, render *render.Render, validate *validator.Validate) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &AccountHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/me/export", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.GetExportHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.RequestExportHandler)
+	})
+	r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/v1/me", handler.DeleteAccountHandler)
+}
+
+type AccountHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.AccountService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// RequestExportHandler queues an archive with the personal data of the user
+func (h *AccountHandlers) RequestExportHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.RequestExport(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusAccepted, domain.WrapResponse[*domain.DataExport]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetExportHandler returns the status of the last export and its download link
+func (h *AccountHandlers) GetExportHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.GetExport(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.DataExport]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// DeleteAccountHandler deletes the account once the password is confirmed
+func (h *AccountHandlers) DeleteAccountHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.DeleteAccountRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate form
+	err = form.Validate(h.validate)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
+
+	if err := h.service.DeleteAccount(ctx, UserID, form); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "Your account was deleted."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *AccountHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	h.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		var retry *httpErrors.RetryAfterError
+		if errors.As(err, &retry) {
+			tooManyAttempts(h.response, w, retry)
+		} else if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrUserNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserNotFound.Error()})
+		} else if errors.Is(err, httpErrors.ErrExportNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrExportNotFound.Error()})
+		} else if errors.Is(err, httpErrors.ErrBadPassword) {
+			_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBadPassword.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
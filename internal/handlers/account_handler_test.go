@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccountHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockAccountService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Request Export OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/export",
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().RequestExport(gomock.Any(), 1).Times(1).Return(&domain.DataExport{ID: 5, UserID: 1, Status: domain.ExportPending, TaskID: "task-1"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"status":"pending"`)
+				assert.Contains(t, recorder.Body.String(), `"task_id":"task-1"`)
+			},
+		},
+		"Get Export OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/export",
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().GetExport(gomock.Any(), 1).Times(1).Return(&domain.DataExport{ID: 5, UserID: 1, Status: domain.ExportReady, FileKey: "exports/1/a.zip", DownloadURL: "/v1/files/exports/1/a.zip?sig=x"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"download_url"`)
+				assert.NotContains(t, recorder.Body.String(), `"file_key"`)
+			},
+		},
+		"Get Export Not Found": {
+			method: http.MethodGet,
+			url:    "/v1/me/export",
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().GetExport(gomock.Any(), 1).Times(1).Return(nil, httpErrors.ErrExportNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Delete Account OK": {
+			method: http.MethodDelete,
+			url:    "/v1/me",
+			body:   `{"password":"123456"}`,
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().DeleteAccount(gomock.Any(), 1, &domain.DeleteAccountRequest{Password: "123456", IP: "192.0.2.1"}).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Delete Account Wrong Password": {
+			method: http.MethodDelete,
+			url:    "/v1/me",
+			body:   `{"password":"nope"}`,
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().DeleteAccount(gomock.Any(), 1, gomock.Any()).Times(1).Return(httpErrors.ErrBadPassword)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Delete Account Locked": {
+			method: http.MethodDelete,
+			url:    "/v1/me",
+			body:   `{"password":"123456"}`,
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().DeleteAccount(gomock.Any(), 1, gomock.Any()).Times(1).
+					Return(&httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: 1500 * time.Millisecond})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+			},
+		},
+		"Delete Account Without Password": {
+			method: http.MethodDelete,
+			url:    "/v1/me",
+			body:   `{}`,
+			buildStubs: func(uc *mock.MockAccountService) {
+				uc.EXPECT().DeleteAccount(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAccountService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAccountHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
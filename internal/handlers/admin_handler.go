@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/middlewares"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ handlerPort.AdminHandlers = (*AdminHandlers)(nil)
+
+// NewAdminHandlers creates an instance of admin handlers
+func NewAdminHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AdminService, render *render.Render, validate *validator.Validate, rbac *middlewares.RBAC) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &AdminHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/admin", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(tokenAuth))
+		r.Use(jwtauth.Authenticator(tokenAuth))
+		r.Use(rbac.RequireRole(domain.RoleAdmin))
+
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Get("/users", handler.SearchUsersHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Post("/users/{id}/suspend", handler.SuspendUserHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Post("/users/{id}/unsuspend", handler.UnsuspendUserHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Get("/users/{id}/bonds", handler.GetUserBondsHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Get("/users/{id}/transactions", handler.GetUserTransactionsHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageUsers)).Get("/auth-events", handler.SearchAuthEventsHandler)
+		r.With(rbac.RequirePermission(domain.PermissionApproveListings)).Post("/market/{id}/delist", handler.DelistMarketBondHandler)
+		r.With(rbac.RequirePermission(domain.PermissionApproveListings)).Post("/bonds/{id}/freeze", handler.FreezeBondHandler)
+		r.With(rbac.RequirePermission(domain.PermissionApproveListings)).Post("/bonds/{id}/unfreeze", handler.UnfreezeBondHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageJobs)).Get("/jobs", handler.ListJobsHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageJobs)).Get("/jobs/{name}/runs", handler.ListJobRunsHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageJobs)).Post("/jobs/{name}/run", handler.RunJobHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageJobs)).Post("/jobs/{name}/pause", handler.PauseJobHandler)
+		r.With(rbac.RequirePermission(domain.PermissionManageJobs)).Post("/jobs/{name}/resume", handler.ResumeJobHandler)
+		// expvar counters, e.g. the hits and misses of the caches under "cache"
+		r.Method(http.MethodGet, "/metrics", expvar.Handler())
+	})
+}
+
+type AdminHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.AdminService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// SearchUsersHandler finds users by email or username
+func (h *AdminHandlers) SearchUsersHandler(w http.ResponseWriter, req *http.Request) {
+	var search = &domain.UserSearchRequest{Query: req.URL.Query().Get("q")}
+	var err error
+	if v := req.URL.Query().Get("limit"); v != "" {
+		if search.Limit, err = strconv.Atoi(v); err != nil {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+			return
+		}
+	}
+	if v := req.URL.Query().Get("offset"); v != "" {
+		if search.Offset, err = strconv.Atoi(v); err != nil {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+			return
+		}
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.SearchUsers(ctx, actorFromRequest(req), search)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.AdminUser]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// SuspendUserHandler blocks the sign in of a user
+func (h *AdminHandlers) SuspendUserHandler(w http.ResponseWriter, req *http.Request) {
+	uid, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	form, ok := h.readModerationRequest(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.SuspendUser(ctx, actorFromRequest(req), uid, form.Reason); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The user has been suspended."})
+}
+
+// UnsuspendUserHandler reinstates a suspended user
+func (h *AdminHandlers) UnsuspendUserHandler(w http.ResponseWriter, req *http.Request) {
+	uid, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.UnsuspendUser(ctx, actorFromRequest(req), uid); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The user has been unsuspended."})
+}
+
+// GetUserBondsHandler lists the bonds of any user
+func (h *AdminHandlers) GetUserBondsHandler(w http.ResponseWriter, req *http.Request) {
+	uid, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.GetUserBonds(ctx, actorFromRequest(req), uid)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.Bond]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetUserTransactionsHandler lists the transactions of any user
+func (h *AdminHandlers) GetUserTransactionsHandler(w http.ResponseWriter, req *http.Request) {
+	uid, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.GetUserTransactions(ctx, actorFromRequest(req), uid)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.Transaction]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// SearchAuthEventsHandler queries the sign in activity of every account
+func (h *AdminHandlers) SearchAuthEventsHandler(w http.ResponseWriter, req *http.Request) {
+	search, err := parseAuthEventSearch(req)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.SearchAuthEvents(ctx, actorFromRequest(req), search)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.AuthEvent]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// DelistMarketBondHandler withdraws a bond on sale from the market
+func (h *AdminHandlers) DelistMarketBondHandler(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	form, ok := h.readModerationRequest(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.DelistMarketBond(ctx, actorFromRequest(req), id, form.Reason); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The bond has been delisted from the market."})
+}
+
+// FreezeBondHandler blocks a bond from trading
+func (h *AdminHandlers) FreezeBondHandler(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	form, ok := h.readModerationRequest(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.FreezeBond(ctx, actorFromRequest(req), id, form.Reason); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The bond has been frozen."})
+}
+
+// UnfreezeBondHandler allows trading a frozen bond again
+func (h *AdminHandlers) UnfreezeBondHandler(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.UnfreezeBond(ctx, actorFromRequest(req), id); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The bond has been unfrozen."})
+}
+
+// ListJobsHandler lists the scheduled jobs with their state and last run
+func (h *AdminHandlers) ListJobsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	resp, err := h.service.ListJobs(ctx)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.ScheduledJob]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// ListJobRunsHandler lists the latest runs of a job
+func (h *AdminHandlers) ListJobRunsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	resp, err := h.service.ListJobRuns(ctx, chi.URLParam(req, "name"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.JobRun]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RunJobHandler starts a job now, the run is listed in its history
+func (h *AdminHandlers) RunJobHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if err := h.service.RunJob(ctx, actorFromRequest(req), chi.URLParam(req, "name")); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusAccepted, domain.SuccessResponse{Message: "The job has been started."})
+}
+
+// PauseJobHandler stops the scheduled runs of a job
+func (h *AdminHandlers) PauseJobHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if err := h.service.PauseJob(ctx, actorFromRequest(req), chi.URLParam(req, "name")); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The job has been paused."})
+}
+
+// ResumeJobHandler schedules a paused job again
+func (h *AdminHandlers) ResumeJobHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if err := h.service.ResumeJob(ctx, actorFromRequest(req), chi.URLParam(req, "name")); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The job has been resumed."})
+}
+
+// parseAuthEventSearch reads the filters of the auth events, from and to are RFC 3339 dates
+func parseAuthEventSearch(req *http.Request) (*domain.AuthEventSearch, error) {
+	var query = req.URL.Query()
+	var search = &domain.AuthEventSearch{
+		Email:   query.Get("email"),
+		IP:      query.Get("ip"),
+		Event:   query.Get("event"),
+		Outcome: query.Get("outcome"),
+	}
+	for name, dst := range map[string]*int{"user_id": &search.UserID, "limit": &search.Limit, "offset": &search.Offset} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, err
+			}
+			*dst = n
+		}
+	}
+	for name, dst := range map[string]**time.Time{"from": &search.From, "to": &search.To} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, err
+			}
+			*dst = &t
+		}
+	}
+	return search, nil
+}
+
+// readModerationRequest reads the optional reason of a moderation action
+func (h *AdminHandlers) readModerationRequest(w http.ResponseWriter, req *http.Request) (*domain.ModerationRequest, bool) {
+	var form = &domain.ModerationRequest{}
+	if req.ContentLength == 0 {
+		return form, true
+	}
+	if err := httpUtils.ReadJSON(w, req, &form); err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return nil, false
+	}
+	if err := form.Validate(h.validate); err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return nil, false
+	}
+	return form, true
+}
+
+// writeError maps service errors to responses
+func (h *AdminHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrSelfModeration) {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrSelfModeration.Error()})
+		} else if errors.Is(err, httpErrors.ErrUserNotFound) || errors.Is(err, httpErrors.ErrBondNotExist) || errors.Is(err, httpErrors.ErrMarketBondNotExist) || errors.Is(err, httpErrors.ErrJobNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else if errors.Is(err, httpErrors.ErrJobRunning) {
+			_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
+
+// actorFromRequest identifies the admin performing the request
+func actorFromRequest(req *http.Request) *domain.Actor {
+	return &domain.Actor{
+		ID: httpUtils.GetUserIDInJWTHeader(req),
+		IP: httpUtils.ClientIP(req),
+	}
+}
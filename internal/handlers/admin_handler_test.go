@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/middlewares"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSuspendUserHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		roles         []string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockAdminService, roles *mock.MockRoleService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/users/2/suspend",
+			body:  `{"reason": "fraud"}`,
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), []string{domain.RoleAdmin}, domain.PermissionManageUsers).Return(true, nil)
+				uc.EXPECT().
+					SuspendUser(gomock.Any(), &domain.Actor{ID: 1, IP: "192.0.2.1"}, 2, "fraud").
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Without body": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/users/2/suspend",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), 2, "").Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Not Admin": {
+			roles: []string{domain.RoleCustomer},
+			url:   "/v1/admin/users/2/suspend",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				uc.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Invalid URL Param": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/users/ID/suspend",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Not Found": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/users/9/suspend",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), 9, "").Times(1).Return(httpErrors.ErrUserNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Self Moderation": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/users/1/suspend",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), 1, "").Times(1).Return(httpErrors.ErrSelfModeration)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAdminService(ctrl)
+			roles := mock.NewMockRoleService(ctrl)
+			tc.buildStubs(uc, roles)
+
+			recorder := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodPost, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1, "roles": tc.roles})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			slogger := logger.Sugar()
+			r := render.New()
+			NewAdminHandlers(router, slogger, uc, r, validator.New(), middlewares.NewRBAC(slogger, roles, r))
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSearchAuthEventsHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		roles         []string
+		url           string
+		buildStubs    func(uc *mock.MockAdminService, roles *mock.MockRoleService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/auth-events?user_id=2&outcome=failure&from=2024-01-01T00:00:00Z&limit=50",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), []string{domain.RoleAdmin}, domain.PermissionManageUsers).Return(true, nil)
+				uc.EXPECT().
+					SearchAuthEvents(gomock.Any(), &domain.Actor{ID: 1, IP: "192.0.2.1"}, &domain.AuthEventSearch{UserID: 2, Outcome: domain.AuthOutcomeFailure, From: &from, Limit: 50}).
+					Times(1).
+					Return([]*domain.AuthEvent{{ID: 3, Event: domain.AuthEventSignIn, Outcome: domain.AuthOutcomeFailure, Reason: "bad password"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"reason":"bad password"`)
+			},
+		},
+		"Invalid Date": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/auth-events?from=yesterday",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().SearchAuthEvents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Not Admin": {
+			roles: []string{domain.RoleCustomer},
+			url:   "/v1/admin/auth-events",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				uc.EXPECT().SearchAuthEvents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAdminService(ctrl)
+			roles := mock.NewMockRoleService(ctrl)
+			tc.buildStubs(uc, roles)
+
+			recorder := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1, "roles": tc.roles})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			slogger := logger.Sugar()
+			r := render.New()
+			NewAdminHandlers(router, slogger, uc, r, validator.New(), middlewares.NewRBAC(slogger, roles, r))
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRunJobHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		roles         []string
+		url           string
+		buildStubs    func(uc *mock.MockAdminService, roles *mock.MockRoleService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/jobs/job_runs.purge/run",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), []string{domain.RoleAdmin}, domain.PermissionManageJobs).Return(true, nil)
+				uc.EXPECT().
+					RunJob(gomock.Any(), &domain.Actor{ID: 1, IP: "192.0.2.1"}, "job_runs.purge").
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		"Already Running": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/jobs/job_runs.purge/run",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().RunJob(gomock.Any(), gomock.Any(), "job_runs.purge").Times(1).Return(httpErrors.ErrJobRunning)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		"Not Found": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/jobs/nope/pause",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().PauseJob(gomock.Any(), gomock.Any(), "nope").Times(1).Return(httpErrors.ErrJobNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Resume": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/jobs/job_runs.purge/resume",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				uc.EXPECT().ResumeJob(gomock.Any(), gomock.Any(), "job_runs.purge").Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Without Permission": {
+			roles: []string{domain.RoleAdmin},
+			url:   "/v1/admin/jobs/job_runs.purge/run",
+			buildStubs: func(uc *mock.MockAdminService, roles *mock.MockRoleService) {
+				roles.EXPECT().HasPermission(gomock.Any(), gomock.Any(), domain.PermissionManageJobs).Return(false, nil)
+				uc.EXPECT().RunJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAdminService(ctrl)
+			roles := mock.NewMockRoleService(ctrl)
+			tc.buildStubs(uc, roles)
+
+			recorder := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodPost, tc.url, nil)
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1, "roles": tc.roles})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			slogger := logger.Sugar()
+			r := render.New()
+			NewAdminHandlers(router, slogger, uc, r, validator.New(), middlewares.NewRBAC(slogger, roles, r))
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
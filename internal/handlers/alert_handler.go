@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+var _ handlerPort.AlertHandlers = (*AlertHandlers)(nil)
+
+// NewAlertHandlers creates an instance of watchlist and alert handlers
+func NewAlertHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AlertService, render *render.Render, validate *validator.Validate) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &AlertHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/me/watchlist", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListWatchlistHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.AddToWatchlistHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/{bond_uuid}", handler.RemoveFromWatchlistHandler)
+	})
+	r.Route("/v1/me/alerts", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.CreateAlertHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListAlertsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/{id}", handler.GetAlertHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Put("/{id}", handler.UpdateAlertHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/{id}", handler.DeleteAlertHandler)
+	})
+}
+
+type AlertHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.AlertService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// ListWatchlistHandler lists the bonds followed by the user with their market
+func (h *AlertHandlers) ListWatchlistHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.ListWatchlist(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.WatchlistItem]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// AddToWatchlistHandler follows a bond
+func (h *AlertHandlers) AddToWatchlistHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.WatchlistRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.AddToWatchlist(ctx, UserID, form); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusCreated, domain.SuccessResponse{Message: "The bond has been added to the watchlist."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RemoveFromWatchlistHandler unfollows a bond
+func (h *AlertHandlers) RemoveFromWatchlistHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	if err := h.service.RemoveFromWatchlist(ctx, UserID, chi.URLParam(req, "bond_uuid")); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The bond has been removed from the watchlist."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// CreateAlertHandler sets an alert on a bond
+func (h *AlertHandlers) CreateAlertHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.AlertRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Create(ctx, UserID, form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusCreated, domain.WrapResponse[*domain.Alert]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// ListAlertsHandler lists the alerts of the user
+func (h *AlertHandlers) ListAlertsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.List(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.Alert]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetAlertHandler returns an alert of the user
+func (h *AlertHandlers) GetAlertHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Get(ctx, UserID, id)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.Alert]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// UpdateAlertHandler replaces an alert of the user
+func (h *AlertHandlers) UpdateAlertHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	var form = &domain.AlertRequest{}
+
+	err = httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Update(ctx, UserID, id, form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.Alert]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// DeleteAlertHandler deletes an alert of the user
+func (h *AlertHandlers) DeleteAlertHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.Delete(ctx, UserID, id); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The alert has been deleted."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *AlertHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrAlertNotFound) || errors.Is(err, httpErrors.ErrWatchlistItemNotFound) || errors.Is(err, httpErrors.ErrBondNotExist) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else if errors.Is(err, httpErrors.ErrTooManyAlerts) {
+			_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAlertHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	bondUUID := "0b6f2d3e-4c1a-4f7e-9a8b-1c2d3e4f5a6b"
+	threshold := 9.5
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockAlertService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"List Watchlist OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/watchlist",
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().ListWatchlist(gomock.Any(), 1).Times(1).
+					Return([]*domain.WatchlistItem{{BondUUID: bondUUID, Name: "Bond", Available: 30}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"available":30`)
+			},
+		},
+		"Add To Watchlist OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/watchlist",
+			body:   `{"bond_uuid": "` + bondUUID + `"}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().AddToWatchlist(gomock.Any(), 1, &domain.WatchlistRequest{BondUUID: bondUUID}).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		"Add To Watchlist Bond Not Found": {
+			method: http.MethodPost,
+			url:    "/v1/me/watchlist",
+			body:   `{"bond_uuid": "` + bondUUID + `"}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().AddToWatchlist(gomock.Any(), 1, gomock.Any()).Times(1).Return(httpErrors.ErrBondNotExist)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Remove From Watchlist Not Found": {
+			method: http.MethodDelete,
+			url:    "/v1/me/watchlist/" + bondUUID,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().RemoveFromWatchlist(gomock.Any(), 1, bondUUID).Times(1).Return(httpErrors.ErrWatchlistItemNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Create Alert OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/alerts",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "price_below", "threshold": 9.5}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Create(gomock.Any(), 1, &domain.AlertRequest{BondUUID: bondUUID, Type: domain.AlertPriceBelow, Threshold: &threshold}).Times(1).
+					Return(&domain.Alert{ID: 8, BondUUID: bondUUID, Type: domain.AlertPriceBelow, Threshold: &threshold, Active: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"threshold":9.5`)
+			},
+		},
+		"Create New Listing Alert Without Threshold": {
+			method: http.MethodPost,
+			url:    "/v1/me/alerts",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "new_listing"}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Create(gomock.Any(), 1, gomock.Any()).Times(1).
+					Return(&domain.Alert{ID: 9, BondUUID: bondUUID, Type: domain.AlertNewListing, Active: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		"Create Alert Missing Threshold": {
+			method: http.MethodPost,
+			url:    "/v1/me/alerts",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "available_above"}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Create Alert Bad Type": {
+			method: http.MethodPost,
+			url:    "/v1/me/alerts",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "price_above", "threshold": 1}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Create Alert Limit Reached": {
+			method: http.MethodPost,
+			url:    "/v1/me/alerts",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "new_listing"}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Create(gomock.Any(), 1, gomock.Any()).Times(1).Return(nil, httpErrors.ErrTooManyAlerts)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		"List Alerts OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/alerts",
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().List(gomock.Any(), 1).Times(1).Return([]*domain.Alert{{ID: 8, Type: domain.AlertNewListing}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"new_listing"`)
+			},
+		},
+		"Get Alert Not Found": {
+			method: http.MethodGet,
+			url:    "/v1/me/alerts/9",
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Get(gomock.Any(), 1, 9).Times(1).Return(nil, httpErrors.ErrAlertNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Update Alert OK": {
+			method: http.MethodPut,
+			url:    "/v1/me/alerts/8",
+			body:   `{"bond_uuid": "` + bondUUID + `", "type": "new_listing", "active": false}`,
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Update(gomock.Any(), 1, 8, gomock.Any()).Times(1).
+					Return(&domain.Alert{ID: 8, Type: domain.AlertNewListing, Active: false}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"active":false`)
+			},
+		},
+		"Delete Alert Bad ID": {
+			method: http.MethodDelete,
+			url:    "/v1/me/alerts/abc",
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Delete Alert OK": {
+			method: http.MethodDelete,
+			url:    "/v1/me/alerts/8",
+			buildStubs: func(uc *mock.MockAlertService) {
+				uc.EXPECT().Delete(gomock.Any(), 1, 8).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAlertService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAlertHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
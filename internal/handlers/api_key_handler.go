@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+var _ handlerPort.APIKeyHandlers = (*APIKeyHandlers)(nil)
+
+// NewAPIKeyHandlers creates an instance of api key handlers. The keys are
+// managed with the JWT only, an api key can't create other keys.
+func NewAPIKeyHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.APIKeyService, render *render.Render, validate *validator.Validate) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &APIKeyHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/me/api-keys", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.CreateAPIKeyHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListAPIKeysHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/{id}", handler.RevokeAPIKeyHandler)
+	})
+}
+
+type APIKeyHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.APIKeyService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// CreateAPIKeyHandler creates a key, the response is the only time the secret is shown
+func (h *APIKeyHandlers) CreateAPIKeyHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.APIKeyRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Create(ctx, UserID, form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusCreated, domain.WrapResponse[*domain.APIKeyCreated]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// ListAPIKeysHandler lists the active keys with their usage
+func (h *APIKeyHandlers) ListAPIKeysHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.List(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.APIKey]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RevokeAPIKeyHandler revokes a key
+func (h *APIKeyHandlers) RevokeAPIKeyHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.Revoke(ctx, UserID, id); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The api key has been revoked."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *APIKeyHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrAPIKeyNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else if errors.Is(err, httpErrors.ErrAPIKeyExpiryInPast) {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
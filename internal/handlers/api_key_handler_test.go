@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeyHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockAPIKeyService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Create OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/api-keys",
+			body:   `{"name": "bot", "scopes": ["market:read"], "allowed_ips": ["10.0.0.0/8"]}`,
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().
+					Create(gomock.Any(), 1, gomock.Any()).
+					Times(1).
+					Return(&domain.APIKeyCreated{APIKey: &domain.APIKey{ID: 1, Name: "bot"}, Key: "mbk_a1b2c3d4e5f6_secret"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "mbk_a1b2c3d4e5f6_secret")
+			},
+		},
+		"Create Unknown Scope": {
+			method: http.MethodPost,
+			url:    "/v1/me/api-keys",
+			body:   `{"name": "bot", "scopes": ["admin"]}`,
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Create Invalid IP": {
+			method: http.MethodPost,
+			url:    "/v1/me/api-keys",
+			body:   `{"name": "bot", "scopes": ["market:read"], "allowed_ips": ["nope"]}`,
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Create Expiry In Past": {
+			method: http.MethodPost,
+			url:    "/v1/me/api-keys",
+			body:   `{"name": "bot", "scopes": ["market:read"], "expires_at": "2000-01-01T00:00:00Z"}`,
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Create(gomock.Any(), 1, gomock.Any()).Times(1).Return(nil, httpErrors.ErrAPIKeyExpiryInPast)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"List OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/api-keys",
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().List(gomock.Any(), 1).Times(1).Return([]*domain.APIKey{{ID: 1, Name: "bot", Prefix: "a1b2c3d4e5f6"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "a1b2c3d4e5f6")
+			},
+		},
+		"Revoke OK": {
+			method: http.MethodDelete,
+			url:    "/v1/me/api-keys/1",
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 1).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Revoke Not Found": {
+			method: http.MethodDelete,
+			url:    "/v1/me/api-keys/9",
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 9).Times(1).Return(httpErrors.ErrAPIKeyNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Revoke Bad ID": {
+			method: http.MethodDelete,
+			url:    "/v1/me/api-keys/abc",
+			buildStubs: func(uc *mock.MockAPIKeyService) {
+				uc.EXPECT().Revoke(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAPIKeyService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAPIKeyHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
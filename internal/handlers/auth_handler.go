@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/unrolled/render"
 	"go.uber.org/zap"
@@ -13,23 +14,32 @@ import (
 	httpErrors "kiramishima/m-backend/pkg/errors"
 
 	httpUtils "kiramishima/m-backend/pkg/utils"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 var _ handlerPort.AuthHandlers = (*AuthHandlers)(nil)
 
 // NewAuthHandlers creates a instance of auth handlers
-func NewAuthHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AuthService, render *render.Render, validate *validator.Validate) {
+func NewAuthHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AuthService, render *render.Render, validate *validator.Validate, cookies *httpUtils.SessionCookies) {
+	var tokenAuth = httpUtils.TokenAuth
+
 	handler := &AuthHandlers{
 		logger:   logger,
 		service:  s,
 		response: render,
 		validate: validate,
+		cookies:  cookies,
 	}
 
 	r.Route("/v1/auth", func(r chi.Router) {
 		r.Post("/sign-in", handler.SignInHandler)
 		r.Post("/sign-up", handler.SignUpHandler)
+		r.Post("/2fa", handler.TwoFactorHandler)
+		r.Post("/refresh", handler.RefreshHandler)
+		r.Get("/unlock", handler.UnlockHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/password", handler.ChangePasswordHandler)
 	})
 }
 
@@ -38,6 +48,7 @@ type AuthHandlers struct {
 	service  svcports.AuthService
 	response *render.Render
 	validate *validator.Validate
+	cookies  *httpUtils.SessionCookies
 }
 
 func (h *AuthHandlers) SignInHandler(w http.ResponseWriter, req *http.Request) {
@@ -50,7 +61,6 @@ func (h *AuthHandlers) SignInHandler(w http.ResponseWriter, req *http.Request) {
 		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
 		return
 	}
-	h.logger.Info(form)
 	// Validate Form
 	err = form.Validate(h.validate)
 	if err != nil {
@@ -60,6 +70,8 @@ func (h *AuthHandlers) SignInHandler(w http.ResponseWriter, req *http.Request) {
 	}
 
 	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
 
 	resp, err := h.service.FindByCredentials(ctx, form)
 	if err != nil {
@@ -69,12 +81,17 @@ func (h *AuthHandlers) SignInHandler(w http.ResponseWriter, req *http.Request) {
 		case <-ctx.Done():
 			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
 		default:
-			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
+			var retry *httpErrors.RetryAfterError
+			if errors.As(err, &retry) {
+				tooManyAttempts(h.response, w, retry)
+			} else if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
 			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBadEmailOrPassword.Error()})
 			} else if errors.Is(err, httpErrors.ErrBadPassword) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBadPassword.Error()})
+			} else if errors.Is(err, httpErrors.ErrUserSuspended) {
+				_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserSuspended.Error()})
 			} else {
 				_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
 			}
@@ -82,11 +99,7 @@ func (h *AuthHandlers) SignInHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err := h.response.JSON(w, http.StatusOK, resp); err != nil {
-		h.logger.Error(err)
-		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
-		return
-	}
+	h.writeTokens(w, req, resp)
 }
 
 // SignUpHandler for register new users
@@ -100,7 +113,6 @@ func (h *AuthHandlers) SignUpHandler(w http.ResponseWriter, req *http.Request) {
 		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
 		return
 	}
-	h.logger.Info(form)
 	// Validate form
 	err = form.Validate(h.validate)
 	if err != nil {
@@ -109,6 +121,8 @@ func (h *AuthHandlers) SignUpHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
 
 	err = h.service.Register(ctx, form)
 	if err != nil {
@@ -137,3 +151,217 @@ func (h *AuthHandlers) SignUpHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 }
+
+// TwoFactorHandler completes the sign in of an account with 2FA enabled
+func (h *AuthHandlers) TwoFactorHandler(w http.ResponseWriter, req *http.Request) {
+	var form = &domain.TwoFactorLoginRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+
+	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
+
+	resp, err := h.service.CompleteTwoFactor(ctx, form)
+	if err != nil {
+		h.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		default:
+			var retry *httpErrors.RetryAfterError
+			if errors.As(err, &retry) {
+				tooManyAttempts(h.response, w, retry)
+			} else if errors.Is(err, httpErrors.ErrInvalidChallengeToken) {
+				_ = h.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidChallengeToken.Error()})
+			} else if errors.Is(err, httpErrors.ErrInvalidTwoFactorCode) || errors.Is(err, httpErrors.ErrTwoFactorNotEnabled) {
+				_ = h.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidTwoFactorCode.Error()})
+			} else if errors.Is(err, httpErrors.ErrUserSuspended) {
+				_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserSuspended.Error()})
+			} else {
+				_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			}
+		}
+		return
+	}
+
+	h.writeTokens(w, req, resp)
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and refresh token
+func (h *AuthHandlers) RefreshHandler(w http.ResponseWriter, req *http.Request) {
+	var form = &domain.RefreshRequest{}
+	var err error
+
+	if cookie, cookieErr := req.Cookie(httpUtils.RefreshCookie); h.cookies.Requested(req) && cookieErr == nil {
+		// the cookie session mode sends the refresh token in its cookie, not in the body
+		form.RefreshToken = cookie.Value
+	} else if err = httpUtils.ReadJSON(w, req, &form); err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+
+	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
+
+	resp, err := h.service.Refresh(ctx, form)
+	if err != nil {
+		h.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		default:
+			if errors.Is(err, httpErrors.ErrInvalidRefreshToken) {
+				_ = h.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRefreshToken.Error()})
+			} else if errors.Is(err, httpErrors.ErrUserSuspended) {
+				_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserSuspended.Error()})
+			} else if errors.Is(err, httpErrors.ErrTimeout) {
+				_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+			} else {
+				_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			}
+		}
+		return
+	}
+
+	h.writeTokens(w, req, resp)
+}
+
+// UnlockHandler lifts an account lockout with the token of the unlock email
+func (h *AuthHandlers) UnlockHandler(w http.ResponseWriter, req *http.Request) {
+	var token = req.URL.Query().Get("token")
+	if token == "" {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidUnlockToken.Error()})
+		return
+	}
+
+	ctx := req.Context()
+
+	err := h.service.Unlock(ctx, token, httpUtils.ClientIP(req))
+	if err != nil {
+		h.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		default:
+			if errors.Is(err, httpErrors.ErrInvalidUnlockToken) {
+				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidUnlockToken.Error()})
+			} else {
+				_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			}
+		}
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "Your account has been unlocked."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// ChangePasswordHandler replaces the password of the signed in user
+func (h *AuthHandlers) ChangePasswordHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.ChangePasswordRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+
+	ctx := req.Context()
+	form.IP = httpUtils.ClientIP(req)
+	form.UserAgent = req.UserAgent()
+	form.SessionID = httpUtils.GetSessionIDInJWTHeader(req)
+
+	err = h.service.ChangePassword(ctx, UserID, form)
+	if err != nil {
+		h.logger.Error(err.Error())
+
+		select {
+		case <-ctx.Done():
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		default:
+			var retry *httpErrors.RetryAfterError
+			if errors.As(err, &retry) {
+				tooManyAttempts(h.response, w, retry)
+			} else if errors.Is(err, httpErrors.ErrBadPassword) {
+				_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBadPassword.Error()})
+			} else if errors.Is(err, httpErrors.ErrUserNotFound) {
+				_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserNotFound.Error()})
+			} else if errors.Is(err, httpErrors.ErrTimeout) {
+				_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+			} else {
+				_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			}
+		}
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "Your password has been changed."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeTokens answers with the tokens, in HttpOnly cookies when the client asked
+// for the cookie session mode
+func (h *AuthHandlers) writeTokens(w http.ResponseWriter, req *http.Request, resp *domain.AuthResponse) {
+	if h.cookies.Requested(req) {
+		if err := h.cookies.Write(w, resp); err != nil {
+			h.logger.Error(err)
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, resp); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// tooManyAttempts writes a 429 with the seconds to wait in Retry-After
+func tooManyAttempts(response *render.Render, w http.ResponseWriter, err *httpErrors.RetryAfterError) {
+	seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	_ = response.JSON(w, http.StatusTooManyRequests, domain.ErrorResponse{ErrorMessage: err.Error()})
+}
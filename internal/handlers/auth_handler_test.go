@@ -4,16 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/unrolled/render"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
 	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestSignInHandler(t *testing.T) {
@@ -28,10 +33,24 @@ func TestSignInHandler(t *testing.T) {
 				uc.EXPECT().
 					FindByCredentials(gomock.Any(), &domain.AuthRequest{Email: "gini@mail.com", Password: "123456"}).
 					Times(1).
-					Return(&domain.User{}, nil)
+					Return(&domain.AuthResponse{Token: "token"}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusAccepted, recorder.Code)
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Locked": {
+			ID: 1,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().
+					FindByCredentials(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: 1500 * time.Millisecond})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+				assert.Contains(t, recorder.Body.String(), httpErrors.ErrAccountLocked.Error())
 			},
 		},
 		/*"Invalid URL Param": {
@@ -99,10 +118,373 @@ func TestSignInHandler(t *testing.T) {
 			logger, _ := zap.NewProduction()
 			slogger := logger.Sugar()
 			r := render.New()
-			NewAuthHandlers(router, slogger, uc, r)
+			NewAuthHandlers(router, slogger, uc, r, validator.New(), nil)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+
+}
+
+func TestRefreshHandler(t *testing.T) {
+	testCases := map[string]struct {
+		body          string
+		buildStubs    func(uc *mock.MockAuthService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			body: `{"refresh_token": "old"}`,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().
+					Refresh(gomock.Any(), &domain.RefreshRequest{RefreshToken: "old", IP: "192.0.2.1", UserAgent: "Mozilla/5.0"}).
+					Times(1).
+					Return(&domain.AuthResponse{Token: "token", RefreshToken: "new"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"refresh_token":"new"`)
+			},
+		},
+		"Missing Token": {
+			body: `{}`,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Invalid Token": {
+			body: `{"refresh_token": "old"}`,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrInvalidRefreshToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		"Suspended": {
+			body: `{"refresh_token": "old"}`,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrUserSuspended)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAuthService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", bytes.NewBufferString(tc.body))
+			request.Header.Set("User-Agent", "Mozilla/5.0")
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAuthHandlers(router, logger.Sugar(), uc, render.New(), validator.New(), nil)
 			router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
+}
+
+func TestCookieSessionMode(t *testing.T) {
+	testCases := map[string]struct {
+		url           string
+		body          string
+		enabled       bool
+		setHeaders    func(req *http.Request)
+		buildStubs    func(uc *mock.MockAuthService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Sign In": {
+			url:     "/v1/auth/sign-in",
+			body:    `{"email": "gini@mail.com", "password": "123456"}`,
+			enabled: true,
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(httpUtils.SessionModeHeader, "cookie")
+			},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(1).Return(&domain.AuthResponse{Token: "token", RefreshToken: "refresh"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var resp domain.AuthResponse
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				assert.Empty(t, resp.Token)
+				assert.Empty(t, resp.RefreshToken)
+				assert.NotEmpty(t, resp.CSRFToken)
 
+				cookies := map[string]*http.Cookie{}
+				for _, cookie := range recorder.Result().Cookies() {
+					cookies[cookie.Name] = cookie
+				}
+				assert.Equal(t, "token", cookies[httpUtils.AccessCookie].Value)
+				assert.True(t, cookies[httpUtils.AccessCookie].HttpOnly)
+				assert.Equal(t, http.SameSiteStrictMode, cookies[httpUtils.AccessCookie].SameSite)
+				assert.Equal(t, "refresh", cookies[httpUtils.RefreshCookie].Value)
+				assert.Equal(t, "/v1/auth", cookies[httpUtils.RefreshCookie].Path)
+				assert.True(t, cookies[httpUtils.RefreshCookie].HttpOnly)
+				assert.Equal(t, resp.CSRFToken, cookies[httpUtils.CSRFCookie].Value)
+				assert.False(t, cookies[httpUtils.CSRFCookie].HttpOnly)
+				assert.NoError(t, httpUtils.ValidateCSRFToken(resp.CSRFToken, time.Now()))
+			},
+		},
+		"Sign In 2FA Challenge": {
+			url:     "/v1/auth/sign-in",
+			body:    `{"email": "gini@mail.com", "password": "123456"}`,
+			enabled: true,
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(httpUtils.SessionModeHeader, "cookie")
+			},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(1).Return(&domain.AuthResponse{ChallengeToken: "challenge", TwoFactorRequired: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"challenge_token":"challenge"`)
+				assert.Empty(t, recorder.Result().Cookies())
+			},
+		},
+		"Sign In Without Header": {
+			url:        "/v1/auth/sign-in",
+			body:       `{"email": "gini@mail.com", "password": "123456"}`,
+			enabled:    true,
+			setHeaders: func(req *http.Request) {},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(1).Return(&domain.AuthResponse{Token: "token", RefreshToken: "refresh"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"token":"token"`)
+				assert.Empty(t, recorder.Result().Cookies())
+			},
+		},
+		"Sign In Disabled": {
+			url:     "/v1/auth/sign-in",
+			body:    `{"email": "gini@mail.com", "password": "123456"}`,
+			enabled: false,
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(httpUtils.SessionModeHeader, "cookie")
+			},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().FindByCredentials(gomock.Any(), gomock.Any()).Times(1).Return(&domain.AuthResponse{Token: "token", RefreshToken: "refresh"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"token":"token"`)
+				assert.Empty(t, recorder.Result().Cookies())
+			},
+		},
+		"Refresh From Cookie": {
+			url:     "/v1/auth/refresh",
+			enabled: true,
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(httpUtils.SessionModeHeader, "cookie")
+				req.AddCookie(&http.Cookie{Name: httpUtils.RefreshCookie, Value: "old"})
+			},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().
+					Refresh(gomock.Any(), &domain.RefreshRequest{RefreshToken: "old", IP: "192.0.2.1"}).
+					Times(1).
+					Return(&domain.AuthResponse{Token: "token", RefreshToken: "new"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), `"refresh_token"`)
+				assert.Len(t, recorder.Result().Cookies(), 3)
+			},
+		},
+		"Refresh Without Cookie": {
+			url:     "/v1/auth/refresh",
+			enabled: true,
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(httpUtils.SessionModeHeader, "cookie")
+			},
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAuthService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, tc.url, bytes.NewBufferString(tc.body))
+			tc.setHeaders(request)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			cookies := httpUtils.NewSessionCookies(domain.CookieSessions{CookieSessionsEnabled: tc.enabled, CookieSecure: true, CookieSameSite: "strict"}, 3600)
+			NewAuthHandlers(router, logger.Sugar(), uc, render.New(), validator.New(), cookies)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUnlockHandler(t *testing.T) {
+	testCases := map[string]struct {
+		url           string
+		buildStubs    func(uc *mock.MockAuthService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			url: "/v1/auth/unlock?token=abc",
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Unlock(gomock.Any(), "abc", "192.0.2.1").Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Missing Token": {
+			url: "/v1/auth/unlock",
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Unlock(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Invalid Token": {
+			url: "/v1/auth/unlock?token=abc",
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().Unlock(gomock.Any(), "abc", gomock.Any()).Times(1).Return(httpErrors.ErrInvalidUnlockToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAuthService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			slogger := logger.Sugar()
+			r := render.New()
+			NewAuthHandlers(router, slogger, uc, r, validator.New(), nil)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		body          string
+		withToken     bool
+		buildStubs    func(uc *mock.MockAuthService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			body:      `{"current_password": "123456", "new_password": "abcdef"}`,
+			withToken: true,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().
+					ChangePassword(gomock.Any(), 1, &domain.ChangePasswordRequest{CurrentPassword: "123456", NewPassword: "abcdef", IP: "192.0.2.1", UserAgent: "Mozilla/5.0", SessionID: 4}).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Wrong Password": {
+			body:      `{"current_password": "nope", "new_password": "abcdef"}`,
+			withToken: true,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().ChangePassword(gomock.Any(), 1, gomock.Any()).Times(1).Return(httpErrors.ErrBadPassword)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Too Many Attempts": {
+			body:      `{"current_password": "nope", "new_password": "abcdef"}`,
+			withToken: true,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().ChangePassword(gomock.Any(), 1, gomock.Any()).Times(1).
+					Return(&httpErrors.RetryAfterError{Err: httpErrors.ErrAccountLocked, RetryAfter: 1500 * time.Millisecond})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+			},
+		},
+		"Same Password": {
+			body:      `{"current_password": "123456", "new_password": "123456"}`,
+			withToken: true,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Without Token": {
+			body: `{"current_password": "123456", "new_password": "abcdef"}`,
+			buildStubs: func(uc *mock.MockAuthService) {
+				uc.EXPECT().ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAuthService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/v1/auth/password", bytes.NewBufferString(tc.body))
+			request.Header.Set("User-Agent", "Mozilla/5.0")
+			if tc.withToken {
+				_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1, "sid": 4})
+				assert.NoError(t, err)
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAuthHandlers(router, logger.Sugar(), uc, render.New(), validator.New(), nil)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+// multipartOverhead room for the boundaries and the headers of the form
+const multipartOverhead = 64 << 10
+
+var _ handlerPort.AvatarHandlers = (*AvatarHandlers)(nil)
+
+// NewAvatarHandlers creates an instance of avatar handlers
+func NewAvatarHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.AvatarService, render *render.Render, maxSize int64) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &AvatarHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		maxSize:  maxSize,
+	}
+
+	r.Route("/v1/me/photo", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.GetAvatarHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.UploadAvatarHandler)
+	})
+}
+
+type AvatarHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.AvatarService
+	response *render.Render
+	maxSize  int64
+}
+
+// UploadAvatarHandler takes the "photo" field of a multipart form
+func (h *AvatarHandlers) UploadAvatarHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	req.Body = http.MaxBytesReader(w, req.Body, h.maxSize+multipartOverhead)
+
+	mr, err := req.MultipartReader()
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// The file is streamed from the form, it is never written to disk
+	var file io.Reader
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			h.writeError(req.Context(), w, h.bodyError(err))
+			return
+		}
+		if part.FormName() == "photo" {
+			file = part
+			break
+		}
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Upload(ctx, UserID, file)
+	if err != nil {
+		h.writeError(ctx, w, h.bodyError(err))
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusCreated, domain.WrapResponse[*domain.Avatar]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetAvatarHandler returns the signed URLs of the profile photo
+func (h *AvatarHandlers) GetAvatarHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.Get(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.Avatar]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// bodyError maps the errors of reading the form
+func (h *AvatarHandlers) bodyError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return httpErrors.ErrAvatarTooLarge
+	}
+	if errors.Is(err, io.EOF) {
+		// The form has no photo field
+		return httpErrors.ErrInvalidRequestBody
+	}
+	return err
+}
+
+// writeError maps service errors to responses
+func (h *AvatarHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	h.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		for _, known := range []struct {
+			err    error
+			status int
+		}{
+			{httpErrors.ErrTimeout, http.StatusGatewayTimeout},
+			{httpErrors.ErrInvalidRequestBody, http.StatusBadRequest},
+			{httpErrors.ErrAvatarTooLarge, http.StatusRequestEntityTooLarge},
+			{httpErrors.NotAllowedImageHeader, http.StatusUnsupportedMediaType},
+			{httpErrors.ErrInvalidImage, http.StatusUnprocessableEntity},
+			{httpErrors.ErrAvatarNotFound, http.StatusNotFound},
+			{httpErrors.ErrUserNotFound, http.StatusNotFound},
+		} {
+			if errors.Is(err, known.err) {
+				_ = h.response.JSON(w, known.status, domain.ErrorResponse{ErrorMessage: known.err.Error()})
+				return
+			}
+		}
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// photoForm builds a multipart body with the file in the given field
+func photoForm(t *testing.T, field string, content []byte) (io.Reader, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile(field, "photo.png")
+	assert.NoError(t, err)
+	_, err = part.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func TestAvatarHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          func(t *testing.T) (io.Reader, string)
+		buildStubs    func(uc *mock.MockAvatarService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Upload OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return photoForm(t, "photo", []byte("image"))
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), 1, gomock.Any()).Times(1).DoAndReturn(func(_ interface{}, _ int, r io.Reader) (*domain.Avatar, error) {
+					data, _ := io.ReadAll(r)
+					assert.Equal(t, "image", string(data))
+					return &domain.Avatar{Small: "http://localhost/v1/files/avatars/1/a_64.png"}, nil
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "a_64.png")
+			},
+		},
+		"Upload Missing Field": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return photoForm(t, "file", []byte("image"))
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Upload Not Multipart": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return bytes.NewBufferString(`{"photo":"x"}`), "application/json"
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Upload Too Large": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return photoForm(t, "photo", bytes.Repeat([]byte("a"), 1024+multipartOverhead))
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), 1, gomock.Any()).Times(1).DoAndReturn(func(_ interface{}, _ int, r io.Reader) (*domain.Avatar, error) {
+					_, err := io.ReadAll(r)
+					return nil, err
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		"Upload Unsupported Type": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return photoForm(t, "photo", []byte("GIF89a"))
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), 1, gomock.Any()).Times(1).Return(nil, httpErrors.NotAllowedImageHeader)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		"Upload Invalid Image": {
+			method: http.MethodPost,
+			url:    "/v1/me/photo",
+			body: func(t *testing.T) (io.Reader, string) {
+				return photoForm(t, "photo", []byte("\x89PNG broken"))
+			},
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Upload(gomock.Any(), 1, gomock.Any()).Times(1).Return(nil, httpErrors.ErrInvalidImage)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		"Get OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/photo",
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Get(gomock.Any(), 1).Times(1).Return(&domain.Avatar{Medium: "http://localhost/v1/files/avatars/1/a_256.png"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "a_256.png")
+			},
+		},
+		"Get Not Found": {
+			method: http.MethodGet,
+			url:    "/v1/me/photo",
+			buildStubs: func(uc *mock.MockAvatarService) {
+				uc.EXPECT().Get(gomock.Any(), 1).Times(1).Return(nil, httpErrors.ErrAvatarNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockAvatarService(ctrl)
+			tc.buildStubs(uc)
+
+			var body io.Reader
+			var contentType string
+			if tc.body != nil {
+				body, contentType = tc.body(t)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, body)
+			if contentType != "" {
+				request.Header.Set("Content-Type", contentType)
+			}
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewAvatarHandlers(router, logger.Sugar(), uc, render.New(), 1024)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
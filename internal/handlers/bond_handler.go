@@ -13,7 +13,6 @@ import (
 	httpErrors "kiramishima/m-backend/pkg/errors"
 	httpUtils "kiramishima/m-backend/pkg/utils"
 	"net/http"
-	"os"
 	"strconv"
 )
 
@@ -21,7 +20,7 @@ var _ handlerPort.BondHandlers = (*BondHandlers)(nil)
 
 // NewBondHandlers creates an instance of bond handlers
 func NewBondHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.BondService, render *render.Render, validate *validator.Validate) {
-	var tokenAuth = jwtauth.New("HS256", []byte(os.Getenv("SecretKey")), nil)
+	var tokenAuth = httpUtils.TokenAuth
 
 	handler := &BondHandlers{
 		logger:   logger,
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ handlerPort.FileHandlers = (*FileHandlers)(nil)
+
+// NewFileHandlers creates an instance of file handlers. The links are signed
+// by the storage, so the route needs no token.
+func NewFileHandlers(r *chi.Mux, logger *zap.SugaredLogger, storage svcports.Storage, render *render.Render, secret []byte) {
+	handler := &FileHandlers{
+		logger:   logger,
+		storage:  storage,
+		response: render,
+		secret:   secret,
+		now:      time.Now,
+	}
+
+	r.Get("/v1/files/*", handler.GetFileHandler)
+}
+
+type FileHandlers struct {
+	logger   *zap.SugaredLogger
+	storage  svcports.Storage
+	response *render.Render
+	secret   []byte
+	now      func() time.Time
+}
+
+// GetFileHandler serves a file of the storage with a signed link
+func (h *FileHandlers) GetFileHandler(w http.ResponseWriter, req *http.Request) {
+	key := chi.URLParam(req, "*")
+	q := req.URL.Query()
+	if !httpUtils.VerifySignedURL(h.secret, key, q.Get("expires"), q.Get("signature"), h.now()) {
+		_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidSignedURL.Error()})
+		return
+	}
+
+	file, err := h.storage.Open(req.Context(), key)
+	if err != nil {
+		if errors.Is(err, httpErrors.ErrFileNotFound) || errors.Is(err, httpErrors.ErrInvalidFileKey) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrFileNotFound.Error()})
+			return
+		}
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+	defer file.Body.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file.Body); err != nil {
+		h.logger.Warnw("failed to send the file", "key", key, "error", err)
+	}
+}
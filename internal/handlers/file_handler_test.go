@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/storage"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileHandlers(t *testing.T) {
+	secret := []byte("storage-secret")
+	base := "http://localhost/v1/files"
+	store := storage.NewLocalStorage(t.TempDir(), base, secret)
+	assert.NoError(t, store.Put(context.Background(), "avatars/1/a_64.png", strings.NewReader("png"), 3, "image/png"))
+
+	router := chi.NewRouter()
+	logger, _ := zap.NewProduction()
+	NewFileHandlers(router, logger.Sugar(), store, render.New(), secret)
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		return recorder
+	}
+
+	t.Run("Signed OK", func(t *testing.T) {
+		url, err := store.SignedURL(context.Background(), "avatars/1/a_64.png", time.Minute)
+		assert.NoError(t, err)
+
+		recorder := serve(strings.TrimPrefix(url, "http://localhost"))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "png", recorder.Body.String())
+		assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "3", recorder.Header().Get("Content-Length"))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		url := httpUtils.SignURL(secret, base, "avatars/1/a_64.png", time.Now().Add(-time.Minute))
+
+		recorder := serve(strings.TrimPrefix(url, "http://localhost"))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Tampered Key", func(t *testing.T) {
+		url := httpUtils.SignURL(secret, base, "avatars/1/a_64.png", time.Now().Add(time.Minute))
+		url = strings.Replace(url, "avatars/1/", "avatars/2/", 1)
+
+		recorder := serve(strings.TrimPrefix(url, "http://localhost"))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		recorder := serve("/v1/files/avatars/1/a_64.png")
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		url := httpUtils.SignURL(secret, base, "avatars/1/none.png", time.Now().Add(time.Minute))
+
+		recorder := serve(strings.TrimPrefix(url, "http://localhost"))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -6,13 +6,26 @@ import (
 	"github.com/unrolled/render"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
 	"kiramishima/m-backend/internal/core/services"
+	"kiramishima/m-backend/internal/middlewares"
+	httpUtils "kiramishima/m-backend/pkg/utils"
 )
 
 // Module Handlers.
 var Module = fx.Module("handlers",
-	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AuthService, render *render.Render, validate *validator.Validate) {
-		NewAuthHandlers(r, logger, svc, render, validate)
+	fx.Provide(func(cfg *domain.Configuration) *httpUtils.SessionCookies {
+		return httpUtils.NewSessionCookies(cfg.CookieSessions, cfg.RefreshTTL)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AuthService, render *render.Render, validate *validator.Validate, cookies *httpUtils.SessionCookies) {
+		NewAuthHandlers(r, logger, svc, render, validate, cookies)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.OIDCService, render *render.Render, cookies *httpUtils.SessionCookies) {
+		NewOIDCHandlers(r, logger, svc, render, cookies)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.TwoFactorService, render *render.Render, validate *validator.Validate) {
+		NewTwoFactorHandlers(r, logger, svc, render, validate)
 	}),
 	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.BondService, render *render.Render, validate *validator.Validate) {
 		NewBondHandlers(r, logger, svc, render, validate)
@@ -20,7 +33,48 @@ var Module = fx.Module("handlers",
 	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.UserService, render *render.Render, validate *validator.Validate) {
 		NewUserHandlers(r, logger, svc, render, validate)
 	}),
-	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.MarketBondsService, render *render.Render, validate *validator.Validate) {
-		NewMarketBondsHandlers(r, logger, svc, render, validate)
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AccountService, render *render.Render, validate *validator.Validate) {
+		NewAccountHandlers(r, logger, svc, render, validate)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.TaskService, render *render.Render) {
+		NewTaskHandlers(r, logger, svc, render)
+	}),
+	fx.Invoke(func(cfg *domain.Configuration, r *chi.Mux, logger *zap.SugaredLogger, svc *services.AvatarService, render *render.Render) {
+		NewAvatarHandlers(r, logger, svc, render, cfg.AvatarMaxSize)
+	}),
+	fx.Invoke(func(cfg *domain.Configuration, r *chi.Mux, logger *zap.SugaredLogger, storage svcport.Storage, render *render.Render) {
+		// only the local storage signs its links with STORAGE_SIGNING_KEY, S3
+		// presigns them and the key may be empty
+		if cfg.StorageDriver == "s3" {
+			return
+		}
+		NewFileHandlers(r, logger, storage, render, []byte(cfg.StorageSigningKey))
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.MarketBondsService, render *render.Render, validate *validator.Validate, auth *middlewares.Auth) {
+		NewMarketBondsHandlers(r, logger, svc, render, validate, auth)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.SellerService, render *render.Render, validate *validator.Validate, auth *middlewares.Auth) {
+		NewSellerHandlers(r, logger, svc, render, validate, auth)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.SessionService, render *render.Render, cookies *httpUtils.SessionCookies) {
+		NewSessionHandlers(r, logger, svc, render, cookies)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AuthEventService, render *render.Render) {
+		NewSecurityEventHandlers(r, logger, svc, render)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.APIKeyService, render *render.Render, validate *validator.Validate) {
+		NewAPIKeyHandlers(r, logger, svc, render, validate)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.WebhookService, render *render.Render, validate *validator.Validate) {
+		NewWebhookHandlers(r, logger, svc, render, validate)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.NotificationService, render *render.Render, validate *validator.Validate) {
+		NewNotificationHandlers(r, logger, svc, render, validate)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AlertService, render *render.Render, validate *validator.Validate) {
+		NewAlertHandlers(r, logger, svc, render, validate)
+	}),
+	fx.Invoke(func(r *chi.Mux, logger *zap.SugaredLogger, svc *services.AdminService, render *render.Render, validate *validator.Validate, rbac *middlewares.RBAC) {
+		NewAdminHandlers(r, logger, svc, render, validate, rbac)
 	}),
 )
@@ -3,26 +3,23 @@ package handlers
 import (
 	"errors"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/unrolled/render"
 	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
 	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
 	svcports "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/middlewares"
 	httpErrors "kiramishima/m-backend/pkg/errors"
 	httpUtils "kiramishima/m-backend/pkg/utils"
 	"net/http"
-	"os"
 	"strconv"
 )
 
 var _ handlerPort.MarketBondsHandlers = (*MarketBondsHandlers)(nil)
 
 // NewMarketBondsHandlers creates an instance of market bonds handlers
-func NewMarketBondsHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.MarketBondsService, render *render.Render, validate *validator.Validate) {
-	var tokenAuth = jwtauth.New("HS256", []byte(os.Getenv("SecretKey")), nil)
-
+func NewMarketBondsHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.MarketBondsService, render *render.Render, validate *validator.Validate, auth *middlewares.Auth) {
 	handler := &MarketBondsHandlers{
 		logger:   logger,
 		service:  s,
@@ -31,10 +28,10 @@ func NewMarketBondsHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.Ma
 	}
 
 	r.Route("/v1/market", func(r chi.Router) {
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListMarketBondsHandler)
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/{id}", handler.GetMarketBondByIDHandler)
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/{id}/buy", handler.BuyMarketBondHandler)
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/sell", handler.SellMarketBondHandler)
+		r.With(auth.Authenticate(domain.ScopeMarketRead)).Get("/", handler.ListMarketBondsHandler)
+		r.With(auth.Authenticate(domain.ScopeMarketRead)).Post("/{id}", handler.GetMarketBondByIDHandler)
+		r.With(auth.Authenticate(domain.ScopeMarketTrade)).Post("/{id}/buy", handler.BuyMarketBondHandler)
+		r.With(auth.Authenticate(domain.ScopeMarketTrade)).Post("/sell", handler.SellMarketBondHandler)
 	})
 }
 
@@ -138,6 +135,8 @@ func (h *MarketBondsHandlers) BuyMarketBondHandler(w http.ResponseWriter, req *h
 		default:
 			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+			} else if errors.Is(err, httpErrors.ErrBondFrozen) {
+				_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBondFrozen.Error()})
 			} else if errors.Is(err, httpErrors.ErrBeginTransaction) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
 			} else if errors.Is(err, httpErrors.ErrCommit) {
@@ -183,6 +182,8 @@ func (h *MarketBondsHandlers) SellMarketBondHandler(w http.ResponseWriter, req *
 		default:
 			if errors.Is(err, httpErrors.ErrInvalidRequestBody) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+			} else if errors.Is(err, httpErrors.ErrBondFrozen) {
+				_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: httpErrors.ErrBondFrozen.Error()})
 			} else if errors.Is(err, httpErrors.ErrBeginTransaction) {
 				_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
 			} else if errors.Is(err, httpErrors.ErrCommit) {
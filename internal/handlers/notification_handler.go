@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+var _ handlerPort.NotificationHandlers = (*NotificationHandlers)(nil)
+
+// NewNotificationHandlers creates an instance of notification handlers
+func NewNotificationHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.NotificationService, render *render.Render, validate *validator.Validate) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &NotificationHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/me/notifications", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListNotificationsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/{id}/read", handler.MarkReadHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/read-all", handler.MarkAllReadHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/preferences", handler.GetPreferencesHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Put("/preferences", handler.UpdatePreferencesHandler)
+	})
+}
+
+type NotificationHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.NotificationService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// ListNotificationsHandler lists the inbox, status=read or status=unread filters it
+func (h *NotificationHandlers) ListNotificationsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var search = &domain.NotificationSearch{UserID: UserID}
+	var err error
+	if v := req.URL.Query().Get("limit"); v != "" {
+		if search.Limit, err = strconv.Atoi(v); err != nil {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+			return
+		}
+	}
+	if v := req.URL.Query().Get("offset"); v != "" {
+		if search.Offset, err = strconv.Atoi(v); err != nil {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+			return
+		}
+	}
+	switch req.URL.Query().Get("status") {
+	case "":
+	case "read":
+		read := true
+		search.Read = &read
+	case "unread":
+		read := false
+		search.Read = &read
+	default:
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.List(ctx, search)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.NotificationList]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// MarkReadHandler marks a notification as read
+func (h *NotificationHandlers) MarkReadHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.MarkRead(ctx, UserID, id); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The notification has been marked as read."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// MarkAllReadHandler marks the whole inbox as read
+func (h *NotificationHandlers) MarkAllReadHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	if err := h.service.MarkAllRead(ctx, UserID); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "Every notification has been marked as read."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetPreferencesHandler returns the notification channels of the user
+func (h *NotificationHandlers) GetPreferencesHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.GetPreferences(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.NotificationPreferences]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// UpdatePreferencesHandler saves the notification channels of the user
+func (h *NotificationHandlers) UpdatePreferencesHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.NotificationPreferencesRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate Form
+	err = form.Validate(h.validate)
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.UpdatePreferences(ctx, UserID, form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.NotificationPreferences]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *NotificationHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrNotificationNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
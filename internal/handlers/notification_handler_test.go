@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotificationHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	unread := false
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockNotificationService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"List OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/notifications?status=unread&limit=10",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().List(gomock.Any(), &domain.NotificationSearch{UserID: 1, Read: &unread, Limit: 10}).Times(1).
+					Return(&domain.NotificationList{Items: []*domain.Notification{{ID: 3, Type: domain.EventTradeExecuted, Title: "Your bonds were sold"}}, Unread: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"unread":1`)
+				assert.Contains(t, recorder.Body.String(), "Your bonds were sold")
+			},
+		},
+		"List Bad Status": {
+			method: http.MethodGet,
+			url:    "/v1/me/notifications?status=archived",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().List(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Mark Read OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/notifications/3/read",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().MarkRead(gomock.Any(), 1, 3).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Mark Read Not Found": {
+			method: http.MethodPost,
+			url:    "/v1/me/notifications/9/read",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().MarkRead(gomock.Any(), 1, 9).Times(1).Return(httpErrors.ErrNotificationNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Mark All Read OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/notifications/read-all",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().MarkAllRead(gomock.Any(), 1).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Get Preferences OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/notifications/preferences",
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().GetPreferences(gomock.Any(), 1).Times(1).Return(domain.DefaultNotificationPreferences(), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"in_app":true`)
+			},
+		},
+		"Update Preferences OK": {
+			method: http.MethodPut,
+			url:    "/v1/me/notifications/preferences",
+			body:   `{"in_app": true, "email": false, "webhook": true}`,
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().UpdatePreferences(gomock.Any(), 1, gomock.Any()).Times(1).
+					Return(&domain.NotificationPreferences{InApp: true, Email: false, Webhook: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"email":false`)
+			},
+		},
+		"Update Preferences Missing Channel": {
+			method: http.MethodPut,
+			url:    "/v1/me/notifications/preferences",
+			body:   `{"in_app": true}`,
+			buildStubs: func(uc *mock.MockNotificationService) {
+				uc.EXPECT().UpdatePreferences(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockNotificationService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewNotificationHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+var _ handlerPort.OIDCHandlers = (*OIDCHandlers)(nil)
+
+// NewOIDCHandlers creates a instance of the single sign-on handlers
+func NewOIDCHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.OIDCService, render *render.Render, cookies *httpUtils.SessionCookies) {
+	handler := &OIDCHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		cookies:  cookies,
+	}
+
+	r.Route("/v1/auth/oidc", func(r chi.Router) {
+		r.Get("/login", handler.LoginHandler)
+		r.Get("/callback", handler.CallbackHandler)
+	})
+}
+
+type OIDCHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.OIDCService
+	response *render.Render
+	cookies  *httpUtils.SessionCookies
+}
+
+// LoginHandler redirects to the login page of the identity provider
+func (h *OIDCHandlers) LoginHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	authURL, state, err := h.service.Begin(ctx)
+	if err != nil {
+		h.writeError(w, req, err)
+		return
+	}
+
+	httpUtils.SetOIDCStateCookie(w, state)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, req, authURL, http.StatusFound)
+}
+
+// CallbackHandler finishes the login, the response is the same as the sign in.
+// The state has to match the cookie set by LoginHandler, so a callback started
+// in another browser can't sign this one in.
+func (h *OIDCHandlers) CallbackHandler(w http.ResponseWriter, req *http.Request) {
+	var query = req.URL.Query()
+	var stateMatches = httpUtils.OIDCStateMatches(req, query.Get("state"))
+	httpUtils.ClearOIDCStateCookie(w)
+
+	if query.Get("error") != "" {
+		h.logger.Errorw("identity provider error", "error", query.Get("error"), "description", query.Get("error_description"))
+		_ = h.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrOIDCExchange.Error()})
+		return
+	}
+	if query.Get("state") == "" || query.Get("code") == "" {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	if !stateMatches {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidOIDCState.Error()})
+		return
+	}
+
+	resp, err := h.service.Callback(req.Context(), query.Get("state"), query.Get("code"), httpUtils.ClientIP(req), req.UserAgent())
+	if err != nil {
+		h.writeError(w, req, err)
+		return
+	}
+
+	// the callback is a browser redirect that cannot ask for the cookie session
+	// mode, so it always uses it when it is enabled
+	if h.cookies.Enabled() {
+		if err := h.cookies.Write(w, resp); err != nil {
+			h.writeError(w, req, err)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.response.JSON(w, http.StatusOK, resp); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *OIDCHandlers) writeError(w http.ResponseWriter, req *http.Request, err error) {
+	h.logger.Error(err.Error())
+
+	select {
+	case <-req.Context().Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrOIDCDisabled) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrOIDCDisabled.Error()})
+		} else if errors.Is(err, httpErrors.ErrInvalidOIDCState) {
+			_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidOIDCState.Error()})
+		} else if errors.Is(err, httpErrors.ErrOIDCExchange) {
+			_ = h.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrOIDCExchange.Error()})
+		} else if errors.Is(err, httpErrors.ErrOIDCEmailNotVerified) {
+			_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrOIDCEmailNotVerified.Error()})
+		} else if errors.Is(err, httpErrors.ErrOIDCUserNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrOIDCUserNotFound.Error()})
+		} else if errors.Is(err, httpErrors.ErrUserSuspended) {
+			_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserSuspended.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOIDCHandlers(t *testing.T) {
+	testCases := map[string]struct {
+		url           string
+		stateCookie   string
+		buildStubs    func(uc *mock.MockOIDCService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Login Redirects": {
+			url: "/v1/auth/oidc/login",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Begin(gomock.Any()).Times(1).Return("https://idp.example.com/authorize?state=abc", "abc", nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusFound, recorder.Code)
+				assert.Equal(t, "https://idp.example.com/authorize?state=abc", recorder.Header().Get("Location"))
+				cookies := recorder.Result().Cookies()
+				assert.Len(t, cookies, 1)
+				assert.Equal(t, httpUtils.OIDCStateCookie, cookies[0].Name)
+				assert.Equal(t, "abc", cookies[0].Value)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.True(t, cookies[0].Secure)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			},
+		},
+		"Login Disabled": {
+			url: "/v1/auth/oidc/login",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Begin(gomock.Any()).Times(1).Return("", "", httpErrors.ErrOIDCDisabled)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Callback OK": {
+			url:         "/v1/auth/oidc/callback?state=abc&code=xyz",
+			stateCookie: "abc",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), "abc", "xyz", gomock.Any(), gomock.Any()).Times(1).Return(&domain.AuthResponse{Token: "token"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"token":"token"`)
+			},
+		},
+		"Callback Without State Cookie": {
+			url: "/v1/auth/oidc/callback?state=abc&code=xyz",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.ErrInvalidOIDCState.Error())
+			},
+		},
+		"Callback State Of Another Login": {
+			url:         "/v1/auth/oidc/callback?state=abc&code=xyz",
+			stateCookie: "def",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Contains(t, recorder.Header().Get("Set-Cookie"), httpUtils.OIDCStateCookie+"=;")
+			},
+		},
+		"Callback Provider Error": {
+			url: "/v1/auth/oidc/callback?state=abc&error=access_denied",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		"Callback Missing Code": {
+			url: "/v1/auth/oidc/callback?state=abc",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Callback Invalid State": {
+			url:         "/v1/auth/oidc/callback?state=abc&code=xyz",
+			stateCookie: "abc",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), "abc", "xyz", gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrInvalidOIDCState)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Callback Unverified Email": {
+			url:         "/v1/auth/oidc/callback?state=abc&code=xyz",
+			stateCookie: "abc",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), "abc", "xyz", gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrOIDCEmailNotVerified)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Callback Unknown User": {
+			url:         "/v1/auth/oidc/callback?state=abc&code=xyz",
+			stateCookie: "abc",
+			buildStubs: func(uc *mock.MockOIDCService) {
+				uc.EXPECT().Callback(gomock.Any(), "abc", "xyz", gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrOIDCUserNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockOIDCService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.stateCookie != "" {
+				request.AddCookie(&http.Cookie{Name: httpUtils.OIDCStateCookie, Value: tc.stateCookie})
+			}
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			r := render.New()
+			NewAuthHandlers(router, logger.Sugar(), nil, r, nil, nil)
+			NewOIDCHandlers(router, logger.Sugar(), uc, r, nil)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
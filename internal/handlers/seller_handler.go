@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/middlewares"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+var _ handlerPort.SellerHandlers = (*SellerHandlers)(nil)
+
+// NewSellerHandlers creates an instance of seller handlers
+func NewSellerHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.SellerService, render *render.Render, validate *validator.Validate, auth *middlewares.Auth) {
+	handler := &SellerHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/users", func(r chi.Router) {
+		r.With(auth.Authenticate(domain.ScopeMarketRead)).Get("/{username}", handler.GetSellerProfileHandler)
+		r.With(auth.Authenticate(domain.ScopeMarketTrade)).Post("/{username}/ratings", handler.RateSellerHandler)
+	})
+}
+
+type SellerHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.SellerService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// GetSellerProfileHandler returns the public profile of a seller
+func (h *SellerHandlers) GetSellerProfileHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	resp, err := h.service.GetProfile(ctx, chi.URLParam(req, "username"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.SellerProfile]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RateSellerHandler rates the seller of a settled purchase
+func (h *SellerHandlers) RateSellerHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.SellerRatingRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	// Validate form
+	err = form.Validate(h.validate)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Rate(ctx, UserID, chi.URLParam(req, "username"), form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusCreated, domain.WrapResponse[*domain.SellerRating]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *SellerHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	h.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrSellerNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrSellerNotFound.Error()})
+		} else if errors.Is(err, httpErrors.ErrTransactionNotRateable) {
+			_ = h.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTransactionNotRateable.Error()})
+		} else if errors.Is(err, httpErrors.ErrAlreadyRated) {
+			_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: httpErrors.ErrAlreadyRated.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/middlewares"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSellerHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	rating := 4.5
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockSellerService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Get Profile OK": {
+			method: http.MethodGet,
+			url:    "/v1/users/ginigini",
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().GetProfile(gomock.Any(), "ginigini").Times(1).Return(&domain.SellerProfile{UserID: 2, UserName: "ginigini", CompletedTrades: 10, Rating: &rating, Ratings: 2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"rating":4.5`)
+				assert.Contains(t, recorder.Body.String(), `"completed_trades":10`)
+			},
+		},
+		"Get Profile Not Found": {
+			method: http.MethodGet,
+			url:    "/v1/users/nobody",
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().GetProfile(gomock.Any(), "nobody").Times(1).Return(nil, httpErrors.ErrSellerNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Rate OK": {
+			method: http.MethodPost,
+			url:    "/v1/users/ginigini/ratings",
+			body:   `{"transaction_id":9,"score":5,"comment":"fast"}`,
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().Rate(gomock.Any(), 1, "ginigini", gomock.Any()).Times(1).Return(&domain.SellerRating{ID: 4, TransactionID: 9, Score: 5}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		"Rate Score Out Of Range": {
+			method: http.MethodPost,
+			url:    "/v1/users/ginigini/ratings",
+			body:   `{"transaction_id":9,"score":6}`,
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().Rate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Rate Without Transaction": {
+			method: http.MethodPost,
+			url:    "/v1/users/ginigini/ratings",
+			body:   `{"score":4}`,
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().Rate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Rate Not The Buyer": {
+			method: http.MethodPost,
+			url:    "/v1/users/ginigini/ratings",
+			body:   `{"transaction_id":9,"score":1}`,
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().Rate(gomock.Any(), 1, "ginigini", gomock.Any()).Times(1).Return(nil, httpErrors.ErrTransactionNotRateable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Rate Twice": {
+			method: http.MethodPost,
+			url:    "/v1/users/ginigini/ratings",
+			body:   `{"transaction_id":9,"score":1}`,
+			buildStubs: func(uc *mock.MockSellerService) {
+				uc.EXPECT().Rate(gomock.Any(), 1, "ginigini", gomock.Any()).Times(1).Return(nil, httpErrors.ErrAlreadyRated)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockSellerService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			auth := middlewares.NewAuth(logger.Sugar(), mock.NewMockAPIKeyService(ctrl), render.New(), httpUtils.TokenAuth, middlewares.NewRateLimiter(logger.Sugar(), nil, render.New(), httpUtils.TokenAuth, domain.RateLimit{}))
+			NewSellerHandlers(router, logger.Sugar(), uc, render.New(), validator.New(), auth)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strconv"
+)
+
+var _ handlerPort.SessionHandlers = (*SessionHandlers)(nil)
+
+// NewSessionHandlers creates an instance of session handlers
+func NewSessionHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.SessionService, render *render.Render, cookies *httpUtils.SessionCookies) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &SessionHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		cookies:  cookies,
+	}
+
+	r.Route("/v1/me/sessions", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.ListSessionsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/", handler.RevokeAllSessionsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Delete("/{id}", handler.RevokeSessionHandler)
+	})
+	r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/v1/auth/sign-out", handler.SignOutHandler)
+}
+
+type SessionHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.SessionService
+	response *render.Render
+	cookies  *httpUtils.SessionCookies
+}
+
+// ListSessionsHandler lists the devices signed in to the account
+func (h *SessionHandlers) ListSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.List(ctx, UserID, httpUtils.GetSessionIDInJWTHeader(req))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.UserSession]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RevokeSessionHandler signs out one device
+func (h *SessionHandlers) RevokeSessionHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	id, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.BadQueryParams.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.Revoke(ctx, UserID, id); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "The session has been revoked."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RevokeAllSessionsHandler signs out every device, including the current one
+func (h *SessionHandlers) RevokeAllSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	if err := h.service.RevokeAll(ctx, UserID); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "You have been logged out everywhere."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// SignOutHandler revokes the session of the token and clears the session cookies
+func (h *SessionHandlers) SignOutHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	// a token without session has nothing to revoke, a session already revoked is fine
+	if sid := httpUtils.GetSessionIDInJWTHeader(req); sid > 0 {
+		if err := h.service.Revoke(ctx, UserID, sid); err != nil && !errors.Is(err, httpErrors.ErrSessionNotFound) {
+			h.writeError(ctx, w, err)
+			return
+		}
+	}
+	h.cookies.Clear(w)
+
+	if err := h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "You have been logged out."}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *SessionHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrSessionNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrSessionNotFound.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		buildStubs    func(uc *mock.MockSessionService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"List OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/sessions",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().List(gomock.Any(), 1, 7).Times(1).Return([]*domain.UserSession{{ID: 7, Device: "Firefox on Windows", Current: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"current":true`)
+				assert.NotContains(t, recorder.Body.String(), "token_hash")
+			},
+		},
+		"Revoke OK": {
+			method: http.MethodDelete,
+			url:    "/v1/me/sessions/3",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 3).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Revoke Not Found": {
+			method: http.MethodDelete,
+			url:    "/v1/me/sessions/3",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 3).Times(1).Return(httpErrors.ErrSessionNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Revoke Bad ID": {
+			method: http.MethodDelete,
+			url:    "/v1/me/sessions/abc",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Logout Everywhere": {
+			method: http.MethodDelete,
+			url:    "/v1/me/sessions",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().RevokeAll(gomock.Any(), 1).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Sign Out": {
+			method: http.MethodPost,
+			url:    "/v1/auth/sign-out",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 7).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				cookies := recorder.Result().Cookies()
+				assert.Len(t, cookies, 3)
+				for _, cookie := range cookies {
+					assert.Empty(t, cookie.Value)
+					assert.Equal(t, -1, cookie.MaxAge)
+				}
+			},
+		},
+		"Sign Out Revoked Session": {
+			method: http.MethodPost,
+			url:    "/v1/auth/sign-out",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 7).Times(1).Return(httpErrors.ErrSessionNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Sign Out Error": {
+			method: http.MethodPost,
+			url:    "/v1/auth/sign-out",
+			buildStubs: func(uc *mock.MockSessionService) {
+				uc.EXPECT().Revoke(gomock.Any(), 1, 7).Times(1).Return(httpErrors.ErrTimeout)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+				assert.Empty(t, recorder.Result().Cookies())
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockSessionService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, nil)
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1, "sid": 7})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			cookies := httpUtils.NewSessionCookies(domain.CookieSessions{CookieSessionsEnabled: true}, 3600)
+			NewSessionHandlers(router, logger.Sugar(), uc, render.New(), cookies)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTaskHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		url           string
+		buildStubs    func(uc *mock.MockTaskService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			url: "/v1/me/tasks/task-1",
+			buildStubs: func(uc *mock.MockTaskService) {
+				uc.EXPECT().GetTask(gomock.Any(), 1, "task-1").Times(1).Return(&domain.Task{
+					ID:      "task-1",
+					Type:    domain.TaskAccountExport,
+					UserID:  1,
+					Payload: json.RawMessage(`{"export_id":4}`),
+					Status:  domain.TaskSucceeded,
+					Result:  json.RawMessage(`{"size":10}`),
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"status":"succeeded"`)
+				assert.Contains(t, recorder.Body.String(), `"result":{"size":10}`)
+				assert.NotContains(t, recorder.Body.String(), "export_id")
+			},
+		},
+		"Not Found": {
+			url: "/v1/me/tasks/task-2",
+			buildStubs: func(uc *mock.MockTaskService) {
+				uc.EXPECT().GetTask(gomock.Any(), 1, "task-2").Times(1).Return(nil, httpErrors.ErrTaskNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockTaskService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewTaskHandlers(router, logger.Sugar(), uc, render.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
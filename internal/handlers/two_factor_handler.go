@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+var _ handlerPort.TwoFactorHandlers = (*TwoFactorHandlers)(nil)
+
+// NewTwoFactorHandlers creates an instance of two-factor handlers
+func NewTwoFactorHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.TwoFactorService, render *render.Render, validate *validator.Validate) {
+	var tokenAuth = httpUtils.TokenAuth
+
+	handler := &TwoFactorHandlers{
+		logger:   logger,
+		service:  s,
+		response: render,
+		validate: validate,
+	}
+
+	r.Route("/v1/me/2fa", func(r chi.Router) {
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/enroll", handler.EnrollHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/confirm", handler.ConfirmHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/recovery-codes", handler.RecoveryCodesHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/disable", handler.DisableHandler)
+	})
+}
+
+type TwoFactorHandlers struct {
+	logger   *zap.SugaredLogger
+	service  svcports.TwoFactorService
+	response *render.Render
+	validate *validator.Validate
+}
+
+// EnrollHandler starts the enrollment and returns the QR provisioning URI
+func (h *TwoFactorHandlers) EnrollHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.Enroll(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.TwoFactorEnrollment]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// ConfirmHandler enables 2FA and returns the recovery codes
+func (h *TwoFactorHandlers) ConfirmHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	form, ok := h.readCode(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.Confirm(ctx, UserID, form.Code)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.RecoveryCodesResponse]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// RecoveryCodesHandler replaces the recovery codes
+func (h *TwoFactorHandlers) RecoveryCodesHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	form, ok := h.readCode(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.RegenerateRecoveryCodes(ctx, UserID, form.Code)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.RecoveryCodesResponse]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// DisableHandler turns off 2FA
+func (h *TwoFactorHandlers) DisableHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	form, ok := h.readCode(w, req)
+	if !ok {
+		return
+	}
+	ctx := req.Context()
+
+	if err := h.service.Disable(ctx, UserID, form.Code); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	_ = h.response.JSON(w, http.StatusOK, domain.SuccessResponse{Message: "Two-factor authentication has been disabled."})
+}
+
+// readCode reads and validates the code of the request body
+func (h *TwoFactorHandlers) readCode(w http.ResponseWriter, req *http.Request) (*domain.TwoFactorCodeRequest, bool) {
+	var form = &domain.TwoFactorCodeRequest{}
+	if err := httpUtils.ReadJSON(w, req, &form); err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return nil, false
+	}
+	if err := form.Validate(h.validate); err != nil {
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return nil, false
+	}
+	return form, true
+}
+
+// writeError maps service errors to responses
+func (h *TwoFactorHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrInvalidTwoFactorCode) {
+			_ = h.response.JSON(w, http.StatusUnprocessableEntity, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else if errors.Is(err, httpErrors.ErrTwoFactorAlreadyEnabled) || errors.Is(err, httpErrors.ErrTwoFactorNotEnabled) || errors.Is(err, httpErrors.ErrTwoFactorNotEnrolled) {
+			_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: err.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
+}
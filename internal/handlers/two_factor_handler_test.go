@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfirmTwoFactorHandler(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+	testCases := map[string]struct {
+		body          string
+		buildStubs    func(uc *mock.MockTwoFactorService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			body: `{"code": "123456"}`,
+			buildStubs: func(uc *mock.MockTwoFactorService) {
+				uc.EXPECT().
+					Confirm(gomock.Any(), 1, "123456").
+					Times(1).
+					Return(&domain.RecoveryCodesResponse{Codes: []string{"abcde-12345"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "abcde-12345")
+			},
+		},
+		"Missing Code": {
+			body: `{}`,
+			buildStubs: func(uc *mock.MockTwoFactorService) {
+				uc.EXPECT().Confirm(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Invalid Code": {
+			body: `{"code": "000000"}`,
+			buildStubs: func(uc *mock.MockTwoFactorService) {
+				uc.EXPECT().Confirm(gomock.Any(), 1, "000000").Times(1).Return(nil, httpErrors.ErrInvalidTwoFactorCode)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		"Already Enabled": {
+			body: `{"code": "123456"}`,
+			buildStubs: func(uc *mock.MockTwoFactorService) {
+				uc.EXPECT().Confirm(gomock.Any(), 1, "123456").Times(1).Return(nil, httpErrors.ErrTwoFactorAlreadyEnabled)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockTwoFactorService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/me/2fa/confirm", bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			slogger := logger.Sugar()
+			r := render.New()
+			NewUserHandlers(router, slogger, nil, r, validator.New())
+			NewTwoFactorHandlers(router, slogger, uc, r, validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
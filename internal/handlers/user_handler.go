@@ -1,22 +1,26 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/unrolled/render"
 	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
 	handlerPort "kiramishima/m-backend/internal/core/ports/handlers"
 	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
 	"net/http"
-	"os"
 )
 
 var _ handlerPort.UserHandlers = (*UserHandlers)(nil)
 
-// NewBondHandlers creates a instance of auth handlers
+// NewUserHandlers creates an instance of user handlers
 func NewUserHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.UserService, render *render.Render, validate *validator.Validate) {
-	var tokenAuth = jwtauth.New("HS256", []byte(os.Getenv("SecretKey")), nil)
+	var tokenAuth = httpUtils.TokenAuth
 
 	handler := &UserHandlers{
 		logger:   logger,
@@ -27,8 +31,9 @@ func NewUserHandlers(r *chi.Mux, logger *zap.SugaredLogger, s svcports.UserServi
 
 	r.Route("/v1/me", func(r chi.Router) {
 		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/", handler.GetProfileHandler)
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Post("/", handler.UpdateProfileHandler)
-		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Put("/bonds", handler.GetUserBondsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Patch("/", handler.UpdateProfileHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/bonds", handler.GetUserBondsHandler)
+		r.With(jwtauth.Verifier(tokenAuth)).With(jwtauth.Authenticator(tokenAuth)).Get("/transactions", handler.GetUserTransactionsHandler)
 	})
 }
 
@@ -39,17 +44,110 @@ type UserHandlers struct {
 	validate *validator.Validate
 }
 
-func (u UserHandlers) GetProfileHandler(w http.ResponseWriter, req *http.Request) {
-	//TODO implement me
-	panic("implement me")
+// GetProfileHandler returns the profile of the user
+func (h *UserHandlers) GetProfileHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.GetProfile(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.UserProfile]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
 }
 
-func (u UserHandlers) UpdateProfileHandler(w http.ResponseWriter, req *http.Request) {
-	//TODO implement me
-	panic("implement me")
+// UpdateProfileHandler changes the fields of the profile sent in the body
+func (h *UserHandlers) UpdateProfileHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	var form = &domain.UserProfileRequest{}
+
+	err := httpUtils.ReadJSON(w, req, &form)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidRequestBody.Error()})
+		return
+	}
+	form.UserID = &UserID
+	// Validate form
+	err = form.Validate(h.validate)
+	if err != nil {
+		h.logger.Error(err.Error())
+		_ = h.response.JSON(w, http.StatusBadRequest, domain.ErrorResponse{ErrorMessage: err.Error()})
+		return
+	}
+	ctx := req.Context()
+
+	resp, err := h.service.UpdateProfile(ctx, form)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[*domain.UserProfile]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
 }
 
-func (u UserHandlers) GetUserBondsHandler(w http.ResponseWriter, req *http.Request) {
-	//TODO implement me
-	panic("implement me")
+// GetUserBondsHandler lists the bonds created by the user
+func (h *UserHandlers) GetUserBondsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.GetBonds(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.Bond]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// GetUserTransactionsHandler lists the purchases and the sales of the user
+func (h *UserHandlers) GetUserTransactionsHandler(w http.ResponseWriter, req *http.Request) {
+	var UserID = httpUtils.GetUserIDInJWTHeader(req)
+	ctx := req.Context()
+
+	resp, err := h.service.GetTransactions(ctx, UserID)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if err := h.response.JSON(w, http.StatusOK, domain.WrapResponse[[]*domain.Transaction]{Data: resp}); err != nil {
+		h.logger.Error(err)
+		_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		return
+	}
+}
+
+// writeError maps service errors to responses
+func (h *UserHandlers) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	h.logger.Error(err.Error())
+
+	select {
+	case <-ctx.Done():
+		_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	default:
+		if errors.Is(err, httpErrors.ErrTimeout) {
+			_ = h.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+		} else if errors.Is(err, httpErrors.ErrUserNotFound) {
+			_ = h.response.JSON(w, http.StatusNotFound, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUserNotFound.Error()})
+		} else if errors.Is(err, httpErrors.ErrUsernameTaken) {
+			_ = h.response.JSON(w, http.StatusConflict, domain.ErrorResponse{ErrorMessage: httpErrors.ErrUsernameTaken.Error()})
+		} else {
+			_ = h.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+		}
+	}
 }
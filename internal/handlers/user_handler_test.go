@@ -1 +1,168 @@
 package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	uid := 1
+	gender := "female"
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockUserService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Get Profile OK": {
+			method: http.MethodGet,
+			url:    "/v1/me",
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().GetProfile(gomock.Any(), 1).Times(1).Return(&domain.UserProfile{UserID: 1, UserName: "ginigini", Gender: "female"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"username":"ginigini"`)
+			},
+		},
+		"Get Profile Not Found": {
+			method: http.MethodGet,
+			url:    "/v1/me",
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().GetProfile(gomock.Any(), 1).Times(1).Return(nil, httpErrors.ErrUserNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Update Profile OK": {
+			method: http.MethodPatch,
+			url:    "/v1/me",
+			body:   `{"gender": "female"}`,
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().
+					UpdateProfile(gomock.Any(), &domain.UserProfileRequest{UserID: &uid, Gender: &gender}).
+					Times(1).
+					Return(&domain.UserProfile{UserID: 1, UserName: "ginigini", Gender: "female"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"gender":"female"`)
+			},
+		},
+		"Update Profile Short Username": {
+			method: http.MethodPatch,
+			url:    "/v1/me",
+			body:   `{"username": "gini"}`,
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Update Profile Unknown Gender": {
+			method: http.MethodPatch,
+			url:    "/v1/me",
+			body:   `{"gender": "robot"}`,
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Update Profile Invalid Body": {
+			method: http.MethodPatch,
+			url:    "/v1/me",
+			body:   `{"username": 1}`,
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Get Bonds OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/bonds",
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().GetBonds(gomock.Any(), 1).Times(1).Return([]*domain.Bond{{Name: "Bond A", IsOwner: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "Bond A")
+			},
+		},
+		"Get Bonds Failed": {
+			method: http.MethodGet,
+			url:    "/v1/me/bonds",
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().GetBonds(gomock.Any(), 1).Times(1).Return(nil, httpErrors.InternalServerError)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		"Update Profile Username Taken": {
+			method: http.MethodPatch,
+			url:    "/v1/me",
+			body:   `{"username":"takenname"}`,
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrUsernameTaken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		"Get Transactions OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/transactions",
+			buildStubs: func(uc *mock.MockUserService) {
+				uc.EXPECT().GetTransactions(gomock.Any(), 1).Times(1).Return([]*domain.Transaction{{ID: 9, BondName: "Bond A", Status: domain.TransactionSettled}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"id":9`)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockUserService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewUserHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
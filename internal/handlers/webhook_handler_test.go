@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	httpUtils.TokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+	code := http.StatusOK
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		body          string
+		buildStubs    func(uc *mock.MockWebhookService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Create OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks",
+			body:   `{"url": "https://example.com/hook", "events": ["trade.executed"]}`,
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().
+					Create(gomock.Any(), 1, &domain.WebhookRequest{URL: "https://example.com/hook", Events: []string{"trade.executed"}}).
+					Times(1).
+					Return(&domain.WebhookCreated{Webhook: &domain.Webhook{ID: 1, URL: "https://example.com/hook", Secret: "whsec"}, Secret: "whsec"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"secret":"whsec"`)
+			},
+		},
+		"Create Unknown Event": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks",
+			body:   `{"url": "https://example.com/hook", "events": ["user.deleted"]}`,
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"Create Invalid URL": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks",
+			body:   `{"url": "ftp://example.com", "events": ["trade.executed"]}`,
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		"List OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/webhooks",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().List(gomock.Any(), 1).Times(1).Return([]*domain.Webhook{{ID: 1, URL: "https://example.com/hook", Secret: "whsec"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "https://example.com/hook")
+				assert.NotContains(t, recorder.Body.String(), "whsec")
+			},
+		},
+		"Delete Not Found": {
+			method: http.MethodDelete,
+			url:    "/v1/me/webhooks/9",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Delete(gomock.Any(), 1, 9).Times(1).Return(httpErrors.ErrWebhookNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Deliveries OK": {
+			method: http.MethodGet,
+			url:    "/v1/me/webhooks/1/deliveries",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().ListDeliveries(gomock.Any(), 1, 1).Times(1).
+					Return([]*domain.WebhookDelivery{{ID: 3, WebhookID: 1, Status: domain.WebhookDeliverySucceeded, StatusCode: &code}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"status_code":200`)
+			},
+		},
+		"Redeliver Accepted": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks/1/deliveries/3/redeliver",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Redeliver(gomock.Any(), 1, 1, 3).Times(1).
+					Return(&domain.WebhookDelivery{ID: 3, WebhookID: 1, Status: domain.WebhookDeliveryPending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		"Redeliver Not Found": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks/1/deliveries/4/redeliver",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Redeliver(gomock.Any(), 1, 1, 4).Times(1).Return(nil, httpErrors.ErrWebhookDeliveryNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		"Ping OK": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks/1/ping",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Ping(gomock.Any(), 1, 1).Times(1).
+					Return(&domain.WebhookDelivery{ID: 5, WebhookID: 1, EventType: domain.WebhookEventPing, Status: domain.WebhookDeliverySucceeded, StatusCode: &code}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"event_type":"ping"`)
+			},
+		},
+		"Ping Bad ID": {
+			method: http.MethodPost,
+			url:    "/v1/me/webhooks/abc/ping",
+			buildStubs: func(uc *mock.MockWebhookService) {
+				uc.EXPECT().Ping(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mock.NewMockWebhookService(ctrl)
+			tc.buildStubs(uc)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			_, token, err := httpUtils.TokenAuth.Encode(map[string]interface{}{"user_id": 1})
+			assert.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			router := chi.NewRouter()
+			logger, _ := zap.NewProduction()
+			NewWebhookHandlers(router, logger.Sugar(), uc, render.New(), validator.New())
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader header that carries an API key, "Authorization: ApiKey <key>" works too
+const APIKeyHeader = "X-API-Key"
+
+// Auth middleware accepts a bearer JWT or an API key
+type Auth struct {
+	logger    *zap.SugaredLogger
+	service   svcports.APIKeyService
+	response  *render.Render
+	tokenAuth *jwtauth.JWTAuth
+	limiter   *RateLimiter
+}
+
+// NewAuth creates an instance of the auth middleware
+func NewAuth(logger *zap.SugaredLogger, s svcports.APIKeyService, render *render.Render, tokenAuth *jwtauth.JWTAuth, limiter *RateLimiter) *Auth {
+	return &Auth{
+		logger:    logger,
+		service:   s,
+		response:  render,
+		tokenAuth: tokenAuth,
+		limiter:   limiter,
+	}
+}
+
+// Authenticate lets through a valid bearer JWT, or an API key that grants the
+// scope. API key requests get a token in the context with the user_id of the
+// owner and no roles, so GetUserIDInJWTHeader keeps working and RBAC routes
+// stay closed to them.
+func (m *Auth) Authenticate(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		jwtHandler := jwtauth.Verifier(m.tokenAuth)(jwtauth.Authenticator(m.tokenAuth)(next))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			raw := apiKeyFromRequest(req)
+			if raw == "" {
+				jwtHandler.ServeHTTP(w, req)
+				return
+			}
+
+			key, err := m.service.Authenticate(req.Context(), raw, httpUtils.ClientIP(req), scope)
+			if err != nil {
+				m.writeError(w, err)
+				return
+			}
+			if !m.limiter.AllowAPIKey(w, req, key.ID) {
+				return
+			}
+
+			token, _, err := m.tokenAuth.Encode(map[string]interface{}{
+				"user_id":    float64(key.UserID),
+				"api_key_id": float64(key.ID),
+				"scopes":     key.Scopes,
+			})
+			if err != nil {
+				m.logger.Error(err.Error())
+				_ = m.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+				return
+			}
+
+			ctx := jwtauth.NewContext(req.Context(), token, nil)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+func (m *Auth) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, httpErrors.ErrInvalidAPIKey) {
+		_ = m.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrInvalidAPIKey.Error()})
+	} else if errors.Is(err, httpErrors.ErrAPIKeyIPNotAllowed) {
+		_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrAPIKeyIPNotAllowed.Error()})
+	} else if errors.Is(err, httpErrors.ErrAPIKeyScopeDenied) {
+		_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ErrAPIKeyScopeDenied.Error()})
+	} else if errors.Is(err, httpErrors.ErrTimeout) {
+		_ = m.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+	} else {
+		m.logger.Error(err.Error())
+		_ = m.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+	}
+}
+
+// apiKeyFromRequest reads the key from X-API-Key or from an ApiKey Authorization header
+func apiKeyFromRequest(req *http.Request) string {
+	if key := req.Header.Get(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	scheme, key, found := strings.Cut(req.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
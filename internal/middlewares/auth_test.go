@@ -0,0 +1,144 @@
+package middlewares
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	testCases := map[string]struct {
+		setHeaders    func(t *testing.T, req *http.Request)
+		buildStubs    func(svc *mock.MockAPIKeyService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Bearer Token": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				_, token, err := tokenAuth.Encode(map[string]interface{}{"user_id": 3})
+				assert.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer "+token)
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "3", recorder.Body.String())
+			},
+		},
+		"API Key Header": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_secret")
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().
+					Authenticate(gomock.Any(), "mbk_a1b2c3d4e5f6_secret", gomock.Any(), domain.ScopeMarketRead).
+					Times(1).
+					Return(&domain.APIKey{ID: 7, UserID: 5, Scopes: []string{domain.ScopeMarketRead}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "5", recorder.Body.String())
+			},
+		},
+		"API Key Authorization": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "ApiKey mbk_a1b2c3d4e5f6_secret")
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().
+					Authenticate(gomock.Any(), "mbk_a1b2c3d4e5f6_secret", gomock.Any(), domain.ScopeMarketRead).
+					Times(1).
+					Return(&domain.APIKey{ID: 7, UserID: 5, Scopes: []string{domain.ScopeMarketRead}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Forwarded IP Of An Untrusted Peer": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_secret")
+				req.Header.Set("X-Forwarded-For", "203.0.113.10")
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				// the allowlist is checked against the peer, not the header
+				svc.EXPECT().
+					Authenticate(gomock.Any(), "mbk_a1b2c3d4e5f6_secret", "192.0.2.1", domain.ScopeMarketRead).
+					Times(1).
+					Return(nil, httpErrors.ErrAPIKeyIPNotAllowed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Invalid Key": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_wrong")
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrInvalidAPIKey)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		"Scope Denied": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_secret")
+			},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrAPIKeyScopeDenied)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"No Credentials": {
+			setHeaders: func(t *testing.T, req *http.Request) {},
+			buildStubs: func(svc *mock.MockAPIKeyService) {
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockAPIKeyService(ctrl)
+			tc.buildStubs(svc)
+
+			logger, _ := zap.NewProduction()
+			m := NewAuth(logger.Sugar(), svc, render.New(), tokenAuth, NewRateLimiter(logger.Sugar(), nil, render.New(), tokenAuth, domain.RateLimit{}))
+
+			realIP, err := NewRealIP(nil)
+			assert.NoError(t, err)
+
+			router := chi.NewRouter()
+			router.Use(realIP.Handler)
+			router.With(m.Authenticate(domain.ScopeMarketRead)).Get("/", func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(strconv.Itoa(httpUtils.GetUserIDInJWTHeader(req))))
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			tc.setHeaders(t, request)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"time"
+)
+
+// CSRF middleware enforces the double-submit token on requests authenticated by cookie
+type CSRF struct {
+	logger   *zap.SugaredLogger
+	response *render.Render
+}
+
+// NewCSRF creates an instance of the CSRF middleware
+func NewCSRF(logger *zap.SugaredLogger, render *render.Render) *CSRF {
+	return &CSRF{
+		logger:   logger,
+		response: render,
+	}
+}
+
+// Protect checks the state-changing requests that carry a session cookie: the
+// X-CSRF-Token header must match the csrf_token cookie and be a valid token.
+// Requests with an Authorization or an API key header are not checked, the
+// browser never adds those on its own and jwtauth prefers the header.
+func (m *CSRF) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isSafeMethod(req.Method) || !hasSessionCookie(req) ||
+			req.Header.Get("Authorization") != "" || req.Header.Get(APIKeyHeader) != "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if err := checkCSRF(req); err != nil {
+			m.writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (m *CSRF) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, httpErrors.CSRFNotPresented) {
+		_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.CSRFNotPresented.Error()})
+	} else if errors.Is(err, httpErrors.ExpiredCSRFError) {
+		_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.ExpiredCSRFError.Error()})
+	} else if errors.Is(err, httpErrors.WrongCSRFToken) {
+		_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.WrongCSRFToken.Error()})
+	} else {
+		m.logger.Error(err.Error())
+		_ = m.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+	}
+}
+
+func checkCSRF(req *http.Request) error {
+	header := req.Header.Get(httpUtils.CSRFHeader)
+	cookie, err := req.Cookie(httpUtils.CSRFCookie)
+	if header == "" || err != nil || cookie.Value == "" {
+		return httpErrors.CSRFNotPresented
+	}
+	if subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+		return httpErrors.WrongCSRFToken
+	}
+	return httpUtils.ValidateCSRFToken(header, time.Now())
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func hasSessionCookie(req *http.Request) bool {
+	for _, name := range []string{httpUtils.AccessCookie, httpUtils.RefreshCookie} {
+		if c, err := req.Cookie(name); err == nil && c.Value != "" {
+			return true
+		}
+	}
+	return false
+}
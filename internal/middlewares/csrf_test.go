@@ -0,0 +1,156 @@
+package middlewares
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProtect(t *testing.T) {
+	token, err := httpUtils.GenerateCSRFToken(time.Hour)
+	assert.NoError(t, err)
+	other, err := httpUtils.GenerateCSRFToken(time.Hour)
+	assert.NoError(t, err)
+	expired, err := httpUtils.GenerateCSRFToken(-time.Minute)
+	assert.NoError(t, err)
+
+	sessionCookie := &http.Cookie{Name: httpUtils.AccessCookie, Value: "access-token"}
+
+	testCases := map[string]struct {
+		method        string
+		setHeaders    func(req *http.Request)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.AddCookie(&http.Cookie{Name: httpUtils.CSRFCookie, Value: token})
+				req.Header.Set(httpUtils.CSRFHeader, token)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Refresh Cookie": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(&http.Cookie{Name: httpUtils.RefreshCookie, Value: "refresh-token"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.CSRFNotPresented.Error())
+			},
+		},
+		"Safe Method": {
+			method: http.MethodGet,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"No Session Cookie": {
+			method:     http.MethodDelete,
+			setHeaders: func(req *http.Request) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Bearer Token": {
+			method: http.MethodPut,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.Header.Set("Authorization", "Bearer access-token")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Missing Header": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.AddCookie(&http.Cookie{Name: httpUtils.CSRFCookie, Value: token})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.CSRFNotPresented.Error())
+			},
+		},
+		"Missing Cookie": {
+			method: http.MethodPatch,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.Header.Set(httpUtils.CSRFHeader, token)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.CSRFNotPresented.Error())
+			},
+		},
+		"Mismatch": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.AddCookie(&http.Cookie{Name: httpUtils.CSRFCookie, Value: token})
+				req.Header.Set(httpUtils.CSRFHeader, other)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.WrongCSRFToken.Error())
+			},
+		},
+		"Forged Token": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.AddCookie(&http.Cookie{Name: httpUtils.CSRFCookie, Value: "forged"})
+				req.Header.Set(httpUtils.CSRFHeader, "forged")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.WrongCSRFToken.Error())
+			},
+		},
+		"Expired": {
+			method: http.MethodPost,
+			setHeaders: func(req *http.Request) {
+				req.AddCookie(sessionCookie)
+				req.AddCookie(&http.Cookie{Name: httpUtils.CSRFCookie, Value: expired})
+				req.Header.Set(httpUtils.CSRFHeader, expired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.ExpiredCSRFError.Error())
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger, _ := zap.NewProduction()
+			m := NewCSRF(logger.Sugar(), render.New())
+
+			router := chi.NewRouter()
+			router.Use(m.Protect)
+			router.MethodFunc(tc.method, "/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, "/", nil)
+			tc.setHeaders(request)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
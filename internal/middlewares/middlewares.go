@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"github.com/unrolled/render"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	cache "kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/core/domain"
+	"kiramishima/m-backend/internal/core/services"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+)
+
+// Module Middlewares.
+var Module = fx.Module("middlewares",
+	fx.Provide(func(logger *zap.SugaredLogger, svc *services.RoleService, render *render.Render) *RBAC {
+		return NewRBAC(logger, svc, render)
+	}),
+	fx.Provide(func(logger *zap.SugaredLogger, svc *services.APIKeyService, render *render.Render, limiter *RateLimiter) *Auth {
+		return NewAuth(logger, svc, render, httpUtils.TokenAuth, limiter)
+	}),
+	fx.Provide(func(logger *zap.SugaredLogger, svc *services.SessionService, render *render.Render) *ActiveSession {
+		return NewActiveSession(logger, svc, render, httpUtils.TokenAuth)
+	}),
+	fx.Provide(func(logger *zap.SugaredLogger, render *render.Render) *CSRF {
+		return NewCSRF(logger, render)
+	}),
+	fx.Provide(func(cfg *domain.Configuration) (*RealIP, error) {
+		return NewRealIP(cfg.TrustedProxies)
+	}),
+	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, store *cache.RateLimitRepository, render *render.Render) *RateLimiter {
+		return NewRateLimiter(logger, store, render, httpUtils.TokenAuth, cfg.RateLimit)
+	}),
+)
@@ -0,0 +1,202 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimitStoreTimeout keeps a slow Redis from holding the requests, past it
+// the limiter counts in memory
+const rateLimitStoreTimeout = 250 * time.Millisecond
+
+// RateLimiter middleware gives every principal (user, API key or anonymous IP)
+// a budget per route class. The counters live in Redis so every replica shares
+// them; while Redis is down every replica counts on its own.
+type RateLimiter struct {
+	logger        *zap.SugaredLogger
+	store         repport.RateLimitRepository
+	local         *localRateLimiter
+	response      *render.Render
+	tokenAuth     *jwtauth.JWTAuth
+	window        time.Duration
+	limits        map[string]int
+	fallbackRetry time.Duration
+	// storeDownUntil unix nanoseconds until the limiter tries Redis again
+	storeDownUntil atomic.Int64
+}
+
+// NewRateLimiter creates an instance of the rate limit middleware
+func NewRateLimiter(logger *zap.SugaredLogger, store repport.RateLimitRepository, render *render.Render, tokenAuth *jwtauth.JWTAuth, cfg domain.RateLimit) *RateLimiter {
+	return &RateLimiter{
+		logger:    logger,
+		store:     store,
+		local:     newLocalRateLimiter(),
+		response:  render,
+		tokenAuth: tokenAuth,
+		window:    time.Duration(cfg.RateLimitWindow) * time.Second,
+		limits: map[string]int{
+			domain.RateLimitClassAuth:  cfg.RateLimitAuth,
+			domain.RateLimitClassRead:  cfg.RateLimitRead,
+			domain.RateLimitClassTrade: cfg.RateLimitTrade,
+		},
+		fallbackRetry: time.Duration(cfg.RateLimitFallbackRetry) * time.Second,
+	}
+}
+
+// Limit counts the request against the budget of its class and answers 429
+// once the budget is spent. A class with a budget of 0 is not limited.
+func (m *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !m.allow(w, req, m.principal(req)) {
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// AllowAPIKey counts a request of an API key already checked by Auth against
+// the budget of the key, and answers 429 when it is spent
+func (m *RateLimiter) AllowAPIKey(w http.ResponseWriter, req *http.Request, keyID int) bool {
+	return m.allow(w, req, "key:"+strconv.Itoa(keyID))
+}
+
+// allow counts the request of principal, sets the RateLimit headers and writes
+// the 429 when the budget of the class is spent
+func (m *RateLimiter) allow(w http.ResponseWriter, req *http.Request, principal string) bool {
+	class := routeClass(req)
+	limit := m.limits[class]
+	if limit <= 0 || m.window <= 0 {
+		return true
+	}
+
+	hit := m.hit(req.Context(), class+":"+principal)
+	reset := int(math.Ceil(hit.Reset.Seconds()))
+	remaining := int64(limit) - hit.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(m.window.Seconds())))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(reset))
+
+	if hit.Count > int64(limit) {
+		w.Header().Set("Retry-After", strconv.Itoa(reset))
+		_ = m.response.JSON(w, http.StatusTooManyRequests, domain.ErrorResponse{ErrorMessage: httpErrors.ErrRateLimited.Error()})
+		return false
+	}
+	return true
+}
+
+// hit counts in Redis, or in memory while Redis is failing
+func (m *RateLimiter) hit(ctx context.Context, key string) *domain.RateLimitHit {
+	now := time.Now()
+	if now.UnixNano() >= m.storeDownUntil.Load() {
+		ctx, cancel := context.WithTimeout(ctx, rateLimitStoreTimeout)
+		defer cancel()
+
+		hit, err := m.store.Hit(ctx, key, m.window)
+		if err == nil {
+			return hit
+		}
+		m.logger.Warnw("rate limit store unavailable, counting in memory", "error", err.Error())
+		m.storeDownUntil.Store(now.Add(m.fallbackRetry).UnixNano())
+	}
+	return m.local.Hit(key, m.window, now)
+}
+
+// principal the user of a valid access token or the client IP. An API key
+// can't be trusted before Auth checks it, so its requests count for the IP
+// here and for the key in AllowAPIKey.
+func (m *RateLimiter) principal(req *http.Request) string {
+	raw := jwtauth.TokenFromHeader(req)
+	if raw == "" {
+		raw = jwtauth.TokenFromCookie(req)
+	}
+	if raw != "" {
+		if token, err := jwtauth.VerifyToken(m.tokenAuth, raw); err == nil {
+			if uid, ok := token.PrivateClaims()["user_id"].(float64); ok {
+				return "user:" + strconv.Itoa(int(uid))
+			}
+		}
+	}
+
+	return "ip:" + httpUtils.ClientIP(req)
+}
+
+// routeClass the sign in flows, the market orders, and everything else
+func routeClass(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/v1/auth/"):
+		return domain.RateLimitClassAuth
+	case req.Method == http.MethodPost && strings.HasPrefix(path, "/v1/market/") &&
+		(strings.HasSuffix(path, "/buy") || path == "/v1/market/sell"):
+		return domain.RateLimitClassTrade
+	default:
+		return domain.RateLimitClassRead
+	}
+}
+
+// localRateLimiter fixed window counters of one replica
+type localRateLimiter struct {
+	mu        sync.Mutex
+	counters  map[string]*localCounter
+	lastSweep time.Time
+}
+
+type localCounter struct {
+	start time.Time
+	count int64
+}
+
+func newLocalRateLimiter() *localRateLimiter {
+	return &localRateLimiter{
+		counters: make(map[string]*localCounter),
+	}
+}
+
+// Hit counts a request of the key, the counters of past windows are dropped
+// once per window
+func (l *localRateLimiter) Hit(key string, window time.Duration, now time.Time) *domain.RateLimitHit {
+	start := now.Truncate(window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= window {
+		for k, c := range l.counters {
+			if c.start.Before(start) {
+				delete(l.counters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.counters[key]
+	if !ok || !c.start.Equal(start) {
+		c = &localCounter{start: start}
+		l.counters[key] = c
+	}
+	c.count++
+
+	return &domain.RateLimitHit{
+		Count: c.count,
+		Reset: start.Add(window).Sub(now),
+	}
+}
@@ -0,0 +1,274 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var rateLimitConfig = domain.RateLimit{
+	RateLimitWindow:        60,
+	RateLimitAuth:          5,
+	RateLimitRead:          100,
+	RateLimitTrade:         10,
+	RateLimitFallbackRetry: 5,
+}
+
+func TestRateLimit(t *testing.T) {
+	_, userToken, err := tokenAuth.Encode(map[string]interface{}{"user_id": 3})
+	assert.NoError(t, err)
+
+	testCases := map[string]struct {
+		method        string
+		url           string
+		setHeaders    func(req *http.Request)
+		buildStubs    func(store *mock.MockRateLimitRepository)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Read By User": {
+			method: http.MethodGet,
+			url:    "/v1/bonds",
+			setHeaders: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+userToken)
+			},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "read:user:3", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: 30 * time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "100", recorder.Header().Get("RateLimit-Limit"))
+				assert.Equal(t, "99", recorder.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "30", recorder.Header().Get("RateLimit-Reset"))
+				assert.Equal(t, "100;w=60", recorder.Header().Get("RateLimit-Policy"))
+			},
+		},
+		"Unchecked API Key Counts By IP": {
+			method: http.MethodPost,
+			url:    "/v1/auth/sign-in",
+			setHeaders: func(req *http.Request) {
+				req.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_secret")
+			},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "auth:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 4, Reset: time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+			},
+		},
+		"Auth By IP": {
+			method:     http.MethodPost,
+			url:        "/v1/auth/sign-in",
+			setHeaders: func(req *http.Request) {},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "auth:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Invalid Token Counts By IP": {
+			method: http.MethodGet,
+			url:    "/v1/bonds",
+			setHeaders: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer not-a-token")
+			},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "read:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Market Read Is Not Trade": {
+			method: http.MethodPost,
+			url:    "/v1/market/7",
+			setHeaders: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+userToken)
+			},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "read:user:3", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Budget Spent": {
+			method: http.MethodPost,
+			url:    "/v1/market/sell",
+			setHeaders: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+userToken)
+			},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "trade:user:3", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 11, Reset: 1500 * time.Millisecond}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+				assert.Contains(t, recorder.Body.String(), httpErrors.ErrRateLimited.Error())
+			},
+		},
+		"Store Down": {
+			method:     http.MethodGet,
+			url:        "/v1/bonds",
+			setHeaders: func(req *http.Request) {},
+			buildStubs: func(store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "99", recorder.Header().Get("RateLimit-Remaining"))
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock.NewMockRateLimitRepository(ctrl)
+			tc.buildStubs(store)
+
+			logger, _ := zap.NewProduction()
+			m := NewRateLimiter(logger.Sugar(), store, render.New(), tokenAuth, rateLimitConfig)
+
+			router := chi.NewRouter()
+			router.Use(m.Limit)
+			router.HandleFunc("/*", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.url, nil)
+			tc.setHeaders(request)
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRateLimitAPIKey(t *testing.T) {
+	testCases := map[string]struct {
+		buildStubs    func(svc *mock.MockAPIKeyService, store *mock.MockRateLimitRepository)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Checked Key": {
+			buildStubs: func(svc *mock.MockAPIKeyService, store *mock.MockRateLimitRepository) {
+				gomock.InOrder(
+					store.EXPECT().Hit(gomock.Any(), "trade:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil),
+					svc.EXPECT().Authenticate(gomock.Any(), "mbk_a1b2c3d4e5f6_secret", "192.0.2.1", domain.ScopeMarketTrade).Times(1).
+						Return(&domain.APIKey{ID: 7, UserID: 5, Scopes: []string{domain.ScopeMarketTrade}}, nil),
+					store.EXPECT().Hit(gomock.Any(), "trade:key:7", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 4, Reset: time.Second}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "6", recorder.Header().Get("RateLimit-Remaining"))
+			},
+		},
+		"Key Budget Spent": {
+			buildStubs: func(svc *mock.MockAPIKeyService, store *mock.MockRateLimitRepository) {
+				store.EXPECT().Hit(gomock.Any(), "trade:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil)
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(&domain.APIKey{ID: 7, UserID: 5, Scopes: []string{domain.ScopeMarketTrade}}, nil)
+				store.EXPECT().Hit(gomock.Any(), "trade:key:7", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 11, Reset: time.Second}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		"Invalid Key": {
+			buildStubs: func(svc *mock.MockAPIKeyService, store *mock.MockRateLimitRepository) {
+				// a made up key doesn't get a budget of its own
+				store.EXPECT().Hit(gomock.Any(), "trade:ip:192.0.2.1", time.Minute).Times(1).Return(&domain.RateLimitHit{Count: 1, Reset: time.Second}, nil)
+				svc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, httpErrors.ErrInvalidAPIKey)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockAPIKeyService(ctrl)
+			store := mock.NewMockRateLimitRepository(ctrl)
+			tc.buildStubs(svc, store)
+
+			logger, _ := zap.NewProduction()
+			limiter := NewRateLimiter(logger.Sugar(), store, render.New(), tokenAuth, rateLimitConfig)
+			auth := NewAuth(logger.Sugar(), svc, render.New(), tokenAuth, limiter)
+
+			router := chi.NewRouter()
+			router.Use(limiter.Limit)
+			router.With(auth.Authenticate(domain.ScopeMarketTrade)).Post("/v1/market/{id}/buy", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/v1/market/7/buy", nil)
+			request.Header.Set(APIKeyHeader, "mbk_a1b2c3d4e5f6_secret")
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRateLimitFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Redis is only tried again once RATE_LIMIT_FALLBACK_RETRY has passed
+	store := mock.NewMockRateLimitRepository(ctrl)
+	store.EXPECT().Hit(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("connection refused"))
+
+	logger, _ := zap.NewProduction()
+	m := NewRateLimiter(logger.Sugar(), store, render.New(), tokenAuth, rateLimitConfig)
+	handler := m.Limit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 1; i <= 6; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/auth/sign-in", nil))
+		if i <= rateLimitConfig.RateLimitAuth {
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		}
+	}
+}
+
+func TestLocalRateLimiter(t *testing.T) {
+	l := newLocalRateLimiter()
+	now := time.Date(2024, 1, 1, 10, 0, 15, 0, time.UTC)
+
+	hit := l.Hit("read:user:1", time.Minute, now)
+	assert.Equal(t, int64(1), hit.Count)
+	assert.Equal(t, 45*time.Second, hit.Reset)
+
+	hit = l.Hit("read:user:1", time.Minute, now.Add(time.Second))
+	assert.Equal(t, int64(2), hit.Count)
+
+	// A new window starts over and drops the old counters
+	hit = l.Hit("read:user:2", time.Minute, now.Add(time.Minute))
+	assert.Equal(t, int64(1), hit.Count)
+	hit = l.Hit("read:user:1", time.Minute, now.Add(time.Minute))
+	assert.Equal(t, int64(1), hit.Count)
+	assert.Len(t, l.counters, 2)
+}
@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+)
+
+// RBAC middleware checks the permissions granted to the roles of the token
+type RBAC struct {
+	logger   *zap.SugaredLogger
+	service  svcports.RoleService
+	response *render.Render
+}
+
+// NewRBAC creates an instance of the RBAC middleware
+func NewRBAC(logger *zap.SugaredLogger, s svcports.RoleService, render *render.Render) *RBAC {
+	return &RBAC{
+		logger:   logger,
+		service:  s,
+		response: render,
+	}
+}
+
+// RequirePermission rejects requests whose roles don't grant the permission.
+// It must be mounted after the jwtauth Verifier and Authenticator.
+func (m *RBAC) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if _, _, err := jwtauth.FromContext(req.Context()); err != nil {
+				_ = m.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.Unauthorized.Error()})
+				return
+			}
+
+			allowed, err := m.service.HasPermission(req.Context(), httpUtils.GetRolesInJWTHeader(req), permission)
+			if err != nil {
+				m.logger.Error(err.Error())
+				_ = m.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+				return
+			}
+			if !allowed {
+				_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.PermissionDenied.Error()})
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// RequireRole rejects requests whose token doesn't carry the role.
+// It must be mounted after the jwtauth Verifier and Authenticator.
+func (m *RBAC) RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if _, _, err := jwtauth.FromContext(req.Context()); err != nil {
+				_ = m.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.Unauthorized.Error()})
+				return
+			}
+
+			for _, r := range httpUtils.GetRolesInJWTHeader(req) {
+				if r == role {
+					next.ServeHTTP(w, req)
+					return
+				}
+			}
+
+			_ = m.response.JSON(w, http.StatusForbidden, domain.ErrorResponse{ErrorMessage: httpErrors.PermissionDenied.Error()})
+		})
+	}
+}
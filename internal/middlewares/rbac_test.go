@@ -0,0 +1,163 @@
+package middlewares
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var tokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+func TestRequirePermission(t *testing.T) {
+	testCases := map[string]struct {
+		roles         []string
+		withToken     bool
+		buildStubs    func(svc *mock.MockRoleService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"OK": {
+			roles:     []string{domain.RoleAdmin},
+			withToken: true,
+			buildStubs: func(svc *mock.MockRoleService) {
+				svc.EXPECT().
+					HasPermission(gomock.Any(), []string{domain.RoleAdmin}, domain.PermissionManageUsers).
+					Times(1).
+					Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Forbidden": {
+			roles:     []string{domain.RoleCustomer},
+			withToken: true,
+			buildStubs: func(svc *mock.MockRoleService) {
+				svc.EXPECT().
+					HasPermission(gomock.Any(), []string{domain.RoleCustomer}, domain.PermissionManageUsers).
+					Times(1).
+					Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		"Unauthorized": {
+			withToken: false,
+			buildStubs: func(svc *mock.MockRoleService) {
+				svc.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		"Unexpected Error": {
+			roles:     []string{domain.RoleAdmin},
+			withToken: true,
+			buildStubs: func(svc *mock.MockRoleService) {
+				svc.EXPECT().
+					HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, httpErrors.InternalServerError)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockRoleService(ctrl)
+			tc.buildStubs(svc)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/v1/admin", nil)
+			assert.NoError(t, err)
+			if tc.withToken {
+				_, token, err := tokenAuth.Encode(map[string]interface{}{"user_id": 1, "roles": tc.roles})
+				assert.NoError(t, err)
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			logger, _ := zap.NewProduction()
+			rbac := NewRBAC(logger.Sugar(), svc, render.New())
+
+			router := chi.NewRouter()
+			router.With(jwtauth.Verifier(tokenAuth)).
+				With(jwtauth.Authenticator(tokenAuth)).
+				With(rbac.RequirePermission(domain.PermissionManageUsers)).
+				Get("/v1/admin", func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})
+			router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	testCases := map[string]struct {
+		roles        []string
+		withToken    bool
+		expectedCode int
+	}{
+		"OK": {
+			roles:        []string{domain.RoleCustomer, domain.RoleAdmin},
+			withToken:    true,
+			expectedCode: http.StatusOK,
+		},
+		"Forbidden": {
+			roles:        []string{domain.RoleCustomer},
+			withToken:    true,
+			expectedCode: http.StatusForbidden,
+		},
+		"Unauthorized": {
+			withToken:    false,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockRoleService(ctrl)
+			svc.EXPECT().HasPermission(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/v1/admin", nil)
+			assert.NoError(t, err)
+			if tc.withToken {
+				_, token, err := tokenAuth.Encode(map[string]interface{}{"user_id": 1, "roles": tc.roles})
+				assert.NoError(t, err)
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			logger, _ := zap.NewProduction()
+			rbac := NewRBAC(logger.Sugar(), svc, render.New())
+
+			router := chi.NewRouter()
+			router.With(jwtauth.Verifier(tokenAuth)).
+				With(jwtauth.Authenticator(tokenAuth)).
+				With(rbac.RequireRole(domain.RoleAdmin)).
+				Get("/v1/admin", func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})
+			router.ServeHTTP(recorder, request)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP middleware replaces RemoteAddr with the address of the client when
+// the request comes through a trusted proxy. The forwarded headers of any
+// other peer are ignored, so a client can't choose the IP used by the login
+// guard, the rate limits and the API key allowlists.
+type RealIP struct {
+	trusted []netip.Prefix
+}
+
+// NewRealIP creates an instance of the RealIP middleware, proxies are IPs or
+// CIDR ranges
+func NewRealIP(proxies []string) (*RealIP, error) {
+	m := &RealIP{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		m.trusted = append(m.trusted, prefix.Masked())
+	}
+	return m, nil
+}
+
+// Handler rewrites RemoteAddr with the client of a trusted proxy
+func (m *RealIP) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ip, ok := m.clientIP(req); ok {
+			req.RemoteAddr = ip.String()
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// clientIP walks X-Forwarded-For from the right, past the trusted proxies, and
+// returns the first address they didn't add themselves. X-Real-IP is used
+// when the proxy sends no X-Forwarded-For.
+func (m *RealIP) clientIP(req *http.Request) (netip.Addr, bool) {
+	peer, ok := parseIP(req.RemoteAddr)
+	if !ok || !m.isTrusted(peer) {
+		return netip.Addr{}, false
+	}
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		if ip, ok := parseIP(req.Header.Get("X-Real-IP")); ok {
+			return ip, true
+		}
+		return netip.Addr{}, false
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseIP(hops[i])
+		if !ok {
+			break
+		}
+		client = ip
+		if !m.isTrusted(ip) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+func (m *RealIP) isTrusted(ip netip.Addr) bool {
+	for _, prefix := range m.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP parses an address with or without port
+func parseIP(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	ip, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
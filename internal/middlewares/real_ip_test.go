@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	httpUtils "kiramishima/m-backend/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	m, err := NewRealIP([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		"Direct Client": {
+			remoteAddr: "203.0.113.7:5123",
+			want:       "203.0.113.7",
+		},
+		"Spoofed Header From An Untrusted Peer": {
+			remoteAddr: "203.0.113.7:5123",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			want:       "203.0.113.7",
+		},
+		"Trusted Proxy": {
+			remoteAddr: "10.0.0.5:443",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		"Spoofed Hop Before The Proxy": {
+			remoteAddr: "10.0.0.5:443",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 192.168.1.1"},
+			want:       "198.51.100.1",
+		},
+		"X-Real-IP From A Trusted Proxy": {
+			remoteAddr: "192.168.1.1:443",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.3"},
+			want:       "198.51.100.3",
+		},
+		"Invalid Hop": {
+			remoteAddr: "10.0.0.5:443",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip"},
+			want:       "10.0.0.5",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = httpUtils.ClientIP(r)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				request.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("Invalid Proxy", func(t *testing.T) {
+		_, err := NewRealIP([]string{"10.0.0.0/33"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/core/domain"
+	svcports "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+)
+
+// ActiveSession middleware rejects the access tokens whose session was
+// revoked, or whose user was suspended or deleted, before they expire. It runs
+// for every request; a request without a valid token is left to the routes.
+type ActiveSession struct {
+	logger    *zap.SugaredLogger
+	service   svcports.SessionService
+	response  *render.Render
+	tokenAuth *jwtauth.JWTAuth
+}
+
+// NewActiveSession creates an instance of the ActiveSession middleware
+func NewActiveSession(logger *zap.SugaredLogger, s svcports.SessionService, render *render.Render, tokenAuth *jwtauth.JWTAuth) *ActiveSession {
+	return &ActiveSession{
+		logger:    logger,
+		service:   s,
+		response:  render,
+		tokenAuth: tokenAuth,
+	}
+}
+
+// Check answers 401 to a token of a session that isn't active anymore
+func (m *ActiveSession) Check(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, err := jwtauth.VerifyRequest(m.tokenAuth, req, jwtauth.TokenFromHeader, jwtauth.TokenFromCookie)
+		if err != nil || token == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		claims := token.PrivateClaims()
+		uid, okUser := claims["user_id"].(float64)
+		sid, okSession := claims["sid"].(float64)
+		if !okUser || !okSession {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		active, err := m.service.Active(req.Context(), int(uid), int(sid))
+		if err != nil {
+			if errors.Is(err, httpErrors.ErrTimeout) {
+				_ = m.response.JSON(w, http.StatusGatewayTimeout, domain.ErrorResponse{ErrorMessage: httpErrors.ErrTimeout.Error()})
+				return
+			}
+			_ = m.response.JSON(w, http.StatusInternalServerError, domain.ErrorResponse{ErrorMessage: httpErrors.InternalServerError.Error()})
+			return
+		}
+		if !active {
+			_ = m.response.JSON(w, http.StatusUnauthorized, domain.ErrorResponse{ErrorMessage: httpErrors.ErrSessionRevoked.Error()})
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/unrolled/render"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestActiveSession(t *testing.T) {
+	bearer := func(claims map[string]interface{}) func(t *testing.T, req *http.Request) {
+		return func(t *testing.T, req *http.Request) {
+			_, token, err := tokenAuth.Encode(claims)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	testCases := map[string]struct {
+		setHeaders    func(t *testing.T, req *http.Request)
+		buildStubs    func(svc *mock.MockSessionService)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		"Active Session": {
+			setHeaders: bearer(map[string]interface{}{"user_id": 3, "sid": 4}),
+			buildStubs: func(svc *mock.MockSessionService) {
+				svc.EXPECT().Active(gomock.Any(), 3, 4).Times(1).Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Revoked Session": {
+			setHeaders: bearer(map[string]interface{}{"user_id": 3, "sid": 4}),
+			buildStubs: func(svc *mock.MockSessionService) {
+				svc.EXPECT().Active(gomock.Any(), 3, 4).Times(1).Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), httpErrors.ErrSessionRevoked.Error())
+			},
+		},
+		"Check Failed": {
+			setHeaders: bearer(map[string]interface{}{"user_id": 3, "sid": 4}),
+			buildStubs: func(svc *mock.MockSessionService) {
+				svc.EXPECT().Active(gomock.Any(), 3, 4).Times(1).Return(false, httpErrors.InternalServerError)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		"Without Token": {
+			setHeaders: func(t *testing.T, req *http.Request) {},
+			buildStubs: func(svc *mock.MockSessionService) {
+				svc.EXPECT().Active(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		"Invalid Token": {
+			setHeaders: func(t *testing.T, req *http.Request) {
+				req.Header.Set("Authorization", "Bearer not-a-token")
+			},
+			buildStubs: func(svc *mock.MockSessionService) {
+				svc.EXPECT().Active(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockSessionService(ctrl)
+			tc.buildStubs(svc)
+
+			logger, _ := zap.NewProduction()
+			m := NewActiveSession(logger.Sugar(), svc, render.New(), tokenAuth)
+			router := chi.NewRouter()
+			router.Use(m.Check)
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, err)
+			tc.setHeaders(t, req)
+
+			router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
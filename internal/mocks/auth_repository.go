@@ -55,6 +55,21 @@ func (mr *MockAuthRepositoryMockRecorder) FindByCredentials(ctx, data any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCredentials", reflect.TypeOf((*MockAuthRepository)(nil).FindByCredentials), ctx, data)
 }
 
+// FindByID mocks base method.
+func (m *MockAuthRepository) FindByID(ctx context.Context, uid int) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, uid)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockAuthRepositoryMockRecorder) FindByID(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockAuthRepository)(nil).FindByID), ctx, uid)
+}
+
 // Register mocks base method.
 func (m *MockAuthRepository) Register(ctx context.Context, registerReq *domain.RegisterRequest) error {
 	m.ctrl.T.Helper()
@@ -68,3 +83,17 @@ func (mr *MockAuthRepositoryMockRecorder) Register(ctx, registerReq any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthRepository)(nil).Register), ctx, registerReq)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockAuthRepository) UpdatePassword(ctx context.Context, uid, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, uid, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockAuthRepositoryMockRecorder) UpdatePassword(ctx, uid, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockAuthRepository)(nil).UpdatePassword), ctx, uid, hash)
+}
@@ -40,6 +40,49 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthService) ChangePassword(ctx context.Context, uid int, data *domain.ChangePasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, uid, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceMockRecorder) ChangePassword(ctx, uid, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthService)(nil).ChangePassword), ctx, uid, data)
+}
+
+// CompleteTwoFactor mocks base method.
+func (m *MockAuthService) CompleteTwoFactor(ctx context.Context, data *domain.TwoFactorLoginRequest) (*domain.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactor", ctx, data)
+	ret0, _ := ret[0].(*domain.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTwoFactor indicates an expected call of CompleteTwoFactor.
+func (mr *MockAuthServiceMockRecorder) CompleteTwoFactor(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactor", reflect.TypeOf((*MockAuthService)(nil).CompleteTwoFactor), ctx, data)
+}
+
+// CreateAdmin mocks base method.
+func (m *MockAuthService) CreateAdmin(ctx context.Context, registerReq *domain.RegisterRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdmin", ctx, registerReq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAdmin indicates an expected call of CreateAdmin.
+func (mr *MockAuthServiceMockRecorder) CreateAdmin(ctx, registerReq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdmin", reflect.TypeOf((*MockAuthService)(nil).CreateAdmin), ctx, registerReq)
+}
+
 // FindByCredentials mocks base method.
 func (m *MockAuthService) FindByCredentials(ctx context.Context, data *domain.AuthRequest) (*domain.AuthResponse, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +98,21 @@ func (mr *MockAuthServiceMockRecorder) FindByCredentials(ctx, data any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCredentials", reflect.TypeOf((*MockAuthService)(nil).FindByCredentials), ctx, data)
 }
 
+// Refresh mocks base method.
+func (m *MockAuthService) Refresh(ctx context.Context, data *domain.RefreshRequest) (*domain.AuthResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, data)
+	ret0, _ := ret[0].(*domain.AuthResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceMockRecorder) Refresh(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthService)(nil).Refresh), ctx, data)
+}
+
 // Register mocks base method.
 func (m *MockAuthService) Register(ctx context.Context, registerReq *domain.RegisterRequest) error {
 	m.ctrl.T.Helper()
@@ -68,3 +126,17 @@ func (mr *MockAuthServiceMockRecorder) Register(ctx, registerReq any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, registerReq)
 }
+
+// Unlock mocks base method.
+func (m *MockAuthService) Unlock(ctx context.Context, token, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, token, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockAuthServiceMockRecorder) Unlock(ctx, token, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockAuthService)(nil).Unlock), ctx, token, ip)
+}
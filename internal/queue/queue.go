@@ -0,0 +1,313 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/adapters/pubsub/psnats"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	"kiramishima/m-backend/internal/scheduler"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTaskError characters of the error kept with a task
+const maxTaskError = 255
+
+var _ svcport.TaskQueue = (*Queue)(nil)
+
+// Handler runs a task, the result is stored with the task as JSON. An error
+// retries the task after the backoff until its attempts are spent, unless it
+// is Permanent. The context ends with the visibility timeout.
+type Handler func(ctx context.Context, task *domain.Task) (any, error)
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent fails the task right away, retrying it wouldn't help
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Queue struct, runs the queued tasks on a pool of workers. Every instance
+// runs workers, the repository hands each task to one of them.
+type Queue struct {
+	logger         *zap.SugaredLogger
+	tasks          repport.TaskRepository
+	workers        int
+	visibility     time.Duration
+	maxAttempts    int
+	backoff        time.Duration
+	poll           time.Duration
+	now            func() time.Time
+	contextTimeOut time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	// wake tells an idle worker a task was queued on this instance
+	wake chan struct{}
+	// stopClaims ends the claims, stopRuns cancels the tasks running
+	stopClaims context.CancelFunc
+	stopRuns   context.CancelFunc
+	runs       context.Context
+	wg         sync.WaitGroup
+}
+
+// New creates a queue without handlers, Start launches the workers
+func New(logger *zap.SugaredLogger, tasks repport.TaskRepository, cfg domain.Tasks, timeout time.Duration) *Queue {
+	return &Queue{
+		logger:         logger,
+		tasks:          tasks,
+		workers:        cfg.TaskWorkers,
+		visibility:     time.Duration(cfg.TaskVisibilityTimeout) * time.Second,
+		maxAttempts:    cfg.TaskMaxAttempts,
+		backoff:        time.Duration(cfg.TaskBackoff) * time.Second,
+		poll:           cfg.TaskPollInterval,
+		now:            time.Now,
+		contextTimeOut: timeout,
+		handlers:       make(map[string]Handler),
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Handle sets the handler of a task type
+func (q *Queue) Handle(taskType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[taskType] = handler
+}
+
+// Enqueue queues a task of the user with the JSON of payload
+func (q *Queue) Enqueue(ctx context.Context, uid int, taskType string, payload any) (*domain.Task, error) {
+	if q.handler(taskType) == nil {
+		return nil, fmt.Errorf("no handler for task type %q", taskType)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the payload of %s: %w", taskType, err)
+	}
+
+	now := q.now().UTC().Truncate(time.Second)
+	task := &domain.Task{
+		ID:          uuid.NewString(),
+		Type:        taskType,
+		UserID:      uid,
+		Payload:     data,
+		Status:      domain.TaskQueued,
+		MaxAttempts: q.maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	if err := q.tasks.Create(ctx, task); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return task, nil
+}
+
+// Get returns a task
+func (q *Queue) Get(ctx context.Context, id string) (*domain.Task, error) {
+	return q.tasks.Get(ctx, id)
+}
+
+// Start launches the workers
+func (q *Queue) Start(c context.Context) error {
+	claims, stopClaims := context.WithCancel(context.Background())
+	runs, stopRuns := context.WithCancel(context.Background())
+	q.stopClaims, q.stopRuns, q.runs = stopClaims, stopRuns, runs
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(claims)
+	}
+	return nil
+}
+
+// Stop stops claiming tasks and waits for the running ones. When c ends first
+// they are cancelled and retried later.
+func (q *Queue) Stop(c context.Context) error {
+	if q.stopClaims == nil {
+		return nil
+	}
+	q.stopClaims()
+	defer q.stopRuns()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-c.Done():
+		return c.Err()
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		task, err := q.tasks.Claim(ctx, q.now().UTC(), q.visibility)
+		if err == nil {
+			q.execute(task)
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !errors.Is(err, httpErrors.ErrTaskNotFound) {
+			q.logger.Errorw("failed to claim a task", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(q.poll):
+		}
+	}
+}
+
+// execute runs a claimed task and stores its result, or queues it again
+func (q *Queue) execute(task *domain.Task) {
+	var result any
+	var err error
+	handler := q.handler(task.Type)
+	switch {
+	case task.Attempts > task.MaxAttempts:
+		// every attempt ran past the visibility timeout, or its worker stopped
+		err = Permanent(errors.New("the task timed out on every attempt"))
+	case handler == nil:
+		err = Permanent(fmt.Errorf("no handler for task type %q", task.Type))
+	default:
+		ctx, cancel := context.WithTimeout(q.runs, q.visibility)
+		result, err = call(ctx, handler, task)
+		cancel()
+	}
+
+	if err == nil && result != nil {
+		task.Result, err = json.Marshal(result)
+		if err != nil {
+			err = Permanent(fmt.Errorf("failed to encode the result: %w", err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.contextTimeOut)
+	defer cancel()
+	now := q.now().UTC().Truncate(time.Second)
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		task.Status = domain.TaskSucceeded
+		task.Error = ""
+		task.FinishedAt = &now
+		err = q.tasks.Finish(ctx, task)
+	case errors.As(err, &permanent) || task.Attempts >= task.MaxAttempts:
+		q.logger.Errorw("task failed", "task", task.ID, "type", task.Type, "attempts", task.Attempts, "error", err)
+		task.Status = domain.TaskFailed
+		task.Error = truncate(err.Error(), maxTaskError)
+		task.FinishedAt = &now
+		err = q.tasks.Finish(ctx, task)
+	default:
+		q.logger.Warnw("task failed, retrying", "task", task.ID, "type", task.Type, "attempts", task.Attempts, "error", err)
+		task.Status = domain.TaskQueued
+		task.Error = truncate(err.Error(), maxTaskError)
+		task.RunAt = now.Add(q.delay(task.Attempts))
+		err = q.tasks.Retry(ctx, task)
+	}
+	switch {
+	case errors.Is(err, httpErrors.ErrTaskLeaseLost):
+		// another worker claimed the task after the visibility timeout, its
+		// outcome is the one stored
+		q.logger.Warnw("task lease lost, dropping the outcome", "task", task.ID, "type", task.Type, "attempts", task.Attempts)
+	case err != nil:
+		// the task is claimed again once its visibility timeout passes
+		q.logger.Errorw("failed to store the task", "task", task.ID, "error", err)
+	}
+}
+
+// delay before the next attempt, the backoff doubled after each attempt
+func (q *Queue) delay(attempts int) time.Duration {
+	return q.backoff << min(attempts-1, 10)
+}
+
+func (q *Queue) handler(taskType string) Handler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[taskType]
+}
+
+// call runs the handler, a panic fails the attempt instead of the worker
+func call(ctx context.Context, handler Handler, task *domain.Task) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, task)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// purgeTasks the job that deletes the finished tasks past their retention
+func purgeTasks(logger *zap.SugaredLogger, tasks repport.TaskRepository, retention time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "tasks.purge",
+		Schedule: "@hourly",
+		Run: func(ctx context.Context) error {
+			n, err := tasks.Purge(ctx, time.Now().UTC().Add(-retention))
+			if err != nil {
+				return err
+			}
+			logger.Infof("purged %d tasks", n)
+			return nil
+		},
+	}
+}
+
+// Module the task queue, TASK_QUEUE_DRIVER selects where the tasks are stored
+var Module = fx.Module("queue",
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger, db *repository.TaskRepository, ps *psnats.NATSPubSub, jobs *scheduler.Scheduler) (*Queue, error) {
+		var tasks repport.TaskRepository = db
+		if cfg.TaskQueueDriver == "nats" {
+			js, err := psnats.NewTaskRepository(ps, time.Duration(cfg.TaskVisibilityTimeout)*time.Second, time.Duration(cfg.TaskRetention)*time.Hour, cfg.TaskPollInterval)
+			if err != nil {
+				return nil, err
+			}
+			tasks = js
+		}
+
+		q := New(logger, tasks, cfg.Tasks, time.Duration(cfg.ContextTimeout)*time.Second)
+		if err := jobs.Register(purgeTasks(logger, tasks, time.Duration(cfg.TaskRetention)*time.Hour)); err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStart: q.Start,
+			OnStop:  q.Stop,
+		})
+		return q, nil
+	}),
+)
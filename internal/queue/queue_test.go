@@ -0,0 +1,176 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+func newTestQueue(t *testing.T) (*Queue, *mock.MockTaskRepository) {
+	logger, _ := zap.NewProduction()
+	mockCtrl := gomock.NewController(t)
+	tasks := mock.NewMockTaskRepository(mockCtrl)
+
+	q := New(logger.Sugar(), tasks, domain.Tasks{TaskWorkers: 1, TaskVisibilityTimeout: 60, TaskMaxAttempts: 3, TaskBackoff: 10, TaskPollInterval: 10 * time.Millisecond}, 2*time.Second)
+	q.now = func() time.Time { return testNow }
+	q.runs = context.Background()
+	return q, tasks
+}
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle(domain.TaskAccountExport, func(ctx context.Context, task *domain.Task) (any, error) { return nil, nil })
+		tasks.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		task, err := q.Enqueue(ctx, 2, domain.TaskAccountExport, domain.AccountExportPayload{ExportID: 4})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, task.ID)
+		assert.Equal(t, 2, task.UserID)
+		assert.Equal(t, domain.TaskQueued, task.Status)
+		assert.Equal(t, 3, task.MaxAttempts)
+		assert.Equal(t, testNow, task.RunAt)
+		assert.JSONEq(t, `{"export_id":4}`, string(task.Payload))
+		assert.Len(t, q.wake, 1)
+	})
+
+	t.Run("Without Handler", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		tasks.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := q.Enqueue(ctx, 2, "statement.generate", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestExecute(t *testing.T) {
+	finished := testNow
+
+	t.Run("Succeeded", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			return map[string]int{"rows": 3}, nil
+		})
+		tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			assert.Equal(t, domain.TaskSucceeded, task.Status)
+			assert.JSONEq(t, `{"rows":3}`, string(task.Result))
+			assert.Equal(t, &finished, task.FinishedAt)
+			return nil
+		})
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 1, MaxAttempts: 3})
+	})
+
+	t.Run("Retried with backoff", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			return nil, errors.New("storage is down")
+		})
+		tasks.EXPECT().Retry(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			assert.Equal(t, domain.TaskQueued, task.Status)
+			assert.Equal(t, "storage is down", task.Error)
+			assert.Equal(t, testNow.Add(20*time.Second), task.RunAt)
+			return nil
+		})
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 2, MaxAttempts: 3})
+	})
+
+	t.Run("Failed on the last attempt", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			panic("boom")
+		})
+		tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			assert.Equal(t, domain.TaskFailed, task.Status)
+			assert.Equal(t, "panic: boom", task.Error)
+			return nil
+		})
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 3, MaxAttempts: 3})
+	})
+
+	t.Run("Permanent error", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			return nil, Permanent(errors.New("invalid file"))
+		})
+		tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			assert.Equal(t, domain.TaskFailed, task.Status)
+			assert.Equal(t, "invalid file", task.Error)
+			return nil
+		})
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 1, MaxAttempts: 3})
+	})
+
+	t.Run("Attempts spent by timeouts", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			t.Fatal("the task ran past its attempts")
+			return nil, nil
+		})
+		tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			assert.Equal(t, domain.TaskFailed, task.Status)
+			return nil
+		})
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 4, MaxAttempts: 3})
+	})
+
+	t.Run("Lease lost", func(t *testing.T) {
+		q, tasks := newTestQueue(t)
+		core, logs := observer.New(zap.WarnLevel)
+		q.logger = zap.New(core).Sugar()
+		q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+			return nil, nil
+		})
+		tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).Return(httpErrors.ErrTaskLeaseLost)
+		tasks.EXPECT().Retry(gomock.Any(), gomock.Any()).Times(0)
+
+		q.execute(&domain.Task{ID: "task-1", Type: "import", Status: domain.TaskRunning, Attempts: 1, MaxAttempts: 3})
+		assert.Equal(t, 1, logs.FilterMessage("task lease lost, dropping the outcome").Len())
+		assert.Zero(t, logs.FilterMessage("failed to store the task").Len())
+	})
+}
+
+func TestWorkers(t *testing.T) {
+	q, tasks := newTestQueue(t)
+	done := make(chan struct{})
+	q.Handle("import", func(ctx context.Context, task *domain.Task) (any, error) {
+		close(done)
+		return nil, nil
+	})
+	gomock.InOrder(
+		tasks.EXPECT().Claim(gomock.Any(), testNow, time.Minute).Return(nil, httpErrors.ErrTaskNotFound),
+		tasks.EXPECT().Claim(gomock.Any(), testNow, time.Minute).Return(&domain.Task{ID: "task-1", Type: "import", Attempts: 1, MaxAttempts: 3}, nil),
+	)
+	tasks.EXPECT().Finish(gomock.Any(), gomock.Any()).Return(nil)
+	tasks.EXPECT().Claim(gomock.Any(), testNow, time.Minute).DoAndReturn(func(ctx context.Context, _ time.Time, _ time.Duration) (*domain.Task, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}).AnyTimes()
+
+	assert.NoError(t, q.Start(context.Background()))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task never ran")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, q.Stop(ctx))
+}
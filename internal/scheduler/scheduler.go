@@ -0,0 +1,482 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/adapters/database/postgresql/repository"
+	"kiramishima/m-backend/internal/core/domain"
+	repport "kiramishima/m-backend/internal/core/ports/repository"
+	svcport "kiramishima/m-backend/internal/core/ports/services"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRunError characters of the error kept with a run
+const maxRunError = 255
+
+// errStopped a run was asked for while the scheduler stops
+var errStopped = errors.New("the scheduler is stopped")
+
+var _ svcport.Scheduler = (*Scheduler)(nil)
+
+// Job a task run on its schedule by one replica at a time
+type Job struct {
+	// Name identifies the job in the locks, the run history and the admin endpoints
+	Name string
+	// Schedule cron expression of 5 fields in UTC, or a descriptor as
+	// @hourly or @every 10m
+	Schedule string
+	// Timeout cancels a run, SCHEDULER_JOB_TIMEOUT when zero
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type entry struct {
+	job Job
+	id  cron.EntryID
+}
+
+// Scheduler struct. Every replica schedules every job; the one that claims
+// the tick in Redis runs it, holding the lock of the job and renewing its
+// lease until it ends. The claim of a tick expires instead of being released,
+// so a replica firing it late doesn't run it again. A replica that loses the
+// lease cancels the run.
+type Scheduler struct {
+	logger         *zap.SugaredLogger
+	locks          repport.JobLockRepository
+	runs           repport.JobRepository
+	cron           *cron.Cron
+	instance       string
+	lockTTL        time.Duration
+	timeout        time.Duration
+	now            func() time.Time
+	contextTimeOut time.Duration
+
+	mu      sync.RWMutex
+	jobs    map[string]*entry
+	stopped bool
+	// manual runs, the scheduled ones are tracked by cron
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a scheduler without jobs
+func New(logger *zap.SugaredLogger, locks repport.JobLockRepository, runs repport.JobRepository, cfg domain.Scheduler, timeout time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger:         logger,
+		locks:          locks,
+		runs:           runs,
+		cron:           cron.New(cron.WithLocation(time.UTC)),
+		instance:       instanceName(),
+		lockTTL:        time.Duration(cfg.SchedulerLockTTL) * time.Second,
+		timeout:        time.Duration(cfg.SchedulerJobTimeout) * time.Second,
+		now:            time.Now,
+		contextTimeOut: timeout,
+		jobs:           make(map[string]*entry),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Register adds a job, the names are unique
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("a job needs a name and a run function")
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = s.timeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule of job %q: %w", job.Name, err)
+	}
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		schedule = everySchedule{delay: every.Delay}
+	}
+	name := job.Name
+	id := s.cron.Schedule(schedule, cron.FuncJob(func() { s.runScheduled(name, s.tick(name)) }))
+	s.jobs[job.Name] = &entry{job: job, id: id}
+
+	return nil
+}
+
+// Start fails the runs left running by stopped replicas and starts the schedules
+func (s *Scheduler) Start(c context.Context) error {
+	s.mu.RLock()
+	longest := s.timeout
+	for _, e := range s.jobs {
+		if e.job.Timeout > longest {
+			longest = e.job.Timeout
+		}
+	}
+	count := len(s.jobs)
+	s.mu.RUnlock()
+
+	// no replica runs a job longer than its timeout plus a lease
+	n, err := s.runs.FailStaleRuns(c, s.now().UTC().Add(-longest-s.lockTTL))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Warnf("%d job runs were interrupted and marked as failed", n)
+	}
+
+	s.cron.Start()
+	s.logger.Infow("scheduler started", "jobs", count, "instance", s.instance)
+	return nil
+}
+
+// Stop stops the schedules and waits for the runs in progress, they are
+// cancelled when c is done first
+func (s *Scheduler) Stop(c context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	scheduled := s.cron.Stop()
+	done := make(chan struct{})
+	go func() {
+		<-scheduled.Done()
+		s.wg.Wait()
+		close(done)
+	}()
+
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-c.Done():
+		return c.Err()
+	}
+}
+
+// Jobs returns the registered jobs with their state and last run
+func (s *Scheduler) Jobs(ctx context.Context) ([]*domain.ScheduledJob, error) {
+	latest, err := s.runs.ListLatestRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paused, err := s.runs.ListPaused(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastRuns := make(map[string]*domain.JobRun, len(latest))
+	for _, run := range latest {
+		lastRuns[run.Job] = run
+	}
+	pausedJobs := make(map[string]bool, len(paused))
+	for _, name := range paused {
+		pausedJobs[name] = true
+	}
+
+	s.mu.RLock()
+	list := make([]*domain.ScheduledJob, 0, len(s.jobs))
+	for name, e := range s.jobs {
+		job := &domain.ScheduledJob{
+			Name:     name,
+			Schedule: e.job.Schedule,
+			Paused:   pausedJobs[name],
+			LastRun:  lastRuns[name],
+		}
+		if next := s.cron.Entry(e.id).Next; !next.IsZero() && !job.Paused {
+			job.NextRun = &next
+		}
+		list = append(list, job)
+	}
+	s.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list, nil
+}
+
+// Runs returns the latest runs of a job
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]*domain.JobRun, error) {
+	if _, err := s.lookup(name); err != nil {
+		return nil, err
+	}
+	return s.runs.ListRuns(ctx, name, limit)
+}
+
+// Trigger runs the job now on this replica, in the background. It fails
+// with ErrJobRunning while a replica runs it.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	e, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	token, ok, err := s.acquire(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httpErrors.ErrJobRunning
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		s.release(name, token)
+		return errStopped
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		s.execute(e.job, token, domain.JobSourceManual)
+	}()
+	return nil
+}
+
+// Pause stops the scheduled runs of the job on every replica
+func (s *Scheduler) Pause(ctx context.Context, name string) error {
+	if _, err := s.lookup(name); err != nil {
+		return err
+	}
+	return s.runs.SetPaused(ctx, name, true)
+}
+
+// Resume schedules the job again
+func (s *Scheduler) Resume(ctx context.Context, name string) error {
+	if _, err := s.lookup(name); err != nil {
+		return err
+	}
+	return s.runs.SetPaused(ctx, name, false)
+}
+
+// runScheduled runs the tick of a job unless it is paused, another replica
+// claimed the tick or the previous run is still going
+func (s *Scheduler) runScheduled(name string, tick time.Time) {
+	e, err := s.lookup(name)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.contextTimeOut)
+	paused, err := s.runs.IsPaused(ctx, name)
+	if err != nil {
+		cancel()
+		s.logger.Errorw("failed to check if the job is paused", "job", name, "error", err)
+		return
+	}
+	if paused {
+		cancel()
+		return
+	}
+	// the claim outlives the run, the replicas that fire the tick after it
+	// ended find it taken
+	claimed, err := s.locks.ClaimTick(ctx, name, tick, e.job.Timeout+s.lockTTL)
+	if err != nil {
+		cancel()
+		s.logger.Errorw("failed to claim the scheduled run", "job", name, "tick", tick, "error", err)
+		return
+	}
+	if !claimed {
+		cancel()
+		return
+	}
+	token, ok, err := s.acquire(ctx, name)
+	cancel()
+	if err != nil {
+		s.logger.Errorw("failed to lock the job", "job", name, "error", err)
+		return
+	}
+	if !ok {
+		s.logger.Warnw("the job is still running, skipping the scheduled run", "job", name, "tick", tick)
+		return
+	}
+
+	s.execute(e.job, token, domain.JobSourceSchedule)
+}
+
+// execute runs a job holding its lock, stores the run and releases the lock
+func (s *Scheduler) execute(job Job, token string, source string) {
+	ctx, cancel := context.WithTimeout(s.ctx, job.Timeout)
+	defer cancel()
+
+	renewing := make(chan struct{})
+	go func() {
+		defer close(renewing)
+		s.renew(ctx, cancel, job.Name, token)
+	}()
+
+	run := &domain.JobRun{
+		Job:       job.Name,
+		Instance:  s.instance,
+		Source:    source,
+		Status:    domain.JobRunRunning,
+		StartedAt: s.now().UTC().Truncate(time.Second),
+	}
+	if err := s.runs.CreateRun(ctx, run); err != nil {
+		s.logger.Errorw("failed to store the job run", "job", job.Name, "error", err)
+	}
+
+	err := call(ctx, job)
+	finished := s.now().UTC().Truncate(time.Second)
+	run.FinishedAt = &finished
+	run.Status = domain.JobRunSucceeded
+	if err != nil {
+		run.Status = domain.JobRunFailed
+		run.Error = truncate(err.Error(), maxRunError)
+		s.logger.Errorw("job failed", "job", job.Name, "source", source, "error", err)
+	} else {
+		s.logger.Infow("job succeeded", "job", job.Name, "source", source, "duration", finished.Sub(run.StartedAt).String())
+	}
+	cancel()
+	<-renewing
+
+	if run.ID > 0 {
+		store, done := context.WithTimeout(context.Background(), s.contextTimeOut)
+		if err := s.runs.FinishRun(store, run); err != nil {
+			s.logger.Errorw("failed to store the job result", "job", job.Name, "error", err)
+		}
+		done()
+	}
+	s.release(job.Name, token)
+}
+
+// renew extends the lease of the job until ctx is done. A lost lease cancels
+// the run, another replica may be running the job.
+func (s *Scheduler) renew(ctx context.Context, cancel context.CancelFunc, name string, token string) {
+	ticker := time.NewTicker(s.lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := s.locks.Renew(ctx, name, token, s.lockTTL)
+			if err != nil {
+				// the lease is still valid for a while, try again on the next tick
+				s.logger.Warnw("failed to renew the job lock", "job", name, "error", err)
+				continue
+			}
+			if !ok {
+				s.logger.Errorw("lost the job lock, cancelling the run", "job", name)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// tick the scheduled time of the run cron just started for the job
+func (s *Scheduler) tick(name string) time.Time {
+	e, err := s.lookup(name)
+	if err != nil {
+		return time.Time{}
+	}
+	return s.cron.Entry(e.id).Prev
+}
+
+func (s *Scheduler) acquire(ctx context.Context, name string) (string, bool, error) {
+	token := uuid.NewString()
+	ok, err := s.locks.Acquire(ctx, name, token, s.lockTTL)
+	return token, ok, err
+}
+
+func (s *Scheduler) release(name string, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.contextTimeOut)
+	defer cancel()
+	if err := s.locks.Release(ctx, name, token); err != nil {
+		s.logger.Errorw("failed to release the job lock", "job", name, "error", err)
+	}
+}
+
+func (s *Scheduler) lookup(name string) (*entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.jobs[name]
+	if !ok {
+		return nil, httpErrors.ErrJobNotFound
+	}
+	return e, nil
+}
+
+// everySchedule an @every schedule on the multiples of the delay, so the
+// replicas fire the same ticks whenever they started
+type everySchedule struct {
+	delay time.Duration
+}
+
+// Next the first multiple of the delay after t
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(e.delay).Add(e.delay)
+}
+
+// call runs the job, a panic fails the run instead of the replica
+func call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// instanceName identifies the replica in the run history
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// purgeRuns the built-in job that deletes the run history past its retention
+func purgeRuns(logger *zap.SugaredLogger, runs repport.JobRepository, retention int) Job {
+	return Job{
+		Name:     "job_runs.purge",
+		Schedule: "@daily",
+		Run: func(ctx context.Context) error {
+			n, err := runs.PurgeRuns(ctx, time.Now().UTC().AddDate(0, 0, -retention))
+			if err != nil {
+				return err
+			}
+			logger.Infof("purged %d job runs", n)
+			return nil
+		},
+	}
+}
+
+var Module = fx.Module("scheduler",
+	fx.Provide(func(lc fx.Lifecycle, cfg *domain.Configuration, logger *zap.SugaredLogger, locks *redis.JobLockRepository, runs *repository.JobRepository) (*Scheduler, error) {
+		s := New(logger, locks, runs, cfg.Scheduler, time.Duration(cfg.ContextTimeout)*time.Second)
+		if err := s.Register(purgeRuns(logger, runs, cfg.JobRunsRetention)); err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStart: s.Start,
+			OnStop:  s.Stop,
+		})
+		return s, nil
+	}),
+)
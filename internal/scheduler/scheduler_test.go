@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"kiramishima/m-backend/internal/adapters/cache/redis"
+	"kiramishima/m-backend/internal/core/domain"
+	mock "kiramishima/m-backend/internal/mocks"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testTick = time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *mock.MockJobLockRepository, *mock.MockJobRepository) {
+	logger, _ := zap.NewProduction()
+	mockCtrl := gomock.NewController(t)
+	locks := mock.NewMockJobLockRepository(mockCtrl)
+	runs := mock.NewMockJobRepository(mockCtrl)
+
+	s := New(logger.Sugar(), locks, runs, domain.Scheduler{SchedulerLockTTL: 30, SchedulerJobTimeout: 60}, 2*time.Second)
+	s.instance = "api-1"
+	return s, locks, runs
+}
+
+// expectRun expects the run of the job to be stored with the status
+func expectRun(runs *mock.MockJobRepository, job string, source string, status string, message string) {
+	runs.EXPECT().CreateRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *domain.JobRun) error {
+		if run.Job != job || run.Source != source || run.Status != domain.JobRunRunning || run.Instance != "api-1" {
+			return errors.New("unexpected run")
+		}
+		run.ID = 7
+		return nil
+	})
+	runs.EXPECT().FinishRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *domain.JobRun) error {
+		if run.ID != 7 || run.Status != status || run.Error != message || run.FinishedAt == nil {
+			return errors.New("unexpected result")
+		}
+		return nil
+	})
+}
+
+func TestRegister(t *testing.T) {
+	s, _, _ := newTestScheduler(t)
+	run := func(ctx context.Context) error { return nil }
+
+	assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "*/5 * * * *", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "coupons.pay", Schedule: "every day", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "coupons.pay", Schedule: "@daily"}))
+	assert.Equal(t, time.Minute, s.jobs["cache.warm"].job.Timeout)
+}
+
+func TestTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		ran := false
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			ran = true
+			return nil
+		}}))
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), 30*time.Second).Return(true, nil)
+		expectRun(runs, "cache.warm", domain.JobSourceManual, domain.JobRunSucceeded, "")
+		locks.EXPECT().Release(gomock.Any(), "cache.warm", gomock.Any()).Return(nil)
+
+		assert.NoError(t, s.Trigger(ctx, "cache.warm"))
+		s.wg.Wait()
+		assert.True(t, ran)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			return errors.New("redis is down")
+		}}))
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(true, nil)
+		expectRun(runs, "cache.warm", domain.JobSourceManual, domain.JobRunFailed, "redis is down")
+		locks.EXPECT().Release(gomock.Any(), "cache.warm", gomock.Any()).Return(nil)
+
+		assert.NoError(t, s.Trigger(ctx, "cache.warm"))
+		s.wg.Wait()
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			panic("boom")
+		}}))
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(true, nil)
+		expectRun(runs, "cache.warm", domain.JobSourceManual, domain.JobRunFailed, "panic: boom")
+		locks.EXPECT().Release(gomock.Any(), "cache.warm", gomock.Any()).Return(nil)
+
+		assert.NoError(t, s.Trigger(ctx, "cache.warm"))
+		s.wg.Wait()
+	})
+
+	t.Run("Already Running", func(t *testing.T) {
+		s, locks, _ := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error { return nil }}))
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(false, nil)
+
+		err := s.Trigger(ctx, "cache.warm")
+		assert.ErrorIs(t, err, httpErrors.ErrJobRunning)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		s, _, _ := newTestScheduler(t)
+
+		err := s.Trigger(ctx, "cache.warm")
+		assert.ErrorIs(t, err, httpErrors.ErrJobNotFound)
+	})
+
+	t.Run("Stopped", func(t *testing.T) {
+		s, locks, _ := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error { return nil }}))
+		assert.NoError(t, s.Stop(ctx))
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(true, nil)
+		locks.EXPECT().Release(gomock.Any(), "cache.warm", gomock.Any()).Return(nil)
+
+		err := s.Trigger(ctx, "cache.warm")
+		assert.ErrorIs(t, err, errStopped)
+	})
+}
+
+func TestRunScheduled(t *testing.T) {
+	t.Run("Paused", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			t.Fatal("a paused job ran")
+			return nil
+		}}))
+		runs.EXPECT().IsPaused(gomock.Any(), "cache.warm").Return(true, nil)
+		locks.EXPECT().ClaimTick(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		locks.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		s.runScheduled("cache.warm", testTick)
+	})
+
+	t.Run("Claimed by another replica", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			t.Fatal("the job ran on two replicas")
+			return nil
+		}}))
+		runs.EXPECT().IsPaused(gomock.Any(), "cache.warm").Return(false, nil)
+		locks.EXPECT().ClaimTick(gomock.Any(), "cache.warm", testTick, 90*time.Second).Return(false, nil)
+		locks.EXPECT().Acquire(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		s.runScheduled("cache.warm", testTick)
+	})
+
+	t.Run("Previous run still going", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			t.Fatal("the job ran twice at once")
+			return nil
+		}}))
+		runs.EXPECT().IsPaused(gomock.Any(), "cache.warm").Return(false, nil)
+		locks.EXPECT().ClaimTick(gomock.Any(), "cache.warm", testTick, gomock.Any()).Return(true, nil)
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(false, nil)
+		runs.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Times(0)
+
+		s.runScheduled("cache.warm", testTick)
+	})
+
+	t.Run("Lost lease cancels the run", func(t *testing.T) {
+		s, locks, runs := newTestScheduler(t)
+		s.lockTTL = 30 * time.Millisecond
+		assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}))
+		runs.EXPECT().IsPaused(gomock.Any(), "cache.warm").Return(false, nil)
+		locks.EXPECT().ClaimTick(gomock.Any(), "cache.warm", testTick, gomock.Any()).Return(true, nil)
+		locks.EXPECT().Acquire(gomock.Any(), "cache.warm", gomock.Any(), gomock.Any()).Return(true, nil)
+		locks.EXPECT().Renew(gomock.Any(), "cache.warm", gomock.Any(), 30*time.Millisecond).Return(false, nil)
+		expectRun(runs, "cache.warm", domain.JobSourceSchedule, domain.JobRunFailed, context.Canceled.Error())
+		locks.EXPECT().Release(gomock.Any(), "cache.warm", gomock.Any()).Return(nil)
+
+		s.runScheduled("cache.warm", testTick)
+	})
+}
+
+func TestRunScheduledOnTwoReplicas(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	locks := redis.NewJobLockRepository(client)
+	logger, _ := zap.NewProduction()
+	mockCtrl := gomock.NewController(t)
+
+	var ran atomic.Int32
+	replicas := make([]*Scheduler, 2)
+	for i := range replicas {
+		runs := mock.NewMockJobRepository(mockCtrl)
+		runs.EXPECT().IsPaused(gomock.Any(), "cache.warm").Return(false, nil).AnyTimes()
+		runs.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		replicas[i] = New(logger.Sugar(), locks, runs, domain.Scheduler{SchedulerLockTTL: 30, SchedulerJobTimeout: 60}, 2*time.Second)
+		assert.NoError(t, replicas[i].Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: func(ctx context.Context) error {
+			ran.Add(1)
+			return nil
+		}}))
+	}
+
+	// the first replica is done and released the job lock before the second
+	// one fires the same tick
+	replicas[0].runScheduled("cache.warm", testTick)
+	time.Sleep(5 * time.Millisecond)
+	replicas[1].runScheduled("cache.warm", testTick)
+	assert.Equal(t, int32(1), ran.Load())
+	assert.False(t, srv.Exists("scheduler:lock:cache.warm"))
+
+	// the next tick runs again
+	replicas[1].runScheduled("cache.warm", testTick.Add(time.Hour))
+	assert.Equal(t, int32(2), ran.Load())
+}
+
+func TestEverySchedule(t *testing.T) {
+	schedule := everySchedule{delay: 10 * time.Minute}
+
+	// the replicas agree on the ticks whenever they started
+	assert.Equal(t, testTick.Add(10*time.Minute), schedule.Next(testTick))
+	assert.Equal(t, testTick.Add(10*time.Minute), schedule.Next(testTick.Add(3*time.Minute+250*time.Millisecond)))
+	assert.Equal(t, testTick.Add(20*time.Minute), schedule.Next(testTick.Add(10*time.Minute)))
+}
+
+func TestJobs(t *testing.T) {
+	s, _, runs := newTestScheduler(t)
+	run := func(ctx context.Context) error { return nil }
+	assert.NoError(t, s.Register(Job{Name: "job_runs.purge", Schedule: "@daily", Run: run}))
+	assert.NoError(t, s.Register(Job{Name: "cache.warm", Schedule: "@hourly", Run: run}))
+	last := &domain.JobRun{ID: 7, Job: "cache.warm", Status: domain.JobRunSucceeded}
+	runs.EXPECT().ListLatestRuns(gomock.Any()).Return([]*domain.JobRun{last}, nil)
+	runs.EXPECT().ListPaused(gomock.Any()).Return([]string{"job_runs.purge"}, nil)
+
+	list, err := s.Jobs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.ScheduledJob{
+		{Name: "cache.warm", Schedule: "@hourly", LastRun: last},
+		{Name: "job_runs.purge", Schedule: "@daily", Paused: true},
+	}, list)
+}
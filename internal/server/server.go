@@ -8,76 +8,121 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"kiramishima/m-backend/internal/core/domain"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	certFile          = "ssl/Server.crt"
-	keyFile           = "ssl/Server.pem"
 	maxHeaderBytes    = 1 << 20
-	ctxTimeout        = 10
 	ReadHeaderTimeout = 3 * time.Second
 )
 
+// Server holds the http.Server of the API. It is started and stopped by the fx
+// lifecycle, on stop the readiness probe fails first and then the open
+// connections are drained.
 type Server struct {
-	router chi.Router
+	server *http.Server
 	logger *zap.SugaredLogger
 	cfg    *domain.Configuration
+	// ready is reported by /readyz, it is false until the listener is open and
+	// again as soon as the shutdown starts
+	ready atomic.Bool
 }
 
 func NewServer(cfg *domain.Configuration, logger *zap.SugaredLogger, r *chi.Mux) *Server {
-	return &Server{
-		router: r,
+	s := &Server{
 		logger: logger,
 		cfg:    cfg,
 	}
-}
-
-func (s *Server) Run() error {
-	// Create a new http.Server with the specified read header timeout and handler
-	var addr = fmt.Sprintf("%s:%d", s.cfg.ServerAddress, s.cfg.Port)
-
-	server := &http.Server{
-		Addr:              addr,
+	s.server = &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.ServerAddress, cfg.Port),
 		ReadHeaderTimeout: ReadHeaderTimeout,
 		MaxHeaderBytes:    maxHeaderBytes,
-		Handler:           s.router,
+		Handler:           s.probes(r),
 	}
+	return s
+}
 
-	go func() {
-		s.logger.Infof("Server is listening on PORT: %d", s.cfg.Port)
+// Start opens the listener, so a port in use fails the start of the app, and
+// serves in the background
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	return s.Serve(ln)
+}
 
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			s.logger.Fatalf("Error starting Server: ", err)
+// Serve serves the API on ln in the background
+func (s *Server) Serve(ln net.Listener) error {
+	s.logger.Infof("Server is listening on %s", ln.Addr())
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Errorf("Error serving HTTP: %s", err)
 		}
 	}()
+	s.ready.Store(true)
 	return nil
-	// graceful shutdown
-	// return waitForShutdown(s.logger, server)
 }
 
-// waitForShutdown graceful shutdown
-func waitForShutdown(logger *zap.SugaredLogger, server *http.Server) error {
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
-	<-sig
+// Stop fails the readiness probe, waits HTTP_SERVER_SHUTDOWN_DELAY so the load
+// balancer stops sending new requests, and drains the connections for up to
+// HTTP_SERVER_SHUTDOWN_TIMEOUT. The requests still running after that are cut.
+func (s *Server) Stop(ctx context.Context) error {
+	s.ready.Store(false)
+	s.logger.Info("Shutting down the server")
+	sleep(ctx, s.cfg.ShutdownDelay)
 
-	ctx, cancel := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Info("Server Exited Properly")
-		logger.Fatal("Failed gracefully")
-		logger.Fatal("failed to gracefully shut down server", err)
-		return err
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return fmt.Errorf("failed to drain the HTTP connections: %w", err)
 	}
+	s.logger.Info("Server exited properly")
 	return nil
 }
 
+// Ready reports whether the server takes new requests
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// probes answers /healthz and /readyz before the router, so the probes skip the
+// rate limits, CORS and the request log
+func (s *Server) probes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(http.StatusOK)
+		case "/readyz":
+			if !s.Ready() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// sleep waits d or until ctx ends
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
 // Module Server Module
 var Module = fx.Module("server",
 	fx.Provide(func(cfg *domain.Configuration, logger *zap.SugaredLogger, r *chi.Mux) *Server {
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"io"
+	"kiramishima/m-backend/internal/core/domain"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, cfg domain.HTTPServer, r *chi.Mux) (*Server, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	logger, _ := zap.NewProduction()
+	s := NewServer(&domain.Configuration{HTTPServer: cfg}, logger.Sugar(), r)
+	require.NoError(t, s.Serve(ln))
+	return s, "http://" + ln.Addr().String()
+}
+
+func status(t *testing.T, url string) int {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServerProbes(t *testing.T) {
+	s, url := newTestServer(t, domain.HTTPServer{ShutdownDelay: 200 * time.Millisecond, ShutdownTimeout: time.Second}, chi.NewRouter())
+
+	assert.Equal(t, http.StatusOK, status(t, url+"/healthz"))
+	assert.Equal(t, http.StatusOK, status(t, url+"/readyz"))
+	assert.Equal(t, http.StatusNotFound, status(t, url+"/v1/unknown"))
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+
+	// the readiness fails during the delay, the requests are still served
+	assert.Eventually(t, func() bool { return !s.Ready() }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, status(t, url+"/readyz"))
+	assert.Equal(t, http.StatusOK, status(t, url+"/healthz"))
+
+	assert.NoError(t, <-stopped)
+	_, err := http.Get(url + "/healthz")
+	assert.Error(t, err)
+}
+
+func TestServerStopDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	r := chi.NewRouter()
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+	s, url := newTestServer(t, domain.HTTPServer{ShutdownTimeout: time.Second}, r)
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	<-started
+
+	assert.NoError(t, s.Stop(context.Background()))
+	assert.Equal(t, "done", <-body)
+}
+
+func TestServerStopTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	r := chi.NewRouter()
+	r.Get("/stuck", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	s, url := newTestServer(t, domain.HTTPServer{ShutdownTimeout: 100 * time.Millisecond}, r)
+
+	failed := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+		failed <- err
+	}()
+	<-started
+
+	err := s.Stop(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Error(t, <-failed)
+}
+
+func TestServerStartPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	logger, _ := zap.NewProduction()
+	cfg := &domain.Configuration{HTTPServer: domain.HTTPServer{ServerAddress: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}}
+	s := NewServer(cfg, logger.Sugar(), chi.NewRouter())
+
+	assert.Error(t, s.Start(context.Background()))
+	assert.False(t, s.Ready())
+}
@@ -0,0 +1 @@
+ALTER TABLE users MODIFY password VARCHAR(255) NOT NULL CHECK(password != "" AND CHAR_LENGTH(password) >= 6);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE market_bonds
+    DROP COLUMN delisted_at,
+    MODIFY status ENUM('available', 'bought') NOT NULL DEFAULT 'available' CHECK ( status IN ('available', 'bought'));
+
+ALTER TABLE bonds DROP COLUMN frozen_at;
+
+ALTER TABLE users
+    DROP COLUMN suspended_reason,
+    DROP COLUMN suspended_at;
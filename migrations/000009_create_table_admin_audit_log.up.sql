@@ -0,0 +1,23 @@
+ALTER TABLE users
+    ADD COLUMN suspended_at TIMESTAMP NULL AFTER email_verified_at,
+    ADD COLUMN suspended_reason VARCHAR(255) NULL AFTER suspended_at;
+
+ALTER TABLE bonds
+    ADD COLUMN frozen_at TIMESTAMP NULL AFTER status;
+
+ALTER TABLE market_bonds
+    MODIFY status ENUM('available', 'bought', 'delisted') NOT NULL DEFAULT 'available' CHECK ( status IN ('available', 'bought', 'delisted')),
+    ADD COLUMN delisted_at TIMESTAMP NULL AFTER status;
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    admin_id BIGINT NOT NULL,
+    action VARCHAR(60) NOT NULL CHECK(action != ""),
+    target_type VARCHAR(40) NOT NULL,
+    target_id BIGINT NOT NULL,
+    details VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX IDX_AuditTarget (target_type, target_id),
+    CONSTRAINT FK_AuditAdmin FOREIGN KEY (admin_id) REFERENCES users(id)
+) ENGINE=INNODB;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id BIGINT NOT NULL PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL CHECK(secret != ""),
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    CONSTRAINT FK_UserTOTP FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX UQ_UserRecoveryCode (user_id, code_hash),
+    CONSTRAINT FK_UserRecoveryCodes FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS auth_events (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    event VARCHAR(60) NOT NULL CHECK(event != ""),
+    outcome VARCHAR(20) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX IDX_AuthEventUser (user_id, created_at),
+    INDEX IDX_AuthEventType (event, created_at)
+) ENGINE=INNODB;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL CHECK(name != ""),
+    prefix CHAR(12) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    allowed_ips VARCHAR(1024) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    last_used_ip VARCHAR(45) NOT NULL DEFAULT '',
+    usage_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL,
+    UNIQUE INDEX UQ_ApiKeyPrefix (prefix),
+    INDEX IDX_ApiKeyUser (user_id),
+    CONSTRAINT FK_ApiKeyUser FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    issuer VARCHAR(255) NOT NULL CHECK(issuer != ""),
+    subject VARCHAR(255) NOT NULL CHECK(subject != ""),
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX UQ_UserIdentity (issuer, subject),
+    INDEX IDX_UserIdentityUser (user_id),
+    CONSTRAINT FK_UserIdentityUser FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    previous_hash CHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    UNIQUE INDEX UQ_UserSessionToken (token_hash),
+    INDEX IDX_UserSessionPrevious (previous_hash),
+    INDEX IDX_UserSessionUser (user_id, revoked_at),
+    CONSTRAINT FK_UserSessionUser FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
@@ -0,0 +1 @@
+ALTER TABLE users_profile DROP COLUMN gender;
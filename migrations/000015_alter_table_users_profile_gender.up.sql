@@ -0,0 +1 @@
+ALTER TABLE users_profile ADD COLUMN gender VARCHAR(20) NOT NULL DEFAULT 'undisclosed' CHECK(gender IN ('female', 'male', 'non_binary', 'undisclosed')) AFTER photo;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS seller_ratings;
+ALTER TABLE users_profile DROP INDEX UQ_ProfileUsername;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- usernames repeated before the index keep the oldest profile, the others get
+-- their user id appended
+UPDATE users_profile p
+    INNER JOIN (
+        SELECT username, MIN(user_id) AS user_id FROM users_profile GROUP BY username HAVING COUNT(*) > 1
+    ) d ON d.username = p.username
+SET p.username = CONCAT(LEFT(p.username, 24 - CHAR_LENGTH(p.user_id)), '_', p.user_id), p.updated_at = NOW()
+WHERE p.user_id <> d.user_id;
+
+ALTER TABLE users_profile ADD UNIQUE INDEX UQ_ProfileUsername (username);
+
+CREATE TABLE IF NOT EXISTS seller_ratings (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    seller_id BIGINT NOT NULL,
+    buyer_id BIGINT NOT NULL,
+    score TINYINT NOT NULL CHECK(score >= 1 AND score <= 5),
+    comment VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX UQ_SellerRatingTransaction (transaction_id),
+    INDEX IDX_SellerRatingSeller (seller_id),
+    CONSTRAINT FK_SellerRatingTransaction FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    CONSTRAINT FK_SellerRatingSeller FOREIGN KEY (seller_id) REFERENCES users(id),
+    CONSTRAINT FK_SellerRatingBuyer FOREIGN KEY (buyer_id) REFERENCES users(id)
+) ENGINE=INNODB;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS data_exports (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    status ENUM('pending', 'running', 'ready', 'failed') NOT NULL DEFAULT 'pending' CHECK ( status IN ('pending', 'running', 'ready', 'failed')),
+    file_key VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL,
+    INDEX IDX_DataExportUser (user_id, created_at),
+    CONSTRAINT FK_DataExportUser FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
\ No newline at end of file
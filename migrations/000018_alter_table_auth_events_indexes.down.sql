@@ -0,0 +1 @@
+ALTER TABLE auth_events DROP INDEX IDX_AuthEventEmail, DROP INDEX IDX_AuthEventIP;
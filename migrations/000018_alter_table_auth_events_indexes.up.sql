@@ -0,0 +1 @@
+ALTER TABLE auth_events ADD INDEX IDX_AuthEventEmail (email, created_at), ADD INDEX IDX_AuthEventIP (ip, created_at);
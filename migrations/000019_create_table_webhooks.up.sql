@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    url VARCHAR(2048) NOT NULL CHECK(url != ""),
+    events VARCHAR(255) NOT NULL,
+    secret CHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX IDX_WebhookUser (user_id),
+    CONSTRAINT FK_WebhookUser FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=INNODB;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status ENUM('pending', 'succeeded', 'failed') NOT NULL DEFAULT 'pending' CHECK ( status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    status_code INT NULL,
+    error VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMP NULL,
+    delivered_at TIMESTAMP NULL,
+    UNIQUE INDEX UQ_WebhookDeliveryEvent (webhook_id, event_id),
+    INDEX IDX_WebhookDeliveryCreated (webhook_id, created_at),
+    CONSTRAINT FK_WebhookDeliveryWebhook FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+) ENGINE=INNODB;
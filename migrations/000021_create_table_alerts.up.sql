@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS watchlist (
+    user_id BIGINT NOT NULL,
+    bond_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, bond_id),
+    CONSTRAINT FK_WatchlistUser FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT FK_WatchlistBond FOREIGN KEY (bond_id) REFERENCES bonds(id)
+) ENGINE=INNODB;
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    bond_id BIGINT NOT NULL,
+    type ENUM('price_below', 'new_listing', 'available_above') NOT NULL CHECK ( type IN ('price_below', 'new_listing', 'available_above')),
+    threshold DECIMAL(13, 4) NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX IDX_AlertUser (user_id),
+    INDEX IDX_AlertBond (bond_id, active),
+    CONSTRAINT FK_AlertUser FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT FK_AlertBond FOREIGN KEY (bond_id) REFERENCES bonds(id)
+) ENGINE=INNODB;
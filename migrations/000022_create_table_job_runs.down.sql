@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'jobs:manage';
+
+DROP TABLE IF EXISTS scheduled_jobs;
+DROP TABLE IF EXISTS job_runs;
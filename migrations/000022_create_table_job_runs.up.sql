@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    job VARCHAR(60) NOT NULL,
+    instance VARCHAR(100) NOT NULL,
+    source ENUM('schedule', 'manual') NOT NULL CHECK ( source IN ('schedule', 'manual')),
+    status ENUM('running', 'succeeded', 'failed') NOT NULL DEFAULT 'running' CHECK ( status IN ('running', 'succeeded', 'failed')),
+    error VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP NULL,
+    INDEX IDX_JobRunsJob (job, id),
+    INDEX IDX_JobRunsStatus (status, started_at)
+) ENGINE=INNODB;
+
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name VARCHAR(60) NOT NULL PRIMARY KEY,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=INNODB;
+
+INSERT INTO permissions (name, description) VALUES
+    ('jobs:manage', 'List, run and pause the scheduled jobs');
+
+INSERT INTO role_permissions (role_id, permission_id)
+    SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'jobs:manage';
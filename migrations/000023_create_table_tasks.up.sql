@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    type VARCHAR(60) NOT NULL,
+    user_id INT NOT NULL DEFAULT 0,
+    payload TEXT NOT NULL,
+    status ENUM('queued', 'running', 'succeeded', 'failed') NOT NULL DEFAULT 'queued' CHECK ( status IN ('queued', 'running', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    result TEXT NULL,
+    error VARCHAR(255) NOT NULL DEFAULT '',
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    INDEX IDX_TasksQueued (status, run_at),
+    INDEX IDX_TasksLocked (status, locked_until),
+    INDEX IDX_TasksUser (user_id)
+) ENGINE=INNODB;
@@ -1,6 +1,9 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Entity Errors
 var (
@@ -11,4 +14,129 @@ var (
 	ErrLongPassword    = errors.New("password longer than 72 characters")
 	ErrInvalidEmail    = errors.New("invalid email address")
 	ErrUserNotFound    = errors.New("user not found")
+	ErrUserSuspended   = errors.New("the account is suspended")
+	ErrSelfModeration  = errors.New("admins can't moderate their own account")
+)
+
+// Password hash errors
+var (
+	ErrInvalidHash         = errors.New("the encoded hash is not in the correct format")
+	ErrIncompatibleVersion = errors.New("incompatible version of argon2")
+	ErrUnknownHashFormat   = errors.New("unknown password hash format")
+)
+
+// Two-factor authentication errors
+var (
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = errors.New("the two-factor code is not valid")
+	ErrInvalidChallengeToken   = errors.New("the challenge token is invalid or expired")
+)
+
+// Brute-force protection errors
+var (
+	ErrAccountLocked      = errors.New("the account is temporarily locked, check your email to unlock it")
+	ErrTooManyAttempts    = errors.New("too many sign in attempts, try again later")
+	ErrInvalidUnlockToken = errors.New("the unlock token is invalid or expired")
+)
+
+// RetryAfterError an error that can be retried once RetryAfter has passed
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// API key errors
+var (
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("the api key is invalid, revoked or expired")
+	ErrAPIKeyIPNotAllowed = errors.New("the api key can't be used from this IP")
+	ErrAPIKeyScopeDenied  = errors.New("the api key doesn't grant this scope")
+	ErrAPIKeyExpiryInPast = errors.New("the expiration date must be in the future")
+)
+
+// OpenID Connect
+var (
+	ErrOIDCDisabled         = errors.New("single sign-on is not configured")
+	ErrInvalidOIDCState     = errors.New("the login state is invalid or expired")
+	ErrOIDCExchange         = errors.New("the identity provider rejected the login")
+	ErrOIDCEmailNotVerified = errors.New("the email of the identity provider is not verified")
+	ErrOIDCUserNotFound     = errors.New("there is no account with the email of the identity provider")
+	ErrIdentityNotLinked    = errors.New("the identity is not linked to an account")
+)
+
+// Sessions
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("the refresh token is invalid or expired")
+	ErrSessionRevoked      = errors.New("the session was revoked")
+)
+
+// Uploads
+var (
+	ErrFileNotFound     = errors.New("file not found")
+	ErrInvalidFileKey   = errors.New("the file key is not valid")
+	ErrInvalidSignedURL = errors.New("the link is invalid or expired")
+	ErrAvatarTooLarge   = errors.New("the photo is too large")
+	ErrInvalidImage     = errors.New("the image is corrupt or too large to process")
+	ErrAvatarNotFound   = errors.New("the user has no profile photo")
+)
+
+// Sellers
+var (
+	ErrSellerNotFound         = errors.New("seller not found")
+	ErrUsernameTaken          = errors.New("the username is already taken")
+	ErrTransactionNotRateable = errors.New("only the buyer of a settled transaction with this seller can rate it")
+	ErrAlreadyRated           = errors.New("the transaction is already rated")
+)
+
+// Personal data
+var (
+	ErrExportNotFound = errors.New("no data export was requested")
+)
+
+// Rate limiting
+var (
+	ErrRateLimited = errors.New("too many requests, try again later")
+)
+
+// Webhooks
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Notifications
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// Alerts
+var (
+	ErrAlertNotFound         = errors.New("alert not found")
+	ErrWatchlistItemNotFound = errors.New("bond isn't in the watchlist")
+	ErrTooManyAlerts         = errors.New("the maximum number of alerts has been reached")
+)
+
+// Jobs
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("the job is already running")
+)
+
+// Tasks
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskLeaseLost the visibility timeout of the claim passed and the task
+	// was claimed again, the worker that lost it must not store its outcome
+	ErrTaskLeaseLost = errors.New("task lease lost")
 )
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"kiramishima/m-backend/internal/core/domain"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cookies of the browser session mode. AccessCookie is the name jwtauth.Verifier
+// looks for, so every JWT route accepts it without changes.
+const (
+	AccessCookie  = "jwt"
+	RefreshCookie = "refresh_token"
+	CSRFCookie    = "csrf_token"
+	// SessionModeHeader asks for the cookie session mode with the value "cookie"
+	SessionModeHeader = "X-Session-Mode"
+)
+
+// refreshCookiePath keeps the refresh token out of every request but the auth ones
+const refreshCookiePath = "/v1/auth"
+
+// SessionCookies writes and clears the cookies of the browser session mode
+type SessionCookies struct {
+	enabled    bool
+	domain     string
+	secure     bool
+	sameSite   http.SameSite
+	refreshTTL time.Duration
+}
+
+// NewSessionCookies creates the cookie writer, refreshTTL is in seconds. The CSRF
+// token lives as long as the refresh token and is renewed on every refresh.
+func NewSessionCookies(cfg domain.CookieSessions, refreshTTL int) *SessionCookies {
+	var sameSite = http.SameSiteLaxMode
+	switch strings.ToLower(cfg.CookieSameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return &SessionCookies{
+		enabled: cfg.CookieSessionsEnabled,
+		domain:  cfg.CookieDomain,
+		// browsers drop SameSite=None cookies that are not Secure
+		secure:     cfg.CookieSecure || sameSite == http.SameSiteNoneMode,
+		sameSite:   sameSite,
+		refreshTTL: time.Duration(refreshTTL) * time.Second,
+	}
+}
+
+// Enabled the cookie session mode is turned on
+func (c *SessionCookies) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Requested the request asked for the cookie session mode and it is enabled
+func (c *SessionCookies) Requested(req *http.Request) bool {
+	return c.Enabled() && strings.EqualFold(req.Header.Get(SessionModeHeader), "cookie")
+}
+
+// Write moves the tokens of resp to HttpOnly cookies and sets a new CSRF token,
+// a response without an access token (2FA challenge) is left as it is
+func (c *SessionCookies) Write(w http.ResponseWriter, resp *domain.AuthResponse) error {
+	if resp.Token == "" {
+		return nil
+	}
+	csrf, err := GenerateCSRFToken(c.refreshTTL)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, c.cookie(AccessCookie, resp.Token, "/", TokenTTL(), true))
+	if resp.RefreshToken != "" {
+		http.SetCookie(w, c.cookie(RefreshCookie, resp.RefreshToken, refreshCookiePath, c.refreshTTL, true))
+	}
+	http.SetCookie(w, c.cookie(CSRFCookie, csrf, "/", c.refreshTTL, false))
+
+	resp.Token = ""
+	resp.RefreshToken = ""
+	resp.CSRFToken = csrf
+	return nil
+}
+
+// Clear expires every cookie of the session
+func (c *SessionCookies) Clear(w http.ResponseWriter) {
+	if c == nil {
+		return
+	}
+	http.SetCookie(w, c.cookie(AccessCookie, "", "/", -1, true))
+	http.SetCookie(w, c.cookie(RefreshCookie, "", refreshCookiePath, -1, true))
+	http.SetCookie(w, c.cookie(CSRFCookie, "", "/", -1, false))
+}
+
+// cookie the CSRF cookie is not HttpOnly, the web app reads it to fill the header
+func (c *SessionCookies) cookie(name string, value string, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	var maxAge = int(ttl / time.Second)
+	if ttl < 0 {
+		maxAge = -1
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.domain,
+		MaxAge:   maxAge,
+		Secure:   c.secure,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite,
+	}
+}
+
+// OIDCStateCookie binds the state of an SSO login to the browser that started
+// it, the callback is refused when the cookie doesn't match the state. Lax is
+// needed for the cookie to come back on the redirect of the provider.
+const (
+	OIDCStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/v1/auth/oidc"
+)
+
+// SetOIDCStateCookie stores the state of a login that just started
+func SetOIDCStateCookie(w http.ResponseWriter, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookie,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearOIDCStateCookie removes the state cookie, a state is used once
+func ClearOIDCStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookie,
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// OIDCStateMatches reports whether the state cookie of the request is state
+func OIDCStateMatches(req *http.Request, state string) bool {
+	cookie, err := req.Cookie(OIDCStateCookie)
+	if err != nil || cookie.Value == "" || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) == 1
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	httpErrors "kiramishima/m-backend/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSRFHeader header that carries the double-submit token, it must match the CSRFCookie
+const CSRFHeader = "X-CSRF-Token"
+
+// csrfKey signs the CSRF tokens so a forged cookie is rejected even when it
+// matches the header
+var csrfKey = deriveKey(privateKey, "csrf")
+
+// GenerateCSRFToken returns a random token signed with its expiry, "<nonce>.<expires>.<signature>"
+func GenerateCSRFToken(ttl time.Duration) (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	exp := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+
+	return nonce + "." + exp + "." + csrfSignature(nonce, exp), nil
+}
+
+// ValidateCSRFToken checks the signature and the expiry of a token of GenerateCSRFToken
+func ValidateCSRFToken(token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return httpErrors.WrongCSRFToken
+	}
+	if !hmac.Equal([]byte(csrfSignature(parts[0], parts[1])), []byte(parts[2])) {
+		return httpErrors.WrongCSRFToken
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return httpErrors.WrongCSRFToken
+	}
+	if now.Unix() > exp {
+		return httpErrors.ExpiredCSRFError
+	}
+	return nil
+}
+
+func csrfSignature(nonce string, expires string) string {
+	mac := hmac.New(sha256.New, csrfKey)
+	mac.Write([]byte(nonce))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -5,10 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 )
 
+// ClientIP returns the address of the client without the port.
+// RemoteAddr is already rewritten by the RealIP middleware when the request
+// comes through a trusted proxy (TRUSTED_PROXIES).
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // ReadJSON el body contiene un campo que no puede ser mapeado
 func ReadJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	// usamos MaxBytesReader para limitar el tamaño del request body a 1MB
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"kiramishima/m-backend/internal/core/domain"
+	httpErrors "kiramishima/m-backend/pkg/errors"
 	"net/http"
 	"os"
 	"strconv"
@@ -14,58 +17,88 @@ import (
 )
 
 var privateKey = []byte(os.Getenv("JWT_PRIVATE_KEY"))
-var TokenAuth = jwtauth.New("HS256", []byte(os.Getenv("JWT_PRIVATE_KEY")), nil)
+var TokenAuth = jwtauth.New("HS256", privateKey, nil)
 
-// GenerateJWT generate JWT token
-func GenerateJWT(user *domain.User) (string, error) {
-	tokenTTL, _ := strconv.Atoi(os.Getenv("TOKEN_TTL"))
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  user.ID,
-		"iat": time.Now().Unix(),
-		"eat": time.Now().Add(time.Second * time.Duration(tokenTTL)).Unix(),
-	})
-	return token.SignedString(privateKey)
-}
+// challengeKey signs the 2FA challenge tokens. It differs from privateKey so
+// a challenge token is never accepted as an access token.
+var challengeKey = deriveKey(privateKey, "2fa-challenge")
 
-// ValidateJWT validate JWT token
-func ValidateJWT(req *http.Request) error {
-	token, err := getToken(req)
+const challengePurpose = "2fa"
+
+// GenerateJWT generate JWT token, sid is the session of the refresh token (0 for none)
+func GenerateJWT(user *domain.User, sid int) (string, error) {
+	userID, err := strconv.Atoi(user.ID)
 	if err != nil {
-		return err
+		return "", httpErrors.InvalidJWTClaims
 	}
-	_, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		return nil
+	roles := user.Roles
+	if roles == nil {
+		roles = make([]string, 0)
 	}
-	return errors.New("invalid token provided")
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"roles":   roles,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(TokenTTL()).Unix(),
+	}
+	if sid > 0 {
+		claims["sid"] = sid
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(privateKey)
 }
 
-// ValidateAdminRoleJWT validate Admin role
-func ValidateAdminRoleJWT(req *http.Request) error {
-	token, err := getToken(req)
+// TokenTTL lifetime of the access tokens, TOKEN_TTL seconds or an hour
+func TokenTTL() time.Duration {
+	tokenTTL, _ := strconv.Atoi(os.Getenv("TOKEN_TTL"))
+	if tokenTTL <= 0 {
+		tokenTTL = 3600
+	}
+	return time.Duration(tokenTTL) * time.Second
+}
+
+// GenerateChallengeJWT generate the short-lived token that identifies a
+// sign in waiting for its second factor
+func GenerateChallengeJWT(uid int, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": uid,
+		"purpose": challengePurpose,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+	return token.SignedString(challengeKey)
+}
+
+// ParseChallengeJWT validates a challenge token and returns its user id
+func ParseChallengeJWT(tokenString string) (int, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return challengeKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
 	if err != nil {
-		return err
+		return 0, httpErrors.InvalidJWTToken
 	}
 	claims, ok := token.Claims.(jwt.MapClaims)
-	userRole := uint(claims["role"].(float64))
-	if ok && token.Valid && userRole == 1 {
-		return nil
+	if !ok || claims["purpose"] != challengePurpose {
+		return 0, httpErrors.InvalidJWTClaims
+	}
+	uid, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, httpErrors.InvalidJWTClaims
 	}
-	return errors.New("invalid admin token provided")
+	return int(uid), nil
 }
 
-// ValidateCustomerRoleJWT validate Customer role
-func ValidateCustomerRoleJWT(req *http.Request) error {
+// ValidateJWT validate JWT token
+func ValidateJWT(req *http.Request) error {
 	token, err := getToken(req)
 	if err != nil {
 		return err
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
-	userRole := uint(claims["role"].(float64))
-	if ok && token.Valid && userRole == 2 || userRole == 1 {
+	_, ok := token.Claims.(jwt.MapClaims)
+	if ok && token.Valid {
 		return nil
 	}
-	return errors.New("invalid customer or admin token provided")
+	return errors.New("invalid token provided")
 }
 
 func GetUserIDInJWTHeader(req *http.Request) int {
@@ -74,6 +107,29 @@ func GetUserIDInJWTHeader(req *http.Request) int {
 	return int(ID)
 }
 
+// GetSessionIDInJWTHeader returns the session of the token, 0 when it has none
+func GetSessionIDInJWTHeader(req *http.Request) int {
+	_, decoded, _ := jwtauth.FromContext(req.Context())
+	sid, _ := decoded["sid"].(float64)
+	return int(sid)
+}
+
+// GetRolesInJWTHeader returns the roles embedded in the token
+func GetRolesInJWTHeader(req *http.Request) []string {
+	_, decoded, _ := jwtauth.FromContext(req.Context())
+	var roles = make([]string, 0)
+	claim, ok := decoded["roles"].([]interface{})
+	if !ok {
+		return roles
+	}
+	for _, role := range claim {
+		if name, ok := role.(string); ok {
+			roles = append(roles, name)
+		}
+	}
+	return roles
+}
+
 // getToken check token validity
 func getToken(req *http.Request) (*jwt.Token, error) {
 	tokenString := getTokenFromRequest(req)
@@ -96,3 +152,10 @@ func getTokenFromRequest(req *http.Request) string {
 	}
 	return ""
 }
+
+// deriveKey derives a signing key for a specific kind of token
+func deriveKey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignURL returns baseURL/key with the expiry and an HMAC-SHA256 signature of both
+func SignURL(secret []byte, baseURL string, key string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", exp)
+	q.Set("signature", urlSignature(secret, key, exp))
+
+	return strings.TrimSuffix(baseURL, "/") + "/" + escapeKey(key) + "?" + q.Encode()
+}
+
+// VerifySignedURL checks the signature and the expiry of a key signed with SignURL
+func VerifySignedURL(secret []byte, key string, expires string, signature string, now time.Time) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return false
+	}
+	expected := urlSignature(secret, key, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func urlSignature(secret []byte, key string, expires string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escapeKey escapes every segment of the key, keeping the slashes
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}